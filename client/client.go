@@ -0,0 +1,305 @@
+// Package client is the Go client of the http api: typed methods, token refresh when the api answers 401
+// and retries with backoff of the idempotent calls.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultBackoff    = time.Millisecond * 100
+	DefaultMaxBackoff = time.Second * 5
+)
+
+// apiPrefix is the version of the api routes the client is written against
+const apiPrefix = "/v1"
+
+var ErrNoBaseURL = errors.New("client: base url is required")
+
+type Config struct {
+	// BaseURL of the api, e.g. http://localhost:8080
+	BaseURL    string
+	HTTPClient *http.Client
+
+	// Token to start with, optional when Username is set
+	Token string
+	// Username and Password log in again when the token is rejected, Login sets them too
+	Username string
+	Password string
+
+	// MaxRetries of idempotent calls failing with a network error, 429 or 5xx, DefaultMaxRetries when 0
+	// and none when negative
+	MaxRetries int
+	// Backoff before the first retry, doubled on every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	mu       sync.Mutex
+	token    string
+	username string
+	password string
+}
+
+func New(config *Config) (*Client, error) {
+	if config.BaseURL == "" {
+		return nil, ErrNoBaseURL
+	}
+
+	baseURL, err := url.Parse(strings.TrimRight(config.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base url: %w", err)
+	}
+
+	c := &Client{
+		baseURL:    baseURL,
+		httpClient: config.HTTPClient,
+		maxRetries: config.MaxRetries,
+		backoff:    config.Backoff,
+		maxBackoff: config.MaxBackoff,
+		token:      config.Token,
+		username:   config.Username,
+		password:   config.Password,
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.maxRetries == 0 {
+		c.maxRetries = DefaultMaxRetries
+	} else if c.maxRetries < 0 {
+		c.maxRetries = 0
+	}
+	if c.backoff <= 0 {
+		c.backoff = DefaultBackoff
+	}
+	if c.maxBackoff <= 0 {
+		c.maxBackoff = DefaultMaxBackoff
+	}
+
+	return c, nil
+}
+
+// Token returns the current token, it changes when the client logs in again
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.token
+}
+
+// request of the api, path is the route without apiPrefix, symbol and currency give context to the errors
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// accept is the media type of the response, JSON when empty
+	accept string
+
+	auth       bool
+	idempotent bool
+
+	symbol   string
+	currency string
+}
+
+func (c *Client) do(ctx context.Context, req *request, out interface{}) error {
+	resp, err := c.open(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer discard(resp)
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: invalid response of %s %s: %w", req.method, req.path, err)
+	}
+
+	return nil
+}
+
+// open sends req until it succeeds or fails for good, the caller closes the body of the response
+func (c *Client) open(ctx context.Context, req *request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, err
+		}
+	}
+
+	refreshed := false
+
+	for attempt := 0; ; attempt++ {
+		token := c.Token()
+
+		resp, err := c.send(ctx, req, body, token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			if req.idempotent && attempt < c.maxRetries {
+				if err := c.wait(ctx, attempt, 0); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			return nil, err
+		}
+
+		// the token expired or was revoked, log in again once and repeat the call
+		if resp.StatusCode == http.StatusUnauthorized && req.auth && !refreshed && c.canRefresh() {
+			discard(resp)
+			refreshed = true
+
+			if err := c.refresh(ctx, token); err != nil {
+				return nil, err
+			}
+
+			attempt--
+			continue
+		}
+
+		if retryable(resp.StatusCode) && req.idempotent && attempt < c.maxRetries {
+			retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+			discard(resp)
+
+			if err := c.wait(ctx, attempt, retryAfter); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode >= 400 {
+			defer discard(resp)
+			return nil, decodeError(resp, req)
+		}
+
+		return resp, nil
+	}
+}
+
+func (c *Client) send(ctx context.Context, req *request, body []byte, token string) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += apiPrefix + req.path
+	u.RawQuery = req.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	accept := req.accept
+	if accept == "" {
+		accept = "application/json"
+	}
+	r.Header.Set("Accept", accept)
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	// the api takes the token as the basic auth username
+	if req.auth && token != "" {
+		r.SetBasicAuth(token, "")
+	}
+
+	return c.httpClient.Do(r)
+}
+
+func (c *Client) canRefresh() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.username != ""
+}
+
+// refresh logs in again unless a concurrent call already replaced rejected
+func (c *Client) refresh(ctx context.Context, rejected string) error {
+	c.mu.Lock()
+	if c.token != rejected {
+		c.mu.Unlock()
+		return nil
+	}
+	username, password := c.username, c.password
+	c.mu.Unlock()
+
+	_, err := c.Login(ctx, username, password)
+
+	return err
+}
+
+// wait the backoff of attempt, or retryAfter when the api asks for longer
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	delay := c.backoff << uint(attempt)
+	if delay <= 0 || delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+
+	// jitter spreads the retries of concurrent clients
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+func parseRetryAfter(v string) time.Duration {
+	seconds, err := strconv.Atoi(v)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// discard lets the connection be reused
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	apihttp "github.com/falmar/richerage-api/internal/http"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// expired when the api checks expiration
+const testToken = "6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377"
+
+func newTestHandler(t *testing.T, v *viper.Viper) http.Handler {
+	ctx := context.Background()
+
+	v.Set("port", "8080")
+
+	config, err := bootstrap.New(ctx, v, zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := apihttp.Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	return handler
+}
+
+func newTestClient(t *testing.T, url string, config Config) *Client {
+	config.BaseURL = url
+	config.Backoff = time.Millisecond
+
+	c, err := New(&config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	return c
+}
+
+func TestClient_LoginTickersHistory(t *testing.T) {
+	handler := newTestHandler(t, viper.New())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the client avoids the deprecated unprefixed routes
+		if !strings.HasPrefix(r.URL.Path, "/v1/") {
+			t.Errorf("expected a /v1 route, got %s", r.URL.Path)
+		}
+
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := newTestClient(t, server.URL, Config{})
+
+	token, err := c.Login(ctx, "test", "test")
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if token == "" || c.Token() != token {
+		t.Errorf("expected the client to use the token, got %q and %q", token, c.Token())
+	}
+
+	tickers, err := c.Tickers(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if len(tickers) == 0 {
+		t.Fatal("expected the tickers of test, got none")
+	}
+
+	bars, err := c.History(ctx, tickers[0].Symbol, &HistoryOptions{Adjusted: AdjustmentSplit})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if len(bars) == 0 {
+		t.Fatalf("expected the history of %s, got none", tickers[0].Symbol)
+	}
+
+	for _, bar := range bars {
+		if bar.Date.IsZero() || bar.Symbol == "" || bar.Currency == "" {
+			t.Errorf("expected a complete bar, got %+v", bar)
+		}
+	}
+
+	if len(bars) < 2 {
+		return
+	}
+
+	before, err := c.History(ctx, tickers[0].Symbol, &HistoryOptions{Before: bars[0].Date.Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if len(before) != len(bars)-1 {
+		t.Errorf("expected %d bars before %s, got %d", len(bars)-1, bars[0].Date, len(before))
+	}
+}
+
+func TestClient_TypedErrors(t *testing.T) {
+	server := httptest.NewServer(newTestHandler(t, viper.New()))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := newTestClient(t, server.URL, Config{Token: testToken})
+
+	_, err := c.History(ctx, "NOPE", nil)
+	var notFound *ErrTickerNotFound
+	if !errors.As(err, &notFound) || notFound.Symbol != "NOPE" || notFound.Delisted {
+		t.Errorf("expected ErrTickerNotFound of NOPE, got %#v", err)
+	}
+
+	_, err = c.History(ctx, "TWTR", nil)
+	if !errors.As(err, &notFound) || !notFound.Delisted || notFound.Code() != "ticker_delisted" {
+		t.Errorf("expected delisted ErrTickerNotFound, got %#v", err)
+	}
+
+	_, err = c.Tickers(ctx, &TickersOptions{Currency: "XYZ"})
+	var currency *ErrCurrencyNotSupported
+	if !errors.As(err, &currency) || currency.Currency != "XYZ" {
+		t.Errorf("expected ErrCurrencyNotSupported of XYZ, got %#v", err)
+	}
+
+	_, err = c.History(ctx, "AAPL", &HistoryOptions{Resolution: "2d"})
+	var badRequest *BadRequestError
+	if !errors.As(err, &badRequest) || badRequest.Params["resolution"] == "" {
+		t.Errorf("expected BadRequestError of resolution, got %#v", err)
+	}
+
+	// no credentials to log in again
+	c = newTestClient(t, server.URL, Config{Token: "nope"})
+	_, err = c.Tickers(ctx, nil)
+	var invalid *ErrInvalidToken
+	if !errors.As(err, &invalid) {
+		t.Errorf("expected ErrInvalidToken, got %#v", err)
+	}
+}
+
+func TestClient_ExportHistory(t *testing.T) {
+	server := httptest.NewServer(newTestHandler(t, viper.New()))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := newTestClient(t, server.URL, Config{Token: testToken})
+
+	bars, err := c.History(ctx, "AAPL", nil)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	body, err := c.ExportHistory(ctx, []string{"AAPL"}, &ExportOptions{Format: ExportArrow})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer body.Close()
+
+	r, err := ipc.NewReader(body)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer r.Release()
+
+	var rows int64
+	for r.Next() {
+		rows += r.Record().NumRows()
+	}
+	if r.Err() != nil || rows != int64(len(bars)) {
+		t.Errorf("expected %d rows, got %d %v", len(bars), rows, r.Err())
+	}
+
+	_, err = c.ExportHistory(ctx, []string{"AAPL", "NOPE"}, nil)
+	var notFound *ErrTickerNotFound
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected ErrTickerNotFound, got %#v", err)
+	}
+}
+
+func TestClient_RefreshExpiredToken(t *testing.T) {
+	v := viper.New()
+	v.Set("token.expired", true)
+
+	var unauthorized int32
+	handler := newTestHandler(t, v)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		if rec.Code == http.StatusUnauthorized {
+			atomic.AddInt32(&unauthorized, 1)
+		}
+
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	c := newTestClient(t, server.URL, Config{Token: testToken})
+	_, err := c.Tickers(ctx, nil)
+	var expired *ErrExpiredToken
+	if !errors.As(err, &expired) {
+		t.Fatalf("expected ErrExpiredToken without credentials, got %#v", err)
+	}
+
+	atomic.StoreInt32(&unauthorized, 0)
+
+	c = newTestClient(t, server.URL, Config{Token: testToken, Username: "test", Password: "test"})
+	tickers, err := c.Tickers(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if len(tickers) == 0 {
+		t.Error("expected the tickers of test, got none")
+	}
+
+	if c.Token() == testToken {
+		t.Error("expected the client to log in again, got the expired token")
+	}
+	if n := atomic.LoadInt32(&unauthorized); n != 1 {
+		t.Errorf("expected a single 401, got %d", n)
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	handler := newTestHandler(t, viper.New())
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first two calls of every request fail
+		if atomic.AddInt32(&calls, 1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	c := newTestClient(t, server.URL, Config{Token: testToken})
+	if _, err := c.Tickers(ctx, nil); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("expected 3 calls, got %d", n)
+	}
+
+	// login is not idempotent
+	atomic.StoreInt32(&calls, 0)
+	_, err := c.Login(ctx, "test", "test")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.HttpCode() != http.StatusServiceUnavailable {
+		t.Errorf("expected Error of 503, got %#v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected 1 call, got %d", n)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	c = newTestClient(t, server.URL, Config{Token: testToken, MaxRetries: 1})
+	_, err = c.Tickers(ctx, nil)
+	if !errors.As(err, &apiErr) || apiErr.HttpCode() != http.StatusServiceUnavailable {
+		t.Errorf("expected Error of 503 after the retries, got %#v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+}
@@ -0,0 +1,241 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error is an error response of the api without a more specific type, the api body is kit.HttpErrorBody
+type Error struct {
+	StatusCode int
+	ErrCode    string
+	Message    string
+	Params     map[string]string
+}
+
+func (e *Error) HttpCode() int {
+	return e.StatusCode
+}
+
+func (e *Error) Code() string {
+	return e.ErrCode
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf("api error: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+type BadRequestError struct {
+	Params  map[string]string
+	Message string
+}
+
+func (e *BadRequestError) HttpCode() int {
+	return 400
+}
+
+func (e *BadRequestError) Code() string {
+	return "bad_request"
+}
+
+func (e *BadRequestError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return "bad request"
+}
+
+type ErrTickerNotFound struct {
+	Symbol string
+	// Delisted is set when the symbol existed but no longer trades
+	Delisted bool
+	Message  string
+}
+
+func (e *ErrTickerNotFound) HttpCode() int {
+	return 404
+}
+
+func (e *ErrTickerNotFound) Code() string {
+	if e.Delisted {
+		return "ticker_delisted"
+	}
+
+	return "ticker_not_found"
+}
+
+func (e *ErrTickerNotFound) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf("ticker %s not found", e.Symbol)
+}
+
+type ErrCurrencyNotSupported struct {
+	Currency string
+}
+
+func (e *ErrCurrencyNotSupported) HttpCode() int {
+	return 400
+}
+
+func (e *ErrCurrencyNotSupported) Code() string {
+	return "currency_not_supported"
+}
+
+func (e *ErrCurrencyNotSupported) Error() string {
+	return fmt.Sprintf("currency %s is not supported", e.Currency)
+}
+
+type ErrStreamUnavailable struct{}
+
+func (e *ErrStreamUnavailable) HttpCode() int {
+	return 503
+}
+
+func (e *ErrStreamUnavailable) Code() string {
+	return "stream_unavailable"
+}
+
+func (e *ErrStreamUnavailable) Error() string {
+	return "live prices are not available"
+}
+
+type ErrUnauthorized struct {
+	Message string
+}
+
+func (e *ErrUnauthorized) HttpCode() int {
+	return 401
+}
+
+func (e *ErrUnauthorized) Code() string {
+	return "unauthorized"
+}
+
+func (e *ErrUnauthorized) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return "unauthorized"
+}
+
+type ErrCredentialsMismatch struct{}
+
+func (e *ErrCredentialsMismatch) HttpCode() int {
+	return 401
+}
+
+func (e *ErrCredentialsMismatch) Code() string {
+	return "credentials_mismatch"
+}
+
+func (e *ErrCredentialsMismatch) Error() string {
+	return "invalid credentials"
+}
+
+type ErrInvalidToken struct {
+	Message string
+}
+
+func (e *ErrInvalidToken) HttpCode() int {
+	return 401
+}
+
+func (e *ErrInvalidToken) Code() string {
+	return "invalid_token"
+}
+
+func (e *ErrInvalidToken) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return "invalid token"
+}
+
+type ErrExpiredToken struct{}
+
+func (e *ErrExpiredToken) HttpCode() int {
+	return 401
+}
+
+func (e *ErrExpiredToken) Code() string {
+	return "expired_token"
+}
+
+func (e *ErrExpiredToken) Error() string {
+	return "token expired"
+}
+
+type ErrForbidden struct {
+	Message string
+}
+
+func (e *ErrForbidden) HttpCode() int {
+	return 403
+}
+
+func (e *ErrForbidden) Code() string {
+	return "forbidden"
+}
+
+func (e *ErrForbidden) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return "forbidden"
+}
+
+// errorBody mirrors kit.HttpErrorBody
+type errorBody struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Params  map[string]string `json:"params,omitempty"`
+}
+
+// decodeError converts the error response into the error type of its code, *Error when there is none
+func decodeError(resp *http.Response, req *request) error {
+	body := errorBody{}
+
+	b, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(b, &body)
+
+	switch body.Code {
+	case "bad_request":
+		return &BadRequestError{Params: body.Params, Message: body.Message}
+	case "ticker_not_found", "ticker_delisted":
+		return &ErrTickerNotFound{Symbol: req.symbol, Delisted: body.Code == "ticker_delisted", Message: body.Message}
+	case "currency_not_supported":
+		return &ErrCurrencyNotSupported{Currency: req.currency}
+	case "stream_unavailable":
+		return &ErrStreamUnavailable{}
+	case "unauthorized":
+		return &ErrUnauthorized{Message: body.Message}
+	case "credentials_mismatch":
+		return &ErrCredentialsMismatch{}
+	case "invalid_token":
+		return &ErrInvalidToken{Message: body.Message}
+	case "expired_token":
+		return &ErrExpiredToken{}
+	case "forbidden":
+		return &ErrForbidden{Message: body.Message}
+	}
+
+	return &Error{
+		StatusCode: resp.StatusCode,
+		ErrCode:    body.Code,
+		Message:    body.Message,
+		Params:     body.Params,
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ExportFormat is the file format of an exported history
+type ExportFormat string
+
+const (
+	ExportParquet ExportFormat = "parquet"
+	ExportArrow   ExportFormat = "arrow"
+)
+
+type ExportOptions struct {
+	// From and To are the first and last dates exported, unbounded when zero
+	From time.Time
+	To   time.Time
+	// Format of the file, parquet when empty
+	Format ExportFormat
+}
+
+// ExportHistory downloads the daily bars of symbols oldest first as an Arrow IPC stream or a Parquet file,
+// the caller reads the file from the returned body and closes it
+//
+// an export failing midway ends the body with an error, ErrTickerNotFound of an unknown symbol
+// only names it in its message
+func (c *Client) ExportHistory(ctx context.Context, symbols []string, opts *ExportOptions) (io.ReadCloser, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+
+	query := url.Values{}
+	query.Set("symbols", strings.Join(symbols, ","))
+	if !opts.From.IsZero() {
+		query.Set("from", opts.From.Format("2006-01-02"))
+	}
+	if !opts.To.IsZero() {
+		query.Set("to", opts.To.Format("2006-01-02"))
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = ExportParquet
+	}
+	query.Set("format", string(format))
+
+	accept := "application/vnd.apache.parquet"
+	if format == ExportArrow {
+		accept = "application/vnd.apache.arrow.stream"
+	}
+
+	resp, err := c.open(ctx, &request{
+		method:     http.MethodGet,
+		path:       "/export/history",
+		query:      query,
+		accept:     accept,
+		auth:       true,
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Decimal is an exact decimal, prices keep the scale sent by the api
+type Decimal = decimal.Decimal
+
+type Adjustment string
+
+const (
+	AdjustmentNone  Adjustment = "none"
+	AdjustmentSplit Adjustment = "split"
+	AdjustmentTotal Adjustment = "total"
+)
+
+type Resolution string
+
+const (
+	Resolution1m  Resolution = "1m"
+	Resolution5m  Resolution = "5m"
+	Resolution15m Resolution = "15m"
+	Resolution1h  Resolution = "1h"
+	Resolution1d  Resolution = "1d"
+)
+
+type Ticker struct {
+	Symbol   string  `json:"symbol"`
+	Price    Decimal `json:"price"`
+	Currency string  `json:"currency"`
+}
+
+// Bar is a history record, Price is the close
+type Bar struct {
+	Symbol   string    `json:"symbol"`
+	Date     time.Time `json:"date"`
+	Price    Decimal   `json:"price"`
+	Open     Decimal   `json:"open"`
+	High     Decimal   `json:"high"`
+	Low      Decimal   `json:"low"`
+	Volume   int64     `json:"volume"`
+	Currency string    `json:"currency"`
+}
+
+// UnmarshalJSON reads daily dates (2006-01-02) and the RFC3339 times of sub-daily bars
+func (b *Bar) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Symbol   string  `json:"symbol"`
+		Date     string  `json:"date"`
+		Price    Decimal `json:"price"`
+		Open     Decimal `json:"open"`
+		High     Decimal `json:"high"`
+		Low      Decimal `json:"low"`
+		Volume   int64   `json:"volume"`
+		Currency string  `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	date, err := time.Parse("2006-01-02", raw.Date)
+	if err != nil {
+		if date, err = time.Parse(time.RFC3339, raw.Date); err != nil {
+			return fmt.Errorf("invalid bar date %q", raw.Date)
+		}
+	}
+
+	*b = Bar{
+		Symbol:   raw.Symbol,
+		Date:     date,
+		Price:    raw.Price,
+		Open:     raw.Open,
+		High:     raw.High,
+		Low:      raw.Low,
+		Volume:   raw.Volume,
+		Currency: raw.Currency,
+	}
+
+	return nil
+}
+
+type TickersOptions struct {
+	// Currency prices are converted to, the trading currency of each symbol when empty
+	Currency string
+}
+
+type HistoryOptions struct {
+	// Before only returns the bars before this time, all of them when zero
+	Before     time.Time
+	Adjusted   Adjustment
+	Currency   string
+	Resolution Resolution
+}
+
+// Login gets a token for the credentials, the client uses it from then on and logs in again with them
+// when the token is rejected
+func (c *Client) Login(ctx context.Context, username string, password string) (string, error) {
+	var res struct {
+		Token string `json:"token"`
+	}
+
+	err := c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   "/login",
+		body: map[string]string{
+			"username": username,
+			"password": password,
+		},
+	}, &res)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.token = res.Token
+	c.username = username
+	c.password = password
+	c.mu.Unlock()
+
+	return res.Token, nil
+}
+
+// Tickers lists the tickers of the user with their latest price
+func (c *Client) Tickers(ctx context.Context, opts *TickersOptions) ([]Ticker, error) {
+	if opts == nil {
+		opts = &TickersOptions{}
+	}
+
+	query := url.Values{}
+	if opts.Currency != "" {
+		query.Set("currency", opts.Currency)
+	}
+
+	var tickers []Ticker
+
+	err := c.do(ctx, &request{
+		method:     http.MethodGet,
+		path:       "/tickers",
+		query:      query,
+		auth:       true,
+		idempotent: true,
+		currency:   opts.Currency,
+	}, &tickers)
+
+	return tickers, err
+}
+
+// History returns the bars of symbol newest first
+func (c *Client) History(ctx context.Context, symbol string, opts *HistoryOptions) ([]Bar, error) {
+	if opts == nil {
+		opts = &HistoryOptions{}
+	}
+
+	query := url.Values{}
+	if !opts.Before.IsZero() {
+		query.Set("before", opts.Before.Format(time.RFC3339))
+	}
+	if opts.Adjusted != "" {
+		query.Set("adjusted", string(opts.Adjusted))
+	}
+	if opts.Currency != "" {
+		query.Set("currency", opts.Currency)
+	}
+	if opts.Resolution != "" {
+		query.Set("resolution", string(opts.Resolution))
+	}
+
+	var bars []Bar
+
+	err := c.do(ctx, &request{
+		method:     http.MethodGet,
+		path:       "/tickers/" + symbol + "/history",
+		query:      query,
+		auth:       true,
+		idempotent: true,
+		symbol:     symbol,
+		currency:   opts.Currency,
+	}, &bars)
+
+	return bars, err
+}
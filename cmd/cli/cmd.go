@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/falmar/richerage-api/client"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flags shared by the client commands
+type flags struct {
+	server string
+	config string
+	output string
+}
+
+func (f *flags) bind(cmd *cobra.Command, output bool) {
+	cmd.Flags().StringVar(&f.server, "server", "", "url of the api, the one of the last login or "+defaultServer+" when empty")
+	cmd.Flags().StringVar(&f.config, "config", defaultConfigPath(), "file caching the server and token")
+	if output {
+		cmd.Flags().StringVarP(&f.output, "output", "o", outputTable, "output format: table, json or csv")
+	}
+}
+
+// client of the server of the flags or the config file, authenticated with the cached token
+func (f *flags) client() (*client.Client, *fileConfig, error) {
+	cfg, err := readConfig(f.config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read config %s: %w", f.config, err)
+	}
+
+	if f.server != "" {
+		cfg.Server = f.server
+	} else if cfg.Server == "" {
+		cfg.Server = defaultServer
+	}
+
+	c, err := client.New(&client.Config{
+		BaseURL: cfg.Server,
+		Token:   cfg.Token,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c, cfg, nil
+}
+
+func LoginCmd(_ context.Context, config *bootstrap.Config) *cobra.Command {
+	var f flags
+	var username, password string
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in to a running server and cache the token",
+		Long: `Log in to a running server, the server and token are cached in --config for the tickers and
+history commands. Username and password are prompted for when not given, the password is never
+stored: log in again once the token expires.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, cfg, err := f.client()
+			if err != nil {
+				return err
+			}
+
+			in := bufio.NewReader(cmd.InOrStdin())
+			if username == "" {
+				if username, err = prompt(cmd.ErrOrStderr(), in, "Username: "); err != nil {
+					return err
+				}
+			}
+			if password == "" {
+				if password, err = promptPassword(cmd.ErrOrStderr(), in, "Password: "); err != nil {
+					return err
+				}
+			}
+
+			token, err := c.Login(cmd.Context(), username, password)
+			if err != nil {
+				return err
+			}
+
+			cfg.Username = username
+			cfg.Token = token
+			if err := writeConfig(f.config, cfg); err != nil {
+				return err
+			}
+
+			config.Logger.Debug("login: token saved", zap.String("config", f.config))
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "logged in to %s as %s\n", cfg.Server, username)
+
+			return err
+		},
+	}
+
+	f.bind(cmd, false)
+	cmd.Flags().StringVarP(&username, "username", "u", "", "username, prompted for when empty")
+	cmd.Flags().StringVar(&password, "password", "", "password, prompted for when empty")
+
+	return cmd
+}
+
+func TickersCmd(_ context.Context, _ *bootstrap.Config) *cobra.Command {
+	var f flags
+	var currency string
+
+	cmd := &cobra.Command{
+		Use:          "tickers",
+		Short:        "List the tickers of the logged in user",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := checkOutput(f.output); err != nil {
+				return err
+			}
+
+			c, _, err := f.client()
+			if err != nil {
+				return err
+			}
+			if c.Token() == "" {
+				return errNotLoggedIn
+			}
+
+			tickers, err := c.Tickers(cmd.Context(), &client.TickersOptions{Currency: currency})
+			if err != nil {
+				return loginError(err)
+			}
+
+			rows := make([][]string, 0, len(tickers))
+			for _, t := range tickers {
+				rows = append(rows, []string{t.Symbol, t.Price.String(), t.Currency})
+			}
+
+			return printRows(cmd.OutOrStdout(), f.output, []string{"SYMBOL", "PRICE", "CURRENCY"}, rows, tickers)
+		},
+	}
+
+	f.bind(cmd, true)
+	cmd.Flags().StringVar(&currency, "currency", "", "convert prices to this currency")
+
+	return cmd
+}
+
+func HistoryCmd(_ context.Context, _ *bootstrap.Config) *cobra.Command {
+	var f flags
+	var before, adjusted, currency, resolution string
+	var limit, width int
+	var chart bool
+
+	cmd := &cobra.Command{
+		Use:   "history SYMBOL",
+		Short: "Print the history of a ticker",
+		Long: `Print the bars of a ticker newest first, or with --chart a sparkline of the close prices
+oldest first.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkOutput(f.output); err != nil {
+				return err
+			}
+
+			opts := &client.HistoryOptions{
+				Adjusted:   client.Adjustment(adjusted),
+				Currency:   currency,
+				Resolution: client.Resolution(resolution),
+			}
+
+			if before != "" {
+				t, err := parseTime(before)
+				if err != nil {
+					return err
+				}
+				opts.Before = t
+			}
+
+			c, _, err := f.client()
+			if err != nil {
+				return err
+			}
+			if c.Token() == "" {
+				return errNotLoggedIn
+			}
+
+			bars, err := c.History(cmd.Context(), args[0], opts)
+			if err != nil {
+				return loginError(err)
+			}
+
+			if limit > 0 && len(bars) > limit {
+				bars = bars[:limit]
+			}
+
+			if chart {
+				return printChart(cmd.OutOrStdout(), args[0], bars, width)
+			}
+
+			// sub-daily bars need the time of day
+			dateFormat := "2006-01-02"
+			if opts.Resolution != "" && opts.Resolution != client.Resolution1d {
+				dateFormat = time.RFC3339
+			}
+
+			rows := make([][]string, 0, len(bars))
+			for _, b := range bars {
+				rows = append(rows, []string{
+					b.Date.Format(dateFormat),
+					b.Symbol,
+					b.Open.String(),
+					b.High.String(),
+					b.Low.String(),
+					b.Price.String(),
+					strconv.FormatInt(b.Volume, 10),
+					b.Currency,
+				})
+			}
+
+			return printRows(cmd.OutOrStdout(), f.output, []string{"DATE", "SYMBOL", "OPEN", "HIGH", "LOW", "CLOSE", "VOLUME", "CURRENCY"}, rows, bars)
+		},
+	}
+
+	f.bind(cmd, true)
+	cmd.Flags().StringVar(&before, "before", "", "only bars before this date (2006-01-02) or time (RFC3339)")
+	cmd.Flags().StringVar(&adjusted, "adjusted", "", "adjustment for corporate actions: none, split or total")
+	cmd.Flags().StringVar(&currency, "currency", "", "convert prices to this currency")
+	cmd.Flags().StringVar(&resolution, "resolution", "", "bar size: 1m, 5m, 15m, 1h or 1d")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "only the latest n bars, all when 0")
+	cmd.Flags().BoolVar(&chart, "chart", false, "draw a sparkline of the close prices instead")
+	cmd.Flags().IntVar(&width, "width", 60, "characters of the sparkline")
+
+	return cmd
+}
+
+var errNotLoggedIn = errors.New("not logged in, run login first")
+
+// loginError tells to log in again when the cached token is rejected
+func loginError(err error) error {
+	var expired *client.ErrExpiredToken
+	var invalid *client.ErrInvalidToken
+	if errors.As(err, &expired) || errors.As(err, &invalid) {
+		return fmt.Errorf("%w, run login again", err)
+	}
+
+	return err
+}
+
+func printChart(w io.Writer, symbol string, bars []client.Bar, width int) error {
+	if len(bars) == 0 {
+		_, err := fmt.Fprintf(w, "%s: no history\n", symbol)
+		return err
+	}
+
+	// bars are newest first, the chart reads left to right
+	closes := make([]float64, len(bars))
+	low, high := bars[0].Price, bars[0].Price
+	for i, b := range bars {
+		closes[len(bars)-1-i] = b.Price.Float64()
+
+		if b.Price.Cmp(low) < 0 {
+			low = b.Price
+		}
+		if b.Price.Cmp(high) > 0 {
+			high = b.Price
+		}
+	}
+
+	first, last := bars[len(bars)-1], bars[0]
+
+	change := 0.0
+	if !first.Price.IsZero() {
+		change = (last.Price.Float64()/first.Price.Float64() - 1) * 100
+	}
+
+	_, err := fmt.Fprintf(w, "%s %s  %s → %s  (%+.2f%%)  low %s  high %s  %s\n%s\n",
+		symbol, last.Currency,
+		first.Price, last.Price, change,
+		low, high,
+		first.Date.Format("2006-01-02")+" .. "+last.Date.Format("2006-01-02"),
+		sparkline(closes, width),
+	)
+
+	return err
+}
+
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected 2006-01-02 or RFC3339", v)
+	}
+
+	return t, nil
+}
+
+func prompt(w io.Writer, in *bufio.Reader, label string) (string, error) {
+	fmt.Fprint(w, label)
+
+	line, err := in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// promptPassword does not echo on a terminal
+func promptPassword(w io.Writer, in *bufio.Reader, label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt(w, in, label)
+	}
+
+	fmt.Fprint(w, label)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(w)
+
+	return string(b), err
+}
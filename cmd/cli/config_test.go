@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "richerage", "config.json")
+
+	// a missing file is an empty config
+	cfg, err := readConfig(path)
+	if err != nil || *cfg != (fileConfig{}) {
+		t.Fatalf("expected an empty config, got %+v %v", cfg, err)
+	}
+
+	expected := fileConfig{
+		Server:   "http://localhost:8080",
+		Username: "test",
+		Token:    "6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377",
+	}
+	if err := writeConfig(path, &expected); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	cfg, err = readConfig(path)
+	if err != nil || *cfg != expected {
+		t.Errorf("expected %+v, got %+v %v", expected, cfg, err)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	// no temporary file is left behind
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("expected only the config file, got %d entries", len(entries))
+	}
+}
+
+func TestConfig_Permissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	// written by an older version, readable by everyone
+	if err := os.WriteFile(path, []byte(`{"server":"http://localhost:8080"}`), 0o644); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	_ = os.Chmod(path, 0o644)
+
+	if err := writeConfig(path, &fileConfig{Server: "http://localhost:8080", Token: "token"}); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the mode tightened to 0600, got %v", info.Mode().Perm())
+	}
+
+	if cfg, _ := readConfig(path); cfg == nil || cfg.Token != "token" {
+		t.Errorf("expected the token written, got %+v", cfg)
+	}
+}
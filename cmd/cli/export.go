@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/client"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func ExportHistoryCmd(_ context.Context, _ *bootstrap.Config) *cobra.Command {
+	var f flags
+	var from, to, format, file string
+
+	cmd := &cobra.Command{
+		Use:   "export-history SYMBOL...",
+		Short: "Download the history of tickers as a Parquet or Arrow file",
+		Long: `Download the daily bars of the symbols oldest first, as a Parquet file or an Arrow IPC stream
+with the columns symbol, date, open, high, low, close, volume and currency.
+
+The format defaults to the extension of --file (.parquet, .arrow or .arrows), parquet otherwise.
+The file is written as it is downloaded, a failed download leaves no partial file behind.`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := &client.ExportOptions{
+				Format: client.ExportFormat(format),
+			}
+
+			if opts.Format == "" {
+				opts.Format = client.ExportParquet
+				if ext := filepath.Ext(file); ext == ".arrow" || ext == ".arrows" {
+					opts.Format = client.ExportArrow
+				}
+			}
+			if opts.Format != client.ExportParquet && opts.Format != client.ExportArrow {
+				return fmt.Errorf("unsupported format %s, expected parquet or arrow", format)
+			}
+
+			if from != "" {
+				t, err := parseTime(from)
+				if err != nil {
+					return err
+				}
+				opts.From = t
+			}
+			if to != "" {
+				t, err := parseTime(to)
+				if err != nil {
+					return err
+				}
+				opts.To = t
+			}
+
+			if file == "" {
+				file = "history.parquet"
+				if opts.Format == client.ExportArrow {
+					file = "history.arrows"
+				}
+			}
+
+			c, _, err := f.client()
+			if err != nil {
+				return err
+			}
+			if c.Token() == "" {
+				return errNotLoggedIn
+			}
+
+			symbols := make([]string, 0, len(args))
+			for _, arg := range args {
+				symbols = append(symbols, strings.Split(arg, ",")...)
+			}
+
+			body, err := c.ExportHistory(cmd.Context(), symbols, opts)
+			if err != nil {
+				return loginError(err)
+			}
+			defer body.Close()
+
+			if file == "-" {
+				_, err = io.Copy(cmd.OutOrStdout(), body)
+				return err
+			}
+
+			n, err := writeFile(file, body)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s: %d bytes\n", file, n)
+			return err
+		},
+	}
+
+	f.bind(cmd, false)
+	cmd.Flags().StringVar(&from, "from", "", "first date exported (2006-01-02)")
+	cmd.Flags().StringVar(&to, "to", "", "last date exported (2006-01-02)")
+	cmd.Flags().StringVar(&format, "format", "", "file format: parquet or arrow, by the extension of --file when empty")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "file written, history.parquet or history.arrows when empty, - for stdout")
+
+	return cmd
+}
+
+// writeFile copies r next to path and renames it once complete
+func writeFile(path string, r io.Reader) (int64, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return n, err
+	}
+
+	if err := f.Close(); err != nil {
+		return n, err
+	}
+
+	return n, os.Rename(f.Name(), path)
+}
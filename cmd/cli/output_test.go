@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintRows(t *testing.T) {
+	header := []string{"symbol", "price"}
+	rows := [][]string{{"AAPL", "190.12"}, {"KO", "60"}}
+	v := []map[string]interface{}{{"symbol": "AAPL", "price": 190.12}, {"symbol": "KO", "price": 60}}
+
+	cases := []struct {
+		output   string
+		expected string
+	}{
+		{output: outputTable, expected: "symbol  price\nAAPL    190.12\nKO      60\n"},
+		{output: outputCSV, expected: "symbol,price\nAAPL,190.12\nKO,60\n"},
+		{output: outputJSON, expected: "[\n  {\n    \"price\": 190.12,\n    \"symbol\": \"AAPL\"\n  },\n  {\n    \"price\": 60,\n    \"symbol\": \"KO\"\n  }\n]\n"},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := printRows(&buf, c.output, header, rows, v); err != nil {
+			t.Errorf("%s: unexpected error to be nil, got: %v", c.output, err)
+			continue
+		}
+
+		if buf.String() != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.output, c.expected, buf.String())
+		}
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	for _, output := range []string{outputTable, outputJSON, outputCSV} {
+		if err := checkOutput(output); err != nil {
+			t.Errorf("%s: unexpected error to be nil, got: %v", output, err)
+		}
+	}
+
+	if err := checkOutput("yaml"); err == nil {
+		t.Errorf("expected yaml to be unknown")
+	}
+}
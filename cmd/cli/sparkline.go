@@ -0,0 +1,48 @@
+package cli
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values in at most width characters, the values of each character are averaged and
+// a flat series is drawn at mid height
+func sparkline(values []float64, width int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	if width <= 0 || width > len(values) {
+		width = len(values)
+	}
+
+	points := make([]float64, width)
+	for i := range points {
+		from := i * len(values) / width
+		to := (i + 1) * len(values) / width
+
+		sum := 0.0
+		for _, v := range values[from:to] {
+			sum += v
+		}
+		points[i] = sum / float64(to-from)
+	}
+
+	low, high := points[0], points[0]
+	for _, p := range points {
+		if p < low {
+			low = p
+		}
+		if p > high {
+			high = p
+		}
+	}
+
+	line := make([]rune, width)
+	for i, p := range points {
+		level := len(sparks) / 2
+		if high > low {
+			level = int((p - low) / (high - low) * float64(len(sparks)-1))
+		}
+
+		line[i] = sparks[level]
+	}
+
+	return string(line)
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   []float64
+		width    int
+		expected string
+	}{
+		{name: "empty", values: nil, width: 10, expected: ""},
+		{name: "a level per value", values: []float64{1, 2, 3, 4, 5, 6, 7, 8}, width: 8, expected: "▁▂▃▄▅▆▇█"},
+		{name: "buckets are averaged", values: []float64{1, 2, 3, 4}, width: 2, expected: "▁█"},
+		{name: "uneven buckets", values: []float64{1, 1, 1, 9, 9}, width: 2, expected: "▁█"},
+		{name: "flat series at mid height", values: []float64{5, 5, 5}, width: 3, expected: "▅▅▅"},
+		{name: "width larger than the data", values: []float64{1, 2}, width: 10, expected: "▁█"},
+		{name: "no width", values: []float64{3, 1, 2}, width: 0, expected: "█▁▄"},
+	}
+
+	for _, c := range cases {
+		if got := sparkline(c.values, c.width); got != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	appgrpc "github.com/falmar/richerage-api/internal/grpc"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"net"
+)
+
+func Cmd(_ context.Context, config *bootstrap.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "grpc",
+		Short: "Start gRPC server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			port := config.Viper.GetString("port")
+			if port == "" {
+				port = "9090"
+			}
+
+			server, err := appgrpc.Server(ctx, config)
+			if err != nil {
+				return err
+			}
+
+			lis, err := net.Listen("tcp", ":"+port)
+			if err != nil {
+				return err
+			}
+
+			// simulate live prices of the seeded backend until shutdown
+			if config.PriceFeed != nil {
+				go config.PriceFeed.Run(ctx)
+			}
+
+			go func() {
+				<-ctx.Done()
+				config.Logger.Info("grpc: shutdown signal received")
+				server.GracefulStop()
+			}()
+
+			config.Logger.Info("grpc: starting server", zap.String("port", port))
+
+			// returns nil once stopped
+			return server.Serve(lis)
+		},
+	}
+}
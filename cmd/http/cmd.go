@@ -6,6 +6,7 @@ import (
 	apphttp "github.com/falmar/richerage-api/internal/http"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"net"
 	"net/http"
 )
 
@@ -28,9 +29,20 @@ func Cmd(_ context.Context, config *bootstrap.Config) *cobra.Command {
 
 			server := &http.Server{
 				Addr: ":" + port,
+				// requests are cancelled on shutdown, long-lived streams would otherwise hold it
+				BaseContext: func(net.Listener) context.Context {
+					return ctx
+				},
 			}
 			server.Handler = handler
 
+			// deliver queued webhooks until shutdown
+			go config.WebhookDispatcher.Run(ctx)
+			// simulate live prices of the seeded backend until shutdown
+			if config.PriceFeed != nil {
+				go config.PriceFeed.Run(ctx)
+			}
+
 			go func() {
 				<-ctx.Done()
 				config.Logger.Info("http: shutdown signal received")
@@ -0,0 +1,139 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/ingest"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"io"
+	"text/tabwriter"
+)
+
+func Cmd(_ context.Context, config *bootstrap.Config) *cobra.Command {
+	var dryRun bool
+	var strict bool
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "ingest FILE...",
+		Short: "Import daily price history from CSV, JSON-lines or Parquet files",
+		Long: `Import daily price history from vendor files into the configured storage backend.
+
+Columns are matched by name: symbol (or ticker), date, close (or price) are required,
+open, high, low, volume and currency are optional. Rows are validated, a later row of
+the same symbol and date replaces an earlier one, then bars are upserted per symbol.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var f ingest.Format
+			if format != "" {
+				var err error
+				f, err = ingest.ParseFormat(format)
+				if err != nil {
+					return err
+				}
+			}
+
+			readers := make([]ingest.Reader, 0, len(args))
+			for _, path := range args {
+				r, closer, err := ingest.Open(path, f)
+				if err != nil {
+					return err
+				}
+				defer closer.Close()
+
+				readers = append(readers, r)
+			}
+
+			out, err := ingest.New(&ingest.Config{
+				Writer:  config.WritableStorage,
+				Symbols: config.SymbolStorage,
+			}).Ingest(ctx, &ingest.IngestInput{
+				Readers: readers,
+				DryRun:  dryRun,
+				Strict:  strict,
+			})
+			if err != nil {
+				return err
+			}
+
+			config.Logger.Debug("ingest: done", zap.Int("symbols", len(out.Symbols)), zap.Bool("dry_run", dryRun))
+
+			// nothing was written either when strict and any row is invalid
+			written := !dryRun
+			if strict && len(out.Errors) > 0 {
+				written = false
+			}
+
+			if err := printSummary(cmd.OutOrStdout(), out, written); err != nil {
+				return err
+			}
+
+			if !written && !dryRun {
+				return fmt.Errorf("invalid rows, nothing was written")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "validate and summarize without writing")
+	cmd.Flags().BoolVar(&strict, "strict", false, "write nothing when any row is invalid")
+	cmd.Flags().StringVar(&format, "format", "", "file format: csv, jsonl or parquet, detected by extension when empty")
+
+	return cmd
+}
+
+func printSummary(w io.Writer, out *ingest.IngestOutput, written bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SYMBOL\tROWS\tINVALID\tDUPLICATES\tINSERTED\tUPDATED\tFROM\tTO")
+
+	var rows, invalid, duplicates, inserted, updated int
+	for _, s := range out.Symbols {
+		from, to := "-", "-"
+		if !s.From.IsZero() {
+			from, to = s.From.Format("2006-01-02"), s.To.Format("2006-01-02")
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n", s.Symbol, s.Rows, s.Invalid, s.Duplicates,
+			count(s.Inserted, written), count(s.Updated, written), from, to)
+
+		rows += s.Rows
+		invalid += s.Invalid
+		duplicates += s.Duplicates
+		inserted += s.Inserted
+		updated += s.Updated
+	}
+
+	invalid += out.Invalid
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\t%s\t%s\t\t\n", rows, invalid, duplicates, count(inserted, written), count(updated, written))
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, e := range out.Errors {
+		fmt.Fprintln(w, e.Error())
+	}
+	if invalid > len(out.Errors) {
+		fmt.Fprintf(w, "... %d more invalid rows\n", invalid-len(out.Errors))
+	}
+
+	if !written {
+		fmt.Fprintln(w, "nothing was written")
+	}
+
+	return nil
+}
+
+func count(n int, written bool) string {
+	if !written {
+		return "-"
+	}
+
+	return fmt.Sprint(n)
+}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"context"
+	"github.com/falmar/richerage-api/cmd/cli"
+	"github.com/falmar/richerage-api/cmd/grpc"
 	"github.com/falmar/richerage-api/cmd/http"
+	"github.com/falmar/richerage-api/cmd/ingest"
+	"github.com/falmar/richerage-api/cmd/snapshot"
 	"github.com/falmar/richerage-api/internal/bootstrap"
 	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
 	"github.com/spf13/cobra"
@@ -14,7 +18,10 @@ import (
 	"syscall"
 )
 
-var rootCmd = &cobra.Command{}
+var rootCmd = &cobra.Command{
+	// global flags are parsed before the subcommands are added, their flags are parsed on execute
+	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+}
 
 func main() {
 	ctx := context.Background()
@@ -24,6 +31,10 @@ func main() {
 	// set cobra flags
 	v := viper.New()
 	v.SetDefault("token.expired", true)
+	v.SetDefault("storage.path", "richerage.db")
+	// the unprefixed routes are aliases of /v1, deprecated and removed at these dates
+	v.SetDefault("api.deprecation", "2026-10-19")
+	v.SetDefault("api.sunset", "2027-04-19")
 	bindFlags(v)
 
 	err := rootCmd.ParseFlags(os.Args[1:])
@@ -36,7 +47,7 @@ func main() {
 
 	// handle stop signals
 	go func() {
-		sigChan := make(chan os.Signal)
+		sigChan := make(chan os.Signal, 1)
 
 		signal.Notify(sigChan, syscall.SIGINT)
 		signal.Notify(sigChan, syscall.SIGTERM)
@@ -53,8 +64,24 @@ func main() {
 
 	// add http server
 	rootCmd.AddCommand(http.Cmd(ctx, cfg))
+	// add grpc server
+	rootCmd.AddCommand(grpc.Cmd(ctx, cfg))
+	// add market data ingestion
+	rootCmd.AddCommand(ingest.Cmd(ctx, cfg))
+	// add snapshot export and import
+	rootCmd.AddCommand(snapshot.ExportCmd(ctx, cfg))
+	rootCmd.AddCommand(snapshot.ImportCmd(ctx, cfg))
+	// add api client
+	rootCmd.AddCommand(cli.LoginCmd(ctx, cfg))
+	rootCmd.AddCommand(cli.TickersCmd(ctx, cfg))
+	rootCmd.AddCommand(cli.HistoryCmd(ctx, cfg))
+	rootCmd.AddCommand(cli.ExportHistoryCmd(ctx, cfg))
 
-	if err := rootCmd.ExecuteContext(ctx); err != nil {
+	err = rootCmd.ExecuteContext(ctx)
+	if closeErr := cfg.Close(); closeErr != nil {
+		logger.Error("main: close storage", zap.Error(closeErr))
+	}
+	if err != nil {
 		logger.Error("main: error", zap.Error(err))
 		os.Exit(1)
 	}
@@ -64,6 +91,6 @@ func bindFlags(v *viper.Viper) {
 	rootCmd.PersistentFlags().BoolP("debug", "d", false, "debug mode")
 	v.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
 
-	rootCmd.PersistentFlags().StringP("port", "p", "", "http port")
+	rootCmd.PersistentFlags().StringP("port", "p", "", "server port, 8080 for http and 9090 for grpc when empty")
 	v.BindPFlag("port", rootCmd.PersistentFlags().Lookup("port"))
 }
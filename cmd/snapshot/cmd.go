@@ -0,0 +1,145 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/snapshot"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+)
+
+func ExportCmd(_ context.Context, config *bootstrap.Config) *cobra.Command {
+	var users []string
+
+	cmd := &cobra.Command{
+		Use:   "export FILE",
+		Short: "Export symbols, prices, holdings and watchlists into a snapshot archive",
+		Long: `Export every entity of the configured storage backend into a zip archive: the symbol master,
+daily prices, the holdings and the watchlists of each user, with a manifest holding the schema
+version and the checksum of every entry.
+
+The seeded backend cannot list its users, only the holdings of --user are exported, and has
+no watchlists.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			path := args[0]
+
+			// write next to the destination, a failed export leaves no partial archive behind
+			f, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+			if err != nil {
+				return err
+			}
+			defer os.Remove(f.Name())
+			defer f.Close()
+
+			out, err := snapshot.New(&snapshot.Config{
+				Storage: config.Storage,
+				Symbols: config.SymbolStorage,
+			}).Export(ctx, &snapshot.ExportInput{
+				Writer: f,
+				Users:  users,
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := f.Close(); err != nil {
+				return err
+			}
+			if err := os.Rename(f.Name(), path); err != nil {
+				return err
+			}
+
+			config.Logger.Debug("export: done", zap.String("path", path))
+
+			return printManifest(cmd.OutOrStdout(), out.Manifest)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&users, "user", nil, "username whose holdings are exported, repeatable, required for backends that cannot list users")
+
+	return cmd
+}
+
+func ImportCmd(_ context.Context, config *bootstrap.Config) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Restore a snapshot archive into the configured storage backend",
+		Long: `Restore a snapshot archive written by export into the configured storage backend.
+
+The archive is verified first: its schema version, the checksum and record count of every
+entry and that the symbol master knows every archived instrument, nothing is written
+otherwise. Prices replace stored bars of the same date, holdings replace the holdings of
+the archived users and watchlists the archived watchlists of the same user and name.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			info, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			out, err := snapshot.New(&snapshot.Config{
+				Writer:  config.WritableStorage,
+				Symbols: config.SymbolStorage,
+			}).Import(ctx, &snapshot.ImportInput{
+				Reader: f,
+				Size:   info.Size(),
+				DryRun: dryRun,
+			})
+			if err != nil {
+				return err
+			}
+
+			config.Logger.Debug("import: done", zap.Bool("dry_run", dryRun))
+
+			if err := printManifest(cmd.OutOrStdout(), out.Manifest); err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			if dryRun {
+				fmt.Fprintln(w, "archive verified, nothing was written")
+				return nil
+			}
+
+			fmt.Fprintf(w, "prices: %d inserted, %d updated\n", out.Inserted, out.Updated)
+			fmt.Fprintf(w, "holdings: %d users\n", out.Holdings)
+			fmt.Fprintf(w, "watchlists: %d\n", out.Watchlists)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "verify the archive without writing")
+
+	return cmd
+}
+
+func printManifest(w io.Writer, m *snapshot.Manifest) error {
+	fmt.Fprintf(w, "schema version %d, created %s\n", m.SchemaVersion, m.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ENTRY\tRECORDS\tSHA256")
+	for _, e := range m.Entries {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", e.Name, e.Records, e.SHA256)
+	}
+
+	return tw.Flush()
+}
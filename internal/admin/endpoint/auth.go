@@ -0,0 +1,32 @@
+package endpoint
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/auth"
+	authtypes "github.com/falmar/richerage-api/internal/auth/types"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+)
+
+// MakeAdminEndpoint verifies the token and the admin key and only lets admins through
+func MakeAdminEndpoint(svc auth.Service, e kitendpoint.Endpoint) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		token, _ := ctx.Value("auth_token").(string)
+		adminKey, _ := ctx.Value("admin_key").(string)
+
+		out, err := svc.VerifyToken(ctx, &auth.VerifyTokenInput{
+			Token:    token,
+			AdminKey: adminKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !out.Admin {
+			return nil, &authtypes.ErrForbidden{
+				Username: out.Username,
+			}
+		}
+
+		return e(ctx, request)
+	}
+}
@@ -0,0 +1,75 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/admin"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+)
+
+// maxHoldings bounds the symbols held by a single user
+const maxHoldings = 100
+
+type SetHoldingsRequest struct {
+	Username string   `json:"-"`
+	Symbols  []string `json:"symbols"`
+}
+
+type SetHoldingsResponse struct {
+	Username string
+	Symbols  []string
+}
+
+func MakeSetHoldingsEndpoint(svc admin.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifySetHoldingsRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := svc.SetHoldings(ctx, &admin.SetHoldingsInput{
+			Username: req.Username,
+			Symbols:  req.Symbols,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &SetHoldingsResponse{
+			Username: req.Username,
+			Symbols:  out.Symbols,
+		}, nil
+	}
+}
+
+func verifySetHoldingsRequest(request interface{}) (*SetHoldingsRequest, error) {
+	req, ok := request.(*SetHoldingsRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if req.Username == "" {
+		badParams["username"] = "required"
+	}
+
+	// an empty list clears the holdings, a missing one is a mistake
+	if req.Symbols == nil {
+		badParams["symbols"] = "required"
+	} else if len(req.Symbols) > maxHoldings {
+		badParams["symbols"] = fmt.Sprintf("at most %d symbols", maxHoldings)
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,56 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/admin"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"testing"
+)
+
+func TestEndpointSetHoldings(t *testing.T) {
+	ctx := context.Background()
+
+	svc := admin.NewMockService()
+	svc.(*admin.MockService).SetHoldingsFunc = func(ctx context.Context, in *admin.SetHoldingsInput) (*admin.SetHoldingsOutput, error) {
+		return &admin.SetHoldingsOutput{Symbols: []string{"AAPL"}}, nil
+	}
+
+	resp, err := MakeSetHoldingsEndpoint(svc)(ctx, &SetHoldingsRequest{Username: "test", Symbols: []string{"aapl"}})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if r := resp.(*SetHoldingsResponse); r.Username != "test" || len(r.Symbols) != 1 || r.Symbols[0] != "AAPL" {
+		t.Errorf("unexpected response %+v", r)
+	}
+
+	// an empty list clears the holdings
+	if _, err = MakeSetHoldingsEndpoint(svc)(ctx, &SetHoldingsRequest{Username: "test", Symbols: []string{}}); err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+}
+
+func TestEndpointSetHoldings_Verify(t *testing.T) {
+	ctx := context.Background()
+	svc := admin.NewMockService()
+
+	var badRequest *kit.BadRequestError
+
+	_, err := MakeSetHoldingsEndpoint(svc)(ctx, &SetHoldingsRequest{})
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be BadRequestError, got %v", err)
+		return
+	}
+	if badRequest.Params["username"] != "required" || badRequest.Params["symbols"] != "required" {
+		t.Errorf("expected username and symbols to be required, got %v", badRequest.Params)
+	}
+
+	_, err = MakeSetHoldingsEndpoint(svc)(ctx, &SetHoldingsRequest{Username: "test", Symbols: make([]string, maxHoldings+1)})
+	if !errors.As(err, &badRequest) || badRequest.Params["symbols"] == "" {
+		t.Errorf("expected too many symbols to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/admin"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	"time"
+)
+
+// maxPricesPerRequest bounds a single push, larger loads go through the ingest command
+const maxPricesPerRequest = 10000
+
+// PriceBar is an end-of-day bar, prices are JSON numbers or strings holding one
+type PriceBar struct {
+	Date     string      `json:"date"`
+	Open     json.Number `json:"open"`
+	High     json.Number `json:"high"`
+	Low      json.Number `json:"low"`
+	Close    json.Number `json:"close"`
+	Volume   json.Number `json:"volume"`
+	Currency string      `json:"currency"`
+}
+
+type UpsertPricesRequest struct {
+	Symbol string     `json:"-"`
+	Prices []PriceBar `json:"prices"`
+}
+
+type UpsertPricesResponse struct {
+	Symbol   string
+	Inserted int
+	Updated  int
+}
+
+func MakeUpsertPricesEndpoint(svc admin.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyUpsertPricesRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		prices := make([]admin.PriceInput, 0, len(req.Prices))
+		for _, p := range req.Prices {
+			prices = append(prices, admin.PriceInput{
+				Date:     p.Date,
+				Open:     p.Open.String(),
+				High:     p.High.String(),
+				Low:      p.Low.String(),
+				Close:    p.Close.String(),
+				Volume:   p.Volume.String(),
+				Currency: p.Currency,
+			})
+		}
+
+		out, err := svc.UpsertPrices(ctx, &admin.UpsertPricesInput{
+			Symbol: req.Symbol,
+			Prices: prices,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &UpsertPricesResponse{
+			Symbol:   req.Symbol,
+			Inserted: out.Inserted,
+			Updated:  out.Updated,
+		}, nil
+	}
+}
+
+type DeletePricesRequest struct {
+	Symbol string
+	// From and To are dates YYYY-MM-DD, both included, optional
+	From string
+	To   string
+}
+
+type DeletePricesResponse struct {
+	Symbol  string
+	Deleted int
+}
+
+func MakeDeletePricesEndpoint(svc admin.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyDeletePricesRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		// verified
+		from, _ := parseDate(req.From)
+		to, _ := parseDate(req.To)
+
+		out, err := svc.DeletePrices(ctx, &admin.DeletePricesInput{
+			Symbol: req.Symbol,
+			From:   from,
+			To:     to,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &DeletePricesResponse{
+			Symbol:  req.Symbol,
+			Deleted: out.Deleted,
+		}, nil
+	}
+}
+
+func verifyUpsertPricesRequest(request interface{}) (*UpsertPricesRequest, error) {
+	req, ok := request.(*UpsertPricesRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if req.Symbol == "" {
+		badParams["symbol"] = "required"
+	}
+
+	if len(req.Prices) == 0 {
+		badParams["prices"] = "required"
+	} else if len(req.Prices) > maxPricesPerRequest {
+		badParams["prices"] = fmt.Sprintf("at most %d prices per request", maxPricesPerRequest)
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
+
+func verifyDeletePricesRequest(request interface{}) (*DeletePricesRequest, error) {
+	req, ok := request.(*DeletePricesRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if req.Symbol == "" {
+		badParams["symbol"] = "required"
+	}
+
+	from, err := parseDate(req.From)
+	if err != nil {
+		badParams["from"] = fmt.Sprintf("invalid format %s, expected YYYY-MM-DD", req.From)
+	}
+
+	to, err := parseDate(req.To)
+	if err != nil {
+		badParams["to"] = fmt.Sprintf("invalid format %s, expected YYYY-MM-DD", req.To)
+	}
+
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		badParams["to"] = "must not be before from"
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
+
+// parseDate reads an optional date, zero when empty
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse("2006-01-02", s)
+}
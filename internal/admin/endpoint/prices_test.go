@@ -0,0 +1,144 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/admin"
+	"github.com/falmar/richerage-api/internal/auth"
+	authtypes "github.com/falmar/richerage-api/internal/auth/types"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"testing"
+)
+
+func TestEndpointUpsertPrices(t *testing.T) {
+	ctx := context.Background()
+
+	svc := admin.NewMockService()
+	svc.(*admin.MockService).UpsertPricesFunc = func(ctx context.Context, in *admin.UpsertPricesInput) (*admin.UpsertPricesOutput, error) {
+		if in.Symbol != "AAPL" || len(in.Prices) != 1 || in.Prices[0].Close != "185.64" || in.Prices[0].Open != "" {
+			t.Errorf("unexpected input %+v", in)
+		}
+
+		return &admin.UpsertPricesOutput{Inserted: 1}, nil
+	}
+
+	resp, err := MakeUpsertPricesEndpoint(svc)(ctx, &UpsertPricesRequest{
+		Symbol: "AAPL",
+		Prices: []PriceBar{{Date: "2024-01-02", Close: "185.64"}},
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if r := resp.(*UpsertPricesResponse); r.Symbol != "AAPL" || r.Inserted != 1 || r.Updated != 0 {
+		t.Errorf("unexpected response %+v", r)
+	}
+}
+
+func TestEndpointUpsertPrices_Verify(t *testing.T) {
+	ctx := context.Background()
+	svc := admin.NewMockService()
+
+	var badRequest *kit.BadRequestError
+
+	_, err := MakeUpsertPricesEndpoint(svc)(ctx, &UpsertPricesRequest{})
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be BadRequestError, got %v", err)
+		return
+	}
+	if badRequest.Params["symbol"] != "required" || badRequest.Params["prices"] != "required" {
+		t.Errorf("expected symbol and prices to be required, got %v", badRequest.Params)
+	}
+
+	_, err = MakeUpsertPricesEndpoint(svc)(ctx, &UpsertPricesRequest{
+		Symbol: "AAPL",
+		Prices: make([]PriceBar, maxPricesPerRequest+1),
+	})
+	if !errors.As(err, &badRequest) || badRequest.Params["prices"] == "" {
+		t.Errorf("expected too many prices to be rejected, got %v", err)
+	}
+
+	_, err = MakeUpsertPricesEndpoint(svc)(ctx, &DeletePricesRequest{})
+	if !errors.As(err, &badRequest) || badRequest.Message != "invalid request" {
+		t.Errorf("expected invalid request, got %v", err)
+	}
+}
+
+func TestEndpointDeletePrices(t *testing.T) {
+	ctx := context.Background()
+
+	svc := admin.NewMockService()
+	svc.(*admin.MockService).DeletePricesFunc = func(ctx context.Context, in *admin.DeletePricesInput) (*admin.DeletePricesOutput, error) {
+		if in.From.Format("2006-01-02") != "2024-01-02" || !in.To.IsZero() {
+			t.Errorf("unexpected input %+v", in)
+		}
+
+		return &admin.DeletePricesOutput{Deleted: 3}, nil
+	}
+
+	resp, err := MakeDeletePricesEndpoint(svc)(ctx, &DeletePricesRequest{Symbol: "AAPL", From: "2024-01-02"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+	if resp.(*DeletePricesResponse).Deleted != 3 {
+		t.Errorf("expected 3 deleted, got %d", resp.(*DeletePricesResponse).Deleted)
+	}
+
+	var badRequest *kit.BadRequestError
+
+	_, err = MakeDeletePricesEndpoint(svc)(ctx, &DeletePricesRequest{Symbol: "AAPL", From: "2024-01-05", To: "2024-01-02"})
+	if !errors.As(err, &badRequest) || badRequest.Params["to"] == "" {
+		t.Errorf("expected to before from to be rejected, got %v", err)
+	}
+
+	_, err = MakeDeletePricesEndpoint(svc)(ctx, &DeletePricesRequest{Symbol: "AAPL", From: "01/02/2024"})
+	if !errors.As(err, &badRequest) || badRequest.Params["from"] == "" {
+		t.Errorf("expected invalid from to be rejected, got %v", err)
+	}
+}
+
+func TestEndpointAdmin(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "auth_token", "token")
+	ctx = context.WithValue(ctx, "admin_key", "key")
+
+	called := false
+	e := func(ctx context.Context, request interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+
+	svc := auth.NewMockService()
+	svc.(*auth.MockService).VerifyTokenFunc = func(ctx context.Context, in *auth.VerifyTokenInput) (*auth.VerifyTokenOutput, error) {
+		return &auth.VerifyTokenOutput{Username: "test"}, nil
+	}
+
+	// non admins are forbidden
+	_, err := MakeAdminEndpoint(svc, e)(ctx, nil)
+
+	var forbidden *authtypes.ErrForbidden
+	if !errors.As(err, &forbidden) {
+		t.Errorf("expected error to be ErrForbidden, got %v", err)
+	}
+	if called {
+		t.Errorf("expected endpoint not to be called")
+	}
+
+	svc.(*auth.MockService).VerifyTokenFunc = func(ctx context.Context, in *auth.VerifyTokenInput) (*auth.VerifyTokenOutput, error) {
+		if in.Token != "token" || in.AdminKey != "key" {
+			t.Errorf("expected token and key, got %s and %s", in.Token, in.AdminKey)
+		}
+
+		return &auth.VerifyTokenOutput{Username: "test", Admin: true}, nil
+	}
+
+	if _, err = MakeAdminEndpoint(svc, e)(ctx, nil); err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	if !called {
+		t.Errorf("expected endpoint to be called")
+	}
+}
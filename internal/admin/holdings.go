@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/admin/types"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
+	"github.com/falmar/richerage-api/internal/webhooks"
+	webhooktypes "github.com/falmar/richerage-api/internal/webhooks/types"
+	"strings"
+)
+
+type SetHoldingsInput struct {
+	Username string
+	// Symbols replace the holdings of the user, empty clears them
+	Symbols []string
+}
+
+type SetHoldingsOutput struct {
+	// Symbols are the holdings as stored: upper case, without duplicates
+	Symbols []string
+}
+
+func (s *service) SetHoldings(ctx context.Context, in *SetHoldingsInput) (*SetHoldingsOutput, error) {
+	if s.storage == nil {
+		return nil, &types.ErrReadOnlyStorage{}
+	}
+
+	symbols := make([]string, 0, len(in.Symbols))
+	seen := map[string]bool{}
+	params := map[string]string{}
+
+	for i, symbol := range in.Symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+
+		v, err := s.tradingSymbol(ctx, symbol)
+		if err != nil {
+			return nil, err
+		} else if v == nil {
+			params[fmt.Sprintf("symbols[%d]", i)] = fmt.Sprintf("unknown symbol %s", symbol)
+			continue
+		}
+
+		if seen[v.Symbol] {
+			continue
+		}
+
+		seen[v.Symbol] = true
+		symbols = append(symbols, v.Symbol)
+	}
+
+	if len(params) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more symbols are invalid",
+			Params:  params,
+		}
+	}
+
+	if err := s.storage.SetHoldings(ctx, in.Username, symbols); err != nil {
+		return nil, err
+	}
+
+	if s.webhooks != nil {
+		_, err := s.webhooks.Publish(ctx, &webhooks.PublishInput{
+			Username: in.Username,
+			Type:     webhooktypes.EventPortfolioUpdated,
+			Data: &webhooktypes.PortfolioUpdated{
+				Username: in.Username,
+				Symbols:  symbols,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("holdings of %s stored, notifying webhooks: %w", in.Username, err)
+		}
+	}
+
+	return &SetHoldingsOutput{
+		Symbols: symbols,
+	}, nil
+}
+
+// tradingSymbol finds the instrument trading today that ever used symbol, renamed symbols
+// are held under the current one, nil when there is none
+func (s *service) tradingSymbol(ctx context.Context, symbol string) (*symboltypes.Symbol, error) {
+	symbols, err := s.symbols.ResolveSymbol(ctx, symbol)
+
+	var errNotFound *symboltypes.ErrSymbolNotFound
+	if errors.As(err, &errNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for i := range symbols {
+		if symbols[i].Delisted.IsZero() {
+			return &symbols[i], nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/admin/types"
+	"github.com/falmar/richerage-api/internal/ingest"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"time"
+)
+
+// PriceInput is an end-of-day bar as pushed by the data pipeline, values are validated like ingested rows
+type PriceInput struct {
+	Date     string
+	Open     string
+	High     string
+	Low      string
+	Close    string
+	Volume   string
+	Currency string
+}
+
+type UpsertPricesInput struct {
+	Symbol string
+	// Prices replace stored bars of the same date, a later bar of the same date replaces an earlier one
+	Prices []PriceInput
+}
+
+type UpsertPricesOutput struct {
+	Inserted int
+	Updated  int
+}
+
+// UpsertPrices writes every bar or none, invalid bars are reported by their index
+func (s *service) UpsertPrices(ctx context.Context, in *UpsertPricesInput) (*UpsertPricesOutput, error) {
+	if s.storage == nil {
+		return nil, &types.ErrReadOnlyStorage{}
+	}
+
+	rows := make([]*ingest.Row, 0, len(in.Prices))
+	for i, p := range in.Prices {
+		rows = append(rows, &ingest.Row{
+			Source:   "prices",
+			Line:     i + 1,
+			Symbol:   in.Symbol,
+			Date:     p.Date,
+			Open:     p.Open,
+			High:     p.High,
+			Low:      p.Low,
+			Close:    p.Close,
+			Volume:   p.Volume,
+			Currency: p.Currency,
+		})
+	}
+
+	out, err := s.ingester.Ingest(ctx, &ingest.IngestInput{
+		Readers: []ingest.Reader{ingest.NewRowReader(rows)},
+		Strict:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.Errors) > 0 {
+		params := map[string]string{}
+		for _, e := range out.Errors {
+			params[fmt.Sprintf("prices[%d]", e.Line-1)] = e.Message
+		}
+
+		return nil, &kit.BadRequestError{
+			Message: "one or more prices are invalid",
+			Params:  params,
+		}
+	}
+
+	result := &UpsertPricesOutput{}
+	for _, v := range out.Symbols {
+		result.Inserted += v.Inserted
+		result.Updated += v.Updated
+	}
+
+	return result, nil
+}
+
+type DeletePricesInput struct {
+	Symbol string
+	// From and To bound the dates removed, both included, zero is open
+	From time.Time
+	To   time.Time
+}
+
+type DeletePricesOutput struct {
+	Deleted int
+}
+
+func (s *service) DeletePrices(ctx context.Context, in *DeletePricesInput) (*DeletePricesOutput, error) {
+	if s.storage == nil {
+		return nil, &types.ErrReadOnlyStorage{}
+	}
+
+	deleted, err := s.storage.DeletePrices(ctx, in.Symbol, in.From, in.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeletePricesOutput{
+		Deleted: deleted,
+	}, nil
+}
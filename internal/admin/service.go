@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/ingest"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/webhooks"
+)
+
+var ErrInvalidConfig = errors.New("invalid admin service config")
+
+var _ Service = (*service)(nil)
+
+// Service feeds the storage backend, every operation is reserved to admins
+type Service interface {
+	UpsertPrices(ctx context.Context, in *UpsertPricesInput) (*UpsertPricesOutput, error)
+	DeletePrices(ctx context.Context, in *DeletePricesInput) (*DeletePricesOutput, error)
+
+	SetHoldings(ctx context.Context, in *SetHoldingsInput) (*SetHoldingsOutput, error)
+}
+
+type Config struct {
+	// Storage receives the writes, nil when the configured backend is read-only
+	Storage storage.WritableStorage
+	Symbols storage.SymbolStorage
+	// Webhooks is notified of holdings changes, optional
+	Webhooks webhooks.Service
+}
+
+func New(cfg *Config) (Service, error) {
+	if cfg == nil || cfg.Symbols == nil {
+		return nil, ErrInvalidConfig
+	}
+
+	return &service{
+		storage:  cfg.Storage,
+		symbols:  cfg.Symbols,
+		webhooks: cfg.Webhooks,
+		// pushed prices are validated like ingested files
+		ingester: ingest.New(&ingest.Config{
+			Writer:  cfg.Storage,
+			Symbols: cfg.Symbols,
+		}),
+	}, nil
+}
+
+type service struct {
+	storage  storage.WritableStorage
+	symbols  storage.SymbolStorage
+	webhooks webhooks.Service
+	ingester *ingest.Ingester
+}
@@ -0,0 +1,43 @@
+//go:build test
+
+package admin
+
+import (
+	"context"
+	"errors"
+)
+
+var _ Service = (*MockService)(nil)
+var ErrMockUncalledFor = errors.New("uncalled for")
+
+func NewMockService() Service {
+	return &MockService{
+		UpsertPricesFunc: func(ctx context.Context, in *UpsertPricesInput) (*UpsertPricesOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		DeletePricesFunc: func(ctx context.Context, in *DeletePricesInput) (*DeletePricesOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		SetHoldingsFunc: func(ctx context.Context, in *SetHoldingsInput) (*SetHoldingsOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+	}
+}
+
+type MockService struct {
+	UpsertPricesFunc func(ctx context.Context, in *UpsertPricesInput) (*UpsertPricesOutput, error)
+	DeletePricesFunc func(ctx context.Context, in *DeletePricesInput) (*DeletePricesOutput, error)
+	SetHoldingsFunc  func(ctx context.Context, in *SetHoldingsInput) (*SetHoldingsOutput, error)
+}
+
+func (m *MockService) UpsertPrices(ctx context.Context, in *UpsertPricesInput) (*UpsertPricesOutput, error) {
+	return m.UpsertPricesFunc(ctx, in)
+}
+
+func (m *MockService) DeletePrices(ctx context.Context, in *DeletePricesInput) (*DeletePricesOutput, error) {
+	return m.DeletePricesFunc(ctx, in)
+}
+
+func (m *MockService) SetHoldings(ctx context.Context, in *SetHoldingsInput) (*SetHoldingsOutput, error) {
+	return m.SetHoldingsFunc(ctx, in)
+}
@@ -0,0 +1,194 @@
+//go:build test
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/admin/types"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/webhooks"
+	webhooktypes "github.com/falmar/richerage-api/internal/webhooks/types"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, s storage.WritableStorage) Service {
+	svc, err := New(&Config{
+		Storage: s,
+		Symbols: storage.NewEmbeddedSymbols(),
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	return svc
+}
+
+func TestAdmin_New(t *testing.T) {
+	if _, err := New(&Config{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected error to be ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestAdmin_UpsertPrices(t *testing.T) {
+	ctx := context.Background()
+	s := storage.NewMemory()
+	svc := newTestService(t, s)
+
+	out, err := svc.UpsertPrices(ctx, &UpsertPricesInput{
+		Symbol: "AAPL",
+		Prices: []PriceInput{
+			{Date: "2024-01-02", Open: "187.15", High: "188.44", Low: "183.89", Close: "185.64", Volume: "82488700"},
+			{Date: "2024-01-03", Close: "184.25"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if out.Inserted != 2 || out.Updated != 0 {
+		t.Errorf("expected 2 inserted, got %+v", out)
+	}
+
+	out, _ = svc.UpsertPrices(ctx, &UpsertPricesInput{
+		Symbol: "AAPL",
+		Prices: []PriceInput{{Date: "2024-01-03", Close: "184.26"}},
+	})
+	if out == nil || out.Inserted != 0 || out.Updated != 1 {
+		t.Errorf("expected 1 updated, got %+v", out)
+	}
+
+	history, _ := s.GetHistory(ctx, "AAPL", time.Time{})
+	if len(history) != 2 || !history[0].Price.Equal(decimal.MustParse("184.26")) || history[0].Currency != "USD" {
+		t.Errorf("expected the updated close 184.26 USD first, got %+v", history)
+	}
+
+	// nothing is written when any bar is invalid
+	_, err = svc.UpsertPrices(ctx, &UpsertPricesInput{
+		Symbol: "AAPL",
+		Prices: []PriceInput{
+			{Date: "2024-01-04", Close: "181.91"},
+			{Date: "2024-01-05", Close: "-1"},
+		},
+	})
+
+	var badRequest *kit.BadRequestError
+	if !errors.As(err, &badRequest) {
+		t.Fatalf("expected error to be BadRequestError, got %v", err)
+	}
+	if _, ok := badRequest.Params["prices[1]"]; !ok || len(badRequest.Params) != 1 {
+		t.Errorf("expected prices[1] to be invalid, got %v", badRequest.Params)
+	}
+
+	if history, _ := s.GetHistory(ctx, "AAPL", time.Time{}); len(history) != 2 {
+		t.Errorf("expected nothing written, got %d bars", len(history))
+	}
+
+	_, err = svc.UpsertPrices(ctx, &UpsertPricesInput{
+		Symbol: "ZZZZ",
+		Prices: []PriceInput{{Date: "2024-01-04", Close: "1"}},
+	})
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected unknown symbol to be a BadRequestError, got %v", err)
+	}
+}
+
+func TestAdmin_DeletePrices(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, storage.NewMemory())
+
+	_, _ = svc.UpsertPrices(ctx, &UpsertPricesInput{
+		Symbol: "AAPL",
+		Prices: []PriceInput{{Date: "2024-01-02", Close: "185.64"}, {Date: "2024-01-03", Close: "184.25"}},
+	})
+
+	from, _ := time.Parse("2006-01-02", "2024-01-03")
+	out, err := svc.DeletePrices(ctx, &DeletePricesInput{Symbol: "AAPL", From: from})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if out.Deleted != 1 {
+		t.Errorf("expected 1 deleted, got %d", out.Deleted)
+	}
+}
+
+func TestAdmin_SetHoldings(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, storage.NewMemory())
+
+	// renamed symbols are held under the current one
+	out, err := svc.SetHoldings(ctx, &SetHoldingsInput{
+		Username: "test",
+		Symbols:  []string{"aapl", "FB", "META"},
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out.Symbols) != 2 || out.Symbols[0] != "AAPL" || out.Symbols[1] != "META" {
+		t.Errorf("expected AAPL and META, got %v", out.Symbols)
+	}
+
+	_, err = svc.SetHoldings(ctx, &SetHoldingsInput{
+		Username: "test",
+		Symbols:  []string{"AAPL", "ZZZZ"},
+	})
+
+	var badRequest *kit.BadRequestError
+	if !errors.As(err, &badRequest) || badRequest.Params["symbols[1]"] == "" {
+		t.Errorf("expected symbols[1] to be invalid, got %v", err)
+	}
+}
+
+func TestAdmin_SetHoldings_Webhooks(t *testing.T) {
+	ctx := context.Background()
+
+	var published *webhooks.PublishInput
+
+	hooks := webhooks.NewMockService()
+	hooks.(*webhooks.MockService).PublishFunc = func(ctx context.Context, in *webhooks.PublishInput) (*webhooks.PublishOutput, error) {
+		published = in
+		return &webhooks.PublishOutput{}, nil
+	}
+
+	svc, _ := New(&Config{
+		Storage:  storage.NewMemory(),
+		Symbols:  storage.NewEmbeddedSymbols(),
+		Webhooks: hooks,
+	})
+
+	if _, err := svc.SetHoldings(ctx, &SetHoldingsInput{Username: "test", Symbols: []string{"aapl"}}); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if published == nil || published.Username != "test" || published.Type != webhooktypes.EventPortfolioUpdated {
+		t.Fatalf("expected portfolio.updated published to test, got %+v", published)
+	}
+	if data := published.Data.(*webhooktypes.PortfolioUpdated); len(data.Symbols) != 1 || data.Symbols[0] != "AAPL" {
+		t.Errorf("expected the stored holdings as data, got %+v", data)
+	}
+
+	// invalid holdings are not published
+	published = nil
+	if _, err := svc.SetHoldings(ctx, &SetHoldingsInput{Username: "test", Symbols: []string{"ZZZZ"}}); err == nil || published != nil {
+		t.Errorf("expected nothing published, got %+v %v", published, err)
+	}
+}
+
+func TestAdmin_ReadOnly(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, nil)
+
+	var errReadOnly *types.ErrReadOnlyStorage
+
+	if _, err := svc.UpsertPrices(ctx, &UpsertPricesInput{Symbol: "AAPL"}); !errors.As(err, &errReadOnly) {
+		t.Errorf("expected error to be ErrReadOnlyStorage, got %v", err)
+	}
+	if _, err := svc.DeletePrices(ctx, &DeletePricesInput{Symbol: "AAPL"}); !errors.As(err, &errReadOnly) {
+		t.Errorf("expected error to be ErrReadOnlyStorage, got %v", err)
+	}
+	if _, err := svc.SetHoldings(ctx, &SetHoldingsInput{Username: "test"}); !errors.As(err, &errReadOnly) {
+		t.Errorf("expected error to be ErrReadOnlyStorage, got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+)
+
+// AdminKeyHeader carries the admin key of the admin routes, along the token of an admin user
+const AdminKeyHeader = "X-Admin-Key"
+
+// AdminKeyDecoder puts the admin key of the request in ctx, MakeAdminEndpoint checks it
+func AdminKeyDecoder(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, "admin_key", r.Header.Get(AdminKeyHeader))
+}
@@ -0,0 +1,17 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestAdminKeyDecoder(t *testing.T) {
+	r, _ := http.NewRequest("PUT", "/admin/prices/AAPL", nil)
+	r.Header.Set("X-Admin-Key", "key")
+
+	ctx := AdminKeyDecoder(context.Background(), r)
+	if key, _ := ctx.Value("admin_key").(string); key != "key" {
+		t.Errorf("expected admin key to be key, got %q", key)
+	}
+}
@@ -0,0 +1,35 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/falmar/richerage-api/internal/admin/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"net/http"
+)
+
+func SetHoldingsRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	req := &endpoint.SetHoldingsRequest{
+		Username: chi.URLParam(r, "username"),
+	}
+
+	// let SetHoldingsEndpoint handle the validation of empty body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, &kit.BadRequestError{Message: "invalid JSON body: " + err.Error()}
+	}
+
+	return req, nil
+}
+
+func SetHoldingsResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.SetHoldingsResponse)
+
+	return kit.EncodeResponse(ctx, w, http.StatusOK, map[string]interface{}{
+		"username": res.Username,
+		"symbols":  res.Symbols,
+	})
+}
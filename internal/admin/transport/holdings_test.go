@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/admin/endpoint"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetHoldings_RequestDecoder(t *testing.T) {
+	r, _ := http.NewRequest("PUT", "/admin/users/test/holdings", strings.NewReader(`{"symbols":["AAPL","META"]}`))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("username", "test")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	out, err := SetHoldingsRequestDecoder(context.Background(), r)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+		return
+	}
+
+	req := out.(*endpoint.SetHoldingsRequest)
+	if req.Username != "test" || len(req.Symbols) != 2 || req.Symbols[1] != "META" {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
+
+func TestSetHoldings_ResponseEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	_ = SetHoldingsResponseEncoder(context.Background(), w, &endpoint.SetHoldingsResponse{
+		Username: "test",
+		Symbols:  []string{},
+	})
+
+	// cleared holdings are an empty list rather than null
+	if body := strings.TrimSpace(w.Body.String()); body != `{"symbols":[],"username":"test"}` {
+		t.Errorf("unexpected body %s", body)
+	}
+}
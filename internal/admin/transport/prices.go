@@ -0,0 +1,55 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/falmar/richerage-api/internal/admin/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"net/http"
+	"strings"
+)
+
+func UpsertPricesRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	req := &endpoint.UpsertPricesRequest{
+		Symbol: strings.ToUpper(chi.URLParam(r, "symbol")),
+	}
+
+	// let UpsertPricesEndpoint handle the validation of empty body,
+	// a malformed one is the pipeline's mistake rather than ours
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, &kit.BadRequestError{Message: "invalid JSON body: " + err.Error()}
+	}
+
+	return req, nil
+}
+
+func UpsertPricesResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.UpsertPricesResponse)
+
+	return kit.EncodeResponse(ctx, w, http.StatusOK, map[string]interface{}{
+		"symbol":   res.Symbol,
+		"inserted": res.Inserted,
+		"updated":  res.Updated,
+	})
+}
+
+func DeletePricesRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	return &endpoint.DeletePricesRequest{
+		Symbol: strings.ToUpper(chi.URLParam(r, "symbol")),
+		From:   r.URL.Query().Get("from"),
+		To:     r.URL.Query().Get("to"),
+	}, nil
+}
+
+func DeletePricesResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.DeletePricesResponse)
+
+	return kit.EncodeResponse(ctx, w, http.StatusOK, map[string]interface{}{
+		"symbol":  res.Symbol,
+		"deleted": res.Deleted,
+	})
+}
@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/falmar/richerage-api/internal/admin/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func withSymbol(r *http.Request, symbol string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("symbol", symbol)
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestUpsertPrices_RequestDecoder(t *testing.T) {
+	body := `{"prices":[{"date":"2024-01-02","open":187.15,"close":"185.64","volume":82488700}]}`
+	r, _ := http.NewRequest("PUT", "/admin/prices/aapl", strings.NewReader(body))
+
+	out, err := UpsertPricesRequestDecoder(context.Background(), withSymbol(r, "aapl"))
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+		return
+	}
+
+	req := out.(*endpoint.UpsertPricesRequest)
+	if req.Symbol != "AAPL" {
+		t.Errorf("expected symbol to be AAPL, got %s", req.Symbol)
+	}
+	if len(req.Prices) != 1 {
+		t.Errorf("expected 1 price, got %d", len(req.Prices))
+		return
+	}
+
+	p := req.Prices[0]
+	if p.Date != "2024-01-02" || p.Open != "187.15" || p.Close != "185.64" || p.Volume != "82488700" || p.High != "" {
+		t.Errorf("unexpected price %+v", p)
+	}
+}
+
+func TestUpsertPrices_RequestDecoder_Invalid(t *testing.T) {
+	r, _ := http.NewRequest("PUT", "/admin/prices/AAPL", strings.NewReader(`{"prices":`))
+
+	_, err := UpsertPricesRequestDecoder(context.Background(), withSymbol(r, "AAPL"))
+
+	var badRequest *kit.BadRequestError
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be BadRequestError, got %v", err)
+	}
+
+	// an empty body is left to the endpoint
+	r, _ = http.NewRequest("PUT", "/admin/prices/AAPL", strings.NewReader(""))
+	if _, err = UpsertPricesRequestDecoder(context.Background(), withSymbol(r, "AAPL")); err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+}
+
+func TestUpsertPrices_ResponseEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := UpsertPricesResponseEncoder(context.Background(), w, &endpoint.UpsertPricesResponse{
+		Symbol:   "AAPL",
+		Inserted: 2,
+		Updated:  1,
+	})
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(w.Body).Decode(&body)
+
+	if body["symbol"] != "AAPL" || body["inserted"] != 2.0 || body["updated"] != 1.0 {
+		t.Errorf("unexpected body %v", body)
+	}
+}
+
+func TestDeletePrices_RequestDecoder(t *testing.T) {
+	r, _ := http.NewRequest("DELETE", "/admin/prices/aapl?from=2024-01-02&to=2024-01-05", nil)
+
+	out, _ := DeletePricesRequestDecoder(context.Background(), withSymbol(r, "aapl"))
+
+	req := out.(*endpoint.DeletePricesRequest)
+	if req.Symbol != "AAPL" || req.From != "2024-01-02" || req.To != "2024-01-05" {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
+
+func TestDeletePrices_ResponseEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	_ = DeletePricesResponseEncoder(context.Background(), w, &endpoint.DeletePricesResponse{
+		Symbol:  "AAPL",
+		Deleted: 3,
+	})
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(w.Body).Decode(&body)
+
+	if body["symbol"] != "AAPL" || body["deleted"] != 3.0 {
+		t.Errorf("unexpected body %v", body)
+	}
+}
@@ -0,0 +1,16 @@
+package types
+
+// ErrReadOnlyStorage is returned for writes while the configured storage backend is read-only
+type ErrReadOnlyStorage struct{}
+
+func (e *ErrReadOnlyStorage) HttpCode() int {
+	return 501
+}
+
+func (e *ErrReadOnlyStorage) Code() string {
+	return "storage_read_only"
+}
+
+func (e *ErrReadOnlyStorage) Error() string {
+	return "the configured storage backend is read-only"
+}
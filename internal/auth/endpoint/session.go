@@ -0,0 +1,32 @@
+package endpoint
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/auth"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+)
+
+type SessionRequest struct{}
+
+type SessionResponse struct {
+	Username string
+}
+
+// MakeSessionEndpoint authenticates the token the transport put in the context, for transports
+// that resolve the user once per connection or request and then act on its behalf
+func MakeSessionEndpoint(svc auth.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		token, _ := ctx.Value("auth_token").(string)
+
+		out, err := svc.VerifyToken(ctx, &auth.VerifyTokenInput{
+			Token: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &SessionResponse{
+			Username: out.Username,
+		}, nil
+	}
+}
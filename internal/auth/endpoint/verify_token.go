@@ -0,0 +1,56 @@
+package endpoint
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/auth"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+)
+
+type VerifyTokenRequest struct {
+	Token string
+}
+
+type VerifyTokenResponse struct {
+	Username string
+	Admin    bool
+}
+
+func MakeVerifyTokenEndpoint(svc auth.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyVerifyTokenRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := svc.VerifyToken(ctx, &auth.VerifyTokenInput{
+			Token: req.Token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &VerifyTokenResponse{
+			Username: out.Username,
+			Admin:    out.Admin,
+		}, nil
+	}
+}
+
+func verifyVerifyTokenRequest(request interface{}) (*VerifyTokenRequest, error) {
+	req, ok := request.(*VerifyTokenRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	if req.Token == "" {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  map[string]string{"token": "required"},
+		}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,60 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/auth"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"testing"
+)
+
+func TestEndpointVerifyToken(t *testing.T) {
+	ctx := context.Background()
+
+	svc := auth.NewMockService()
+	svc.(*auth.MockService).VerifyTokenFunc = func(ctx context.Context, in *auth.VerifyTokenInput) (*auth.VerifyTokenOutput, error) {
+		if in.Token != "token" {
+			t.Errorf("expected token to be token, got %s", in.Token)
+		}
+
+		return &auth.VerifyTokenOutput{
+			Username: "test",
+			Admin:    true,
+		}, nil
+	}
+
+	resp, err := MakeVerifyTokenEndpoint(svc)(ctx, &VerifyTokenRequest{Token: "token"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if r := resp.(*VerifyTokenResponse); r.Username != "test" || !r.Admin {
+		t.Errorf("expected admin test, got %+v", r)
+	}
+
+	// the token is required
+	_, err = MakeVerifyTokenEndpoint(svc)(ctx, &VerifyTokenRequest{})
+
+	var eBadRequest *kit.BadRequestError
+	if !errors.As(err, &eBadRequest) || eBadRequest.Params["token"] != "required" {
+		t.Errorf("expected token to be required, got %v", err)
+	}
+}
+
+func TestEndpointVerifyToken_Error(t *testing.T) {
+	ctx := context.Background()
+
+	svcErr := errors.New("test error")
+
+	svc := auth.NewMockService()
+	svc.(*auth.MockService).VerifyTokenFunc = func(ctx context.Context, in *auth.VerifyTokenInput) (*auth.VerifyTokenOutput, error) {
+		return nil, svcErr
+	}
+
+	if _, err := MakeVerifyTokenEndpoint(svc)(ctx, &VerifyTokenRequest{Token: "token"}); err != svcErr {
+		t.Errorf("expected service error, got %v", err)
+	}
+}
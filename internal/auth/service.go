@@ -17,6 +17,11 @@ type Service interface {
 
 type Config struct {
 	Hasher hasher.Hasher
+	// Admins are the usernames allowed to use the admin api
+	Admins []string
+	// AdminKey is the secret admins prove on every admin request, the admin api is disabled without it
+	// since tokens are issued to any username
+	AdminKey string
 }
 
 func New(cfg *Config) (Service, error) {
@@ -24,11 +29,20 @@ func New(cfg *Config) (Service, error) {
 		return nil, ErrInvalidConfig
 	}
 
+	admins := map[string]bool{}
+	for _, username := range cfg.Admins {
+		admins[username] = true
+	}
+
 	return &service{
-		hasher: cfg.Hasher,
+		hasher:   cfg.Hasher,
+		admins:   admins,
+		adminKey: []byte(cfg.AdminKey),
 	}, nil
 }
 
 type service struct {
-	hasher hasher.Hasher
+	hasher   hasher.Hasher
+	admins   map[string]bool
+	adminKey []byte
 }
@@ -0,0 +1,41 @@
+package transport
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/pb"
+)
+
+func LoginGRPCRequestDecoder(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.LoginRequest)
+
+	return &endpoint.LoginRequest{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+	}, nil
+}
+
+func LoginGRPCResponseEncoder(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*endpoint.LoginResponse)
+
+	return &pb.LoginResponse{
+		Token: resp.Token,
+	}, nil
+}
+
+func VerifyTokenGRPCRequestDecoder(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.VerifyTokenRequest)
+
+	return &endpoint.VerifyTokenRequest{
+		Token: req.GetToken(),
+	}, nil
+}
+
+func VerifyTokenGRPCResponseEncoder(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*endpoint.VerifyTokenResponse)
+
+	return &pb.VerifyTokenResponse{
+		Username: resp.Username,
+		Admin:    resp.Admin,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/pb"
+	"testing"
+)
+
+func TestLogin_GRPCRequestDecoder(t *testing.T) {
+	out, err := LoginGRPCRequestDecoder(context.Background(), &pb.LoginRequest{Username: "test", Password: "secret"})
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	if req := out.(*endpoint.LoginRequest); req.Username != "test" || req.Password != "secret" {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
+
+func TestLogin_GRPCResponseEncoder(t *testing.T) {
+	out, _ := LoginGRPCResponseEncoder(context.Background(), &endpoint.LoginResponse{Token: "token"})
+
+	if resp := out.(*pb.LoginResponse); resp.GetToken() != "token" {
+		t.Errorf("expected token to be token, got %s", resp.GetToken())
+	}
+}
+
+func TestVerifyToken_GRPC(t *testing.T) {
+	out, _ := VerifyTokenGRPCRequestDecoder(context.Background(), &pb.VerifyTokenRequest{Token: "token"})
+	if req := out.(*endpoint.VerifyTokenRequest); req.Token != "token" {
+		t.Errorf("expected token to be token, got %s", req.Token)
+	}
+
+	out, _ = VerifyTokenGRPCResponseEncoder(context.Background(), &endpoint.VerifyTokenResponse{Username: "test", Admin: true})
+	if resp := out.(*pb.VerifyTokenResponse); resp.GetUsername() != "test" || !resp.GetAdmin() {
+		t.Errorf("unexpected response %v", resp)
+	}
+}
@@ -0,0 +1,23 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+)
+
+func LoginJSONRPCRequestDecoder(_ context.Context, params json.RawMessage) (interface{}, error) {
+	req := &endpoint.LoginRequest{}
+
+	// let LoginEndpoint handle the validation of empty params
+	if err := kit.DecodeJSONRPCParams(params, req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func LoginJSONRPCResponseEncoder(_ context.Context, response interface{}) (json.RawMessage, error) {
+	return json.Marshal(response.(*endpoint.LoginResponse))
+}
@@ -0,0 +1,42 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"testing"
+)
+
+func TestLogin_JSONRPCRequestDecoder(t *testing.T) {
+	out, err := LoginJSONRPCRequestDecoder(context.Background(), json.RawMessage(`{"username": "test", "password": "12345"}`))
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	req, ok := out.(*endpoint.LoginRequest)
+	if !ok || req == nil {
+		t.Errorf("expected request to be of type LoginRequest, got %T", out)
+		return
+	}
+
+	if req.Username != "test" || req.Password != "12345" {
+		t.Errorf("expected test credentials, got %+v", req)
+	}
+
+	_, err = LoginJSONRPCRequestDecoder(context.Background(), json.RawMessage(`["test", "12345"]`))
+	if _, ok := err.(*kit.BadRequestError); !ok {
+		t.Errorf("expected BadRequestError, got %v", err)
+	}
+}
+
+func TestLogin_JSONRPCResponseEncoder(t *testing.T) {
+	b, err := LoginJSONRPCResponseEncoder(context.Background(), &endpoint.LoginResponse{Token: "token"})
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	if string(b) != `{"token":"token"}` {
+		t.Errorf("expected token result, got %s", b)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
 	"io"
 	"net/http"
 )
@@ -21,11 +22,8 @@ func LoginRequestDecoder(_ context.Context, r *http.Request) (interface{}, error
 	return req, nil
 }
 
-func LoginResponseEncoder(_ context.Context, w http.ResponseWriter, response interface{}) error {
+func LoginResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 	resp := response.(*endpoint.LoginResponse)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	return json.NewEncoder(w).Encode(resp)
+	return kit.EncodeResponse(ctx, w, http.StatusOK, resp)
 }
@@ -37,3 +37,24 @@ func (e *ErrCredentialsMismatch) Code() string {
 func (e *ErrCredentialsMismatch) Error() string {
 	return "invalid credentials"
 }
+
+// ErrForbidden is a valid token of a user not allowed to perform the operation
+type ErrForbidden struct {
+	Username string
+}
+
+func (e *ErrForbidden) HttpCode() int {
+	return 403
+}
+
+func (e *ErrForbidden) Code() string {
+	return "forbidden"
+}
+
+func (e *ErrForbidden) Error() string {
+	if e.Username != "" {
+		return "forbidden: " + e.Username
+	}
+
+	return "forbidden"
+}
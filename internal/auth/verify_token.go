@@ -2,14 +2,19 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
 )
 
 type VerifyTokenInput struct {
 	Token string
+	// AdminKey is the admin key sent along the token, if any
+	AdminKey string
 }
 
 type VerifyTokenOutput struct {
 	Username string
+	// Admin is set for the usernames configured as admins that sent the admin key
+	Admin bool
 }
 
 func (s *service) VerifyToken(ctx context.Context, in *VerifyTokenInput) (*VerifyTokenOutput, error) {
@@ -20,5 +25,15 @@ func (s *service) VerifyToken(ctx context.Context, in *VerifyTokenInput) (*Verif
 
 	return &VerifyTokenOutput{
 		Username: string(c),
+		Admin:    s.admins[string(c)] && s.isAdminKey(in.AdminKey),
 	}, nil
 }
+
+// isAdminKey compares key in constant time, always false without a configured key
+func (s *service) isAdminKey(key string) bool {
+	if len(s.adminKey) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(s.adminKey, []byte(key)) == 1
+}
@@ -72,3 +72,48 @@ func TestAuth_VerifyToken_Error(t *testing.T) {
 		return
 	}
 }
+
+func TestAuth_VerifyToken_Admin(t *testing.T) {
+	ctx := context.Background()
+	mock := hasher.NewMock()
+
+	mock.(*hasher.MockHasher).ValidateTokenFunc = func(ctx context.Context, token []byte) ([]byte, error) {
+		return token, nil
+	}
+
+	svc, err := New(&Config{
+		Hasher:   mock,
+		Admins:   []string{"admin"},
+		AdminKey: "key",
+	})
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got %v", err)
+		return
+	}
+
+	out, _ := svc.VerifyToken(ctx, &VerifyTokenInput{Token: "admin", AdminKey: "key"})
+	if out == nil || !out.Admin {
+		t.Errorf("expected admin to be an admin, got %+v", out)
+	}
+
+	// anyone can login as admin, the key is the proof
+	for _, key := range []string{"", "nope"} {
+		out, _ = svc.VerifyToken(ctx, &VerifyTokenInput{Token: "admin", AdminKey: key})
+		if out == nil || out.Admin {
+			t.Errorf("expected admin without the key %q not to be an admin, got %+v", key, out)
+		}
+	}
+
+	out, _ = svc.VerifyToken(ctx, &VerifyTokenInput{Token: "test", AdminKey: "key"})
+	if out == nil || out.Admin {
+		t.Errorf("expected test not to be an admin, got %+v", out)
+	}
+
+	// without a configured key nobody is an admin
+	svc, _ = New(&Config{Hasher: mock, Admins: []string{"admin"}})
+
+	out, _ = svc.VerifyToken(ctx, &VerifyTokenInput{Token: "admin"})
+	if out == nil || out.Admin {
+		t.Errorf("expected no admin without admin key, got %+v", out)
+	}
+}
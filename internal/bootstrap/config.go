@@ -3,12 +3,20 @@ package bootstrap
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/admin"
 	"github.com/falmar/richerage-api/internal/auth"
+	"github.com/falmar/richerage-api/internal/export"
+	"github.com/falmar/richerage-api/internal/markets"
 	"github.com/falmar/richerage-api/internal/pkg/hasher"
+	"github.com/falmar/richerage-api/internal/prices"
 	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/symbols"
 	"github.com/falmar/richerage-api/internal/tickers"
+	"github.com/falmar/richerage-api/internal/webhooks"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"io"
 	"strings"
 	"time"
 )
@@ -19,6 +27,22 @@ type Config struct {
 
 	AuthService      auth.Service
 	RicherageService tickers.Service
+	// PriceFeed simulates live prices of the seeded backend, nil for the other backends
+	PriceFeed *storage.SeededTicker
+
+	SymbolStorage storage.SymbolStorage
+	// Storage is the configured storage backend
+	Storage storage.Storage
+	// WritableStorage is the same backend when it is writable, nil when it is read-only
+	WritableStorage storage.WritableStorage
+
+	WebhooksService   webhooks.Service
+	WebhookDispatcher *webhooks.Dispatcher
+
+	MarketsService markets.Service
+	SymbolsService symbols.Service
+	AdminService   admin.Service
+	ExportService  export.Service
 }
 
 func New(_ context.Context, v *viper.Viper, logger *zap.Logger) (*Config, error) {
@@ -44,11 +68,108 @@ func New(_ context.Context, v *viper.Viper, logger *zap.Logger) (*Config, error)
 			TTL:          time.Hour * 24 * 7 * 30,
 			CheckExpired: v.GetBool("token.expired"),
 		}),
+		Admins:   adminUsers(v),
+		AdminKey: v.GetString("admin.key"),
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	// bootstrap dependencies
+	symbolStorage := storage.NewEmbeddedSymbols()
+	cfg.SymbolStorage = symbolStorage
+	cfg.SymbolsService, err = symbols.New(&symbols.Config{
+		Storage: symbolStorage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// seeded (default) generates read-only data, memory and bolt are populated through ingest,
+	// snapshot import and the admin api, only bolt keeps them across processes.
+	// Generated dividends, rates and live prices only match generated prices, stored prices get
+	// the embedded splits, no rates and no live prices
+	var tickerStorage storage.Storage
+	var publisher prices.Publisher
+	corporateActions := storage.NewEmbeddedSplits()
+	fx := storage.NewMemoryFX(nil)
+
+	switch backend := v.GetString("storage.backend"); backend {
+	case "", "seeded":
+		tickerStorage = storage.NewSeeded(&storage.SeededConfig{
+			Symbols: symbolStorage,
+		})
+		corporateActions = storage.NewSeededCorporateActions(&storage.SeededConfig{
+			Symbols: symbolStorage,
+		})
+		fx = storage.NewSeededFX()
+
+		cfg.PriceFeed = storage.NewSeededTicker(&storage.SeededTickerConfig{
+			Interval: v.GetDuration("stream.interval"),
+			Symbols:  symbolStorage,
+		})
+		publisher = cfg.PriceFeed
+	case "memory":
+		cfg.WritableStorage = storage.NewMemory()
+		tickerStorage = cfg.WritableStorage
+	case "bolt":
+		cfg.WritableStorage, err = storage.NewBolt(&storage.BoltConfig{
+			Path:    v.GetString("storage.path"),
+			Timeout: v.GetDuration("storage.timeout"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		tickerStorage = cfg.WritableStorage
+	default:
+		return nil, fmt.Errorf("unknown storage backend %s, expected seeded, memory or bolt", backend)
+	}
+	cfg.Storage = tickerStorage
+
 	cfg.RicherageService, err = tickers.New(&tickers.Config{
-		Storage: storage.NewSeeded(),
+		Storage:          tickerStorage,
+		Publisher:        publisher,
+		Symbols:          symbolStorage,
+		CorporateActions: corporateActions,
+		FX:               fx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.ExportService, err = export.New(&export.Config{
+		Storage: tickerStorage,
+		Symbols: symbolStorage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	webhookStorage := storage.NewMemoryWebhooks()
+	cfg.WebhookDispatcher = webhooks.NewDispatcher(&webhooks.DispatcherConfig{
+		Storage:     webhookStorage,
+		Logger:      logger,
+		MaxAttempts: v.GetInt("webhooks.max_attempts"),
+		Backoff:     v.GetDuration("webhooks.backoff"),
+		MaxBackoff:  v.GetDuration("webhooks.max_backoff"),
+	})
+	cfg.WebhooksService, err = webhooks.New(&webhooks.Config{
+		Storage:    webhookStorage,
+		Dispatcher: cfg.WebhookDispatcher,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.MarketsService, err = markets.New(&markets.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.AdminService, err = admin.New(&admin.Config{
+		Storage:  cfg.WritableStorage,
+		Symbols:  symbolStorage,
+		Webhooks: cfg.WebhooksService,
 	})
 	if err != nil {
 		return nil, err
@@ -56,3 +177,27 @@ func New(_ context.Context, v *viper.Viper, logger *zap.Logger) (*Config, error)
 
 	return cfg, nil
 }
+
+// Close releases the storage backend, the bolt file stays locked until then
+func (c *Config) Close() error {
+	if closer, ok := c.Storage.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// adminUsers are the usernames of admin.users, separated by commas or spaces (ADMIN_USERS=alice,bob)
+func adminUsers(v *viper.Viper) []string {
+	var users []string
+
+	for _, value := range v.GetStringSlice("admin.users") {
+		for _, username := range strings.Split(value, ",") {
+			if username = strings.TrimSpace(username); username != "" {
+				users = append(users, username)
+			}
+		}
+	}
+
+	return users
+}
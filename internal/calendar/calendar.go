@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"time"
+)
+
+const dateFormat = "2006-01-02"
+
+// Calendar holds the regular session hours of an exchange plus its holidays and half days.
+// Dates outside of the data files are treated as regular weekdays.
+type Calendar struct {
+	Exchange string
+	Name     string
+	Location *time.Location
+
+	open       clock
+	close      clock
+	earlyClose clock
+
+	holidays map[string]string
+	halfDays map[string]string
+}
+
+type clock struct {
+	hour   int
+	minute int
+}
+
+func (c clock) on(date time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), c.hour, c.minute, 0, 0, loc)
+}
+
+// Holiday returns the name of the holiday on the civil date of date
+func (c *Calendar) Holiday(date time.Time) (string, bool) {
+	name, ok := c.holidays[date.Format(dateFormat)]
+
+	return name, ok
+}
+
+// IsHalfDay reports whether the exchange closes early on the civil date of date
+func (c *Calendar) IsHalfDay(date time.Time) bool {
+	_, ok := c.halfDays[date.Format(dateFormat)]
+
+	return ok
+}
+
+// IsTradingDay reports whether there is a session on the civil date of date,
+// the date is taken as is, without converting it to the exchange timezone
+func (c *Calendar) IsTradingDay(date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+
+	_, holiday := c.Holiday(date)
+
+	return !holiday
+}
+
+// Session returns the open and close times of the civil date of date, ok is false when there is no session
+func (c *Calendar) Session(date time.Time) (open time.Time, close time.Time, ok bool) {
+	if !c.IsTradingDay(date) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	open = c.open.on(date, c.Location)
+	close = c.close.on(date, c.Location)
+
+	if c.IsHalfDay(date) {
+		close = c.earlyClose.on(date, c.Location)
+	}
+
+	return open, close, true
+}
+
+// NextSession returns the first session that has not closed yet at t, it may be in progress
+func (c *Calendar) NextSession(t time.Time) (open time.Time, close time.Time) {
+	date := t.In(c.Location)
+
+	// a year without sessions is not a calendar
+	for i := 0; i < 366; i++ {
+		open, close, ok := c.Session(date)
+		if ok && t.Before(close) {
+			return open, close
+		}
+
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return time.Time{}, time.Time{}
+}
+
+// PreviousTradingDay returns date or the closest trading day before it
+func (c *Calendar) PreviousTradingDay(date time.Time) time.Time {
+	for i := 0; i < 366 && !c.IsTradingDay(date); i++ {
+		date = date.AddDate(0, 0, -1)
+	}
+
+	return date
+}
+
+// NextTradingDay returns date or the closest trading day after it
+func (c *Calendar) NextTradingDay(date time.Time) time.Time {
+	for i := 0; i < 366 && !c.IsTradingDay(date); i++ {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return date
+}
@@ -0,0 +1,143 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustGet(t *testing.T, exchange string) *Calendar {
+	cal, err := Get(exchange)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	return cal
+}
+
+func date(s string) time.Time {
+	d, _ := time.Parse(dateFormat, s)
+
+	return d
+}
+
+func TestCalendar_Get(t *testing.T) {
+	for _, code := range []string{"NYSE", "NASDAQ", "LSE", "nyse"} {
+		if _, err := Get(code); err != nil {
+			t.Errorf("expected %s to be found, got %v", code, err)
+		}
+	}
+
+	_, err := Get("TSX")
+
+	var errNotFound *ErrExchangeNotFound
+	if !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be %T, got %T", errNotFound, err)
+	}
+
+	if got := Exchanges(); len(got) != 3 || got[0] != "LSE" {
+		t.Errorf("expected [LSE NASDAQ NYSE], got %v", got)
+	}
+}
+
+func TestCalendar_IsTradingDay(t *testing.T) {
+	nyse := mustGet(t, "NYSE")
+	lse := mustGet(t, "LSE")
+
+	matrix := []struct {
+		cal    *Calendar
+		date   string
+		expect bool
+	}{
+		{nyse, "2024-07-04", false}, // independence day
+		{nyse, "2024-07-05", true},
+		{nyse, "2024-07-06", false}, // saturday
+		{nyse, "2025-01-09", false}, // day of mourning
+		{nyse, "2026-07-03", false}, // observed on friday
+		{lse, "2024-07-04", true},
+		{lse, "2024-12-26", false}, // boxing day
+		{lse, "2024-08-26", false}, // summer bank holiday
+		{nyse, "2030-01-01", true}, // outside the data files
+	}
+
+	for _, m := range matrix {
+		if got := m.cal.IsTradingDay(date(m.date)); got != m.expect {
+			t.Errorf("expected %s %s trading day to be %v, got %v", m.cal.Exchange, m.date, m.expect, got)
+		}
+	}
+}
+
+func TestCalendar_Session(t *testing.T) {
+	nyse := mustGet(t, "NYSE")
+
+	open, close, ok := nyse.Session(date("2024-11-29"))
+	if !ok {
+		t.Fatalf("expected a session on the day after thanksgiving")
+	}
+
+	// half day closes at 13:00 new york, 18:00 UTC in winter
+	if got := open.UTC().Format(time.RFC3339); got != "2024-11-29T14:30:00Z" {
+		t.Errorf("expected open at 2024-11-29T14:30:00Z, got %s", got)
+	}
+	if got := close.UTC().Format(time.RFC3339); got != "2024-11-29T18:00:00Z" {
+		t.Errorf("expected close at 2024-11-29T18:00:00Z, got %s", got)
+	}
+
+	// daylight saving time is taken from the exchange timezone
+	_, close, _ = nyse.Session(date("2024-07-05"))
+	if got := close.UTC().Format(time.RFC3339); got != "2024-07-05T20:00:00Z" {
+		t.Errorf("expected close at 2024-07-05T20:00:00Z, got %s", got)
+	}
+
+	if _, _, ok = nyse.Session(date("2024-12-25")); ok {
+		t.Errorf("expected no session on christmas")
+	}
+}
+
+func TestCalendar_NextSession(t *testing.T) {
+	nyse := mustGet(t, "NYSE")
+
+	matrix := []struct {
+		at    string
+		open  string
+		close string
+	}{
+		// before the open
+		{"2024-07-05T12:00:00Z", "2024-07-05T13:30:00Z", "2024-07-05T20:00:00Z"},
+		// in session
+		{"2024-07-05T15:00:00Z", "2024-07-05T13:30:00Z", "2024-07-05T20:00:00Z"},
+		// after the close on friday, next is monday
+		{"2024-07-05T20:00:00Z", "2024-07-08T13:30:00Z", "2024-07-08T20:00:00Z"},
+		// half day before a holiday
+		{"2024-07-03T18:00:00Z", "2024-07-05T13:30:00Z", "2024-07-05T20:00:00Z"},
+	}
+
+	for _, m := range matrix {
+		at, _ := time.Parse(time.RFC3339, m.at)
+		open, close := nyse.NextSession(at)
+
+		if got := open.UTC().Format(time.RFC3339); got != m.open {
+			t.Errorf("at %s expected open %s, got %s", m.at, m.open, got)
+		}
+		if got := close.UTC().Format(time.RFC3339); got != m.close {
+			t.Errorf("at %s expected close %s, got %s", m.at, m.close, got)
+		}
+	}
+}
+
+func TestCalendar_PreviousNextTradingDay(t *testing.T) {
+	nyse := mustGet(t, "NYSE")
+
+	// sunday after good friday
+	if got := nyse.PreviousTradingDay(date("2024-03-31")).Format(dateFormat); got != "2024-03-28" {
+		t.Errorf("expected 2024-03-28, got %s", got)
+	}
+	if got := nyse.NextTradingDay(date("2024-03-29")).Format(dateFormat); got != "2024-04-01" {
+		t.Errorf("expected 2024-04-01, got %s", got)
+	}
+
+	// trading days are returned as is
+	if got := nyse.PreviousTradingDay(date("2024-04-01")).Format(dateFormat); got != "2024-04-01" {
+		t.Errorf("expected 2024-04-01, got %s", got)
+	}
+}
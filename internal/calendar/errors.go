@@ -0,0 +1,19 @@
+package calendar
+
+import "fmt"
+
+type ErrExchangeNotFound struct {
+	Exchange string
+}
+
+func (e *ErrExchangeNotFound) HttpCode() int {
+	return 404
+}
+
+func (e *ErrExchangeNotFound) Code() string {
+	return "exchange_not_found"
+}
+
+func (e *ErrExchangeNotFound) Error() string {
+	return fmt.Sprintf("exchange %s not found", e.Exchange)
+}
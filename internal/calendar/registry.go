@@ -0,0 +1,130 @@
+package calendar
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	// exchange timezones must resolve on images without a zoneinfo database
+	_ "time/tzdata"
+)
+
+//go:embed data/*.json
+var dataFiles embed.FS
+
+var calendars = map[string]*Calendar{}
+
+func init() {
+	entries, err := dataFiles.ReadDir("data")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		b, err := dataFiles.ReadFile("data/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+
+		cal, err := parse(b)
+		if err != nil {
+			panic(fmt.Sprintf("calendar: %s: %s", entry.Name(), err))
+		}
+
+		calendars[cal.Exchange] = cal
+	}
+}
+
+// Get returns the calendar of the exchange, the code is case-insensitive
+func Get(exchange string) (*Calendar, error) {
+	cal, ok := calendars[strings.ToUpper(exchange)]
+	if !ok {
+		return nil, &ErrExchangeNotFound{
+			Exchange: exchange,
+		}
+	}
+
+	return cal, nil
+}
+
+// Exchanges returns the codes of all known exchanges, sorted
+func Exchanges() []string {
+	codes := make([]string, 0, len(calendars))
+	for code := range calendars {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+
+	return codes
+}
+
+type calendarFile struct {
+	Exchange   string            `json:"exchange"`
+	Name       string            `json:"name"`
+	Timezone   string            `json:"timezone"`
+	Open       string            `json:"open"`
+	Close      string            `json:"close"`
+	EarlyClose string            `json:"early_close"`
+	Holidays   map[string]string `json:"holidays"`
+	HalfDays   map[string]string `json:"half_days"`
+}
+
+func parse(b []byte) (*Calendar, error) {
+	var f calendarFile
+
+	err := json.Unmarshal(b, &f)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(f.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calendar{
+		Exchange: f.Exchange,
+		Name:     f.Name,
+		Location: loc,
+		holidays: map[string]string{},
+		halfDays: map[string]string{},
+	}
+
+	for _, c := range []struct {
+		value string
+		dst   *clock
+	}{
+		{f.Open, &cal.open},
+		{f.Close, &cal.close},
+		{f.EarlyClose, &cal.earlyClose},
+	} {
+		t, err := time.Parse("15:04", c.value)
+		if err != nil {
+			return nil, err
+		}
+
+		*c.dst = clock{hour: t.Hour(), minute: t.Minute()}
+	}
+
+	for date, name := range f.Holidays {
+		if _, err := time.Parse(dateFormat, date); err != nil {
+			return nil, err
+		}
+
+		cal.holidays[date] = name
+	}
+
+	for date, name := range f.HalfDays {
+		if _, err := time.Parse(dateFormat, date); err != nil {
+			return nil, err
+		}
+
+		cal.halfDays[date] = name
+	}
+
+	return cal, nil
+}
@@ -0,0 +1,23 @@
+package endpoint
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/auth"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+)
+
+// MakeAuthEndpoint verifies the token, any user may export the history
+func MakeAuthEndpoint(svc auth.Service, e kitendpoint.Endpoint) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		token, _ := ctx.Value("auth_token").(string)
+
+		_, err := svc.VerifyToken(ctx, &auth.VerifyTokenInput{
+			Token: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return e(ctx, request)
+	}
+}
@@ -0,0 +1,129 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/export"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	"strings"
+	"time"
+)
+
+// maxExportSymbols bounds the symbols of a single export
+const maxExportSymbols = 100
+
+type ExportHistoryRequest struct {
+	Symbols []string
+	// From and To are dates YYYY-MM-DD, both included, optional
+	From string
+	To   string
+	// Format of the file, the first of export.Formats when empty
+	Format string
+}
+
+type ExportHistoryResponse struct {
+	Batches <-chan export.Batch
+	Format  export.Format
+}
+
+func MakeExportHistoryEndpoint(svc export.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyExportHistoryRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		// verified
+		from, _ := parseDate(req.From)
+		to, _ := parseDate(req.To)
+
+		out, err := svc.ExportHistory(ctx, &export.ExportHistoryInput{
+			Symbols: req.Symbols,
+			From:    from,
+			To:      to,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &ExportHistoryResponse{
+			Batches: out.Batches,
+			Format:  export.Format(req.Format),
+		}, nil
+	}
+}
+
+func verifyExportHistoryRequest(request interface{}) (*ExportHistoryRequest, error) {
+	req, ok := request.(*ExportHistoryRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	req.Symbols = normalizeSymbols(req.Symbols)
+	if len(req.Symbols) == 0 {
+		badParams["symbols"] = "required"
+	} else if len(req.Symbols) > maxExportSymbols {
+		badParams["symbols"] = fmt.Sprintf("at most %d symbols per export", maxExportSymbols)
+	}
+
+	from, err := parseDate(req.From)
+	if err != nil {
+		badParams["from"] = fmt.Sprintf("invalid format %s, expected YYYY-MM-DD", req.From)
+	}
+
+	to, err := parseDate(req.To)
+	if err != nil {
+		badParams["to"] = fmt.Sprintf("invalid format %s, expected YYYY-MM-DD", req.To)
+	}
+
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		badParams["to"] = "must not be before from"
+	}
+
+	if req.Format == "" {
+		req.Format = string(export.Formats[0])
+	}
+	if !export.Format(req.Format).Valid() {
+		badParams["format"] = fmt.Sprintf("unsupported format %s, expected parquet or arrow", req.Format)
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
+
+// normalizeSymbols upper cases the symbols and drops empty and repeated ones, keeping their order
+func normalizeSymbols(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	normalized := make([]string, 0, len(symbols))
+
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+
+		seen[symbol] = true
+		normalized = append(normalized, symbol)
+	}
+
+	return normalized
+}
+
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse("2006-01-02", s)
+}
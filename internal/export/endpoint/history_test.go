@@ -0,0 +1,114 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/auth"
+	"github.com/falmar/richerage-api/internal/export"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"testing"
+)
+
+func TestEndpointExportHistory(t *testing.T) {
+	ctx := context.Background()
+
+	svc := export.NewMockService()
+	svc.(*export.MockService).ExportHistoryFunc = func(ctx context.Context, in *export.ExportHistoryInput) (*export.ExportHistoryOutput, error) {
+		if len(in.Symbols) != 2 || in.Symbols[0] != "MSFT" || in.Symbols[1] != "AAPL" {
+			t.Errorf("expected symbols MSFT and AAPL, got %v", in.Symbols)
+		}
+		if in.From.Format("2006-01-02") != "2024-01-02" || !in.To.IsZero() {
+			t.Errorf("expected from 2024-01-02 and no to, got %s %s", in.From, in.To)
+		}
+
+		return &export.ExportHistoryOutput{Batches: make(chan export.Batch)}, nil
+	}
+
+	resp, err := MakeExportHistoryEndpoint(svc)(ctx, &ExportHistoryRequest{
+		Symbols: []string{" msft", "AAPL", "MSFT", ""},
+		From:    "2024-01-02",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if r := resp.(*ExportHistoryResponse); r.Format != export.FormatParquet || r.Batches == nil {
+		t.Errorf("expected parquet batches, got %+v", r)
+	}
+}
+
+func TestEndpointExportHistory_Verify(t *testing.T) {
+	ctx := context.Background()
+	svc := export.NewMockService()
+
+	var badRequest *kit.BadRequestError
+
+	_, err := MakeExportHistoryEndpoint(svc)(ctx, &ExportHistoryRequest{})
+	if !errors.As(err, &badRequest) || badRequest.Params["symbols"] != "required" {
+		t.Errorf("expected symbols to be required, got %v", err)
+	}
+
+	_, err = MakeExportHistoryEndpoint(svc)(ctx, &ExportHistoryRequest{
+		Symbols: []string{"AAPL"},
+		From:    "2024-01-05",
+		To:      "2024-01-02",
+		Format:  "csv",
+	})
+	if !errors.As(err, &badRequest) || badRequest.Params["to"] == "" || badRequest.Params["format"] == "" {
+		t.Errorf("expected to and format to be rejected, got %v", err)
+	}
+
+	symbols := make([]string, maxExportSymbols+1)
+	for i := range symbols {
+		symbols[i] = string(rune('A'+i/26)) + string(rune('A'+i%26))
+	}
+
+	_, err = MakeExportHistoryEndpoint(svc)(ctx, &ExportHistoryRequest{
+		Symbols: symbols,
+		From:    "02/01/2024",
+	})
+	if !errors.As(err, &badRequest) || badRequest.Params["symbols"] == "" || badRequest.Params["from"] == "" {
+		t.Errorf("expected too many symbols and invalid from to be rejected, got %v", err)
+	}
+}
+
+func TestEndpointExportHistory_Auth(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "auth_token", "token")
+
+	called := false
+	e := func(ctx context.Context, request interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+
+	svc := auth.NewMockService()
+	verifyErr := errors.New("invalid token")
+	svc.(*auth.MockService).VerifyTokenFunc = func(ctx context.Context, in *auth.VerifyTokenInput) (*auth.VerifyTokenOutput, error) {
+		return nil, verifyErr
+	}
+
+	if _, err := MakeAuthEndpoint(svc, e)(ctx, nil); !errors.Is(err, verifyErr) {
+		t.Errorf("expected the verify error, got %v", err)
+	}
+	if called {
+		t.Errorf("expected endpoint not to be called")
+	}
+
+	svc.(*auth.MockService).VerifyTokenFunc = func(ctx context.Context, in *auth.VerifyTokenInput) (*auth.VerifyTokenOutput, error) {
+		if in.Token != "token" {
+			t.Errorf("expected token to be token, got %s", in.Token)
+		}
+
+		return &auth.VerifyTokenOutput{Username: "test"}, nil
+	}
+
+	if _, err := MakeAuthEndpoint(svc, e)(ctx, nil); err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+	if !called {
+		t.Errorf("expected endpoint to be called")
+	}
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"github.com/apache/arrow/go/v11/parquet"
+	"github.com/apache/arrow/go/v11/parquet/compress"
+	"github.com/apache/arrow/go/v11/parquet/pqarrow"
+	"io"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Format of an exported file
+type Format string
+
+const (
+	// FormatArrow is the Arrow IPC streaming format, a record batch per Batch
+	FormatArrow Format = "arrow"
+	// FormatParquet is a Parquet file, a row group per Batch
+	FormatParquet Format = "parquet"
+)
+
+// Formats are the supported formats, the first one is the default
+var Formats = []Format{FormatParquet, FormatArrow}
+
+func (f Format) Valid() bool {
+	return f == FormatArrow || f == FormatParquet
+}
+
+// ContentType of files in the format
+func (f Format) ContentType() string {
+	if f == FormatArrow {
+		return "application/vnd.apache.arrow.stream"
+	}
+
+	return "application/vnd.apache.parquet"
+}
+
+// Extension of files in the format, with the leading dot
+func (f Format) Extension() string {
+	if f == FormatArrow {
+		return ".arrows"
+	}
+
+	return ".parquet"
+}
+
+// HistorySchema are the columns of an exported history, named like the columns of ingested files
+// so a parquet export can be ingested back
+//
+// prices are decimal strings as in the json responses, each keeps the scale it is stored with
+var HistorySchema = arrow.NewSchema([]arrow.Field{
+	{Name: "symbol", Type: arrow.BinaryTypes.String},
+	{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+	{Name: "open", Type: arrow.BinaryTypes.String},
+	{Name: "high", Type: arrow.BinaryTypes.String},
+	{Name: "low", Type: arrow.BinaryTypes.String},
+	{Name: "close", Type: arrow.BinaryTypes.String},
+	{Name: "volume", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "currency", Type: arrow.BinaryTypes.String},
+}, nil)
+
+// recordWriter is implemented by the ipc and parquet writers
+type recordWriter interface {
+	Write(rec arrow.Record) error
+	Close() error
+}
+
+// WriteHistory writes the batches to w as they are received and returns the number of rows written
+//
+// the file is only completed after the last batch, on error it is left without the parquet footer
+// or the arrow end of stream marker, ctx must be the one of the export as a canceled export closes
+// the batches without an error
+func WriteHistory(ctx context.Context, w io.Writer, format Format, batches <-chan Batch) (int64, error) {
+	var rw recordWriter
+
+	switch format {
+	case FormatArrow:
+		rw = ipc.NewWriter(w, ipc.WithSchema(HistorySchema))
+	case FormatParquet:
+		fw, err := pqarrow.NewFileWriter(
+			HistorySchema, w,
+			parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy)),
+			pqarrow.DefaultWriterProps(),
+		)
+		if err != nil {
+			return 0, err
+		}
+		rw = fw
+	default:
+		return 0, ErrUnknownFormat
+	}
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, HistorySchema)
+	defer builder.Release()
+
+	var rows int64
+
+	for batch := range batches {
+		if batch.Err != nil {
+			return rows, batch.Err
+		}
+		if len(batch.History) == 0 {
+			continue
+		}
+
+		record := historyRecord(builder, batch)
+		err := rw.Write(record)
+		record.Release()
+		if err != nil {
+			return rows, err
+		}
+
+		rows += int64(len(batch.History))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return rows, err
+	}
+
+	return rows, rw.Close()
+}
+
+func historyRecord(builder *array.RecordBuilder, batch Batch) arrow.Record {
+	symbol := builder.Field(0).(*array.StringBuilder)
+	date := builder.Field(1).(*array.Date32Builder)
+	open := builder.Field(2).(*array.StringBuilder)
+	high := builder.Field(3).(*array.StringBuilder)
+	low := builder.Field(4).(*array.StringBuilder)
+	price := builder.Field(5).(*array.StringBuilder)
+	volume := builder.Field(6).(*array.Int64Builder)
+	currency := builder.Field(7).(*array.StringBuilder)
+
+	builder.Reserve(len(batch.History))
+
+	for _, v := range batch.History {
+		symbol.Append(v.Symbol)
+		date.Append(arrow.Date32FromTime(v.Date))
+		open.Append(v.Open.String())
+		high.Append(v.High.String())
+		low.Append(v.Low.String())
+		price.Append(v.Price.String())
+		volume.Append(v.Volume)
+		currency.Append(v.Currency)
+	}
+
+	return builder.NewRecord()
+}
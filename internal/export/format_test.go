@@ -0,0 +1,164 @@
+//go:build test
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"github.com/apache/arrow/go/v11/parquet/file"
+	"github.com/apache/arrow/go/v11/parquet/pqarrow"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+	"time"
+)
+
+func testBatches(err error) <-chan Batch {
+	d, _ := time.Parse("2006-01-02", "2024-01-02")
+
+	batches := make(chan Batch, 3)
+	batches <- Batch{History: []types.TickerHistory{
+		{Date: d, Symbol: "AAPL", Open: decimal.MustParse("187.15"), High: decimal.MustParse("188.44"), Low: decimal.MustParse("183.89"), Price: decimal.MustParse("185.64"), Volume: 82488700, Currency: "USD"},
+		{Date: d.AddDate(0, 0, 1), Symbol: "AAPL", Price: decimal.MustParse("184.250"), Currency: "USD"},
+	}}
+	batches <- Batch{History: []types.TickerHistory{
+		{Date: d, Symbol: "MSFT", Price: decimal.MustParse("370.87"), Currency: "USD"},
+	}}
+	if err != nil {
+		batches <- Batch{Err: err}
+	}
+	close(batches)
+
+	return batches
+}
+
+func TestFormat_Valid(t *testing.T) {
+	for _, f := range Formats {
+		if !f.Valid() {
+			t.Errorf("expected %s to be valid", f)
+		}
+	}
+
+	if Format("csv").Valid() {
+		t.Errorf("expected csv to be invalid")
+	}
+}
+
+func TestWriteHistory_Arrow(t *testing.T) {
+	var buf bytes.Buffer
+
+	rows, err := WriteHistory(context.Background(), &buf, FormatArrow, testBatches(nil))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if rows != 3 {
+		t.Errorf("expected 3 rows got %d", rows)
+	}
+
+	r, err := ipc.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	defer r.Release()
+
+	if !r.Schema().Equal(HistorySchema) {
+		t.Errorf("expected the history schema got %s", r.Schema())
+	}
+
+	// a record batch per batch
+	var records []int64
+	for r.Next() {
+		records = append(records, r.Record().NumRows())
+	}
+	if r.Err() != nil {
+		t.Fatalf("expected error to be nil, got %v", r.Err())
+	}
+	if len(records) != 2 || records[0] != 2 || records[1] != 1 {
+		t.Errorf("expected records of 2 and 1 rows got %v", records)
+	}
+}
+
+func TestWriteHistory_Parquet(t *testing.T) {
+	var buf bytes.Buffer
+
+	rows, err := WriteHistory(context.Background(), &buf, FormatParquet, testBatches(nil))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if rows != 3 {
+		t.Errorf("expected 3 rows got %d", rows)
+	}
+
+	pf, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	// a row group per batch
+	if pf.NumRowGroups() != 2 || pf.NumRows() != 3 {
+		t.Errorf("expected 2 row groups and 3 rows got %d and %d", pf.NumRowGroups(), pf.NumRows())
+	}
+
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	tbl, err := fr.ReadTable(context.Background())
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	defer tbl.Release()
+
+	// prices are exact and keep their scale
+	price := tbl.Column(5).Data().Chunk(0).(*array.String)
+	if price.Value(0) != "185.64" || price.Value(1) != "184.250" {
+		t.Errorf("expected the closes 185.64 and 184.250 got %s and %s", price.Value(0), price.Value(1))
+	}
+
+	date := tbl.Column(1).Data().Chunk(0).(*array.Date32)
+	if got := date.Value(1).ToTime().Format("2006-01-02"); got != "2024-01-03" {
+		t.Errorf("expected the date 2024-01-03 got %s", got)
+	}
+}
+
+func TestWriteHistory_Error(t *testing.T) {
+	batchErr := errors.New("storage down")
+
+	for _, f := range Formats {
+		var buf bytes.Buffer
+
+		rows, err := WriteHistory(context.Background(), &buf, f, testBatches(batchErr))
+		if !errors.Is(err, batchErr) || rows != 3 {
+			t.Errorf("%s: expected the batch error after 3 rows, got %d %v", f, rows, err)
+		}
+	}
+
+	// the file is left without its footer
+	var buf bytes.Buffer
+	_, _ = WriteHistory(context.Background(), &buf, FormatParquet, testBatches(batchErr))
+
+	if _, err := file.NewParquetReader(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Errorf("expected an incomplete parquet file to be unreadable")
+	}
+}
+
+func TestWriteHistory_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// a canceled export closes the batches without an error, the file must not look complete
+	if _, err := WriteHistory(ctx, &bytes.Buffer{}, FormatArrow, testBatches(nil)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error to be context.Canceled, got %v", err)
+	}
+}
+
+func TestWriteHistory_UnknownFormat(t *testing.T) {
+	if _, err := WriteHistory(context.Background(), &bytes.Buffer{}, Format("csv"), testBatches(nil)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected error to be ErrUnknownFormat, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/storage"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"time"
+)
+
+var ErrInvalidConfig = errors.New("invalid export service config")
+
+var _ Service = (*service)(nil)
+
+// Service exports the stored history in bulk for analytics
+type Service interface {
+	ExportHistory(ctx context.Context, in *ExportHistoryInput) (*ExportHistoryOutput, error)
+}
+
+type Config struct {
+	Storage storage.Storage
+	// Symbols validates requested symbols, defaults to the embedded symbol master
+	Symbols storage.SymbolStorage
+}
+
+func New(cfg *Config) (Service, error) {
+	if cfg == nil || cfg.Storage == nil {
+		return nil, ErrInvalidConfig
+	}
+
+	s := &service{
+		storage: cfg.Storage,
+		symbols: cfg.Symbols,
+	}
+
+	if s.symbols == nil {
+		s.symbols = storage.NewEmbeddedSymbols()
+	}
+
+	return s, nil
+}
+
+type service struct {
+	storage storage.Storage
+	symbols storage.SymbolStorage
+}
+
+// DefaultBatchSize bounds the bars of a batch when the input leaves it empty
+const DefaultBatchSize = 10000
+
+// Batch is a part of an exported history, the export ends after a batch with Err
+type Batch struct {
+	History []types.TickerHistory
+	Err     error
+}
+
+type ExportHistoryInput struct {
+	// Symbols are exported in order, bars are the ones stored under each symbol
+	Symbols []string
+	// From and To are the first and last dates exported, unbounded when zero
+	From time.Time
+	To   time.Time
+	// BatchSize bounds the bars of each batch, DefaultBatchSize when zero
+	BatchSize int
+}
+
+type ExportHistoryOutput struct {
+	// Batches are sent symbol after symbol, oldest first, the channel is closed after the last batch,
+	// after a batch with an error or once ctx is done
+	Batches <-chan Batch
+}
+
+// ExportHistory validates every symbol before reading any history, the storage is then read one
+// symbol at a time so only the bars of a single symbol are held in memory
+func (s *service) ExportHistory(ctx context.Context, in *ExportHistoryInput) (*ExportHistoryOutput, error) {
+	for _, symbol := range in.Symbols {
+		// renamed instruments keep the bars stored under their previous symbols
+		_, err := s.symbols.ResolveSymbol(ctx, symbol)
+		var errNotFound *symboltypes.ErrSymbolNotFound
+		if errors.As(err, &errNotFound) {
+			return nil, &types.ErrTickerNotFound{
+				Symbol: symbol,
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	size := in.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	batches := make(chan Batch)
+
+	go func() {
+		defer close(batches)
+
+		send := func(batch Batch) bool {
+			select {
+			case batches <- batch:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, symbol := range in.Symbols {
+			records, err := s.history(ctx, symbol, in.From, in.To)
+			if err != nil {
+				send(Batch{Err: err})
+				return
+			}
+
+			for len(records) > 0 {
+				n := size
+				if n > len(records) {
+					n = len(records)
+				}
+
+				if !send(Batch{History: records[:n]}) {
+					return
+				}
+
+				records = records[n:]
+			}
+		}
+	}()
+
+	return &ExportHistoryOutput{
+		Batches: batches,
+	}, nil
+}
+
+// history returns the bars stored under symbol from the first to the last date, oldest first
+func (s *service) history(ctx context.Context, symbol string, from time.Time, to time.Time) ([]types.TickerHistory, error) {
+	records, err := s.storage.GetHistory(ctx, symbol, to)
+
+	var errNotFound *types.ErrTickerNotFound
+	if errors.As(err, &errNotFound) {
+		// a known symbol without stored bars exports nothing
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// records are newest first, keep the ones from the first date and reverse them
+	history := make([]types.TickerHistory, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		if !from.IsZero() && records[i].Date.Before(from) {
+			continue
+		}
+
+		// the storage may leave out the symbol the bars are stored under
+		v := records[i]
+		v.Symbol = symbol
+
+		history = append(history, v)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,27 @@
+//go:build test
+
+package export
+
+import (
+	"context"
+	"errors"
+)
+
+var _ Service = (*MockService)(nil)
+var ErrMockUncalledFor = errors.New("uncalled for")
+
+func NewMockService() Service {
+	return &MockService{
+		ExportHistoryFunc: func(ctx context.Context, in *ExportHistoryInput) (*ExportHistoryOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+	}
+}
+
+type MockService struct {
+	ExportHistoryFunc func(ctx context.Context, in *ExportHistoryInput) (*ExportHistoryOutput, error)
+}
+
+func (m *MockService) ExportHistory(ctx context.Context, in *ExportHistoryInput) (*ExportHistoryOutput, error) {
+	return m.ExportHistoryFunc(ctx, in)
+}
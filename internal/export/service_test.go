@@ -0,0 +1,198 @@
+//go:build test
+
+package export
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+	"time"
+)
+
+func newTestStorage(t *testing.T) storage.WritableStorage {
+	s := storage.NewMemory()
+
+	bars := map[string][]string{
+		"AAPL": {"2024-01-02", "2024-01-03", "2024-01-04", "2024-01-05"},
+		"MSFT": {"2024-01-03", "2024-01-04"},
+	}
+
+	for symbol, dates := range bars {
+		var prices []types.TickerHistory
+		for i, date := range dates {
+			d, _ := time.Parse("2006-01-02", date)
+			prices = append(prices, types.TickerHistory{
+				Date:     d,
+				Price:    decimal.NewFromInt(int64(100 + i)),
+				Symbol:   symbol,
+				Currency: "USD",
+			})
+		}
+
+		if _, err := s.UpsertPrices(context.Background(), symbol, prices); err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+	}
+
+	return s
+}
+
+func collect(out *ExportHistoryOutput) ([]Batch, error) {
+	var batches []Batch
+
+	for batch := range out.Batches {
+		if batch.Err != nil {
+			return batches, batch.Err
+		}
+
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
+
+func TestExport_New(t *testing.T) {
+	if _, err := New(&Config{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected error to be ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestExport_ExportHistory(t *testing.T) {
+	svc, _ := New(&Config{Storage: newTestStorage(t)})
+
+	from, _ := time.Parse("2006-01-02", "2024-01-03")
+	to, _ := time.Parse("2006-01-02", "2024-01-04")
+
+	out, err := svc.ExportHistory(context.Background(), &ExportHistoryInput{
+		Symbols: []string{"MSFT", "AAPL"},
+		From:    from,
+		To:      to,
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	batches, err := collect(out)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	var got []string
+	for _, batch := range batches {
+		for _, v := range batch.History {
+			got = append(got, v.Symbol+" "+v.Date.Format("2006-01-02"))
+		}
+	}
+
+	expected := []string{"MSFT 2024-01-03", "MSFT 2024-01-04", "AAPL 2024-01-03", "AAPL 2024-01-04"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %v got %v", expected, got)
+			break
+		}
+	}
+}
+
+func TestExport_ExportHistory_BatchSize(t *testing.T) {
+	svc, _ := New(&Config{Storage: newTestStorage(t)})
+
+	out, _ := svc.ExportHistory(context.Background(), &ExportHistoryInput{
+		Symbols:   []string{"AAPL", "MSFT"},
+		BatchSize: 3,
+	})
+
+	batches, err := collect(out)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	// a batch never mixes symbols
+	if len(batches) != 3 || len(batches[0].History) != 3 || len(batches[1].History) != 1 || len(batches[2].History) != 2 {
+		t.Errorf("expected batches of 3, 1 and 2 bars, got %+v", batches)
+	}
+}
+
+func TestExport_ExportHistory_NotFound(t *testing.T) {
+	s := storage.NewMock().(*storage.MockStorage)
+	svc, _ := New(&Config{Storage: s})
+
+	// nothing is read before every symbol is validated
+	_, err := svc.ExportHistory(context.Background(), &ExportHistoryInput{
+		Symbols: []string{"AAPL", "NOPE"},
+	})
+
+	var errNotFound *types.ErrTickerNotFound
+	if !errors.As(err, &errNotFound) || errNotFound.Symbol != "NOPE" {
+		t.Errorf("expected ErrTickerNotFound of NOPE, got %v", err)
+	}
+}
+
+func TestExport_ExportHistory_Empty(t *testing.T) {
+	svc, _ := New(&Config{Storage: newTestStorage(t)})
+
+	// a known symbol without stored bars
+	out, _ := svc.ExportHistory(context.Background(), &ExportHistoryInput{
+		Symbols: []string{"ADBE"},
+	})
+
+	batches, err := collect(out)
+	if err != nil || len(batches) != 0 {
+		t.Errorf("expected no batch, got %+v %v", batches, err)
+	}
+}
+
+func TestExport_ExportHistory_StorageError(t *testing.T) {
+	s := storage.NewMock().(*storage.MockStorage)
+	storageErr := errors.New("storage down")
+	s.GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		return nil, storageErr
+	}
+
+	svc, _ := New(&Config{Storage: s})
+
+	out, _ := svc.ExportHistory(context.Background(), &ExportHistoryInput{
+		Symbols: []string{"AAPL"},
+	})
+
+	if _, err := collect(out); !errors.Is(err, storageErr) {
+		t.Errorf("expected the storage error, got %v", err)
+	}
+}
+
+func TestExport_ExportHistory_Canceled(t *testing.T) {
+	svc, _ := New(&Config{Storage: newTestStorage(t)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	out, _ := svc.ExportHistory(ctx, &ExportHistoryInput{
+		Symbols:   []string{"AAPL", "MSFT"},
+		BatchSize: 1,
+	})
+
+	<-out.Batches
+	cancel()
+
+	select {
+	case <-drain(out.Batches):
+	case <-time.After(time.Second):
+		t.Errorf("expected the batches to be closed once ctx is done")
+	}
+}
+
+func drain(batches <-chan Batch) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		for range batches {
+		}
+		close(done)
+	}()
+
+	return done
+}
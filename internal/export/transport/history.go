@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/export"
+	"github.com/falmar/richerage-api/internal/export/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"net/http"
+	"strings"
+)
+
+func ExportHistoryRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+
+	// symbols=AAPL,MSFT or symbols=AAPL&symbols=MSFT
+	var symbols []string
+	for _, v := range query["symbols"] {
+		symbols = append(symbols, strings.Split(v, ",")...)
+	}
+
+	return &endpoint.ExportHistoryRequest{
+		Symbols: symbols,
+		From:    query.Get("from"),
+		To:      query.Get("to"),
+		Format:  query.Get("format"),
+	}, nil
+}
+
+// ExportHistoryResponseEncoder writes the file as the batches are read from the storage
+func ExportHistoryResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.ExportHistoryResponse)
+
+	w.Header().Set("Content-Type", res.Format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="history`+res.Format.Extension()+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := export.WriteHistory(ctx, w, res.Format, res.Batches); err != nil {
+		return &exportAbortedError{err: err}
+	}
+
+	return nil
+}
+
+// exportAbortedError is returned once part of the file was sent, the status can no longer change
+type exportAbortedError struct {
+	err error
+}
+
+func (e *exportAbortedError) Error() string {
+	return "export aborted: " + e.err.Error()
+}
+
+func (e *exportAbortedError) Unwrap() error {
+	return e.err
+}
+
+// MakeErrorEncoder encodes the errors returned before the export started with next, an export that
+// fails midway aborts the response so clients see a truncated download rather than an error body
+func MakeErrorEncoder(next kithttp.ErrorEncoder) kithttp.ErrorEncoder {
+	return func(ctx context.Context, err error, w http.ResponseWriter) {
+		if _, ok := err.(*exportAbortedError); ok {
+			panic(http.ErrAbortHandler)
+		}
+
+		next(ctx, err, w)
+	}
+}
@@ -0,0 +1,102 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+	"github.com/falmar/richerage-api/internal/export"
+	"github.com/falmar/richerage-api/internal/export/endpoint"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExportHistory_RequestDecoder(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/export/history?symbols=AAPL,MSFT&symbols=GOOGL&from=2024-01-02&to=2024-01-05&format=arrow", nil)
+
+	out, err := ExportHistoryRequestDecoder(context.Background(), r)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+		return
+	}
+
+	req := out.(*endpoint.ExportHistoryRequest)
+	if len(req.Symbols) != 3 || req.Symbols[0] != "AAPL" || req.Symbols[2] != "GOOGL" {
+		t.Errorf("expected symbols AAPL, MSFT and GOOGL, got %v", req.Symbols)
+	}
+	if req.From != "2024-01-02" || req.To != "2024-01-05" || req.Format != "arrow" {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
+
+func TestExportHistory_ResponseEncoder(t *testing.T) {
+	batches := make(chan export.Batch, 1)
+	batches <- export.Batch{History: []types.TickerHistory{{Date: time.Now(), Symbol: "AAPL"}}}
+	close(batches)
+
+	w := httptest.NewRecorder()
+
+	err := ExportHistoryResponseEncoder(context.Background(), w, &endpoint.ExportHistoryResponse{
+		Batches: batches,
+		Format:  export.FormatArrow,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/vnd.apache.arrow.stream" {
+		t.Errorf("expected the arrow stream content type, got %s", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="history.arrows"` {
+		t.Errorf("expected a history.arrows attachment, got %s", cd)
+	}
+
+	r, err := ipc.NewReader(w.Body)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+	defer r.Release()
+
+	if !r.Next() || r.Record().NumRows() != 1 {
+		t.Errorf("expected a record of 1 row")
+	}
+}
+
+func TestExportHistory_ErrorEncoder(t *testing.T) {
+	batchErr := errors.New("storage down")
+
+	batches := make(chan export.Batch, 1)
+	batches <- export.Batch{Err: batchErr}
+	close(batches)
+
+	err := ExportHistoryResponseEncoder(context.Background(), httptest.NewRecorder(), &endpoint.ExportHistoryResponse{
+		Batches: batches,
+		Format:  export.FormatParquet,
+	})
+	if !errors.Is(err, batchErr) {
+		t.Errorf("expected the batch error, got %v", err)
+	}
+
+	called := false
+	encoder := MakeErrorEncoder(func(ctx context.Context, err error, w http.ResponseWriter) {
+		called = true
+	})
+
+	// errors before the export are left to next
+	encoder(context.Background(), batchErr, httptest.NewRecorder())
+	if !called {
+		t.Errorf("expected next to be called")
+	}
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("expected the response to be aborted, got %v", v)
+		}
+	}()
+
+	encoder(context.Background(), err, httptest.NewRecorder())
+}
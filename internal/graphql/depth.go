@@ -0,0 +1,80 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"strings"
+)
+
+const errDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = (*DepthLimit)(nil)
+
+// DepthLimit rejects operations nesting fields deeper than Limit, fragments count as the fields they
+// spread and introspection fields are not counted
+type DepthLimit struct {
+	Limit int
+}
+
+func (d *DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d *DepthLimit) Validate(_ graphql.ExecutableSchema) error {
+	if d.Limit <= 0 {
+		return fmt.Errorf("DepthLimit limit must be positive")
+	}
+
+	return nil
+}
+
+func (d *DepthLimit) MutateOperationContext(_ context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	op := rc.Doc.Operations.ForName(rc.OperationName)
+	if op == nil {
+		return nil
+	}
+
+	if depth := selectionDepth(op.SelectionSet); depth > d.Limit {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Limit)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+
+	return nil
+}
+
+// selectionDepth is the deepest nesting of fields in set, validation already rejected fragment cycles
+func selectionDepth(set ast.SelectionSet) int {
+	depth := 0
+
+	for _, selection := range set {
+		var d int
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			d = 1 + selectionDepth(s.SelectionSet)
+		case *ast.InlineFragment:
+			d = selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				d = selectionDepth(s.Definition.SelectionSet)
+			}
+		}
+
+		if d > depth {
+			depth = d
+		}
+	}
+
+	return depth
+}
@@ -0,0 +1,5 @@
+// Package graphql serves the tickers of the user of the token over GraphQL,
+// the executable schema is generated by gqlgen from schema.graphqls
+package graphql
+
+//go:generate gqlgen generate --config gqlgen.yml
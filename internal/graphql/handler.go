@@ -0,0 +1,189 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	authendpoint "github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers"
+	tickerstransport "github.com/falmar/richerage-api/internal/tickers/transport"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultMaxDepth      = 6
+	DefaultMaxComplexity = 10000
+)
+
+// estimatedTickers is the complexity multiplier of a list of tickers, the holdings of a user are not known upfront
+const estimatedTickers = 10
+
+type HandlerConfig struct {
+	// Session authenticates the request, see authendpoint.MakeSessionEndpoint
+	Session kitendpoint.Endpoint
+	Tickers tickers.Service
+
+	ErrorEncoder kithttp.ErrorEncoder
+	ErrorHandler interface {
+		Handle(ctx context.Context, err error)
+	}
+
+	// MaxDepth bounds the nesting of the fields of an operation, DefaultMaxDepth when zero
+	MaxDepth int
+	// MaxComplexity bounds the estimated cost of an operation, every field costs 1 and lists
+	// multiply the cost of their fields by their length, DefaultMaxComplexity when zero
+	MaxComplexity int
+}
+
+func NewHandler(cfg *HandlerConfig) http.Handler {
+	h := &graphqlHandler{
+		session:      cfg.Session,
+		tickers:      cfg.Tickers,
+		errorEncoder: cfg.ErrorEncoder,
+		errorHandler: cfg.ErrorHandler,
+	}
+
+	if h.errorEncoder == nil {
+		h.errorEncoder = kithttp.DefaultErrorEncoder
+	}
+
+	maxDepth := cfg.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	maxComplexity := cfg.MaxComplexity
+	if maxComplexity <= 0 {
+		maxComplexity = DefaultMaxComplexity
+	}
+
+	schema := Config{
+		Resolvers: &Resolver{
+			Tickers: cfg.Tickers,
+		},
+	}
+
+	historyComplexity := func(childComplexity int, last *int) int {
+		n := 30
+		if last != nil && *last > 0 && *last <= maxHistoryLast {
+			n = *last
+		} else if last != nil {
+			n = maxHistoryLast
+		}
+
+		return childComplexity * n
+	}
+	tickersComplexity := func(childComplexity int, _ *string) int {
+		return childComplexity * estimatedTickers
+	}
+
+	schema.Complexity.Query.History = func(childComplexity int, _ string, last *int, _ *time.Time, _ *time.Time, _ *Adjustment, _ *string, _ *Resolution) int {
+		return historyComplexity(childComplexity, last)
+	}
+	schema.Complexity.Ticker.History = func(childComplexity int, last *int, _ *time.Time, _ *time.Time, _ *Adjustment, _ *string, _ *Resolution) int {
+		return historyComplexity(childComplexity, last)
+	}
+	schema.Complexity.Query.Tickers = tickersComplexity
+	schema.Complexity.User.Tickers = tickersComplexity
+
+	srv := handler.New(NewExecutableSchema(schema))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.SetQueryCache(lru.New(1000))
+	srv.Use(extension.Introspection{})
+	srv.Use(&DepthLimit{Limit: maxDepth})
+	srv.Use(extension.FixedComplexityLimit(maxComplexity))
+	srv.SetErrorPresenter(h.presentError)
+	srv.SetRecoverFunc(func(ctx context.Context, err interface{}) error {
+		// presented as an unexpected error
+		return fmt.Errorf("graphql: panic: %v", err)
+	})
+
+	h.server = srv
+
+	return h
+}
+
+type graphqlHandler struct {
+	session      kitendpoint.Endpoint
+	tickers      tickers.Service
+	errorEncoder kithttp.ErrorEncoder
+	errorHandler interface {
+		Handle(ctx context.Context, err error)
+	}
+
+	server *handler.Server
+}
+
+func (h *graphqlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := tickerstransport.TokenDecoder(r.Context(), r)
+
+	response, err := h.session(ctx, &authendpoint.SessionRequest{})
+	if err != nil {
+		if h.errorHandler != nil {
+			h.errorHandler.Handle(ctx, err)
+		}
+		h.errorEncoder(ctx, err, w)
+		return
+	}
+
+	ctx = context.WithValue(ctx, "graphql_user", &User{
+		Username: response.(*authendpoint.SessionResponse).Username,
+	})
+	// lookups of the request are batched, never across requests
+	ctx = context.WithValue(ctx, "graphql_history_loader", newHistoryLoader(h.tickers))
+
+	h.server.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// presentError sets the api error code as the "code" extension, with the invalid parameters of
+// bad requests as "params"; unexpected errors are logged and hidden
+func (h *graphqlHandler) presentError(ctx context.Context, err error) *gqlerror.Error {
+	// parsing, validation and limit errors of gqlgen are presented as they are
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) && gqlErr.Unwrap() == nil {
+		return graphql.DefaultErrorPresenter(ctx, err)
+	}
+
+	cause := err
+	if gqlErr != nil {
+		cause = gqlErr.Unwrap()
+	}
+
+	if h.errorHandler != nil {
+		h.errorHandler.Handle(ctx, cause)
+	}
+
+	presented := graphql.DefaultErrorPresenter(ctx, err)
+
+	var coded kit.CodedError
+	if !errors.As(cause, &coded) {
+		presented.Message = "internal server error"
+		presented.Extensions = map[string]interface{}{
+			"code": "internal_server_error",
+		}
+
+		return presented
+	}
+
+	presented.Message = coded.Error()
+	presented.Extensions = map[string]interface{}{
+		"code": coded.Code(),
+	}
+
+	var badRequest *kit.BadRequestError
+	if errors.As(cause, &badRequest) && len(badRequest.Params) > 0 {
+		presented.Extensions["params"] = badRequest.Params
+	}
+
+	return presented
+}
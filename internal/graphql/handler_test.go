@@ -0,0 +1,279 @@
+//go:build test
+
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	authendpoint "github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/auth/types"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers"
+	tickerstypes "github.com/falmar/richerage-api/internal/tickers/types"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newTestHandler(svc tickers.Service, maxDepth int, maxComplexity int) http.Handler {
+	errorHandler := &kit.ErrorHandler{
+		Logger: zap.NewNop(),
+	}
+
+	return NewHandler(&HandlerConfig{
+		Session: func(ctx context.Context, _ interface{}) (interface{}, error) {
+			if token, _ := ctx.Value("auth_token").(string); token != "test" {
+				return nil, &types.ErrUnauthorized{}
+			}
+
+			return &authendpoint.SessionResponse{Username: "john.doe"}, nil
+		},
+		Tickers:       svc,
+		ErrorEncoder:  errorHandler.ErrorEncoder,
+		ErrorHandler:  errorHandler,
+		MaxDepth:      maxDepth,
+		MaxComplexity: maxComplexity,
+	})
+}
+
+func doQuery(t *testing.T, h http.Handler, query string) (int, *graphqlResponse) {
+	body, _ := json.Marshal(map[string]string{"query": query})
+
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth("test", "")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	resp := &graphqlResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), resp); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v: %s", err, rec.Body.String())
+	}
+
+	return rec.Code, resp
+}
+
+func getHistory(symbol string) []tickerstypes.TickerHistory {
+	var history []tickerstypes.TickerHistory
+	date := time.Date(2023, 7, 21, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		history = append(history, tickerstypes.TickerHistory{
+			Date:     date.Add(-time.Hour * 24 * time.Duration(i)),
+			Price:    decimal.NewFromInt(int64(100 + i)),
+			Symbol:   symbol,
+			Currency: "USD",
+		})
+	}
+
+	return history
+}
+
+func TestGraphQL_TickersHistory(t *testing.T) {
+	svc := tickers.NewMockService()
+
+	svc.(*tickers.MockService).GetTickersFunc = func(ctx context.Context, in *tickers.GetTickersInput) (*tickers.GetTickersOutput, error) {
+		if in.Username != "john.doe" {
+			t.Errorf("expected username john.doe, got %s", in.Username)
+		}
+
+		return &tickers.GetTickersOutput{Tickers: []tickerstypes.Ticker{
+			{Symbol: "AAPL", Price: decimal.MustParse("190.10"), Currency: "USD"},
+			{Symbol: "MSFT", Price: decimal.MustParse("330.25"), Currency: "USD"},
+		}}, nil
+	}
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	svc.(*tickers.MockService).GetTickerHistoryFunc = func(ctx context.Context, in *tickers.GetTickerHistoryInput) (*tickers.GetTickerHistoryOutput, error) {
+		mu.Lock()
+		calls[in.Symbol]++
+		mu.Unlock()
+
+		if in.Adjusted != tickerstypes.AdjustmentNone || in.Resolution != tickerstypes.Resolution1d {
+			t.Errorf("expected raw daily bars, got %s %s", in.Adjusted, in.Resolution)
+		}
+
+		return &tickers.GetTickerHistoryOutput{History: getHistory(in.Symbol)}, nil
+	}
+
+	code, resp := doQuery(t, newTestHandler(svc, 0, 0), `{
+		me {
+			username
+			tickers { symbol price history(last: 2) { date close } }
+		}
+		history(symbol: "AAPL", from: "2023-07-19T00:00:00Z") { close }
+	}`)
+	if code != http.StatusOK || len(resp.Errors) > 0 {
+		t.Fatalf("expected StatusOK without errors, got %d %v", code, resp.Errors)
+	}
+
+	var me struct {
+		Username string `json:"username"`
+		Tickers  []struct {
+			Symbol  string                        `json:"symbol"`
+			Price   json.Number                   `json:"price"`
+			History []struct{ Close json.Number } `json:"history"`
+		} `json:"tickers"`
+	}
+	_ = json.Unmarshal(resp.Data["me"], &me)
+
+	if me.Username != "john.doe" || len(me.Tickers) != 2 {
+		t.Fatalf("unexpected me %s", resp.Data["me"])
+	}
+	// prices are numbers with their scale
+	if me.Tickers[0].Price.String() != "190.10" {
+		t.Errorf("expected price 190.10, got %s", me.Tickers[0].Price)
+	}
+	for _, ticker := range me.Tickers {
+		if len(ticker.History) != 2 || ticker.History[0].Close.String() != "100" {
+			t.Errorf("expected the 2 newest bars of %s, got %v", ticker.Symbol, ticker.History)
+		}
+	}
+
+	var history []struct{ Close json.Number }
+	_ = json.Unmarshal(resp.Data["history"], &history)
+
+	// bars starting from 2023-07-19
+	if len(history) != 3 {
+		t.Errorf("expected 3 bars, got %d", len(history))
+	}
+
+	// AAPL is looked up twice with the same prices, a single call
+	if calls["AAPL"] != 1 || calls["MSFT"] != 1 {
+		t.Errorf("expected a single call per symbol, got %v", calls)
+	}
+}
+
+func TestGraphQL_Batch(t *testing.T) {
+	svc := tickers.NewMockService()
+
+	svc.(*tickers.MockService).GetTickersFunc = func(ctx context.Context, in *tickers.GetTickersInput) (*tickers.GetTickersOutput, error) {
+		return &tickers.GetTickersOutput{Tickers: []tickerstypes.Ticker{
+			{Symbol: "AAPL"}, {Symbol: "MSFT"}, {Symbol: "AMZN"},
+		}}, nil
+	}
+
+	// every lookup of the batch waits for the others, it would time out if they were loaded one by one
+	var wg sync.WaitGroup
+	wg.Add(3)
+	svc.(*tickers.MockService).GetTickerHistoryFunc = func(ctx context.Context, in *tickers.GetTickerHistoryInput) (*tickers.GetTickerHistoryOutput, error) {
+		wg.Done()
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("expected %s to be loaded with the other symbols", in.Symbol)
+		}
+
+		return &tickers.GetTickerHistoryOutput{History: getHistory(in.Symbol)}, nil
+	}
+
+	code, resp := doQuery(t, newTestHandler(svc, 0, 0), `{ tickers { symbol history { close } } }`)
+	if code != http.StatusOK || len(resp.Errors) > 0 {
+		t.Errorf("expected StatusOK without errors, got %d %v", code, resp.Errors)
+	}
+}
+
+func TestGraphQL_Limits(t *testing.T) {
+	svc := tickers.NewMockService()
+
+	cases := map[string]struct {
+		query string
+		code  string
+	}{
+		"depth": {
+			query: `{ me { tickers { history { date } } } }`,
+			code:  errDepthLimit,
+		},
+		"complexity": {
+			query: `{ history(symbol: "AAPL", last: 500) { date open high low close } }`,
+			code:  "COMPLEXITY_LIMIT_EXCEEDED",
+		},
+		"fragment depth": {
+			query: `{ me { ...tickers } } fragment tickers on User { tickers { history { date } } }`,
+			code:  errDepthLimit,
+		},
+	}
+
+	for name, c := range cases {
+		_, resp := doQuery(t, newTestHandler(svc, 3, 2000), c.query)
+
+		if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != c.code {
+			t.Errorf("%s: expected %s, got %v", name, c.code, resp.Errors)
+		}
+		if resp.Data != nil {
+			t.Errorf("%s: expected no data, got %v", name, resp.Data)
+		}
+	}
+
+	// introspection is not limited by depth
+	_, resp := doQuery(t, newTestHandler(svc, 3, 2000), `{ __schema { types { fields { type { ofType { name } } } } } }`)
+	if len(resp.Errors) > 0 {
+		t.Errorf("expected introspection without errors, got %v", resp.Errors)
+	}
+}
+
+func TestGraphQL_Errors(t *testing.T) {
+	svc := tickers.NewMockService()
+
+	svc.(*tickers.MockService).GetTickerHistoryFunc = func(ctx context.Context, in *tickers.GetTickerHistoryInput) (*tickers.GetTickerHistoryOutput, error) {
+		if in.Symbol == "ZZZZ" {
+			return nil, &tickerstypes.ErrTickerNotFound{Symbol: in.Symbol}
+		}
+
+		return nil, context.DeadlineExceeded
+	}
+
+	h := newTestHandler(svc, 0, 0)
+
+	_, resp := doQuery(t, h, `{ history(symbol: "ZZZZ") { close } }`)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "ticker_not_found" {
+		t.Errorf("expected ticker_not_found, got %v", resp.Errors)
+	}
+
+	_, resp = doQuery(t, h, `{ history(symbol: "AAPL", last: 0) { close } }`)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "bad_request" {
+		t.Errorf("expected bad_request, got %v", resp.Errors)
+	} else if params, _ := resp.Errors[0].Extensions["params"].(map[string]interface{}); params["last"] == nil {
+		t.Errorf("expected last to be invalid, got %v", resp.Errors[0].Extensions)
+	}
+
+	// unexpected errors are hidden
+	_, resp = doQuery(t, h, `{ history(symbol: "AAPL") { close } }`)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "internal server error" {
+		t.Errorf("expected internal server error, got %v", resp.Errors)
+	}
+}
+
+func TestGraphQL_Unauthorized(t *testing.T) {
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewReader([]byte(`{"query": "{ me { username } }"}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	newTestHandler(tickers.NewMockService(), 0, 0).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected StatusUnauthorized, got %d", rec.Code)
+	}
+}
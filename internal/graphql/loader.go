@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/tickers"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"github.com/graph-gophers/dataloader/v7"
+	"sync"
+	"time"
+)
+
+// historyKey identifies the history lookups served by a single service call
+type historyKey struct {
+	Symbol     string
+	Before     time.Time
+	Adjusted   types.Adjustment
+	Currency   string
+	Resolution types.Resolution
+}
+
+type historyLoader = dataloader.Loader[historyKey, []types.TickerHistory]
+
+// newHistoryLoader batches the history lookups of a request, it caches every key so it must not
+// outlive the request; distinct keys of a batch are fetched concurrently
+func newHistoryLoader(svc tickers.Service) *historyLoader {
+	batch := func(ctx context.Context, keys []historyKey) []*dataloader.Result[[]types.TickerHistory] {
+		results := make([]*dataloader.Result[[]types.TickerHistory], len(keys))
+
+		var wg sync.WaitGroup
+		for i, key := range keys {
+			wg.Add(1)
+
+			go func(i int, key historyKey) {
+				defer wg.Done()
+
+				out, err := svc.GetTickerHistory(ctx, &tickers.GetTickerHistoryInput{
+					Symbol:     key.Symbol,
+					Before:     key.Before,
+					Adjusted:   key.Adjusted,
+					Currency:   key.Currency,
+					Resolution: key.Resolution,
+				})
+				if err != nil {
+					results[i] = &dataloader.Result[[]types.TickerHistory]{Error: err}
+					return
+				}
+
+				results[i] = &dataloader.Result[[]types.TickerHistory]{Data: out.History}
+			}(i, key)
+		}
+
+		wg.Wait()
+
+		return results
+	}
+
+	return dataloader.NewBatchedLoader(batch, dataloader.WithWait[historyKey, []types.TickerHistory](time.Millisecond*2))
+}
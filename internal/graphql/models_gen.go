@@ -0,0 +1,104 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graphql
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Adjustment of historical prices for corporate actions
+type Adjustment string
+
+const (
+	// raw prices
+	AdjustmentNone Adjustment = "NONE"
+	// back-adjusted for splits
+	AdjustmentSplit Adjustment = "SPLIT"
+	// back-adjusted for splits and reinvested cash dividends
+	AdjustmentTotal Adjustment = "TOTAL"
+)
+
+var AllAdjustment = []Adjustment{
+	AdjustmentNone,
+	AdjustmentSplit,
+	AdjustmentTotal,
+}
+
+func (e Adjustment) IsValid() bool {
+	switch e {
+	case AdjustmentNone, AdjustmentSplit, AdjustmentTotal:
+		return true
+	}
+	return false
+}
+
+func (e Adjustment) String() string {
+	return string(e)
+}
+
+func (e *Adjustment) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Adjustment(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Adjustment", str)
+	}
+	return nil
+}
+
+func (e Adjustment) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Resolution is the length of each bar of a history
+type Resolution string
+
+const (
+	ResolutionOneMinute      Resolution = "ONE_MINUTE"
+	ResolutionFiveMinutes    Resolution = "FIVE_MINUTES"
+	ResolutionFifteenMinutes Resolution = "FIFTEEN_MINUTES"
+	ResolutionOneHour        Resolution = "ONE_HOUR"
+	ResolutionOneDay         Resolution = "ONE_DAY"
+)
+
+var AllResolution = []Resolution{
+	ResolutionOneMinute,
+	ResolutionFiveMinutes,
+	ResolutionFifteenMinutes,
+	ResolutionOneHour,
+	ResolutionOneDay,
+}
+
+func (e Resolution) IsValid() bool {
+	switch e {
+	case ResolutionOneMinute, ResolutionFiveMinutes, ResolutionFifteenMinutes, ResolutionOneHour, ResolutionOneDay:
+		return true
+	}
+	return false
+}
+
+func (e Resolution) String() string {
+	return string(e)
+}
+
+func (e *Resolution) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Resolution(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Resolution", str)
+	}
+	return nil
+}
+
+func (e Resolution) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"strings"
+	"time"
+)
+
+// maxHistoryLast bounds the bars of a single history field
+const maxHistoryLast = 1000
+
+var adjustments = map[Adjustment]types.Adjustment{
+	AdjustmentNone:  types.AdjustmentNone,
+	AdjustmentSplit: types.AdjustmentSplit,
+	AdjustmentTotal: types.AdjustmentTotal,
+}
+
+var resolutions = map[Resolution]types.Resolution{
+	ResolutionOneMinute:      types.Resolution1m,
+	ResolutionFiveMinutes:    types.Resolution5m,
+	ResolutionFifteenMinutes: types.Resolution15m,
+	ResolutionOneHour:        types.Resolution1h,
+	ResolutionOneDay:         types.Resolution1d,
+}
+
+type Resolver struct {
+	Tickers tickers.Service
+}
+
+// historyArgs are the range and price arguments of a history field, nil when omitted
+type historyArgs struct {
+	last       *int
+	from       *time.Time
+	to         *time.Time
+	adjusted   *Adjustment
+	currency   *string
+	resolution *Resolution
+}
+
+// history loads the bars of symbol through the loader of the request, lookups of the same
+// symbol with the same prices share a service call whatever their range
+func (r *Resolver) history(ctx context.Context, symbol string, args historyArgs) ([]types.TickerHistory, error) {
+	last := 30
+	if args.last != nil {
+		last = *args.last
+	}
+
+	if last < 1 || last > maxHistoryLast {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params: map[string]string{
+				"last": fmt.Sprintf("invalid value %d: expected 1 to %d", last, maxHistoryLast),
+			},
+		}
+	}
+
+	key := historyKey{
+		Symbol:     symbol,
+		Adjusted:   types.AdjustmentNone,
+		Resolution: types.Resolution1d,
+	}
+	if args.to != nil {
+		key.Before = args.to.UTC()
+	}
+	if args.adjusted != nil {
+		key.Adjusted = adjustments[*args.adjusted]
+	}
+	if args.currency != nil {
+		key.Currency = strings.ToUpper(*args.currency)
+	}
+	if args.resolution != nil {
+		key.Resolution = resolutions[*args.resolution]
+	}
+
+	loader, ok := ctx.Value("graphql_history_loader").(*historyLoader)
+	if !ok {
+		loader = newHistoryLoader(r.Tickers)
+	}
+
+	history, err := loader.Load(ctx, key)()
+	if err != nil {
+		return nil, err
+	}
+
+	// history is newest first, the loaded slice is shared with other fields
+	out := make([]types.TickerHistory, 0, last)
+	for _, v := range history {
+		if args.from != nil && v.Date.Before(*args.from) {
+			break
+		}
+
+		out = append(out, v)
+		if len(out) == last {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+// user is the user authenticated by the handler
+func user(ctx context.Context) (*User, error) {
+	u, ok := ctx.Value("graphql_user").(*User)
+	if !ok {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	return u, nil
+}
+
+func (r *Resolver) tickers(ctx context.Context, username string, currency *string) ([]types.Ticker, error) {
+	in := &tickers.GetTickersInput{
+		Username: username,
+	}
+	if currency != nil {
+		in.Currency = strings.ToUpper(*currency)
+	}
+
+	out, err := r.Tickers.GetTickers(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Tickers, nil
+}
@@ -0,0 +1,39 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"io"
+	"strconv"
+)
+
+// User is the user of the token
+type User struct {
+	Username string
+}
+
+func MarshalDecimal(d decimal.Decimal) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, d.String())
+	})
+}
+
+// UnmarshalDecimal accepts a number or a decimal string
+func UnmarshalDecimal(v interface{}) (decimal.Decimal, error) {
+	switch v := v.(type) {
+	case string:
+		return decimal.Parse(v)
+	case json.Number:
+		return decimal.Parse(v.String())
+	case int:
+		return decimal.NewFromInt(int64(v)), nil
+	case int64:
+		return decimal.NewFromInt(v), nil
+	case float64:
+		return decimal.Parse(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return decimal.Decimal{}, fmt.Errorf("%T is not a decimal", v)
+	}
+}
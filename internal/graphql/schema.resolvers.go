@@ -0,0 +1,55 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.36
+
+import (
+	"context"
+	"time"
+
+	"github.com/falmar/richerage-api/internal/tickers/types"
+)
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*User, error) {
+	return user(ctx)
+}
+
+// Tickers is the resolver for the tickers field.
+func (r *queryResolver) Tickers(ctx context.Context, currency *string) ([]types.Ticker, error) {
+	u, err := user(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.tickers(ctx, u.Username, currency)
+}
+
+// History is the resolver for the history field.
+func (r *queryResolver) History(ctx context.Context, symbol string, last *int, from *time.Time, to *time.Time, adjusted *Adjustment, currency *string, resolution *Resolution) ([]types.TickerHistory, error) {
+	return r.history(ctx, symbol, historyArgs{last, from, to, adjusted, currency, resolution})
+}
+
+// History is the resolver for the history field.
+func (r *tickerResolver) History(ctx context.Context, obj *types.Ticker, last *int, from *time.Time, to *time.Time, adjusted *Adjustment, currency *string, resolution *Resolution) ([]types.TickerHistory, error) {
+	return r.history(ctx, obj.Symbol, historyArgs{last, from, to, adjusted, currency, resolution})
+}
+
+// Tickers is the resolver for the tickers field.
+func (r *userResolver) Tickers(ctx context.Context, obj *User, currency *string) ([]types.Ticker, error) {
+	return r.tickers(ctx, obj.Username, currency)
+}
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Ticker returns TickerResolver implementation.
+func (r *Resolver) Ticker() TickerResolver { return &tickerResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type queryResolver struct{ *Resolver }
+type tickerResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
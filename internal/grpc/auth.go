@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pb"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+)
+
+var _ pb.AuthServiceServer = (*authServer)(nil)
+
+type authServer struct {
+	pb.UnimplementedAuthServiceServer
+
+	login       kitgrpc.Handler
+	verifyToken kitgrpc.Handler
+}
+
+func (s *authServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	_, resp, err := s.login.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, kit.GRPCError(err)
+	}
+
+	return resp.(*pb.LoginResponse), nil
+}
+
+func (s *authServer) VerifyToken(ctx context.Context, req *pb.VerifyTokenRequest) (*pb.VerifyTokenResponse, error) {
+	_, resp, err := s.verifyToken.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, kit.GRPCError(err)
+	}
+
+	return resp.(*pb.VerifyTokenResponse), nil
+}
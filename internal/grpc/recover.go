@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"runtime/debug"
+)
+
+// recoverUnary answers codes.Internal to a call that panics instead of ending the process
+func recoverUnary(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = recovered(logger, info.FullMethod, v)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// recoverStream is recoverUnary for streams, the messages sent before the panic are kept
+func recoverStream(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = recovered(logger, info.FullMethod, v)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovered(logger *zap.Logger, method string, v interface{}) error {
+	logger.Error("grpc: panic",
+		zap.String("method", method),
+		zap.Any("panic", v),
+		zap.ByteString("stack", debug.Stack()),
+	)
+
+	return status.Error(codes.Internal, "internal error")
+}
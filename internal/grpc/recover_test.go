@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	logger := zaplogger.New(true)
+
+	_, err := recoverUnary(logger)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/Tickers"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("decimal: overflows")
+		})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected codes.Internal, got %v", err)
+	}
+
+	err = recoverStream(logger)(nil, nil, &grpc.StreamServerInfo{FullMethod: "/StreamTickerHistory"},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			panic("decimal: overflows")
+		})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected codes.Internal, got %v", err)
+	}
+
+	// calls that do not panic are untouched
+	resp, err := recoverUnary(logger)(context.Background(), "req", &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+	if resp != "req" || err != nil {
+		t.Errorf("expected the response of the handler, got %v %v", resp, err)
+	}
+}
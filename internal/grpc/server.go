@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+	authendpoints "github.com/falmar/richerage-api/internal/auth/endpoint"
+	authtransport "github.com/falmar/richerage-api/internal/auth/transport"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pb"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	tickersendpoints "github.com/falmar/richerage-api/internal/tickers/endpoint"
+	tickerstransport "github.com/falmar/richerage-api/internal/tickers/transport"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server registers the gRPC services over the same endpoints as the http handler
+func Server(_ context.Context, config *bootstrap.Config) (*grpc.Server, error) {
+	loggerHandler := &kit.LoggerHandler{
+		Logger: config.Logger,
+	}
+	errorHandler := &kit.ErrorHandler{
+		Logger: config.Logger,
+	}
+
+	options := []kitgrpc.ServerOption{
+		kitgrpc.ServerErrorHandler(errorHandler),
+		kitgrpc.ServerBefore(loggerHandler.GRPCBefore),
+		kitgrpc.ServerAfter(loggerHandler.GRPCAfter),
+	}
+	// tickers are served to the user of the token
+	authOptions := append([]kitgrpc.ServerOption{
+		kitgrpc.ServerBefore(tickerstransport.GRPCTokenDecoder),
+	}, options...)
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(recoverUnary(config.Logger)),
+		grpc.ChainStreamInterceptor(recoverStream(config.Logger)),
+	)
+
+	pb.RegisterAuthServiceServer(server, &authServer{
+		login: kitgrpc.NewServer(
+			authendpoints.MakeLoginEndpoint(config.AuthService),
+			authtransport.LoginGRPCRequestDecoder,
+			authtransport.LoginGRPCResponseEncoder,
+			options...,
+		),
+		verifyToken: kitgrpc.NewServer(
+			authendpoints.MakeVerifyTokenEndpoint(config.AuthService),
+			authtransport.VerifyTokenGRPCRequestDecoder,
+			authtransport.VerifyTokenGRPCResponseEncoder,
+			options...,
+		),
+	})
+
+	tickerEndpoint := tickersendpoints.MakeTickersEndpoint(config.RicherageService)
+	tickerEndpoint = tickersendpoints.MakeTickersAuthEndpoint(config.AuthService, tickerEndpoint)
+
+	historyEndpoint := tickersendpoints.MakeTickerHistoryEndpoint(config.RicherageService)
+	historyEndpoint = tickersendpoints.MakeTickerHistoryAuthEndpoint(config.AuthService, historyEndpoint)
+
+	historyStreamEndpoint := tickersendpoints.MakeTickerHistoryStreamEndpoint(config.RicherageService)
+	historyStreamEndpoint = tickersendpoints.MakeTickerHistoryStreamAuthEndpoint(config.AuthService, historyStreamEndpoint)
+
+	watchPricesEndpoint := tickersendpoints.MakeTickersStreamEndpoint(config.RicherageService)
+	watchPricesEndpoint = tickersendpoints.MakeTickersStreamAuthEndpoint(config.AuthService, watchPricesEndpoint)
+
+	pb.RegisterTickersServiceServer(server, &tickersServer{
+		tickers: kitgrpc.NewServer(
+			tickerEndpoint,
+			tickerstransport.TickersGRPCRequestDecoder,
+			tickerstransport.TickersGRPCResponseEncoder,
+			authOptions...,
+		),
+		history: kitgrpc.NewServer(
+			historyEndpoint,
+			tickerstransport.TickerHistoryGRPCRequestDecoder,
+			tickerstransport.TickerHistoryGRPCResponseEncoder,
+			authOptions...,
+		),
+		historyStream: kitgrpc.NewServer(
+			historyStreamEndpoint,
+			tickerstransport.TickerHistoryStreamGRPCRequestDecoder,
+			tickerstransport.TickerHistoryStreamGRPCResponseEncoder,
+			authOptions...,
+		),
+		watchPrices: kitgrpc.NewServer(
+			watchPricesEndpoint,
+			tickerstransport.WatchPricesGRPCRequestDecoder,
+			tickerstransport.WatchPricesGRPCResponseEncoder,
+			authOptions...,
+		),
+	})
+
+	// lets grpcurl and friends list the services
+	reflection.Register(server)
+
+	return server, nil
+}
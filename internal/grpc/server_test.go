@@ -0,0 +1,182 @@
+package grpc
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pb"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+const testToken = "6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377"
+
+// newTestConn serves the gRPC services over an in-memory listener
+func newTestConn(t *testing.T) *grpc.ClientConn {
+	ctx := context.Background()
+
+	config, err := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	return newConfigConn(t, config)
+}
+
+// newConfigConn serves the gRPC services of config over an in-memory listener
+func newConfigConn(t *testing.T, config *bootstrap.Config) *grpc.ClientConn {
+	ctx := context.Background()
+
+	server, err := Server(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// reason is the code of the coded error behind a status
+func reason(st *status.Status) string {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+
+	return ""
+}
+
+func TestGRPC_Login(t *testing.T) {
+	client := pb.NewAuthServiceClient(newTestConn(t))
+
+	resp, err := client.Login(context.Background(), &pb.LoginRequest{Username: "test", Password: "test"})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if resp.GetToken() == "" {
+		t.Errorf("expected token to be not empty")
+	}
+
+	verified, err := client.VerifyToken(context.Background(), &pb.VerifyTokenRequest{Token: resp.GetToken()})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if verified.GetUsername() != "test" || verified.GetAdmin() {
+		t.Errorf("expected non admin test, got %v", verified)
+	}
+}
+
+func TestGRPC_Login_Invalid(t *testing.T) {
+	client := pb.NewAuthServiceClient(newTestConn(t))
+
+	_, err := client.Login(context.Background(), &pb.LoginRequest{Username: "test"})
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || reason(st) != "bad_request" {
+		t.Errorf("expected InvalidArgument bad_request, got %v", err)
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, d := range st.Details() {
+		if v, ok := d.(*errdetails.BadRequest); ok {
+			violations = v.GetFieldViolations()
+		}
+	}
+	if len(violations) != 1 || violations[0].GetField() != "password" || violations[0].GetDescription() != "required" {
+		t.Errorf("expected password to be required, got %v", violations)
+	}
+
+	_, err = client.VerifyToken(context.Background(), &pb.VerifyTokenRequest{Token: "invalid"})
+	if st := status.Convert(err); st.Code() != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestGRPC_GetTickers(t *testing.T) {
+	client := pb.NewTickersServiceClient(newTestConn(t))
+
+	resp, err := client.GetTickers(withToken(testToken), &pb.GetTickersRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if len(resp.GetTickers()) == 0 {
+		t.Errorf("expected tickers, got none")
+	}
+	for _, v := range resp.GetTickers() {
+		if v.GetSymbol() == "" || v.GetPrice() == "" || v.GetCurrency() == "" {
+			t.Errorf("expected symbol, price and currency, got %v", v)
+		}
+	}
+
+	// the bare token is accepted too
+	md := metadata.AppendToOutgoingContext(context.Background(), "authorization", testToken)
+	if _, err := client.GetTickers(md, &pb.GetTickersRequest{}); err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+	}
+
+	_, err = client.GetTickers(context.Background(), &pb.GetTickersRequest{})
+	if st := status.Convert(err); st.Code() != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+
+	_, err = client.GetTickers(withToken(testToken), &pb.GetTickersRequest{Currency: "EURO"})
+	if st := status.Convert(err); st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestGRPC_GetTickerHistory(t *testing.T) {
+	client := pb.NewTickersServiceClient(newTestConn(t))
+
+	resp, err := client.GetTickerHistory(withToken(testToken), &pb.GetTickerHistoryRequest{Symbol: "AAPL"})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	bars := resp.GetBars()
+	if len(bars) == 0 || resp.GetResolution() != "1d" {
+		t.Fatalf("expected daily bars, got %d %s", len(bars), resp.GetResolution())
+	}
+	if !bars[0].GetDate().AsTime().After(bars[len(bars)-1].GetDate().AsTime()) {
+		t.Errorf("expected bars newest first")
+	}
+	if bars[0].GetClose() == "" || bars[0].GetSymbol() != "AAPL" || bars[0].GetCurrency() != "USD" {
+		t.Errorf("unexpected bar %v", bars[0])
+	}
+
+	_, err = client.GetTickerHistory(withToken(testToken), &pb.GetTickerHistoryRequest{Symbol: "ZZZZ"})
+	if st := status.Convert(err); st.Code() != codes.NotFound || reason(st) != "ticker_not_found" {
+		t.Errorf("expected NotFound ticker_not_found, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pb"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestGRPC_StreamHistory(t *testing.T) {
+	client := pb.NewTickersServiceClient(newTestConn(t))
+
+	unary, err := client.GetTickerHistory(withToken(testToken), &pb.GetTickerHistoryRequest{Symbol: "AAPL"})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	stream, err := client.StreamHistory(withToken(testToken), &pb.StreamHistoryRequest{Symbol: "AAPL", ChunkSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	var chunks int
+	var bars []*pb.Bar
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+
+		if len(resp.GetBars()) == 0 || len(resp.GetBars()) > 2 {
+			t.Errorf("expected 1 to 2 bars per chunk, got %d", len(resp.GetBars()))
+		}
+		if resp.GetResolution() != "1d" {
+			t.Errorf("expected resolution 1d, got %s", resp.GetResolution())
+		}
+
+		chunks++
+		bars = append(bars, resp.GetBars()...)
+	}
+
+	// AAPL was never renamed, only the last chunk may be short
+	if expected := (len(unary.GetBars()) + 1) / 2; chunks != expected {
+		t.Errorf("expected %d chunks, got %d", expected, chunks)
+	}
+
+	// same bars as the unary call, newest first
+	if len(bars) != len(unary.GetBars()) {
+		t.Fatalf("expected %d bars, got %d", len(unary.GetBars()), len(bars))
+	}
+	for i, v := range bars {
+		expected := unary.GetBars()[i]
+		if !v.GetDate().AsTime().Equal(expected.GetDate().AsTime()) || v.GetClose() != expected.GetClose() {
+			t.Errorf("expected bar %d to be %v, got %v", i, expected, v)
+		}
+	}
+}
+
+func TestGRPC_StreamHistory_Invalid(t *testing.T) {
+	client := pb.NewTickersServiceClient(newTestConn(t))
+
+	// errors are sent in place of the first chunk
+	recv := func(ctx context.Context, req *pb.StreamHistoryRequest) error {
+		stream, err := client.StreamHistory(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		_, err = stream.Recv()
+		return err
+	}
+
+	err := recv(context.Background(), &pb.StreamHistoryRequest{Symbol: "AAPL"})
+	if st := status.Convert(err); st.Code() != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+
+	err = recv(withToken(testToken), &pb.StreamHistoryRequest{Symbol: "ZZZZ"})
+	if st := status.Convert(err); st.Code() != codes.NotFound || reason(st) != "ticker_not_found" {
+		t.Errorf("expected NotFound ticker_not_found, got %v", err)
+	}
+
+	err = recv(withToken(testToken), &pb.StreamHistoryRequest{Symbol: "AAPL", ChunkSize: 5001})
+	if st := status.Convert(err); st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestGRPC_WatchPrices(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	v := viper.New()
+	v.Set("stream.interval", "5ms")
+
+	config, err := bootstrap.New(ctx, v, zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	go config.PriceFeed.Run(ctx)
+
+	client := pb.NewTickersServiceClient(newConfigConn(t, config))
+
+	// reads n updates, then disconnects
+	readIDs := func(lastEventID uint64, n int) []uint64 {
+		reqCtx, reqCancel := context.WithTimeout(withToken(testToken), time.Second*5)
+		defer reqCancel()
+
+		stream, err := client.WatchPrices(reqCtx, &pb.WatchPricesRequest{LastEventId: lastEventID})
+		if err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+
+		var ids []uint64
+		for len(ids) < n {
+			u, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("unexpected error to be nil, got: %v", err)
+			}
+
+			if u.GetSymbol() == "" || u.GetPrice() == "" || u.GetTime() == nil {
+				t.Errorf("expected symbol, price and time, got %v", u)
+			}
+
+			ids = append(ids, u.GetId())
+		}
+
+		return ids
+	}
+
+	first := readIDs(0, 3)
+
+	// wait for more ticks to be missed
+	time.Sleep(time.Millisecond * 50)
+
+	resumed := readIDs(first[len(first)-1], 1)
+
+	if resumed[0] <= first[len(first)-1] {
+		t.Errorf("expected resumed id to be after %d, got %d", first[len(first)-1], resumed[0])
+	}
+}
+
+func TestGRPC_WatchPrices_Deadline(t *testing.T) {
+	client := pb.NewTickersServiceClient(newTestConn(t))
+
+	// the feed is not running, nothing is sent before the deadline
+	ctx, cancel := context.WithTimeout(withToken(testToken), time.Millisecond*50)
+	defer cancel()
+
+	stream, err := client.WatchPrices(ctx, &pb.WatchPricesRequest{Symbols: []string{"AAPL"}})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	_, err = stream.Recv()
+	if st := status.Convert(err); st.Code() != codes.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+
+	stream, err = client.WatchPrices(withToken(testToken), &pb.WatchPricesRequest{Symbols: []string{"ZZZZ"}})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	_, err = stream.Recv()
+	if st := status.Convert(err); st.Code() != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
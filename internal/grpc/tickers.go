@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pb"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers/transport"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+)
+
+var _ pb.TickersServiceServer = (*tickersServer)(nil)
+
+type tickersServer struct {
+	pb.UnimplementedTickersServiceServer
+
+	tickers kitgrpc.Handler
+	history kitgrpc.Handler
+	// streaming handlers send their messages on the stream set in ctx by their encoder
+	historyStream kitgrpc.Handler
+	watchPrices   kitgrpc.Handler
+}
+
+func (s *tickersServer) GetTickers(ctx context.Context, req *pb.GetTickersRequest) (*pb.GetTickersResponse, error) {
+	_, resp, err := s.tickers.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, kit.GRPCError(err)
+	}
+
+	return resp.(*pb.GetTickersResponse), nil
+}
+
+func (s *tickersServer) GetTickerHistory(ctx context.Context, req *pb.GetTickerHistoryRequest) (*pb.GetTickerHistoryResponse, error) {
+	_, resp, err := s.history.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, kit.GRPCError(err)
+	}
+
+	return resp.(*pb.GetTickerHistoryResponse), nil
+}
+
+func (s *tickersServer) StreamHistory(req *pb.StreamHistoryRequest, stream pb.TickersService_StreamHistoryServer) error {
+	ctx := transport.GRPCStreamContext(stream.Context(), stream)
+
+	if _, _, err := s.historyStream.ServeGRPC(ctx, req); err != nil {
+		return kit.GRPCError(err)
+	}
+
+	return nil
+}
+
+func (s *tickersServer) WatchPrices(req *pb.WatchPricesRequest, stream pb.TickersService_WatchPricesServer) error {
+	ctx := transport.GRPCStreamContext(stream.Context(), stream)
+
+	if _, _, err := s.watchPrices.ServeGRPC(ctx, req); err != nil {
+		return kit.GRPCError(err)
+	}
+
+	return nil
+}
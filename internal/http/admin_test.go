@@ -0,0 +1,272 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const adminToken = "6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377"
+
+const adminKey = "admin key"
+
+func newAdminServer(t *testing.T, backend string) *httptest.Server {
+	ctx := context.Background()
+
+	v := viper.New()
+	v.Set("port", "8080")
+	v.Set("storage.backend", backend)
+	v.Set("admin.users", "test")
+	v.Set("admin.key", adminKey)
+
+	config, err := bootstrap.New(ctx, v, zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	return httptest.NewServer(handler)
+}
+
+func doAdminRequest(t *testing.T, server *httptest.Server, method string, path string, body string, token string) *http.Response {
+	return doKeyedRequest(t, server, method, path, body, token, adminKey)
+}
+
+func doKeyedRequest(t *testing.T, server *httptest.Server, method string, path string, body string, token string, key string) *http.Response {
+	req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Admin-Key", key)
+	req.SetBasicAuth(token, "")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	return resp
+}
+
+func TestHttp_Admin_Prices(t *testing.T) {
+	server := newAdminServer(t, "memory")
+	defer server.Close()
+
+	resp := doAdminRequest(t, server, "PUT", "/admin/prices/aapl",
+		`{"prices":[{"date":"2024-01-02","close":185.64},{"date":"2024-01-03","close":"184.25","volume":58414500}]}`, adminToken)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status code to be 200, got: %d", resp.StatusCode)
+	}
+
+	body := map[string]interface{}{}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	if body["symbol"] != "AAPL" || body["inserted"] != 2.0 || body["updated"] != 0.0 {
+		t.Errorf("unexpected body %v", body)
+	}
+
+	// pushed bars are served right away
+	resp = doAdminRequest(t, server, "GET", "/tickers/AAPL/history", "", adminToken)
+	defer resp.Body.Close()
+
+	var history []map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&history)
+	if len(history) != 2 || history[0]["date"] != "2024-01-03" || history[0]["price"] != 184.25 {
+		t.Errorf("unexpected history %v", history)
+	}
+
+	resp = doAdminRequest(t, server, "DELETE", "/admin/prices/AAPL?to=2024-01-02", "", adminToken)
+	defer resp.Body.Close()
+
+	body = map[string]interface{}{}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK || body["deleted"] != 1.0 {
+		t.Errorf("expected 1 deleted, got %d %v", resp.StatusCode, body)
+	}
+}
+
+func TestHttp_Admin_Prices_Invalid(t *testing.T) {
+	server := newAdminServer(t, "memory")
+	defer server.Close()
+
+	resp := doAdminRequest(t, server, "PUT", "/admin/prices/AAPL",
+		`{"prices":[{"date":"2024-01-02","close":185.64},{"date":"2024-01-03","close":-1}]}`, adminToken)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code to be 400, got: %d", resp.StatusCode)
+	}
+
+	respError := &kit.HttpErrorBody{}
+	_ = json.NewDecoder(resp.Body).Decode(respError)
+	if _, ok := respError.Params["prices[1]"]; !ok {
+		t.Errorf("expected prices[1] to be invalid, got %v", respError.Params)
+	}
+
+	// nothing was written
+	resp = doAdminRequest(t, server, "GET", "/tickers/AAPL/history", "", adminToken)
+	defer resp.Body.Close()
+
+	var history []map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&history)
+	if len(history) != 0 {
+		t.Errorf("expected empty history, got %v", history)
+	}
+}
+
+func TestHttp_Admin_Holdings(t *testing.T) {
+	server := newAdminServer(t, "memory")
+	defer server.Close()
+
+	resp := doAdminRequest(t, server, "PUT", "/admin/prices/META", `{"prices":[{"date":"2024-01-02","close":346.29}]}`, adminToken)
+	resp.Body.Close()
+
+	resp = doAdminRequest(t, server, "PUT", "/admin/users/test/holdings", `{"symbols":["fb"]}`, adminToken)
+	defer resp.Body.Close()
+
+	body := map[string]interface{}{}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK || len(body["symbols"].([]interface{})) != 1 || body["symbols"].([]interface{})[0] != "META" {
+		t.Errorf("expected FB to be held as META, got %d %v", resp.StatusCode, body)
+	}
+
+	resp = doAdminRequest(t, server, "GET", "/tickers", "", adminToken)
+	defer resp.Body.Close()
+
+	var tickers []map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&tickers)
+	if len(tickers) != 1 || tickers[0]["symbol"] != "META" || tickers[0]["price"] != 346.29 {
+		t.Errorf("unexpected tickers %v", tickers)
+	}
+}
+
+func TestHttp_Admin_Forbidden(t *testing.T) {
+	server := newAdminServer(t, "memory")
+	defer server.Close()
+
+	resp := doAdminRequest(t, server, "POST", "/login", `{"username":"other","password":"other"}`, "")
+	defer resp.Body.Close()
+
+	login := map[string]string{}
+	_ = json.NewDecoder(resp.Body).Decode(&login)
+
+	resp = doAdminRequest(t, server, "PUT", "/admin/users/other/holdings", `{"symbols":["AAPL"]}`, login["token"])
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status code to be 403, got: %d", resp.StatusCode)
+	}
+
+	resp = doAdminRequest(t, server, "PUT", "/admin/users/other/holdings", `{"symbols":["AAPL"]}`, "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code to be 401, got: %d", resp.StatusCode)
+	}
+}
+
+func TestHttp_Admin_ClaimedAdmin(t *testing.T) {
+	server := newAdminServer(t, "memory")
+	defer server.Close()
+
+	// any password logs in as the admin username, only the admin key proves the admin
+	resp := doKeyedRequest(t, server, "POST", "/login", `{"username":"test","password":"guess"}`, "", "")
+	defer resp.Body.Close()
+
+	login := map[string]string{}
+	_ = json.NewDecoder(resp.Body).Decode(&login)
+	if login["token"] == "" {
+		t.Fatalf("expected a token, got status %d", resp.StatusCode)
+	}
+
+	for _, key := range []string{"", "guess"} {
+		resp = doKeyedRequest(t, server, "PUT", "/admin/users/test/holdings", `{"symbols":["AAPL"]}`, login["token"], key)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected status code to be 403 with key %q, got: %d", key, resp.StatusCode)
+		}
+	}
+
+	resp = doKeyedRequest(t, server, "PUT", "/admin/users/test/holdings", `{"symbols":["AAPL"]}`, login["token"], adminKey)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status code to be 200 with the admin key, got: %d", resp.StatusCode)
+	}
+}
+
+func TestHttp_Admin_ReadOnly(t *testing.T) {
+	server := newAdminServer(t, "")
+	defer server.Close()
+
+	resp := doAdminRequest(t, server, "PUT", "/admin/prices/AAPL", `{"prices":[{"date":"2024-01-02","close":185.64}]}`, adminToken)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Errorf("expected status code to be 501, got: %d", resp.StatusCode)
+	}
+}
+
+func TestHttp_Admin_Prices_NoSeededSources(t *testing.T) {
+	server := newAdminServer(t, "memory")
+	defer server.Close()
+
+	// a symbol the seeded data pays dividends on
+	var symbol string
+	var exDate time.Time
+	seeded := storage.NewSeededCorporateActions(nil)
+	for _, candidate := range []string{"AAPL", "ADBE", "AMZN", "JNJ", "KO", "MSFT", "NVDA", "PFE", "PG", "V", "WMT"} {
+		actions, _ := seeded.GetCorporateActions(context.Background(), candidate)
+		for _, a := range actions {
+			if a.Type == types.CorporateActionDividend {
+				symbol, exDate = candidate, a.ExDate
+				break
+			}
+		}
+		if symbol != "" {
+			break
+		}
+	}
+	if symbol == "" {
+		t.Skip("no seeded dividends")
+	}
+
+	body := `{"prices":[{"date":"` + exDate.AddDate(0, 0, -7).Format("2006-01-02") + `","close":100.10},` +
+		`{"date":"` + exDate.AddDate(0, 0, 7).Format("2006-01-02") + `","close":100.20}]}`
+	resp := doAdminRequest(t, server, "PUT", "/admin/prices/"+symbol, body, adminToken)
+	resp.Body.Close()
+
+	// stored prices are not adjusted by generated dividends
+	resp = doAdminRequest(t, server, "GET", "/tickers/"+symbol+"/history?adjusted=total", "", adminToken)
+	defer resp.Body.Close()
+
+	var history []map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&history)
+	if len(history) != 2 || history[1]["price"] != 100.10 {
+		t.Errorf("expected the stored prices, got %v", history)
+	}
+
+	// nor converted with generated rates
+	resp = doAdminRequest(t, server, "GET", "/tickers/"+symbol+"/history?currency=EUR", "", adminToken)
+	defer resp.Body.Close()
+
+	respError := &kit.HttpErrorBody{}
+	_ = json.NewDecoder(resp.Body).Decode(respError)
+	if resp.StatusCode != http.StatusBadRequest || respError.Code != "currency_not_supported" {
+		t.Errorf("expected 400 currency_not_supported, got %d %v", resp.StatusCode, respError)
+	}
+}
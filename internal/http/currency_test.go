@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttp_Currency(t *testing.T) {
+	ctx := context.Background()
+
+	config, _ := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	handler, _ := Handler(ctx, config)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(path string, body interface{}) *http.Response {
+		req, _ := http.NewRequest("GET", server.URL+path, nil)
+		req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+		defer resp.Body.Close()
+
+		_ = json.NewDecoder(resp.Body).Decode(body)
+
+		return resp
+	}
+
+	var raw, converted []map[string]interface{}
+	get("/tickers", &raw)
+	resp := get("/tickers?currency=eur", &converted)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+	if len(raw) == 0 || len(raw) != len(converted) {
+		t.Fatalf("expected %d tickers, got %d", len(raw), len(converted))
+	}
+
+	for i := range raw {
+		if raw[i]["currency"] == "" || raw[i]["currency"] == nil {
+			t.Errorf("expected %v currency to be set", raw[i]["symbol"])
+		}
+		if converted[i]["symbol"] != raw[i]["symbol"] || converted[i]["currency"] != "EUR" {
+			t.Errorf("expected %v in EUR, got %v", raw[i]["symbol"], converted[i])
+		}
+		if converted[i]["price"] == raw[i]["price"] {
+			t.Errorf("expected %v price to be converted, got %v", raw[i]["symbol"], converted[i]["price"])
+		}
+	}
+
+	var history []map[string]interface{}
+	resp = get("/tickers/AAPL/history?currency=GBP", &history)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+
+	for _, v := range history {
+		if v["currency"] != "GBP" {
+			t.Errorf("expected history in GBP, got %v", v)
+		}
+	}
+
+	// unknown but well formed currencies are rejected by the rate store
+	for _, path := range []string{"/tickers?currency=XYZ", "/tickers/AAPL/history?currency=XYZ"} {
+		var body map[string]interface{}
+		resp = get(path, &body)
+
+		if resp.StatusCode != http.StatusBadRequest || body["code"] != "currency_not_supported" {
+			t.Errorf("%s: expected 400 currency_not_supported, got %d %v", path, resp.StatusCode, body)
+		}
+	}
+}
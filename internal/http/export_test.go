@@ -0,0 +1,146 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"github.com/apache/arrow/go/v11/parquet/file"
+	"github.com/apache/arrow/go/v11/parquet/pqarrow"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"net/http"
+	"testing"
+)
+
+func TestHttp_ExportHistory_Arrow(t *testing.T) {
+	server := newNegotiationServer(t)
+	defer server.Close()
+
+	_, body := getNegotiated(t, server.URL+"/tickers/AAPL/history", "")
+
+	var bars []map[string]interface{}
+	if err := json.Unmarshal(body, &bars); err != nil || len(bars) == 0 {
+		t.Fatalf("expected the history of AAPL, got %s", body)
+	}
+
+	resp, body := getNegotiated(t, server.URL+"/export/history?symbols=aapl&format=arrow", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code 200, got %d: %s", resp.StatusCode, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/vnd.apache.arrow.stream" {
+		t.Errorf("expected the arrow stream content type, got %s", ct)
+	}
+
+	r, err := ipc.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer r.Release()
+
+	var dates []string
+	for r.Next() {
+		column := r.Record().Column(1).(*array.Date32)
+		for i := 0; i < column.Len(); i++ {
+			dates = append(dates, column.Value(i).ToTime().Format("2006-01-02"))
+		}
+	}
+
+	// every bar of the history, oldest first
+	if len(dates) != len(bars) {
+		t.Fatalf("expected %d bars got %d", len(bars), len(dates))
+	}
+	if dates[0] != bars[len(bars)-1]["date"] || dates[len(dates)-1] != bars[0]["date"] {
+		t.Errorf("expected dates from %s to %s, got %s to %s", bars[len(bars)-1]["date"], bars[0]["date"], dates[0], dates[len(dates)-1])
+	}
+}
+
+func TestHttp_ExportHistory_Parquet(t *testing.T) {
+	server := newNegotiationServer(t)
+	defer server.Close()
+
+	_, body := getNegotiated(t, server.URL+"/tickers/MSFT/history", "")
+
+	var bars []map[string]interface{}
+	if err := json.Unmarshal(body, &bars); err != nil || len(bars) < 3 {
+		t.Skipf("expected at least 3 bars of MSFT, got %s", body)
+	}
+
+	// the bars between the second oldest and the second newest
+	from, to := bars[len(bars)-2]["date"].(string), bars[1]["date"].(string)
+
+	resp, body := getNegotiated(t, server.URL+"/export/history?symbols=MSFT,AAPL&from="+from+"&to="+to, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code 200, got %d: %s", resp.StatusCode, body)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); cd != `attachment; filename="history.parquet"` {
+		t.Errorf("expected a history.parquet attachment, got %s", cd)
+	}
+
+	pf, err := file.NewParquetReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	fr, _ := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	tbl, err := fr.ReadTable(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer tbl.Release()
+
+	symbols := map[string]int{}
+	for _, chunk := range tbl.Column(0).Data().Chunks() {
+		column := chunk.(*array.String)
+		for i := 0; i < column.Len(); i++ {
+			symbols[column.Value(i)]++
+		}
+	}
+	if symbols["MSFT"] != len(bars)-2 || symbols["AAPL"] == 0 {
+		t.Errorf("expected %d bars of MSFT and bars of AAPL, got %v", len(bars)-2, symbols)
+	}
+
+	for _, chunk := range tbl.Column(1).Data().Chunks() {
+		column := chunk.(*array.Date32)
+		for i := 0; i < column.Len(); i++ {
+			if d := column.Value(i).ToTime().Format("2006-01-02"); d < from || d > to {
+				t.Errorf("expected dates from %s to %s, got %s", from, to, d)
+			}
+		}
+	}
+}
+
+func TestHttp_ExportHistory_Errors(t *testing.T) {
+	server := newNegotiationServer(t)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		url    string
+		status int
+		code   string
+	}{
+		{url: "/export/history", status: http.StatusBadRequest, code: "bad_request"},
+		{url: "/export/history?symbols=AAPL&format=csv", status: http.StatusBadRequest, code: "bad_request"},
+		{url: "/export/history?symbols=AAPL,NOPE", status: http.StatusNotFound, code: "ticker_not_found"},
+	} {
+		resp, body := getNegotiated(t, server.URL+tc.url, "")
+
+		var errBody kit.HttpErrorBody
+		_ = json.Unmarshal(body, &errBody)
+
+		if resp.StatusCode != tc.status || errBody.Code != tc.code {
+			t.Errorf("%s: expected %d %s, got %d %s", tc.url, tc.status, tc.code, resp.StatusCode, body)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/export/history?symbols=AAPL")
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code 401, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHttp_GraphQL(t *testing.T) {
+	ctx := context.Background()
+
+	v := viper.New()
+	v.Set("port", "8080")
+
+	config, err := bootstrap.New(ctx, v, zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	query := `{"query": "{ me { username tickers { symbol price currency history(last: 3) { date close symbol } } } }"}`
+
+	req, _ := http.NewRequest("POST", server.URL+"/graphql", strings.NewReader(query))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Me struct {
+				Username string `json:"username"`
+				Tickers  []struct {
+					Symbol  string          `json:"symbol"`
+					Price   json.RawMessage `json:"price"`
+					History []struct {
+						Date   string      `json:"date"`
+						Close  json.Number `json:"close"`
+						Symbol string      `json:"symbol"`
+					} `json:"history"`
+				} `json:"tickers"`
+			} `json:"me"`
+		} `json:"data"`
+		Errors []interface{} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	if len(body.Errors) > 0 {
+		t.Fatalf("expected no errors, got %v", body.Errors)
+	}
+	if body.Data.Me.Username != "test" || len(body.Data.Me.Tickers) == 0 {
+		t.Fatalf("expected the tickers of test, got %+v", body.Data.Me)
+	}
+
+	for _, ticker := range body.Data.Me.Tickers {
+		if len(ticker.History) == 0 || len(ticker.History) > 3 {
+			t.Errorf("expected 1 to 3 bars of %s, got %d", ticker.Symbol, len(ticker.History))
+		}
+		if len(ticker.Price) == 0 || ticker.Price[0] == '"' {
+			t.Errorf("expected price of %s to be a number, got %s", ticker.Symbol, ticker.Price)
+		}
+	}
+
+	// the token is required
+	req, _ = http.NewRequest("POST", server.URL+"/graphql", strings.NewReader(query))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected StatusUnauthorized, got %v", resp.Status)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
 	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
 	"github.com/falmar/richerage-api/internal/tickers/types"
 	"github.com/spf13/viper"
@@ -181,8 +182,8 @@ func TestHttp_History_Auth_SymbolDiff(t *testing.T) {
 		}
 
 		var JSONHistory []struct {
-			Date  string  `json:"date"`
-			Price float64 `json:"price"`
+			Date  string          `json:"date"`
+			Price decimal.Decimal `json:"price"`
 		}
 		err = json.NewDecoder(resp.Body).Decode(&JSONHistory)
 		if err != nil {
@@ -215,7 +216,7 @@ func TestHttp_History_Auth_SymbolDiff(t *testing.T) {
 			if ts.Date.IsZero() {
 				t.Errorf("expected date to be not zero, got %v", ts.Date)
 			}
-			if ts.Price <= 0 {
+			if ts.Price.Sign() <= 0 {
 				t.Errorf("expected price to be above 0, got %v", ts.Price)
 			}
 		}
@@ -228,7 +229,7 @@ func TestHttp_History_Auth_SymbolDiff(t *testing.T) {
 		for z, ts := range history {
 			// date may be the same since its not changing much
 
-			if ts.Price == prevHistory[z].Price {
+			if ts.Price.Equal(prevHistory[z].Price) {
 				t.Errorf("expected price to be different, got %v", ts.Price)
 			}
 		}
@@ -293,8 +294,8 @@ func TestHttp_History_Auth_SymbolSame(t *testing.T) {
 		}
 
 		var JSONHistory []struct {
-			Date  string  `json:"date"`
-			Price float64 `json:"price"`
+			Date  string          `json:"date"`
+			Price decimal.Decimal `json:"price"`
 		}
 		err = json.NewDecoder(resp.Body).Decode(&JSONHistory)
 		if err != nil {
@@ -328,7 +329,7 @@ func TestHttp_History_Auth_SymbolSame(t *testing.T) {
 			if ts.Date.IsZero() {
 				t.Errorf("expected date to be not zero, got %v", ts.Date)
 			}
-			if ts.Price <= 0 {
+			if ts.Price.Sign() <= 0 {
 				t.Errorf("expected price to be above 0, got %v", ts.Price)
 			}
 		}
@@ -342,7 +343,7 @@ func TestHttp_History_Auth_SymbolSame(t *testing.T) {
 			if ts.Date != prevHistory[z].Date {
 				t.Errorf("expected date to be %v, got %v", prevHistory[z].Date, ts.Date)
 			}
-			if ts.Price != prevHistory[z].Price {
+			if !ts.Price.Equal(prevHistory[z].Price) {
 				t.Errorf("expected price to be %v, got %v", prevHistory[z].Price, ts.Price)
 			}
 		}
@@ -400,8 +401,8 @@ func TestHttp_History_Constant(t *testing.T) {
 		}
 
 		var JSONHistory []struct {
-			Date  string  `json:"date"`
-			Price float64 `json:"price"`
+			Date  string          `json:"date"`
+			Price decimal.Decimal `json:"price"`
 		}
 		err = json.NewDecoder(resp.Body).Decode(&JSONHistory)
 		if err != nil {
@@ -432,9 +433,249 @@ func TestHttp_History_Constant(t *testing.T) {
 			if ts.Date != prevHistory[z].Date {
 				t.Errorf("expected date to be %v, got %v", prevHistory[z].Date, ts.Date)
 			}
-			if ts.Price != prevHistory[z].Price {
+			if !ts.Price.Equal(prevHistory[z].Price) {
 				t.Errorf("expected price to be %v, got %v", prevHistory[z].Price, ts.Price)
 			}
 		}
 	}
 }
+
+func TestHttp_History_Renamed(t *testing.T) {
+	ctx := context.Background()
+
+	config, err := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(symbol string) (*http.Response, []map[string]interface{}) {
+		req, _ := http.NewRequest("GET", server.URL+"/tickers/"+symbol+"/history", nil)
+		req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var body []map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+
+		return resp, body
+	}
+
+	// FB was renamed to META, the history is the same
+	resp, fb := get("FB")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+
+	_, meta := get("META")
+	if len(fb) == 0 || len(fb) != len(meta) {
+		t.Fatalf("expected FB and META history to match, got %d and %d records", len(fb), len(meta))
+	}
+
+	for i := range fb {
+		if fb[i]["date"] != meta[i]["date"] || fb[i]["price"] != meta[i]["price"] || fb[i]["symbol"] != "META" {
+			t.Errorf("expected %v, got %v", meta[i], fb[i])
+		}
+	}
+}
+
+func TestHttp_History_Delisted(t *testing.T) {
+	ctx := context.Background()
+
+	config, _ := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	handler, _ := Handler(ctx, config)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	cases := []struct {
+		symbol  string
+		code    string
+		message string
+	}{
+		{symbol: "TWTR", code: "ticker_delisted", message: "ticker TWTR delisted on 2022-11-08"},
+		{symbol: "NOPE", code: "ticker_not_found", message: "ticker NOPE not found"},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest("GET", server.URL+"/tickers/"+c.symbol+"/history", nil)
+		req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+
+		var body map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: expected status code to be 404, got: %d", c.symbol, resp.StatusCode)
+		}
+		if body["code"] != c.code || body["message"] != c.message {
+			t.Errorf("%s: expected %s %q, got: %v", c.symbol, c.code, c.message, body)
+		}
+	}
+}
+
+func TestHttp_History_Delisted_Before(t *testing.T) {
+	ctx := context.Background()
+
+	config, _ := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	handler, _ := Handler(ctx, config)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// TWTR was delisted on 2022-11-08, it traded before that
+	req, _ := http.NewRequest("GET", server.URL+"/tickers/TWTR/history?before=2022-11-07T23:59:59Z", nil)
+	req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+
+	var body []map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+
+	if len(body) == 0 {
+		t.Fatalf("expected history of TWTR, got none")
+	}
+	if body[0]["symbol"] != "TWTR" || body[0]["date"] != "2022-11-07" {
+		t.Errorf("expected the last TWTR bar on 2022-11-07, got %v", body[0])
+	}
+}
+
+func TestHttp_History_Adjusted(t *testing.T) {
+	ctx := context.Background()
+
+	config, _ := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	handler, _ := Handler(ctx, config)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(query string) (*http.Response, []map[string]interface{}) {
+		req, _ := http.NewRequest("GET", server.URL+"/tickers/MSFT/history"+query, nil)
+		req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var body []map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+
+		return resp, body
+	}
+
+	_, raw := get("")
+
+	for _, adjusted := range []string{"none", "split", "total"} {
+		resp, body := get("?adjusted=" + adjusted)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected StatusOK, got %v", adjusted, resp.Status)
+		}
+
+		if len(body) == 0 || len(body) != len(raw) {
+			t.Fatalf("%s: expected %d records, got %d", adjusted, len(raw), len(body))
+		}
+
+		// the latest price is never adjusted, older prices only go down
+		if body[0]["price"] != raw[0]["price"] {
+			t.Errorf("%s: expected latest price %v, got %v", adjusted, raw[0]["price"], body[0]["price"])
+		}
+
+		for i := range body {
+			if body[i]["date"] != raw[i]["date"] || body[i]["price"].(float64) > raw[i]["price"].(float64) {
+				t.Errorf("%s: expected adjusted %v at or below raw %v", adjusted, body[i], raw[i])
+			}
+		}
+	}
+
+	resp, _ := get("?adjusted=dividend")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code to be 400, got: %d", resp.StatusCode)
+	}
+}
+
+func TestHttp_History_Resolution(t *testing.T) {
+	ctx := context.Background()
+
+	config, _ := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	handler, _ := Handler(ctx, config)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(query string) (*http.Response, []map[string]interface{}) {
+		req, _ := http.NewRequest("GET", server.URL+"/tickers/AAPL/history"+query, nil)
+		req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var body []map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+
+		return resp, body
+	}
+
+	resp, body := get("?resolution=5m")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+	if len(body) == 0 {
+		t.Fatalf("expected bars, got none")
+	}
+
+	for _, v := range body {
+		date, err := time.Parse(time.RFC3339, v["date"].(string))
+		if err != nil {
+			t.Errorf("expected RFC3339 date, got %v", v["date"])
+			continue
+		}
+		if date.Minute()%5 != 0 || date.Second() != 0 {
+			t.Errorf("expected bar aligned to 5 minutes, got %v", date)
+		}
+		if v["low"].(float64) > v["price"].(float64) || v["high"].(float64) < v["price"].(float64) {
+			t.Errorf("expected low <= close <= high, got %v", v)
+		}
+	}
+
+	_, daily := get("?resolution=1d")
+	if len(daily) == 0 {
+		t.Fatalf("expected daily bars, got none")
+	}
+	if _, err := time.Parse("2006-01-02", daily[0]["date"].(string)); err != nil {
+		t.Errorf("expected daily date, got %v", daily[0]["date"])
+	}
+
+	resp, _ = get("?resolution=2m")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code to be 400, got: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttp_MarketStatus(t *testing.T) {
+	ctx := context.Background()
+
+	config, err := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/markets/LSE/status?at=2024-12-24T13:00:00Z")
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status code to be 200, got: %d", resp.StatusCode)
+	}
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+
+	// christmas eve is a half day in london
+	if body["open"] != false {
+		t.Errorf("expected market to be closed, got: %v", body["open"])
+	}
+	if body["next_open"] != "2024-12-27T08:00:00Z" {
+		t.Errorf("expected next_open to be 2024-12-27T08:00:00Z, got: %v", body["next_open"])
+	}
+}
+
+func TestHttp_MarketStatus_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	config, _ := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	handler, _ := Handler(ctx, config)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/markets/XXX/status")
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code to be 404, got: %d", resp.StatusCode)
+	}
+}
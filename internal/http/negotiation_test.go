@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newNegotiationServer(t *testing.T) *httptest.Server {
+	ctx := context.Background()
+
+	v := viper.New()
+	v.Set("port", "8080")
+	v.Set("api.deprecation", "2026-10-19")
+	v.Set("api.sunset", "2027-04-19")
+
+	config, err := bootstrap.New(ctx, v, zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	return httptest.NewServer(handler)
+}
+
+func getNegotiated(t *testing.T, url string, accept string) (*http.Response, []byte) {
+	req, _ := http.NewRequest("GET", url, nil)
+	req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	return resp, body
+}
+
+func TestHttp_Negotiation_Tickers(t *testing.T) {
+	server := newNegotiationServer(t)
+	defer server.Close()
+
+	resp, body := getNegotiated(t, server.URL+"/tickers", "")
+	var tickers []map[string]interface{}
+	if err := json.Unmarshal(body, &tickers); err != nil || len(tickers) == 0 {
+		t.Fatalf("expected json tickers, got %s: %v", body, err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != kit.MediaTypeJSON {
+		t.Errorf("expected json content type, got %s", ct)
+	}
+
+	resp, body = getNegotiated(t, server.URL+"/tickers", "text/csv")
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, kit.MediaTypeCSV) {
+		t.Errorf("expected csv content type, got %s", ct)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if len(records) != len(tickers)+1 || strings.Join(records[0], ",") != "symbol,price,currency" {
+		t.Errorf("expected a header and %d rows, got %v", len(tickers), records)
+	}
+	if records[1][0] != tickers[0]["symbol"] {
+		t.Errorf("expected the rows in the order of the json, got %v", records[1])
+	}
+
+	resp, body = getNegotiated(t, server.URL+"/tickers", "application/x-ndjson")
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if resp.Header.Get("Content-Type") != kit.MediaTypeNDJSON || len(lines) != len(tickers) {
+		t.Errorf("expected %d ndjson lines, got %s %q", len(tickers), resp.Header.Get("Content-Type"), body)
+	}
+
+	resp, body = getNegotiated(t, server.URL+"/tickers", "application/msgpack")
+	var decoded []map[string]interface{}
+	if err := msgpack.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if resp.Header.Get("Content-Type") != kit.MediaTypeMsgpack || len(decoded) != len(tickers) || decoded[0]["symbol"] != tickers[0]["symbol"] {
+		t.Errorf("expected the msgpack tickers, got %v", decoded)
+	}
+}
+
+func TestHttp_Negotiation_HistoryFormat(t *testing.T) {
+	server := newNegotiationServer(t)
+	defer server.Close()
+
+	_, body := getNegotiated(t, server.URL+"/tickers", "")
+	var tickers []map[string]interface{}
+	_ = json.Unmarshal(body, &tickers)
+	if len(tickers) == 0 {
+		t.Fatalf("expected tickers, got %s", body)
+	}
+	symbol := tickers[0]["symbol"].(string)
+
+	// format overrides the Accept header
+	resp, body := getNegotiated(t, server.URL+"/tickers/"+symbol+"/history?format=csv", "application/json")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	header := "date,symbol,open,high,low,price,volume,currency"
+	if len(records) < 2 || strings.Join(records[0], ",") != header {
+		t.Errorf("expected header %s and rows, got %v", header, records)
+	}
+}
+
+func TestHttp_Negotiation_NotAcceptable(t *testing.T) {
+	server := newNegotiationServer(t)
+	defer server.Close()
+
+	for _, url := range []string{"/tickers", "/tickers/AAPL/history", "/symbols", "/markets/XNYS/status"} {
+		resp, body := getNegotiated(t, server.URL+url, "application/xml")
+		if resp.StatusCode != http.StatusNotAcceptable {
+			t.Errorf("%s: expected StatusNotAcceptable, got %v", url, resp.Status)
+			continue
+		}
+
+		respError := &kit.HttpErrorBody{}
+		if err := json.Unmarshal(body, respError); err != nil || respError.Code != "not_acceptable" {
+			t.Errorf("%s: expected not_acceptable error, got %s", url, body)
+		}
+	}
+
+	resp, _ := getNegotiated(t, server.URL+"/tickers?format=xml", "")
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("expected StatusNotAcceptable for format=xml, got %v", resp.Status)
+	}
+}
@@ -0,0 +1,307 @@
+package http
+
+import (
+	authendpoints "github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/export"
+	"github.com/falmar/richerage-api/internal/openapi"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"net/http"
+	"strings"
+)
+
+var apiInfo = openapi.Info{
+	Title:       "Richerage API",
+	Description: "Prices and history of the tickers of a user.",
+	Version:     "1.0.0",
+}
+
+// operations describes every route of Handler, keyed by "METHOD /path" as registered: the routes of
+// apiOperations under each version prefix and unprefixed as deprecated aliases of /v1
+func operations() map[string]*openapi.Operation {
+	ops := map[string]*openapi.Operation{
+		"GET /openapi.json": openapi.Op("docs", "This document", "").
+			Returns(http.StatusOK, "OpenAPI 3.1 document", "application/json", &openapi.Schema{Type: "object"}),
+
+		"GET /docs": openapi.Op("docs", "Swagger UI of this document", "").
+			Returns(http.StatusOK, "HTML page", "text/html", openapi.String()),
+
+		"GET /docs/{asset}": openapi.Op("docs", "Swagger UI assets of the docs page", "").
+			Returns(http.StatusOK, "Script or stylesheet", "text/javascript", openapi.String()).
+			Returns(http.StatusOK, "Script or stylesheet", "text/css", openapi.String()).
+			Returns(http.StatusNotFound, "Unknown asset", "text/plain", openapi.String()),
+	}
+
+	// the bodies of the protocols are theirs in every version
+	protocols := map[string]bool{"/graphql": true, "/rpc": true}
+
+	for key, op := range apiOperations() {
+		method, path, _ := strings.Cut(key, " ")
+
+		v2 := op.Clone()
+		if !protocols[path] {
+			v2.Enveloped()
+		}
+
+		ops[method+" "+kit.APIVersion1.Prefix()+path] = op.Clone()
+		ops[method+" "+kit.APIVersion2.Prefix()+path] = v2
+		ops[key] = op.Deprecate(kit.APIVersion1.Prefix() + path)
+	}
+
+	return ops
+}
+
+// apiOperations describes the routes of each API version, keyed by "METHOD /path" without prefix
+func apiOperations() map[string]*openapi.Operation {
+	currency := openapi.String().Describe("ISO 4217 code prices are converted to, the trading currency of each symbol by default").WithFormat("iso4217")
+
+	ticker := openapi.SchemaOf(types.Ticker{})
+
+	// the transport formats the date by the resolution of the bars
+	bar := openapi.SchemaOf(types.TickerHistory{})
+	bar.Properties["date"] = openapi.String().Describe("`2006-01-02` for daily bars, the RFC3339 start time in the exchange timezone for sub-daily bars")
+	bar.Properties["price"].Describe("close price, exact decimal")
+
+	subscription := openapi.Object(map[string]*openapi.Schema{
+		"id":         openapi.String(),
+		"url":        openapi.String().WithFormat("uri"),
+		"events":     openapi.ArrayOf(openapi.String()),
+		"created_at": openapi.String().WithFormat("date-time"),
+	})
+
+	delivery := openapi.Object(map[string]*openapi.Schema{
+		"id":     openapi.String(),
+		"event":  openapi.String(),
+		"status": openapi.String(),
+		"attempts": openapi.ArrayOf(&openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"attempt":     openapi.Integer(),
+				"status_code": openapi.Integer(),
+				"duration_ms": openapi.Integer(),
+				"at":          openapi.String().WithFormat("date-time"),
+				"error":       openapi.String(),
+			},
+			Required: []string{"at", "attempt", "duration_ms", "status_code"},
+		}),
+		"created_at": openapi.String().WithFormat("date-time"),
+		"updated_at": openapi.String().WithFormat("date-time"),
+	})
+
+	symbol := openapi.Object(map[string]*openapi.Schema{
+		"instrument_id": openapi.String(),
+		"symbol":        openapi.String(),
+		"name":          openapi.String(),
+		"exchange":      openapi.String(),
+		"sector":        openapi.String(),
+		"industry":      openapi.String(),
+		"currency":      openapi.String(),
+		"isin":          openapi.String(),
+		"figi":          openapi.String(),
+		"listed":        openapi.String().WithFormat("date").Nullable(),
+		"delisted":      openapi.String().WithFormat("date").Nullable(),
+		"listings": openapi.ArrayOf(openapi.Object(map[string]*openapi.Schema{
+			"symbol": openapi.String(),
+			"from":   openapi.String().WithFormat("date").Nullable(),
+			"to":     openapi.String().WithFormat("date").Nullable(),
+		})),
+	})
+
+	jsonObject := &openapi.Schema{Type: "object"}
+
+	return map[string]*openapi.Operation{
+		"POST /login": openapi.Op("auth", "Login",
+			"Any username and password get a token valid for 1 week.").
+			Body(openapi.SchemaOf(authendpoints.LoginRequest{})).
+			Returns(http.StatusOK, "The auth token", "application/json", openapi.SchemaOf(authendpoints.LoginResponse{})).
+			Errors(http.StatusBadRequest),
+
+		"GET /tickers": openapi.Op("tickers", "List the tickers of the user", "").
+			Authenticated().
+			Query("currency", "", currency).
+			Returns(http.StatusOK, "The tickers with their latest price", "application/json", openapi.ArrayOf(ticker)).
+			Errors(http.StatusBadRequest).
+			Negotiated(),
+
+		"GET /tickers/{symbol}/history": openapi.Op("tickers", "History of a ticker",
+			"OHLCV bars newest first. History follows renames, every bar carries the symbol it traded under.").
+			Authenticated().
+			Path("symbol", "Current or former symbol of the instrument").
+			Query("before", "Only bars before this time", openapi.String().WithFormat("date-time")).
+			Query("adjusted", "Back-adjustment for corporate actions", openapi.Enum(string(types.AdjustmentNone), string(types.AdjustmentSplit), string(types.AdjustmentTotal))).
+			Query("currency", "", currency).
+			Query("resolution", "Bar size, sub-daily bars are limited to the latest 1000", resolutionSchema()).
+			Returns(http.StatusOK, "The bars, null when there are none", "application/json", openapi.ArrayOf(bar).Nullable()).
+			Errors(http.StatusBadRequest, http.StatusNotFound).
+			Negotiated(),
+
+		"GET /export/history": openapi.Op("export", "Export the history of tickers",
+			"Daily bars of each symbol oldest first, as stored under the symbol, streamed as an Arrow IPC stream or a Parquet file with a record batch or row group per batch of bars. "+
+				"A failure midway aborts the download.").
+			Authenticated().
+			Query("symbols", "Comma separated symbols, at most 100", openapi.String()).
+			Query("from", "First date exported", openapi.String().WithFormat("date")).
+			Query("to", "Last date exported", openapi.String().WithFormat("date")).
+			Query("format", "File format", openapi.Enum(string(export.FormatParquet), string(export.FormatArrow)).Describe("default parquet")).
+			Returns(http.StatusOK, "Columns symbol, date, open, high, low, close, volume and currency", export.FormatParquet.ContentType(), openapi.String().WithFormat("binary")).
+			Returns(http.StatusOK, "", export.FormatArrow.ContentType(), openapi.String().WithFormat("binary")).
+			Errors(http.StatusBadRequest, http.StatusNotFound),
+
+		"GET /tickers/stream": openapi.Op("tickers", "Stream the prices of the user tickers",
+			"Server-Sent Events: a `price` event with an `id` per update, `: heartbeat` comments in between.").
+			Authenticated().
+			Header("Last-Event-ID", "Replay the updates after this event").
+			Returns(http.StatusOK, "Event stream of price updates", "text/event-stream", openapi.SchemaOf(types.PriceUpdate{})).
+			Errors(http.StatusServiceUnavailable),
+
+		"GET /ws": openapi.Op("tickers", "WebSocket quotes",
+			"Upgrades to a WebSocket of `subscribe`/`unsubscribe` requests and `snapshot`/`update`/`error` messages.").
+			Authenticated().
+			Query("token", "The token, for clients that can not set the Authorization header", openapi.String()).
+			Returns(http.StatusSwitchingProtocols, "WebSocket upgrade", "", nil),
+
+		"GET /graphql": openapi.Op("graphql", "GraphQL query",
+			"The schema is served by introspection.").
+			Authenticated().
+			Query("query", "GraphQL query", openapi.String()).
+			Returns(http.StatusOK, "GraphQL response", "application/json", jsonObject),
+
+		"POST /graphql": openapi.Op("graphql", "GraphQL query", "").
+			Authenticated().
+			Body(openapi.Object(map[string]*openapi.Schema{"query": openapi.String()})).
+			Returns(http.StatusOK, "GraphQL response", "application/json", jsonObject),
+
+		"POST /rpc": openapi.Op("rpc", "JSON-RPC 2.0",
+			"Calls or batches of calls of `auth.login`, `tickers.list` and `tickers.history`, errors carry the api code in `data.code`.").
+			Body(jsonObject).
+			Returns(http.StatusOK, "Response or batch of responses", "application/json", jsonObject).
+			Returns(http.StatusNoContent, "Only notifications were sent", "", nil),
+
+		"POST /webhooks": openapi.Op("webhooks", "Subscribe a webhook", "").
+			Authenticated().
+			Body(openapi.Object(map[string]*openapi.Schema{
+				"url":    openapi.String().WithFormat("uri"),
+				"events": openapi.ArrayOf(openapi.String()),
+			})).
+			Returns(http.StatusCreated, "The subscription, the secret is only disclosed once", "application/json", withSecret(subscription)).
+			Errors(http.StatusBadRequest),
+
+		"GET /webhooks": openapi.Op("webhooks", "List the webhook subscriptions", "").
+			Authenticated().
+			Returns(http.StatusOK, "The subscriptions", "application/json", openapi.ArrayOf(subscription)).
+			Negotiated(),
+
+		"DELETE /webhooks/{id}": openapi.Op("webhooks", "Delete a webhook subscription", "").
+			Authenticated().
+			Returns(http.StatusNoContent, "Deleted", "", nil).
+			Errors(http.StatusNotFound),
+
+		"POST /webhooks/{id}/ping": openapi.Op("webhooks", "Send a ping event", "").
+			Authenticated().
+			Returns(http.StatusAccepted, "The delivery of the ping", "application/json", openapi.Object(map[string]*openapi.Schema{"delivery_id": openapi.String()})).
+			Errors(http.StatusNotFound),
+
+		"GET /webhooks/{id}/deliveries": openapi.Op("webhooks", "List the deliveries of a subscription", "").
+			Authenticated().
+			Query("status", "Only deliveries with this status", openapi.String()).
+			Returns(http.StatusOK, "The deliveries", "application/json", openapi.ArrayOf(delivery)).
+			Errors(http.StatusBadRequest, http.StatusNotFound).
+			Negotiated(),
+
+		"GET /markets/{exchange}/status": openapi.Op("markets", "Trading status of an exchange",
+			"Times are in the exchange timezone.").
+			Query("at", "Status at this time instead of now", openapi.String().WithFormat("date-time")).
+			Returns(http.StatusOK, "The status", "application/json", openapi.Object(map[string]*openapi.Schema{
+				"exchange":   openapi.String(),
+				"name":       openapi.String(),
+				"timezone":   openapi.String(),
+				"open":       openapi.Boolean(),
+				"next_open":  openapi.String().WithFormat("date-time"),
+				"next_close": openapi.String().WithFormat("date-time"),
+			})).
+			Errors(http.StatusBadRequest, http.StatusNotFound).
+			Negotiated(),
+
+		"GET /symbols": openapi.Op("symbols", "List symbols", "").
+			Query("exchange", "", openapi.String()).
+			Query("sector", "", openapi.String()).
+			Query("delisted", "Include delisted symbols", openapi.Boolean()).
+			Returns(http.StatusOK, "The symbols", "application/json", openapi.ArrayOf(symbol)).
+			Errors(http.StatusBadRequest).
+			Negotiated(),
+
+		"GET /symbols/search": openapi.Op("symbols", "Search symbols", "").
+			Query("q", "Symbol, name or identifier", openapi.String()).
+			Query("limit", "", openapi.Integer()).
+			Returns(http.StatusOK, "The matching symbols", "application/json", openapi.ArrayOf(symbol)).
+			Errors(http.StatusBadRequest).
+			Negotiated(),
+
+		"GET /symbols/{symbol}": openapi.Op("symbols", "Get a symbol", "").
+			Returns(http.StatusOK, "The symbol", "application/json", symbol).
+			Errors(http.StatusNotFound).
+			Negotiated(),
+
+		"PUT /admin/prices/{symbol}": openapi.Op("admin", "Upsert daily prices", "").
+			Authenticated().
+			Header("X-Admin-Key", adminKeyDescription).
+			Body(openapi.Object(map[string]*openapi.Schema{
+				"prices": openapi.ArrayOf(openapi.Object(map[string]*openapi.Schema{
+					"date":     openapi.String().WithFormat("date"),
+					"open":     openapi.Number(),
+					"high":     openapi.Number(),
+					"low":      openapi.Number(),
+					"close":    openapi.Number(),
+					"volume":   openapi.Integer(),
+					"currency": openapi.String(),
+				})),
+			})).
+			Returns(http.StatusOK, "Counts of the written bars", "application/json", openapi.Object(map[string]*openapi.Schema{
+				"symbol":   openapi.String(),
+				"inserted": openapi.Integer(),
+				"updated":  openapi.Integer(),
+			})).
+			Errors(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound),
+
+		"DELETE /admin/prices/{symbol}": openapi.Op("admin", "Delete daily prices", "").
+			Authenticated().
+			Header("X-Admin-Key", adminKeyDescription).
+			Query("from", "", openapi.String().WithFormat("date")).
+			Query("to", "", openapi.String().WithFormat("date")).
+			Returns(http.StatusOK, "Count of the deleted bars", "application/json", openapi.Object(map[string]*openapi.Schema{
+				"symbol":  openapi.String(),
+				"deleted": openapi.Integer(),
+			})).
+			Errors(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound),
+
+		"PUT /admin/users/{username}/holdings": openapi.Op("admin", "Set the tickers of a user", "").
+			Authenticated().
+			Header("X-Admin-Key", adminKeyDescription).
+			Body(openapi.Object(map[string]*openapi.Schema{"symbols": openapi.ArrayOf(openapi.String())})).
+			Returns(http.StatusOK, "The holdings", "application/json", openapi.Object(map[string]*openapi.Schema{
+				"username": openapi.String(),
+				"symbols":  openapi.ArrayOf(openapi.String()),
+			})).
+			Errors(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound),
+	}
+}
+
+const adminKeyDescription = "The `admin.key` of the server, required along the token of an admin user"
+
+func resolutionSchema() *openapi.Schema {
+	var values []string
+	for _, r := range types.Resolutions {
+		values = append(values, string(r))
+	}
+
+	return openapi.Enum(values...).Describe("default 1d")
+}
+
+func withSecret(subscription *openapi.Schema) *openapi.Schema {
+	properties := map[string]*openapi.Schema{"secret": openapi.String()}
+	for name, schema := range subscription.Properties {
+		properties[name] = schema
+	}
+
+	return openapi.Object(properties)
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHttp_OpenAPI_MatchesRouter(t *testing.T) {
+	ctx := context.Background()
+
+	v := viper.New()
+	v.Set("port", "8080")
+
+	config, err := bootstrap.New(ctx, v, zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	// Handler fails when a route is not documented or an operation has no route
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	if spec.OpenAPI != "3.1.0" {
+		t.Errorf("expected openapi 3.1.0, got %s", spec.OpenAPI)
+	}
+
+	routes := map[string]bool{}
+	_ = chi.Walk(handler.(chi.Routes), func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes[method+" "+route] = true
+		return nil
+	})
+
+	documented := map[string]bool{}
+	for path, item := range spec.Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for route := range routes {
+		if !documented[route] {
+			t.Errorf("expected %s to be documented", route)
+		}
+	}
+	for route := range documented {
+		if !routes[route] {
+			t.Errorf("expected documented %s to be a route", route)
+		}
+	}
+}
+
+func TestHttp_OpenAPI_Docs(t *testing.T) {
+	ctx := context.Background()
+
+	config, err := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/docs", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"/openapi.json"`) {
+		t.Errorf("expected the docs page to load /openapi.json, got %s", rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "https://") {
+		t.Errorf("expected the docs page to load nothing from another host, got %s", rec.Body.String())
+	}
+
+	// the assets are served by the binary
+	for path, contentType := range map[string]string{
+		"/docs/swagger-ui-bundle.js": "text/javascript",
+		"/docs/swagger-ui.css":       "text/css",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), contentType) || rec.Body.Len() == 0 {
+			t.Errorf("expected %s served as %s, got %d %s", path, contentType, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/docs/swagger-ui.js", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected StatusNotFound for an unknown asset, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Code   string            `json:"code"`
+			Params map[string]string `json:"params"`
+		} `json:"data"`
+	} `json:"error"`
+	ID json.RawMessage `json:"id"`
+}
+
+func newRPCServer(t *testing.T) *httptest.Server {
+	ctx := context.Background()
+
+	v := viper.New()
+	v.Set("port", "8080")
+	v.Set("rpc.max_batch", 5)
+	v.Set("rpc.max_body", 1024)
+
+	config, err := bootstrap.New(ctx, v, zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	return httptest.NewServer(handler)
+}
+
+func rpcCall(t *testing.T, server *httptest.Server, body string, auth bool) *http.Response {
+	req, _ := http.NewRequest("POST", server.URL+"/rpc", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if auth {
+		req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	return resp
+}
+
+func TestHttp_RPC_Login(t *testing.T) {
+	server := newRPCServer(t)
+	defer server.Close()
+
+	resp := rpcCall(t, server, `{"jsonrpc": "2.0", "method": "auth.login", "params": {"username": "test", "password": "test"}, "id": 1}`, false)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+
+	var body rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	if body.Error != nil {
+		t.Fatalf("expected no error, got %+v", body.Error)
+	}
+	if string(body.ID) != "1" {
+		t.Errorf("expected id 1, got %s", body.ID)
+	}
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	_ = json.Unmarshal(body.Result, &result)
+	if result.Token == "" {
+		t.Errorf("expected a token, got %s", body.Result)
+	}
+}
+
+func TestHttp_RPC_Batch(t *testing.T) {
+	server := newRPCServer(t)
+	defer server.Close()
+
+	resp := rpcCall(t, server, `{"jsonrpc": "2.0", "method": "tickers.list", "id": "list"}`, true)
+	var list rpcResponse
+	_ = json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+
+	var tickers []struct {
+		Symbol string `json:"symbol"`
+	}
+	if err := json.Unmarshal(list.Result, &tickers); err != nil || len(tickers) == 0 {
+		t.Fatalf("expected the tickers of test, got %s, %+v", list.Result, list.Error)
+	}
+
+	batch := `[
+		{"jsonrpc": "2.0", "method": "tickers.history", "params": {"symbol": "` + tickers[0].Symbol + `"}, "id": 1},
+		{"jsonrpc": "2.0", "method": "tickers.history", "params": {"symbol": "NOPE"}, "id": 2},
+		{"jsonrpc": "2.0", "method": "tickers.list"},
+		{"jsonrpc": "2.0", "method": "tickers.history", "params": ["` + tickers[0].Symbol + `"], "id": 3},
+		{"jsonrpc": "2.0", "method": "tickers.delete", "id": 4}
+	]`
+
+	resp = rpcCall(t, server, batch, true)
+	defer resp.Body.Close()
+
+	var body []rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	// the notification gets no response
+	if len(body) != 4 {
+		t.Fatalf("expected 4 responses, got %d", len(body))
+	}
+
+	if body[0].Error != nil || string(body[0].ID) != "1" {
+		t.Errorf("expected the history of %s, got %+v", tickers[0].Symbol, body[0].Error)
+	}
+	var history []map[string]interface{}
+	if err := json.Unmarshal(body[0].Result, &history); err != nil || len(history) == 0 {
+		t.Errorf("expected history bars, got %s", body[0].Result)
+	}
+
+	cases := []struct {
+		id   string
+		code int
+		data string
+	}{
+		{id: "2", code: -32004, data: "ticker_not_found"},
+		{id: "3", code: -32602, data: "bad_request"},
+		{id: "4", code: -32601},
+	}
+
+	for i, c := range cases {
+		res := body[i+1]
+		if string(res.ID) != c.id {
+			t.Errorf("expected id %s, got %s", c.id, res.ID)
+		}
+		if res.Error == nil {
+			t.Errorf("%s: expected error, got result %s", c.id, res.Result)
+			continue
+		}
+		if res.Error.Code != c.code || res.Error.Data.Code != c.data {
+			t.Errorf("%s: expected error %d %q, got %d %q", c.id, c.code, c.data, res.Error.Code, res.Error.Data.Code)
+		}
+	}
+}
+
+func TestHttp_RPC_Unauthorized(t *testing.T) {
+	server := newRPCServer(t)
+	defer server.Close()
+
+	resp := rpcCall(t, server, `{"jsonrpc": "2.0", "method": "tickers.list", "id": 1}`, false)
+	defer resp.Body.Close()
+
+	var body rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	if body.Error == nil || body.Error.Code != -32001 || body.Error.Data.Code != "invalid_token" {
+		t.Errorf("expected invalid_token error, got %+v", body.Error)
+	}
+}
+
+func TestHttp_RPC_InvalidRequests(t *testing.T) {
+	server := newRPCServer(t)
+	defer server.Close()
+
+	cases := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "parse error", body: `{"jsonrpc": "2.0", "method"`, code: -32700},
+		{name: "empty batch", body: `[]`, code: -32600},
+		{name: "no version", body: `{"method": "tickers.list", "id": 1}`, code: -32600},
+		{name: "batch limit", body: `[1, 2, 3, 4, 5, 6]`, code: -32600},
+		{name: "body limit", body: `{"jsonrpc": "2.0", "method": "tickers.list", "params": {"currency": "` + strings.Repeat("x", 1024) + `"}, "id": 1}`, code: -32600},
+	}
+
+	for _, c := range cases {
+		resp := rpcCall(t, server, c.body, true)
+
+		var body rpcResponse
+		err := json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if err != nil {
+			t.Errorf("%s: unexpected error to be nil, got: %v", c.name, err)
+			continue
+		}
+		if body.Error == nil || body.Error.Code != c.code {
+			t.Errorf("%s: expected error code %d, got %+v", c.name, c.code, body.Error)
+		}
+	}
+
+	resp := rpcCall(t, server, `[{"jsonrpc": "2.0", "method": "tickers.list"}]`, true)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected StatusNoContent for a batch of notifications, got %v", resp.Status)
+	}
+}
@@ -2,20 +2,73 @@ package http
 
 import (
 	"context"
+	"fmt"
+	adminendpoints "github.com/falmar/richerage-api/internal/admin/endpoint"
+	admintransport "github.com/falmar/richerage-api/internal/admin/transport"
 	authendpoints "github.com/falmar/richerage-api/internal/auth/endpoint"
 	authtransport "github.com/falmar/richerage-api/internal/auth/transport"
 	"github.com/falmar/richerage-api/internal/bootstrap"
+	exportendpoints "github.com/falmar/richerage-api/internal/export/endpoint"
+	exporttransport "github.com/falmar/richerage-api/internal/export/transport"
+	"github.com/falmar/richerage-api/internal/graphql"
+	marketsendpoints "github.com/falmar/richerage-api/internal/markets/endpoint"
+	marketstransport "github.com/falmar/richerage-api/internal/markets/transport"
+	"github.com/falmar/richerage-api/internal/openapi"
 	"github.com/falmar/richerage-api/internal/pkg/kit"
+	symbolsendpoints "github.com/falmar/richerage-api/internal/symbols/endpoint"
+	symbolstransport "github.com/falmar/richerage-api/internal/symbols/transport"
 	tickersendpoints "github.com/falmar/richerage-api/internal/tickers/endpoint"
 	tickerstransport "github.com/falmar/richerage-api/internal/tickers/transport"
+	webhooksendpoints "github.com/falmar/richerage-api/internal/webhooks/endpoint"
+	webhookstransport "github.com/falmar/richerage-api/internal/webhooks/transport"
 	"github.com/go-chi/chi/v5"
 	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/kit/transport/http/jsonrpc"
+	"github.com/spf13/viper"
 	"net/http"
+	"time"
 )
 
 func Handler(_ context.Context, config *bootstrap.Config) (http.Handler, error) {
+	deprecation, sunset, err := deprecationDates(config.Viper)
+	if err != nil {
+		return nil, err
+	}
+
 	router := chi.NewRouter()
 
+	router.Route(kit.APIVersion1.Prefix(), func(r chi.Router) {
+		r.Use(kit.WithAPIVersion(kit.APIVersion1))
+		routes(r, config)
+	})
+	router.Route(kit.APIVersion2.Prefix(), func(r chi.Router) {
+		r.Use(kit.WithAPIVersion(kit.APIVersion2))
+		routes(r, config)
+	})
+
+	// the unprefixed routes of clients predating the versions are aliases of v1 until the sunset
+	router.Group(func(r chi.Router) {
+		if !deprecation.IsZero() {
+			r.Use(kit.Deprecated(deprecation, sunset, kit.APIVersion1.Prefix()))
+		}
+		routes(r, config)
+	})
+
+	spec := openapi.New(apiInfo)
+	router.Method("GET", "/openapi.json", openapi.SpecHandler(spec))
+	router.Method("GET", "/docs", openapi.DocsHandler())
+	router.Method("GET", "/docs/{asset}", openapi.AssetsHandler("/docs/"))
+
+	// every route must be documented, see operations
+	if err := spec.AddRoutes(router, operations()); err != nil {
+		return nil, err
+	}
+
+	return router, nil
+}
+
+// routes registers the api on router, once per version
+func routes(router chi.Router, config *bootstrap.Config) {
 	loggerHandler := &kit.LoggerHandler{
 		Logger: config.Logger,
 	}
@@ -38,27 +91,298 @@ func Handler(_ context.Context, config *bootstrap.Config) (http.Handler, error)
 	tickerEndpoint = tickersendpoints.MakeTickersAuthEndpoint(config.AuthService, tickerEndpoint)
 	router.Method("GET", "/tickers", kithttp.NewServer(
 		tickerEndpoint,
-		tickerstransport.TickersRequestDecoder,
+		kit.Negotiated(tickerstransport.TickersRequestDecoder),
 		tickerstransport.TickersResponseEncoder,
 		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerBefore(kit.NegotiateRequest),
 		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
 		kithttp.ServerErrorHandler(errorHandler),
 		kithttp.ServerBefore(loggerHandler.Before),
 		kithttp.ServerAfter(loggerHandler.After),
 	))
 
+	heartbeat := config.Viper.GetDuration("stream.heartbeat")
+	if heartbeat <= 0 {
+		heartbeat = time.Second * 15
+	}
+
+	streamEndpoint := tickersendpoints.MakeTickersStreamEndpoint(config.RicherageService)
+	streamEndpoint = tickersendpoints.MakeTickersStreamAuthEndpoint(config.AuthService, streamEndpoint)
+	router.Method("GET", "/tickers/stream", kithttp.NewServer(
+		streamEndpoint,
+		tickerstransport.TickersStreamRequestDecoder,
+		tickerstransport.MakeTickersStreamResponseEncoder(heartbeat),
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	router.Method("GET", "/ws", tickerstransport.NewWebsocketHandler(&tickerstransport.WebsocketConfig{
+		Session:      authendpoints.MakeSessionEndpoint(config.AuthService),
+		Stream:       tickersendpoints.MakeTickersStreamEndpoint(config.RicherageService),
+		ErrorEncoder: errorHandler.ErrorEncoder,
+		ErrorHandler: errorHandler,
+	}))
+
+	graphqlHandler := graphql.NewHandler(&graphql.HandlerConfig{
+		Session:       authendpoints.MakeSessionEndpoint(config.AuthService),
+		Tickers:       config.RicherageService,
+		ErrorEncoder:  errorHandler.ErrorEncoder,
+		ErrorHandler:  errorHandler,
+		MaxDepth:      config.Viper.GetInt("graphql.max_depth"),
+		MaxComplexity: config.Viper.GetInt("graphql.max_complexity"),
+	})
+	router.Method("GET", "/graphql", graphqlHandler)
+	router.Method("POST", "/graphql", graphqlHandler)
+
 	historyEndpoint := tickersendpoints.MakeTickerHistoryEndpoint(config.RicherageService)
 	historyEndpoint = tickersendpoints.MakeTickerHistoryAuthEndpoint(config.AuthService, historyEndpoint)
 	router.Method("GET", "/tickers/{symbol}/history", kithttp.NewServer(
 		historyEndpoint,
-		tickerstransport.TickerHistoryRequestDecoder,
+		kit.Negotiated(tickerstransport.TickerHistoryRequestDecoder),
 		tickerstransport.TickerHistoryResponseEncoder,
 		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerBefore(kit.NegotiateRequest),
 		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
 		kithttp.ServerErrorHandler(errorHandler),
 		kithttp.ServerBefore(loggerHandler.Before),
 		kithttp.ServerAfter(loggerHandler.After),
 	))
 
-	return router, nil
+	exportHistoryEndpoint := exportendpoints.MakeExportHistoryEndpoint(config.ExportService)
+	exportHistoryEndpoint = exportendpoints.MakeAuthEndpoint(config.AuthService, exportHistoryEndpoint)
+	router.Method("GET", "/export/history", kithttp.NewServer(
+		exportHistoryEndpoint,
+		exporttransport.ExportHistoryRequestDecoder,
+		exporttransport.ExportHistoryResponseEncoder,
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerErrorEncoder(exporttransport.MakeErrorEncoder(errorHandler.ErrorEncoder)),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	maxBatch := config.Viper.GetInt("rpc.max_batch")
+	if maxBatch <= 0 {
+		maxBatch = 50
+	}
+	maxBody := config.Viper.GetInt64("rpc.max_body")
+	if maxBody <= 0 {
+		maxBody = 1 << 20
+	}
+
+	router.Method("POST", "/rpc", kit.JSONRPCBatchHandler(jsonrpc.NewServer(
+		jsonrpc.EndpointCodecMap{
+			"auth.login": jsonrpc.EndpointCodec{
+				Endpoint: loginEndpoint,
+				Decode:   authtransport.LoginJSONRPCRequestDecoder,
+				Encode:   authtransport.LoginJSONRPCResponseEncoder,
+			},
+			"tickers.list": jsonrpc.EndpointCodec{
+				Endpoint: tickerEndpoint,
+				Decode:   tickerstransport.TickersJSONRPCRequestDecoder,
+				Encode:   tickerstransport.TickersJSONRPCResponseEncoder,
+			},
+			"tickers.history": jsonrpc.EndpointCodec{
+				Endpoint: historyEndpoint,
+				Decode:   tickerstransport.TickerHistoryJSONRPCRequestDecoder,
+				Encode:   tickerstransport.TickerHistoryJSONRPCResponseEncoder,
+			},
+		},
+		jsonrpc.ServerBefore(tickerstransport.TokenDecoder),
+		jsonrpc.ServerBeforeCodec(kit.JSONRPCRequestID),
+		jsonrpc.ServerErrorEncoder(errorHandler.JSONRPCErrorEncoder),
+		jsonrpc.ServerBefore(loggerHandler.Before),
+		jsonrpc.ServerAfter(loggerHandler.After),
+	), maxBatch, maxBody))
+
+	createWebhookEndpoint := webhooksendpoints.MakeCreateSubscriptionEndpoint(config.WebhooksService)
+	createWebhookEndpoint = webhooksendpoints.MakeAuthEndpoint(config.AuthService, createWebhookEndpoint)
+	router.Method("POST", "/webhooks", kithttp.NewServer(
+		createWebhookEndpoint,
+		webhookstransport.CreateSubscriptionRequestDecoder,
+		webhookstransport.CreateSubscriptionResponseEncoder,
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	listWebhooksEndpoint := webhooksendpoints.MakeListSubscriptionsEndpoint(config.WebhooksService)
+	listWebhooksEndpoint = webhooksendpoints.MakeAuthEndpoint(config.AuthService, listWebhooksEndpoint)
+	router.Method("GET", "/webhooks", kithttp.NewServer(
+		listWebhooksEndpoint,
+		kit.Negotiated(webhookstransport.ListSubscriptionsRequestDecoder),
+		webhookstransport.ListSubscriptionsResponseEncoder,
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerBefore(kit.NegotiateRequest),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	deleteWebhookEndpoint := webhooksendpoints.MakeDeleteSubscriptionEndpoint(config.WebhooksService)
+	deleteWebhookEndpoint = webhooksendpoints.MakeAuthEndpoint(config.AuthService, deleteWebhookEndpoint)
+	router.Method("DELETE", "/webhooks/{id}", kithttp.NewServer(
+		deleteWebhookEndpoint,
+		webhookstransport.DeleteSubscriptionRequestDecoder,
+		webhookstransport.DeleteSubscriptionResponseEncoder,
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	pingWebhookEndpoint := webhooksendpoints.MakePingEndpoint(config.WebhooksService)
+	pingWebhookEndpoint = webhooksendpoints.MakeAuthEndpoint(config.AuthService, pingWebhookEndpoint)
+	router.Method("POST", "/webhooks/{id}/ping", kithttp.NewServer(
+		pingWebhookEndpoint,
+		webhookstransport.PingRequestDecoder,
+		webhookstransport.PingResponseEncoder,
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	deliveriesEndpoint := webhooksendpoints.MakeListDeliveriesEndpoint(config.WebhooksService)
+	deliveriesEndpoint = webhooksendpoints.MakeAuthEndpoint(config.AuthService, deliveriesEndpoint)
+	router.Method("GET", "/webhooks/{id}/deliveries", kithttp.NewServer(
+		deliveriesEndpoint,
+		kit.Negotiated(webhookstransport.ListDeliveriesRequestDecoder),
+		webhookstransport.ListDeliveriesResponseEncoder,
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerBefore(kit.NegotiateRequest),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	marketStatusEndpoint := marketsendpoints.MakeMarketStatusEndpoint(config.MarketsService)
+	router.Method("GET", "/markets/{exchange}/status", kithttp.NewServer(
+		marketStatusEndpoint,
+		kit.Negotiated(marketstransport.MarketStatusRequestDecoder),
+		marketstransport.MarketStatusResponseEncoder,
+		kithttp.ServerBefore(kit.NegotiateRequest),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	listSymbolsEndpoint := symbolsendpoints.MakeListSymbolsEndpoint(config.SymbolsService)
+	router.Method("GET", "/symbols", kithttp.NewServer(
+		listSymbolsEndpoint,
+		kit.Negotiated(symbolstransport.ListSymbolsRequestDecoder),
+		symbolstransport.ListSymbolsResponseEncoder,
+		kithttp.ServerBefore(kit.NegotiateRequest),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	searchSymbolsEndpoint := symbolsendpoints.MakeSearchSymbolsEndpoint(config.SymbolsService)
+	router.Method("GET", "/symbols/search", kithttp.NewServer(
+		searchSymbolsEndpoint,
+		kit.Negotiated(symbolstransport.SearchSymbolsRequestDecoder),
+		symbolstransport.SearchSymbolsResponseEncoder,
+		kithttp.ServerBefore(kit.NegotiateRequest),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	getSymbolEndpoint := symbolsendpoints.MakeGetSymbolEndpoint(config.SymbolsService)
+	router.Method("GET", "/symbols/{symbol}", kithttp.NewServer(
+		getSymbolEndpoint,
+		kit.Negotiated(symbolstransport.GetSymbolRequestDecoder),
+		symbolstransport.GetSymbolResponseEncoder,
+		kithttp.ServerBefore(kit.NegotiateRequest),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	upsertPricesEndpoint := adminendpoints.MakeUpsertPricesEndpoint(config.AdminService)
+	upsertPricesEndpoint = adminendpoints.MakeAdminEndpoint(config.AuthService, upsertPricesEndpoint)
+	router.Method("PUT", "/admin/prices/{symbol}", kithttp.NewServer(
+		upsertPricesEndpoint,
+		admintransport.UpsertPricesRequestDecoder,
+		admintransport.UpsertPricesResponseEncoder,
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerBefore(admintransport.AdminKeyDecoder),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	deletePricesEndpoint := adminendpoints.MakeDeletePricesEndpoint(config.AdminService)
+	deletePricesEndpoint = adminendpoints.MakeAdminEndpoint(config.AuthService, deletePricesEndpoint)
+	router.Method("DELETE", "/admin/prices/{symbol}", kithttp.NewServer(
+		deletePricesEndpoint,
+		admintransport.DeletePricesRequestDecoder,
+		admintransport.DeletePricesResponseEncoder,
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerBefore(admintransport.AdminKeyDecoder),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+
+	setHoldingsEndpoint := adminendpoints.MakeSetHoldingsEndpoint(config.AdminService)
+	setHoldingsEndpoint = adminendpoints.MakeAdminEndpoint(config.AuthService, setHoldingsEndpoint)
+	router.Method("PUT", "/admin/users/{username}/holdings", kithttp.NewServer(
+		setHoldingsEndpoint,
+		admintransport.SetHoldingsRequestDecoder,
+		admintransport.SetHoldingsResponseEncoder,
+		kithttp.ServerBefore(tickerstransport.TokenDecoder),
+		kithttp.ServerBefore(admintransport.AdminKeyDecoder),
+		kithttp.ServerErrorEncoder(errorHandler.ErrorEncoder),
+		kithttp.ServerErrorHandler(errorHandler),
+		kithttp.ServerBefore(loggerHandler.Before),
+		kithttp.ServerAfter(loggerHandler.After),
+	))
+}
+
+// deprecationDates of the unprefixed routes, api.deprecation and api.sunset are dates YYYY-MM-DD,
+// the routes are not announced as deprecated without api.deprecation
+func deprecationDates(v *viper.Viper) (time.Time, time.Time, error) {
+	deprecation, err := configDate(v, "api.deprecation")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	sunset, err := configDate(v, "api.sunset")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return deprecation, sunset, nil
+}
+
+// configDate is the zero time when key is not set
+func configDate(v *viper.Viper, key string) (time.Time, error) {
+	value := v.GetString(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q, expected YYYY-MM-DD", key, value)
+	}
+
+	return t, nil
 }
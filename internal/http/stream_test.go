@@ -0,0 +1,142 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHttp_Stream_NoAuth(t *testing.T) {
+	ctx := context.Background()
+
+	// bootstrap config
+	v := viper.New()
+	v.Set("port", "8080")
+	logger := zaplogger.New(true)
+
+	config, err := bootstrap.New(ctx, v, logger)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/tickers/stream")
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected StatusUnauthorized, got %v", resp.Status)
+	}
+}
+
+func TestHttp_Stream_Resume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// bootstrap config
+	v := viper.New()
+	v.Set("port", "8080")
+	v.Set("stream.interval", "5ms")
+	logger := zaplogger.New(true)
+
+	config, err := bootstrap.New(ctx, v, logger)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	go config.PriceFeed.Run(ctx)
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// reads events until n ids are seen, then disconnects
+	readIDs := func(lastEventID string, n int) []uint64 {
+		reqCtx, reqCancel := context.WithTimeout(ctx, time.Second*5)
+		defer reqCancel()
+
+		req, _ := http.NewRequestWithContext(reqCtx, "GET", server.URL+"/tickers/stream", nil)
+		req.SetBasicAuth("6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377", "")
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected StatusOK, got %v", resp.Status)
+		}
+
+		var ids []uint64
+		scanner := bufio.NewScanner(resp.Body)
+
+		for len(ids) < n && scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "id: ") {
+				continue
+			}
+
+			id, err := strconv.ParseUint(strings.TrimPrefix(line, "id: "), 10, 64)
+			if err != nil {
+				t.Fatalf("unexpected error to be nil, got: %v", err)
+			}
+
+			ids = append(ids, id)
+		}
+
+		if len(ids) < n {
+			t.Fatalf("expected %d events, got %d", n, len(ids))
+		}
+
+		return ids
+	}
+
+	first := readIDs("", 3)
+
+	// wait for more ticks to be missed
+	time.Sleep(time.Millisecond * 50)
+
+	resumed := readIDs(strconv.FormatUint(first[len(first)-1], 10), 1)
+
+	if resumed[0] <= first[len(first)-1] {
+		t.Errorf("expected resumed id to be after %d, got %d", first[len(first)-1], resumed[0])
+	}
+}
+
+func TestHttp_Stream_StoredBackend(t *testing.T) {
+	server := newAdminServer(t, "memory")
+	defer server.Close()
+
+	// the simulated prices do not match stored prices, there is no live feed for them
+	resp := doAdminRequest(t, server, "GET", "/tickers/stream?symbols=AAPL", "", adminToken)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected StatusServiceUnavailable, got %v", resp.Status)
+	}
+}
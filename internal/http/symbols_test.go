@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttp_Symbols(t *testing.T) {
+	ctx := context.Background()
+
+	config, err := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/symbols?exchange=NASDAQ")
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status code to be 200, got: %d", resp.StatusCode)
+	}
+
+	var list []map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&list)
+
+	if len(list) == 0 {
+		t.Errorf("expected NASDAQ symbols, got none")
+	}
+	for _, v := range list {
+		if v["exchange"] != "NASDAQ" {
+			t.Errorf("expected exchange to be NASDAQ, got: %v", v["exchange"])
+		}
+	}
+}
+
+func TestHttp_Symbol(t *testing.T) {
+	ctx := context.Background()
+
+	config, _ := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	handler, _ := Handler(ctx, config)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/symbols/meta")
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status code to be 200, got: %d", resp.StatusCode)
+	}
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+
+	if body["symbol"] != "META" || body["name"] != "Meta Platforms, Inc." {
+		t.Errorf("unexpected body: %v", body)
+	}
+
+	// previous symbols resolve to the renamed instrument
+	resp, err = http.Get(server.URL + "/symbols/FB")
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body = map[string]interface{}{}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+
+	if resp.StatusCode != http.StatusOK || body["symbol"] != "META" {
+		t.Errorf("expected FB to resolve to META, got: %d %v", resp.StatusCode, body)
+	}
+	if listings, _ := body["listings"].([]interface{}); len(listings) != 2 {
+		t.Errorf("expected 2 listings, got: %v", body["listings"])
+	}
+
+	resp, err = http.Get(server.URL + "/symbols/INVALID")
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code to be 404, got: %d", resp.StatusCode)
+	}
+}
+
+func TestHttp_SymbolsSearch(t *testing.T) {
+	ctx := context.Background()
+
+	config, _ := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	handler, _ := Handler(ctx, config)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/symbols/search?q=mircosoft")
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status code to be 200, got: %d", resp.StatusCode)
+	}
+
+	var list []map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&list)
+
+	if len(list) == 0 || list[0]["symbol"] != "MSFT" {
+		t.Errorf("expected MSFT first, got: %v", list)
+	}
+
+	resp, err = http.Get(server.URL + "/symbols/search")
+	if err != nil {
+		t.Errorf("unexpected error to be nil, got: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code to be 400, got: %d", resp.StatusCode)
+	}
+}
@@ -199,7 +199,7 @@ func TestHttp_Tickers_Auth(t *testing.T) {
 			if ticker.Symbol == "" {
 				t.Errorf("expected symbol to be not empty, got empty string")
 			}
-			if ticker.Price <= 0 {
+			if ticker.Price.Sign() <= 0 {
 				t.Errorf("expected price to be above 0, got %v", ticker.Price)
 			}
 		}
@@ -217,7 +217,7 @@ func TestHttp_Tickers_Auth(t *testing.T) {
 			if ticker.Symbol == prevTickers[z].Symbol {
 				t.Errorf("expected symbol to be different, got %s", ticker.Symbol)
 			}
-			if ticker.Price == prevTickers[z].Price {
+			if ticker.Price.Equal(prevTickers[z].Price) {
 				t.Errorf("expected price to be different, got %v", ticker.Price)
 			}
 		}
@@ -292,7 +292,7 @@ func TestHttp_Tickers_Constant(t *testing.T) {
 			if ticker.Symbol != prevTickers[z].Symbol {
 				t.Errorf("expected symbol to be %s, got %s", prevTickers[z].Symbol, ticker.Symbol)
 			}
-			if ticker.Price != prevTickers[z].Price {
+			if !ticker.Price.Equal(prevTickers[z].Price) {
 				t.Errorf("expected price to be %v, got %v", prevTickers[z].Price, ticker.Price)
 			}
 		}
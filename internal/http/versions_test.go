@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHttp_Versions_Envelope(t *testing.T) {
+	server := newNegotiationServer(t)
+	defer server.Close()
+
+	_, body := getNegotiated(t, server.URL+"/v1/tickers/AAPL/history", "")
+
+	var bars []map[string]interface{}
+	if err := json.Unmarshal(body, &bars); err != nil || len(bars) == 0 {
+		t.Fatalf("expected the history of AAPL, got %s", body)
+	}
+
+	resp, body := getNegotiated(t, server.URL+"/v2/tickers/AAPL/history", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code 200, got %d: %s", resp.StatusCode, body)
+	}
+
+	var envelope struct {
+		Data  []map[string]interface{} `json:"data"`
+		Meta  map[string]interface{}   `json:"meta"`
+		Links map[string]string        `json:"links"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	if len(envelope.Data) != len(bars) || envelope.Meta["count"] != float64(len(bars)) {
+		t.Errorf("expected %d bars, got %s", len(bars), body)
+	}
+	if envelope.Meta["resolution"] != "1d" {
+		t.Errorf("expected the resolution 1d, got %v", envelope.Meta["resolution"])
+	}
+	if envelope.Links["self"] != "/v2/tickers/AAPL/history" {
+		t.Errorf("expected the self link, got %v", envelope.Links)
+	}
+
+	// rows are not enveloped
+	_, csv := getNegotiated(t, server.URL+"/v2/tickers/AAPL/history", "text/csv")
+	_, v1csv := getNegotiated(t, server.URL+"/v1/tickers/AAPL/history", "text/csv")
+	if string(csv) != string(v1csv) {
+		t.Errorf("expected the csv of v1, got %s", csv)
+	}
+
+	// errors are the same in every version
+	resp, body = getNegotiated(t, server.URL+"/v2/tickers/NOPE/history", "")
+
+	var errBody kit.HttpErrorBody
+	_ = json.Unmarshal(body, &errBody)
+
+	if resp.StatusCode != http.StatusNotFound || errBody.Code != "ticker_not_found" {
+		t.Errorf("expected 404 ticker_not_found, got %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestHttp_Versions_Deprecated(t *testing.T) {
+	server := newNegotiationServer(t)
+	defer server.Close()
+
+	resp, body := getNegotiated(t, server.URL+"/tickers/AAPL/history", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code 200, got %d: %s", resp.StatusCode, body)
+	}
+
+	if v := resp.Header.Get("Deprecation"); v != "@1792368000" {
+		t.Errorf("expected Deprecation @1792368000, got %s", v)
+	}
+	if v := resp.Header.Get("Sunset"); v != "Mon, 19 Apr 2027 00:00:00 GMT" {
+		t.Errorf("expected the sunset http date, got %s", v)
+	}
+	if v := resp.Header.Get("Link"); v != `</v1/tickers/AAPL/history>; rel="successor-version"` {
+		t.Errorf("expected the successor link, got %s", v)
+	}
+
+	// the alias serves the body of v1
+	_, v1 := getNegotiated(t, server.URL+"/v1/tickers/AAPL/history", "")
+	if string(body) != string(v1) {
+		t.Errorf("expected the body of v1, got %s", body)
+	}
+
+	resp, _ = getNegotiated(t, server.URL+"/v1/tickers/AAPL/history", "")
+	if v := resp.Header.Get("Deprecation"); v != "" {
+		t.Errorf("expected v1 not to be deprecated, got %s", v)
+	}
+}
+
+func TestHttp_Versions_NotDeprecated(t *testing.T) {
+	ctx := context.Background()
+
+	// without api.deprecation the aliases are not announced as deprecated
+	config, err := bootstrap.New(ctx, viper.New(), zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, body := getNegotiated(t, server.URL+"/tickers/AAPL/history", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code 200, got %d: %s", resp.StatusCode, body)
+	}
+	if v := resp.Header.Get("Deprecation"); v != "" {
+		t.Errorf("expected no Deprecation, got %s", v)
+	}
+}
+
+func TestHttp_Versions_InvalidSunset(t *testing.T) {
+	ctx := context.Background()
+
+	v := viper.New()
+	v.Set("port", "8080")
+	v.Set("api.sunset", "next year")
+
+	config, err := bootstrap.New(ctx, v, zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	_, err = Handler(ctx, config)
+	if err == nil || !strings.Contains(err.Error(), "api.sunset") {
+		t.Errorf("expected an invalid api.sunset error, got %v", err)
+	}
+}
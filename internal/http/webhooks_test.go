@@ -0,0 +1,244 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	"github.com/falmar/richerage-api/internal/webhooks"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHttp_Webhooks_NoAuth(t *testing.T) {
+	ctx := context.Background()
+
+	// bootstrap config
+	v := viper.New()
+	v.Set("port", "8080")
+	logger := zaplogger.New(true)
+
+	config, err := bootstrap.New(ctx, v, logger)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/webhooks")
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected StatusUnauthorized, got %v", resp.Status)
+	}
+}
+
+func TestHttp_Webhooks_Ping(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const token = "6YR6GMnnrpzr/V5vw3/j+Z/n78sNNWOoAXcgsIpEur8=.dGVzdA==.1708107377"
+
+	// bootstrap config
+	v := viper.New()
+	v.Set("port", "8080")
+	logger := zaplogger.New(true)
+
+	config, err := bootstrap.New(ctx, v, logger)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	go config.WebhookDispatcher.Run(ctx)
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	received := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(webhooks.HeaderEvent)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	// subscribe
+	body, _ := json.Marshal(map[string]interface{}{
+		"url":    receiver.URL,
+		"events": []string{"portfolio.updated"},
+	})
+	req, _ := http.NewRequest("POST", server.URL+"/webhooks", bytes.NewReader(body))
+	req.SetBasicAuth(token, "")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		resp.Body.Close()
+		t.Fatalf("expected StatusCreated, got %v", resp.Status)
+	}
+
+	var sub struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&sub)
+	resp.Body.Close()
+
+	if sub.ID == "" || sub.Secret == "" {
+		t.Fatalf("expected id and secret to be returned, got %+v", sub)
+	}
+
+	// ping it
+	req, _ = http.NewRequest("POST", server.URL+"/webhooks/"+sub.ID+"/ping", nil)
+	req.SetBasicAuth(token, "")
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected StatusAccepted, got %v", resp.Status)
+	}
+
+	select {
+	case event := <-received:
+		if event != "ping" {
+			t.Errorf("expected ping event, got %s", event)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("expected ping to be delivered")
+	}
+
+	// the delivery log should eventually show it
+	var deliveries []struct {
+		Status string `json:"status"`
+	}
+
+	for i := 0; i < 100; i++ {
+		req, _ = http.NewRequest("GET", server.URL+"/webhooks/"+sub.ID+"/deliveries?status=succeeded", nil)
+		req.SetBasicAuth(token, "")
+
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+
+		_ = json.NewDecoder(resp.Body).Decode(&deliveries)
+		resp.Body.Close()
+
+		if len(deliveries) > 0 {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if len(deliveries) != 1 {
+		t.Fatalf("expected 1 succeeded delivery, got %d", len(deliveries))
+	}
+
+	// another user cannot read the log
+	req, _ = http.NewRequest("GET", server.URL+"/webhooks/"+sub.ID+"/deliveries", nil)
+	req.SetBasicAuth("W2uBwQJSdSb2nuJmme9gIJ9lEeXl++PjDrOiEt90qSc=.dGVzMnQ=.1708107850", "")
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected StatusNotFound, got %v", resp.Status)
+	}
+}
+
+func TestHttp_Webhooks_PortfolioUpdated(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	v := viper.New()
+	v.Set("port", "8080")
+	v.Set("storage.backend", "memory")
+	v.Set("admin.users", "test")
+	v.Set("admin.key", adminKey)
+
+	config, err := bootstrap.New(ctx, v, zaplogger.New(true))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	go config.WebhookDispatcher.Run(ctx)
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	received := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	resp := doAdminRequest(t, server, "POST", "/webhooks", `{"url":"`+receiver.URL+`","events":["portfolio.updated"]}`, adminToken)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected StatusCreated, got %v", resp.Status)
+	}
+
+	// an admin changes the holdings of the subscriber
+	resp = doAdminRequest(t, server, "PUT", "/admin/users/test/holdings", `{"symbols":["aapl","fb"]}`, adminToken)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected StatusOK, got %v", resp.Status)
+	}
+
+	select {
+	case body := <-received:
+		var event struct {
+			Type string `json:"type"`
+			Data struct {
+				Username string   `json:"username"`
+				Symbols  []string `json:"symbols"`
+			} `json:"data"`
+		}
+		_ = json.Unmarshal(body, &event)
+
+		if event.Type != "portfolio.updated" || event.Data.Username != "test" || strings.Join(event.Data.Symbols, ",") != "AAPL,META" {
+			t.Errorf("expected portfolio.updated with AAPL and META, got %s", body)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("expected portfolio.updated to be delivered")
+	}
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/bootstrap"
+	"github.com/falmar/richerage-api/internal/pkg/zaplogger"
+	tickerstransport "github.com/falmar/richerage-api/internal/tickers/transport"
+	"github.com/gorilla/websocket"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHttp_Websocket(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// bootstrap config
+	v := viper.New()
+	v.Set("port", "8080")
+	v.Set("stream.interval", "5ms")
+	logger := zaplogger.New(true)
+
+	config, err := bootstrap.New(ctx, v, logger)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	go config.PriceFeed.Run(ctx)
+
+	handler, err := Handler(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	// handshake requires a valid token
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected handshake to be rejected with 401, got %v", err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token=6YR6GMnnrpzr/V5vw3/j%2BZ/n78sNNWOoAXcgsIpEur8%3D.dGVzdA%3D%3D.1708107377", nil)
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.WriteJSON(tickerstransport.WebsocketMessage{
+		Type:    tickerstransport.WebsocketSubscribe,
+		Symbols: []string{"AAPL", "MSFT"},
+	})
+
+	seen := map[string]bool{}
+	deadline := time.Now().Add(time.Second * 5)
+
+	for !(seen["AAPL"] && seen["MSFT"]) && time.Now().Before(deadline) {
+		var msg tickerstransport.WebsocketMessage
+
+		_ = conn.SetReadDeadline(deadline)
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("unexpected error to be nil, got: %v", err)
+		}
+
+		if msg.Type == tickerstransport.WebsocketError {
+			t.Fatalf("unexpected error message %+v", msg)
+		}
+		if msg.Price.Sign() <= 0 {
+			t.Errorf("expected price to be above 0, got %v", msg.Price)
+		}
+
+		seen[msg.Symbol] = true
+	}
+
+	if !seen["AAPL"] || !seen["MSFT"] {
+		t.Errorf("expected prices for AAPL and MSFT, got %v", seen)
+	}
+}
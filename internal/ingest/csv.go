@@ -0,0 +1,81 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var _ Reader = (*csvReader)(nil)
+
+type csvReader struct {
+	source string
+	r      *csv.Reader
+	header []string
+}
+
+// NewCSVReader reads comma separated rows with a header naming the columns
+func NewCSVReader(source string, r io.Reader) (Reader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: missing header", source)
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	// spreadsheets prefix the file with a byte order mark
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	if err := checkColumns(source, header); err != nil {
+		return nil, err
+	}
+
+	return &csvReader{
+		source: source,
+		r:      cr,
+		header: append([]string(nil), header...),
+	}, nil
+}
+
+func (c *csvReader) Read() (*Row, error) {
+	for {
+		record, err := c.r.Read()
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &RowError{Source: c.source, Line: parseErr.Line, Message: parseErr.Err.Error()}
+		} else if err != nil {
+			return nil, err
+		}
+
+		line, _ := c.r.FieldPos(0)
+
+		// blank lines are skipped by encoding/csv, a lone empty field is not a row either
+		if len(record) == 1 && record[0] == "" {
+			continue
+		}
+
+		if len(record) != len(c.header) {
+			return nil, &RowError{
+				Source:  c.source,
+				Line:    line,
+				Message: fmt.Sprintf("expected %d fields, got %d", len(c.header), len(record)),
+			}
+		}
+
+		row := &Row{Source: c.source, Line: line}
+		for i, value := range record {
+			row.set(c.header[i], value)
+		}
+
+		return row, nil
+	}
+}
@@ -0,0 +1,420 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/storage"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxErrors bounds the row errors kept in the output, every skipped row is still counted
+const MaxErrors = 100
+
+// MaxPrice and MaxVolume bound the bars, converted and adjusted prices and volumes must fit the
+// int64 units of decimal.Decimal
+var (
+	MaxPrice  = decimal.NewFromInt(1_000_000_000)
+	MaxVolume = decimal.NewFromInt(1_000_000_000_000_000)
+)
+
+var ErrNoWriter = errors.New("no writable storage backend configured")
+
+type Config struct {
+	// Writer receives the bars, only dry runs are possible without it
+	Writer storage.PriceWriter
+	// Symbols validates symbols against the symbol master when set
+	Symbols storage.SymbolStorage
+
+	// Now bounds the dates of bars, defaults to time.Now
+	Now func() time.Time
+}
+
+type Ingester struct {
+	writer  storage.PriceWriter
+	symbols storage.SymbolStorage
+	now     func() time.Time
+}
+
+func New(cfg *Config) *Ingester {
+	i := &Ingester{
+		now: time.Now,
+	}
+
+	if cfg != nil {
+		i.writer = cfg.Writer
+		i.symbols = cfg.Symbols
+
+		if cfg.Now != nil {
+			i.now = cfg.Now
+		}
+	}
+
+	return i
+}
+
+type IngestInput struct {
+	// Readers are read in order, a later row of the same symbol and date replaces an earlier one
+	Readers []Reader
+	// DryRun validates and summarizes without writing
+	DryRun bool
+	// Strict writes nothing when any row is invalid
+	Strict bool
+}
+
+type IngestOutput struct {
+	// Symbols are sorted by symbol
+	Symbols []*SymbolSummary
+	// Invalid counts rows skipped without a symbol to report them under
+	Invalid int
+	// Errors are the first MaxErrors skipped rows
+	Errors []*RowError
+}
+
+type SymbolSummary struct {
+	Symbol string
+
+	// Rows are distinct valid bars, Invalid skipped rows and Duplicates rows replaced by a later one
+	Rows       int
+	Invalid    int
+	Duplicates int
+
+	Inserted int
+	Updated  int
+
+	// From and To are the oldest and newest valid dates
+	From time.Time
+	To   time.Time
+}
+
+// Ingest validates every row, deduplicates them by symbol and date and upserts the bars per symbol
+func (i *Ingester) Ingest(ctx context.Context, in *IngestInput) (*IngestOutput, error) {
+	if !in.DryRun && i.writer == nil {
+		return nil, ErrNoWriter
+	}
+
+	out := &IngestOutput{}
+	summaries := map[string]*SymbolSummary{}
+	bars := map[string]map[time.Time]types.TickerHistory{}
+	v := &validator{ingester: i, listings: map[string][]symboltypes.Listing{}, currencies: map[string]string{}}
+
+	summary := func(symbol string) *SymbolSummary {
+		s, ok := summaries[symbol]
+		if !ok {
+			s = &SymbolSummary{Symbol: symbol}
+			summaries[symbol] = s
+		}
+
+		return s
+	}
+
+	skip := func(symbol string, err *RowError) {
+		if symbol == "" {
+			out.Invalid++
+		} else {
+			summary(symbol).Invalid++
+		}
+
+		if len(out.Errors) < MaxErrors {
+			out.Errors = append(out.Errors, err)
+		}
+	}
+
+	for _, r := range in.Readers {
+		for {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			row, err := r.Read()
+
+			var rowErr *RowError
+			if errors.Is(err, io.EOF) {
+				break
+			} else if errors.As(err, &rowErr) {
+				skip("", rowErr)
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			bar, err := v.validate(ctx, row)
+			if errors.As(err, &rowErr) {
+				skip(strings.ToUpper(row.Symbol), rowErr)
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			s := summary(bar.Symbol)
+			if bars[bar.Symbol] == nil {
+				bars[bar.Symbol] = map[time.Time]types.TickerHistory{}
+			}
+
+			if _, ok := bars[bar.Symbol][bar.Date]; ok {
+				s.Duplicates++
+			} else {
+				s.Rows++
+			}
+
+			bars[bar.Symbol][bar.Date] = *bar
+
+			if s.From.IsZero() || bar.Date.Before(s.From) {
+				s.From = bar.Date
+			}
+			if bar.Date.After(s.To) {
+				s.To = bar.Date
+			}
+		}
+	}
+
+	for _, s := range summaries {
+		out.Symbols = append(out.Symbols, s)
+	}
+
+	sort.Slice(out.Symbols, func(a, b int) bool {
+		return out.Symbols[a].Symbol < out.Symbols[b].Symbol
+	})
+
+	if in.DryRun || (in.Strict && out.invalid() > 0) {
+		return out, nil
+	}
+
+	for _, s := range out.Symbols {
+		if len(bars[s.Symbol]) == 0 {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		prices := make([]types.TickerHistory, 0, len(bars[s.Symbol]))
+		for _, bar := range bars[s.Symbol] {
+			prices = append(prices, bar)
+		}
+
+		sort.Slice(prices, func(a, b int) bool {
+			return prices[a].Date.Before(prices[b].Date)
+		})
+
+		result, err := i.writer.UpsertPrices(ctx, s.Symbol, prices)
+		if err != nil {
+			return nil, fmt.Errorf("upsert %s: %w", s.Symbol, err)
+		}
+
+		s.Inserted = result.Inserted
+		s.Updated = result.Updated
+	}
+
+	return out, nil
+}
+
+// invalid counts every skipped row
+func (o *IngestOutput) invalid() int {
+	invalid := o.Invalid
+	for _, s := range o.Symbols {
+		invalid += s.Invalid
+	}
+
+	return invalid
+}
+
+// validator turns rows into bars, it caches the symbol master lookups of a run
+type validator struct {
+	ingester   *Ingester
+	listings   map[string][]symboltypes.Listing
+	currencies map[string]string
+}
+
+// validate returns the bar of row or a *RowError explaining why it is skipped
+func (v *validator) validate(ctx context.Context, row *Row) (*types.TickerHistory, error) {
+	invalid := func(format string, args ...interface{}) (*types.TickerHistory, error) {
+		return nil, &RowError{Source: row.Source, Line: row.Line, Message: fmt.Sprintf(format, args...)}
+	}
+
+	symbol := strings.ToUpper(row.Symbol)
+	if symbol == "" {
+		return invalid("missing symbol")
+	}
+	if !isSymbol(symbol) {
+		return invalid("invalid symbol %q", row.Symbol)
+	}
+
+	if row.Date == "" {
+		return invalid("missing date")
+	}
+
+	date, err := parseDate(row.Date)
+	if err != nil {
+		return invalid("invalid date %q, expected YYYY-MM-DD", row.Date)
+	}
+	if date.After(v.ingester.now()) {
+		return invalid("date %s is in the future", row.Date)
+	}
+
+	if row.Close == "" {
+		return invalid("missing close")
+	}
+
+	bar := &types.TickerHistory{
+		Symbol: symbol,
+		Date:   date,
+	}
+
+	prices := []struct {
+		name  string
+		text  string
+		value *decimal.Decimal
+	}{
+		{"close", row.Close, &bar.Price},
+		{"open", row.Open, &bar.Open},
+		{"high", row.High, &bar.High},
+		{"low", row.Low, &bar.Low},
+	}
+
+	for _, p := range prices {
+		if p.text == "" {
+			// open, high and low default to the close
+			*p.value = bar.Price
+			continue
+		}
+
+		d, err := decimal.Parse(p.text)
+		if err != nil {
+			return invalid("invalid %s %q", p.name, p.text)
+		}
+		if d.Sign() <= 0 {
+			return invalid("%s must be positive, got %s", p.name, p.text)
+		}
+		if d.Cmp(MaxPrice) > 0 {
+			return invalid("%s must be at most %s, got %s", p.name, MaxPrice, p.text)
+		}
+
+		*p.value = d
+	}
+
+	if bar.Low.Cmp(bar.Open) > 0 || bar.Low.Cmp(bar.Price) > 0 || bar.High.Cmp(bar.Open) < 0 || bar.High.Cmp(bar.Price) < 0 {
+		return invalid("expected low <= open, close <= high, got open %s high %s low %s close %s", bar.Open, bar.High, bar.Low, bar.Price)
+	}
+
+	if row.Volume != "" {
+		d, err := decimal.Parse(row.Volume)
+		if err != nil || !d.Round(0).Equal(d) || d.Sign() < 0 {
+			return invalid("invalid volume %q, expected a positive integer", row.Volume)
+		}
+		if d.Cmp(MaxVolume) > 0 {
+			return invalid("volume must be at most %s, got %s", MaxVolume, row.Volume)
+		}
+
+		bar.Volume = d.Round(0).Units()
+	}
+
+	bar.Currency = strings.ToUpper(row.Currency)
+	if bar.Currency != "" && !isCurrency(bar.Currency) {
+		return invalid("invalid currency %q", row.Currency)
+	}
+
+	if v.ingester.symbols == nil {
+		return bar, nil
+	}
+
+	listings, currency, err := v.lookup(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if listings == nil {
+		return invalid("unknown symbol %s", symbol)
+	}
+
+	covered := false
+	for _, l := range listings {
+		covered = covered || l.Covers(date)
+	}
+	if !covered {
+		return invalid("symbol %s was not trading on %s", symbol, date.Format("2006-01-02"))
+	}
+
+	if bar.Currency == "" {
+		bar.Currency = currency
+	} else if currency != "" && bar.Currency != currency {
+		return invalid("currency %s differs from the trading currency %s of %s", bar.Currency, currency, symbol)
+	}
+
+	return bar, nil
+}
+
+// lookup returns the listings under symbol and their trading currency, no listings for unknown symbols
+func (v *validator) lookup(ctx context.Context, symbol string) ([]symboltypes.Listing, string, error) {
+	if listings, ok := v.listings[symbol]; ok {
+		return listings, v.currencies[symbol], nil
+	}
+
+	instruments, err := v.ingester.symbols.ResolveSymbol(ctx, symbol)
+
+	var errNotFound *symboltypes.ErrSymbolNotFound
+	if err != nil && !errors.As(err, &errNotFound) {
+		return nil, "", err
+	}
+
+	var listings []symboltypes.Listing
+	for _, instrument := range instruments {
+		for _, l := range instrument.Listings {
+			if l.Symbol == symbol {
+				listings = append(listings, l)
+				v.currencies[symbol] = instrument.Currency
+			}
+		}
+	}
+
+	v.listings[symbol] = listings
+
+	return listings, v.currencies[symbol], nil
+}
+
+// parseDate reads a date or the date part of an RFC3339 time, as midnight UTC like stored history
+func parseDate(s string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+func isSymbol(s string) bool {
+	if len(s) > 12 {
+		return false
+	}
+
+	for _, c := range s {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '.' && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isCurrency(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,177 @@
+//go:build test
+
+package ingest
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestIngester(writer storage.PriceWriter) *Ingester {
+	return New(&Config{
+		Writer:  writer,
+		Symbols: storage.NewEmbeddedSymbols(),
+		Now: func() time.Time {
+			return time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+		},
+	})
+}
+
+func TestIngest(t *testing.T) {
+	ctx := context.Background()
+
+	data := "symbol,date,open,high,low,close,volume\n" +
+		"AAPL,2024-01-02,185.50,188.44,183.89,185.64,82488700\n" +
+		// a vendor correction of the same day replaces the first row
+		"aapl,2024-01-02,185.50,188.44,183.89,185.65,82488700\n" +
+		"AAPL,2024-01-03T00:00:00Z,,,,184.25,\n" +
+		"MSFT,2024-01-03,10,9,8,9,1\n" +
+		"MSFT,2024-01-04,370.60,372.00,368.00,370.00,-1\n" +
+		"MSFT,2024-07-02,,,,370.00,\n" +
+		// converted and adjusted prices of larger bars would overflow
+		"MSFT,2024-01-05,,,,1000000000.01,\n" +
+		"MSFT,2024-01-08,,,,370.00,1000000000000000001\n" +
+		"ZZZZ,2024-01-03,,,,1,\n" +
+		",2024-01-03,,,,1,\n" +
+		"FB,2023-01-03,,,,124.74,\n" +
+		"META,2021-01-04,,,,268.94,\n"
+
+	var written = map[string][]types.TickerHistory{}
+
+	writer := storage.NewMockPriceWriter()
+	writer.(*storage.MockPriceWriter).UpsertPricesFunc = func(ctx context.Context, symbol string, prices []types.TickerHistory) (*storage.UpsertResult, error) {
+		written[symbol] = prices
+		return &storage.UpsertResult{Inserted: len(prices) - 1, Updated: 1}, nil
+	}
+
+	r, _ := NewCSVReader("prices.csv", strings.NewReader(data))
+	out, err := newTestIngester(writer).Ingest(ctx, &IngestInput{Readers: []Reader{r}})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	summaries := map[string]SymbolSummary{}
+	var symbols []string
+	for _, s := range out.Symbols {
+		summaries[s.Symbol] = *s
+		symbols = append(symbols, s.Symbol)
+	}
+
+	if strings.Join(symbols, ",") != "AAPL,FB,META,MSFT,ZZZZ" {
+		t.Errorf("expected summaries sorted by symbol, got %v", symbols)
+	}
+
+	aapl := summaries["AAPL"]
+	if aapl.Rows != 2 || aapl.Duplicates != 1 || aapl.Invalid != 0 || aapl.Inserted != 1 || aapl.Updated != 1 {
+		t.Errorf("expected AAPL 2 rows 1 duplicate 1 inserted 1 updated, got %+v", aapl)
+	}
+	if aapl.From.Format("2006-01-02") != "2024-01-02" || aapl.To.Format("2006-01-02") != "2024-01-03" {
+		t.Errorf("expected AAPL from 2024-01-02 to 2024-01-03, got %v %v", aapl.From, aapl.To)
+	}
+
+	// invalid OHLC, negative volume, future date, too large price and volume
+	if s := summaries["MSFT"]; s.Rows != 0 || s.Invalid != 5 {
+		t.Errorf("expected MSFT 5 invalid rows, got %+v", s)
+	}
+	if s := summaries["ZZZZ"]; s.Invalid != 1 {
+		t.Errorf("expected ZZZZ to be an unknown symbol, got %+v", s)
+	}
+	if out.Invalid != 1 {
+		t.Errorf("expected 1 invalid row without symbol, got %d", out.Invalid)
+	}
+
+	// renamed: FB until 2022-06-09, META after
+	if s := summaries["FB"]; s.Invalid != 1 {
+		t.Errorf("expected FB to be invalid after the rename, got %+v", s)
+	}
+	if s := summaries["META"]; s.Invalid != 1 {
+		t.Errorf("expected META to be invalid before the rename, got %+v", s)
+	}
+
+	if len(out.Errors) != 9 || out.Errors[0].Line != 5 || !strings.Contains(out.Errors[0].Message, "low") {
+		t.Errorf("expected 9 errors starting on line 5, got %v", out.Errors)
+	}
+	if !strings.Contains(out.Errors[3].Message, "at most") || !strings.Contains(out.Errors[4].Message, "at most") {
+		t.Errorf("expected the too large close and volume, got %v and %v", out.Errors[3], out.Errors[4])
+	}
+
+	prices := written["AAPL"]
+	if len(written) != 1 || len(prices) != 2 {
+		t.Fatalf("expected 2 AAPL bars written, got %v", written)
+	}
+
+	// oldest first, the close of the later row, defaults from the close and the trading currency
+	if !prices[0].Price.Equal(decimal.MustParse("185.65")) || !prices[0].Date.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the corrected 2024-01-02 close 185.65, got %+v", prices[0])
+	}
+	if !prices[1].Open.Equal(prices[1].Price) || !prices[1].Low.Equal(prices[1].Price) || prices[1].Volume != 0 || prices[1].Currency != "USD" {
+		t.Errorf("expected open, high and low of the close in USD, got %+v", prices[1])
+	}
+}
+
+func TestIngest_DryRun(t *testing.T) {
+	ctx := context.Background()
+
+	r := NewJSONLReader("prices.jsonl", strings.NewReader(`{"symbol":"AAPL","date":"2024-01-02","close":185.64}`))
+
+	// the mock fails when called
+	out, err := newTestIngester(storage.NewMockPriceWriter()).Ingest(ctx, &IngestInput{
+		Readers: []Reader{r},
+		DryRun:  true,
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out.Symbols) != 1 || out.Symbols[0].Rows != 1 || out.Symbols[0].Inserted != 0 {
+		t.Errorf("expected 1 row not written, got %+v", out.Symbols)
+	}
+}
+
+func TestIngest_Strict(t *testing.T) {
+	ctx := context.Background()
+
+	r := NewRowReader([]*Row{
+		{Source: "prices", Line: 1, Symbol: "AAPL", Date: "2024-01-02", Close: "185.64"},
+		{Source: "prices", Line: 2, Symbol: "AAPL", Date: "2024-01-03", Close: "0"},
+	})
+
+	// the mock fails when called
+	out, err := newTestIngester(storage.NewMockPriceWriter()).Ingest(ctx, &IngestInput{
+		Readers: []Reader{r},
+		Strict:  true,
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out.Errors) != 1 || out.Errors[0].Line != 2 {
+		t.Errorf("expected line 2 to be invalid, got %+v", out.Errors)
+	}
+	if len(out.Symbols) != 1 || out.Symbols[0].Inserted != 0 {
+		t.Errorf("expected nothing written, got %+v", out.Symbols)
+	}
+}
+
+func TestIngest_NoWriter(t *testing.T) {
+	_, err := newTestIngester(nil).Ingest(context.Background(), &IngestInput{})
+	if !errors.Is(err, ErrNoWriter) {
+		t.Errorf("expected error to be ErrNoWriter, got %v", err)
+	}
+}
+
+func TestIngest_WriterError(t *testing.T) {
+	writer := storage.NewMockPriceWriter()
+
+	r := NewJSONLReader("prices.jsonl", strings.NewReader(`{"symbol":"AAPL","date":"2024-01-02","close":185.64}`))
+	_, err := newTestIngester(writer).Ingest(context.Background(), &IngestInput{Readers: []Reader{r}})
+	if !errors.Is(err, storage.ErrMockUncalledFor) {
+		t.Errorf("expected error to be the writer error, got %v", err)
+	}
+}
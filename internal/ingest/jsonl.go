@@ -0,0 +1,80 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxJSONLine bounds a single line of JSON, rows are a few hundred bytes
+const maxJSONLine = 1024 * 1024
+
+var _ Reader = (*jsonlReader)(nil)
+
+type jsonlReader struct {
+	source string
+	s      *bufio.Scanner
+	line   int
+}
+
+// NewJSONLReader reads one JSON object per line, keys are the column names,
+// values are numbers or strings
+func NewJSONLReader(source string, r io.Reader) Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxJSONLine)
+
+	return &jsonlReader{
+		source: source,
+		s:      s,
+	}
+}
+
+func (j *jsonlReader) Read() (*Row, error) {
+	for j.s.Scan() {
+		j.line++
+
+		data := bytes.TrimSpace(j.s.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var object map[string]interface{}
+
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+
+		if err := d.Decode(&object); err != nil {
+			return nil, j.rowError("invalid JSON: " + err.Error())
+		}
+
+		row := &Row{Source: j.source, Line: j.line}
+		for key, value := range object {
+			switch v := value.(type) {
+			case nil:
+			case string:
+				row.set(key, v)
+			case json.Number:
+				row.set(key, v.String())
+			default:
+				if _, ok := columns[strings.ToLower(key)]; ok {
+					return nil, j.rowError(fmt.Sprintf("%s: expected a number or a string", key))
+				}
+			}
+		}
+
+		return row, nil
+	}
+
+	if err := j.s.Err(); err != nil {
+		return nil, fmt.Errorf("%s:%d: %w", j.source, j.line+1, err)
+	}
+
+	return nil, io.EOF
+}
+
+func (j *jsonlReader) rowError(message string) *RowError {
+	return &RowError{Source: j.source, Line: j.line, Message: message}
+}
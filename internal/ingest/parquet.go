@@ -0,0 +1,172 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"github.com/apache/arrow/go/v11/parquet"
+	"github.com/apache/arrow/go/v11/parquet/file"
+	"github.com/apache/arrow/go/v11/parquet/pqarrow"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parquetBatchSize is the number of rows decoded at once, the file is never loaded whole
+const parquetBatchSize = 4096
+
+var _ Reader = (*parquetReader)(nil)
+
+type parquetReader struct {
+	source  string
+	records pqarrow.RecordReader
+	names   []string
+	// mapped are the indexes of the columns known to Row, others are never decoded
+	mapped []int
+
+	record arrow.Record
+	index  int
+	line   int
+	done   bool
+}
+
+// NewParquetReader reads the rows of a parquet file, columns are matched by name
+//
+// text columns hold strings, prices are floating point, integer or decimal columns,
+// dates are date or timestamp columns or text
+func NewParquetReader(source string, r parquet.ReaderAtSeeker) (Reader, error) {
+	pf, err := file.NewParquetReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{BatchSize: parquetBatchSize}, memory.DefaultAllocator)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	schema, err := fr.Schema()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	names := make([]string, len(schema.Fields()))
+	var mapped []int
+	for i, f := range schema.Fields() {
+		names[i] = f.Name
+
+		if _, ok := columns[strings.ToLower(strings.TrimSpace(f.Name))]; ok {
+			mapped = append(mapped, i)
+		}
+	}
+
+	if err := checkColumns(source, names); err != nil {
+		return nil, err
+	}
+
+	// nested columns have several leaves, read the mapped fields by leaf
+	leaves := make([]int, 0, len(mapped))
+	for _, i := range mapped {
+		leaf := fr.Manifest.Fields[i].Field.Name
+		idx := pf.MetaData().Schema.ColumnIndexByName(leaf)
+		if idx < 0 {
+			return nil, fmt.Errorf("%s: column %s is not a primitive column", source, leaf)
+		}
+
+		leaves = append(leaves, idx)
+	}
+
+	records, err := fr.GetRecordReader(context.Background(), leaves, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	return &parquetReader{
+		source:  source,
+		records: records,
+		names:   names,
+		mapped:  mapped,
+	}, nil
+}
+
+func (p *parquetReader) Read() (*Row, error) {
+	for p.record == nil || p.index >= int(p.record.NumRows()) {
+		if p.done {
+			return nil, io.EOF
+		}
+
+		record, err := p.records.Read()
+		if errors.Is(err, io.EOF) || (err == nil && record == nil) {
+			p.done = true
+			p.records.Release()
+			return nil, io.EOF
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.source, err)
+		}
+
+		// owned by the record reader until the next read
+		p.record = record
+		p.index = 0
+	}
+
+	p.line++
+	row := &Row{Source: p.source, Line: p.line}
+
+	for i, column := range p.record.Columns() {
+		name := p.names[p.mapped[i]]
+
+		value, err := parquetValue(column, p.index)
+		if err != nil {
+			return nil, fmt.Errorf("%s: column %s: %w", p.source, name, err)
+		}
+
+		row.set(name, value)
+	}
+
+	p.index++
+
+	return row, nil
+}
+
+// parquetValue is the text of row i of column, empty when null
+func parquetValue(column arrow.Array, i int) (string, error) {
+	if column.IsNull(i) {
+		return "", nil
+	}
+
+	switch c := column.(type) {
+	case *array.String:
+		return c.Value(i), nil
+	case *array.LargeString:
+		return c.Value(i), nil
+	case *array.Binary:
+		return string(c.Value(i)), nil
+	case *array.Date32:
+		return c.Value(i).ToTime().Format("2006-01-02"), nil
+	case *array.Date64:
+		return c.Value(i).ToTime().Format("2006-01-02"), nil
+	case *array.Timestamp:
+		unit := c.DataType().(*arrow.TimestampType).Unit
+		return c.Value(i).ToTime(unit).Format("2006-01-02T15:04:05Z07:00"), nil
+	case *array.Float64:
+		return strconv.FormatFloat(c.Value(i), 'f', -1, 64), nil
+	case *array.Float32:
+		return strconv.FormatFloat(float64(c.Value(i)), 'f', -1, 32), nil
+	case *array.Int64:
+		return strconv.FormatInt(c.Value(i), 10), nil
+	case *array.Int32:
+		return strconv.FormatInt(int64(c.Value(i)), 10), nil
+	case *array.Uint64:
+		return strconv.FormatUint(c.Value(i), 10), nil
+	case *array.Uint32:
+		return strconv.FormatUint(uint64(c.Value(i)), 10), nil
+	case *array.Decimal128:
+		scale := c.DataType().(*arrow.Decimal128Type).Scale
+		return c.Value(i).ToString(scale), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", column.DataType())
+	}
+}
@@ -0,0 +1,198 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+// Row is a daily bar as written by the vendor, values are kept as text until validated
+type Row struct {
+	// Source is the file the row was read from, Line its line or row number starting at 1
+	Source string
+	Line   int
+
+	Symbol   string
+	Date     string
+	Open     string
+	High     string
+	Low      string
+	Close    string
+	Volume   string
+	Currency string
+}
+
+// Reader reads the rows of a vendor file in order, it returns io.EOF after the last one
+//
+// a *RowError skips a malformed row, reading can continue with the next one, any other error is fatal
+type Reader interface {
+	Read() (*Row, error)
+}
+
+// RowError is a row that was skipped, either malformed or invalid
+type RowError struct {
+	Source  string
+	Line    int
+	Message string
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Source, e.Line, e.Message)
+}
+
+// columns maps the accepted column names, case insensitive, to the field of Row they fill
+var columns = map[string]string{
+	"symbol":   "symbol",
+	"ticker":   "symbol",
+	"date":     "date",
+	"open":     "open",
+	"high":     "high",
+	"low":      "low",
+	"close":    "close",
+	"price":    "close",
+	"volume":   "volume",
+	"currency": "currency",
+}
+
+// requiredColumns every file must have, open, high and low default to the close
+var requiredColumns = []string{"symbol", "date", "close"}
+
+// set assigns value to the field of row named column, unknown columns are ignored
+func (r *Row) set(column string, value string) {
+	value = strings.TrimSpace(value)
+
+	switch columns[strings.ToLower(strings.TrimSpace(column))] {
+	case "symbol":
+		r.Symbol = value
+	case "date":
+		r.Date = value
+	case "open":
+		r.Open = value
+	case "high":
+		r.High = value
+	case "low":
+		r.Low = value
+	case "close":
+		r.Close = value
+	case "volume":
+		r.Volume = value
+	case "currency":
+		r.Currency = value
+	}
+}
+
+// checkColumns fails when a required column is missing from names
+func checkColumns(source string, names []string) error {
+	found := map[string]bool{}
+	for _, name := range names {
+		found[columns[strings.ToLower(strings.TrimSpace(name))]] = true
+	}
+
+	var missing []string
+	for _, c := range requiredColumns {
+		if !found[c] {
+			missing = append(missing, c)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s: missing columns %s", source, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// FormatOf detects the format of a file by its extension
+func FormatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".parquet", ".pq":
+		return FormatParquet, nil
+	default:
+		return "", fmt.Errorf("%s: unknown format, expected .csv, .jsonl or .parquet", path)
+	}
+}
+
+// ParseFormat reads a format name given by the user
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatJSONL, FormatParquet:
+		return f, nil
+	case "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unknown format %s, expected csv, jsonl or parquet", s)
+	}
+}
+
+// Open opens the file at path as a Reader of format, detected by extension when empty,
+// the file is closed by the returned io.Closer
+func Open(path string, format Format) (Reader, io.Closer, error) {
+	var err error
+	if format == "" {
+		format, err = FormatOf(path)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var r Reader
+	switch format {
+	case FormatCSV:
+		r, err = NewCSVReader(path, f)
+	case FormatJSONL:
+		r, err = NewJSONLReader(path, f), nil
+	case FormatParquet:
+		r, err = NewParquetReader(path, f)
+	default:
+		err = errors.New("unknown format " + string(format))
+	}
+
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+
+	return r, f, nil
+}
+
+var _ Reader = (*rowReader)(nil)
+
+type rowReader struct {
+	rows []*Row
+}
+
+// NewRowReader reads rows already in memory, like bars pushed through the api
+func NewRowReader(rows []*Row) Reader {
+	return &rowReader{rows: rows}
+}
+
+func (r *rowReader) Read() (*Row, error) {
+	if len(r.rows) == 0 {
+		return nil, io.EOF
+	}
+
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+
+	return row, nil
+}
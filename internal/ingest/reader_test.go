@@ -0,0 +1,190 @@
+//go:build test
+
+package ingest
+
+import (
+	"bytes"
+	"errors"
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/decimal128"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"github.com/apache/arrow/go/v11/parquet/pqarrow"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, r Reader) ([]*Row, []*RowError) {
+	var rows []*Row
+	var rowErrors []*RowError
+
+	for {
+		row, err := r.Read()
+
+		var rowErr *RowError
+		if errors.Is(err, io.EOF) {
+			return rows, rowErrors
+		} else if errors.As(err, &rowErr) {
+			rowErrors = append(rowErrors, rowErr)
+			continue
+		} else if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+func TestReader_CSV(t *testing.T) {
+	data := "\ufeffTicker,Date,Open,High,Low,Price,Volume,Extra\n" +
+		"AAPL,2024-01-02,185.50,188.44,183.89,185.64,82488700,x\n" +
+		"\n" +
+		"AAPL,2024-01-03\n" +
+		"MSFT,2024-01-03,,,,370.60,,\n"
+
+	r, err := NewCSVReader("prices.csv", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	rows, rowErrors := readAll(t, r)
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	expected := Row{Source: "prices.csv", Line: 2, Symbol: "AAPL", Date: "2024-01-02", Open: "185.50", High: "188.44", Low: "183.89", Close: "185.64", Volume: "82488700"}
+	if *rows[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, *rows[0])
+	}
+	if rows[1].Line != 5 || rows[1].Symbol != "MSFT" || rows[1].Close != "370.60" || rows[1].Open != "" {
+		t.Errorf("expected MSFT on line 5, got %+v", *rows[1])
+	}
+
+	if len(rowErrors) != 1 || rowErrors[0].Line != 4 {
+		t.Errorf("expected a row error on line 4, got %v", rowErrors)
+	}
+
+	_, err = NewCSVReader("prices.csv", strings.NewReader("symbol,open\nAAPL,1\n"))
+	if err == nil || !strings.Contains(err.Error(), "date, close") {
+		t.Errorf("expected missing columns error, got %v", err)
+	}
+}
+
+func TestReader_JSONL(t *testing.T) {
+	data := `{"symbol":"AAPL","date":"2024-01-02","close":185.64,"volume":82488700,"currency":"USD"}` + "\n" +
+		"\n" +
+		`{"symbol":"AAPL",` + "\n" +
+		`{"SYMBOL":"MSFT","Date":"2024-01-03","price":"370.60","open":null,"note":{"a":1}}` + "\n" +
+		`{"symbol":"MSFT","date":"2024-01-04","close":[1]}` + "\n"
+
+	rows, rowErrors := readAll(t, NewJSONLReader("prices.jsonl", strings.NewReader(data)))
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	expected := Row{Source: "prices.jsonl", Line: 1, Symbol: "AAPL", Date: "2024-01-02", Close: "185.64", Volume: "82488700", Currency: "USD"}
+	if *rows[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, *rows[0])
+	}
+	if rows[1].Line != 4 || rows[1].Symbol != "MSFT" || rows[1].Close != "370.60" {
+		t.Errorf("expected MSFT on line 4, got %+v", *rows[1])
+	}
+
+	if len(rowErrors) != 2 || rowErrors[0].Line != 3 || rowErrors[1].Line != 5 {
+		t.Errorf("expected row errors on lines 3 and 5, got %v", rowErrors)
+	}
+}
+
+func TestReader_Parquet(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "symbol", Type: arrow.BinaryTypes.String},
+		{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+		{Name: "close", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}},
+		{Name: "high", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "volume", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+	}, nil)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"AAPL", "MSFT"}, nil)
+	b.Field(1).(*array.Date32Builder).AppendValues([]arrow.Date32{
+		arrow.Date32FromTime(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)),
+		arrow.Date32FromTime(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)),
+	}, nil)
+	b.Field(2).(*array.Decimal128Builder).Append(decimal128.FromI64(18564))
+	b.Field(2).(*array.Decimal128Builder).Append(decimal128.FromI64(37060))
+	b.Field(3).(*array.Float64Builder).AppendValues([]float64{188.44, 0}, []bool{true, false})
+	b.Field(4).(*array.Int64Builder).AppendValues([]int64{82488700, 25258600}, nil)
+	tags := b.Field(5).(*array.ListBuilder)
+	tags.Append(true)
+	tags.ValueBuilder().(*array.StringBuilder).Append("tech")
+	tags.Append(true)
+
+	record := b.NewRecord()
+	defer record.Release()
+
+	var buf bytes.Buffer
+	w, err := pqarrow.NewFileWriter(schema, &buf, nil, pqarrow.DefaultWriterProps())
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if err := w.Write(record); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	r, err := NewParquetReader("prices.parquet", bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	rows, rowErrors := readAll(t, r)
+	if len(rowErrors) != 0 {
+		t.Errorf("expected no row errors, got %v", rowErrors)
+	}
+
+	expected := []Row{
+		{Source: "prices.parquet", Line: 1, Symbol: "AAPL", Date: "2024-01-02", Close: "185.64", High: "188.44", Volume: "82488700"},
+		{Source: "prices.parquet", Line: 2, Symbol: "MSFT", Date: "2024-01-03", Close: "370.60", Volume: "25258600"},
+	}
+
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i := range expected {
+		if *rows[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], *rows[i])
+		}
+	}
+
+	if _, err := r.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF after the last row, got %v", err)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	cases := map[string]Format{
+		"prices.csv":        FormatCSV,
+		"dump/PRICES.JSONL": FormatJSONL,
+		"prices.ndjson":     FormatJSONL,
+		"prices.parquet":    FormatParquet,
+	}
+
+	for path, expected := range cases {
+		if f, err := FormatOf(path); err != nil || f != expected {
+			t.Errorf("%s: expected %s, got %s %v", path, expected, f, err)
+		}
+	}
+
+	if _, err := FormatOf("prices.xlsx"); err == nil {
+		t.Errorf("expected unknown format error")
+	}
+}
@@ -0,0 +1,85 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/markets"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	"time"
+)
+
+type MarketStatusRequest struct {
+	Exchange string
+	At       string
+}
+
+type MarketStatusResponse struct {
+	Exchange string
+	Name     string
+	Location *time.Location
+
+	Open      bool
+	NextOpen  time.Time
+	NextClose time.Time
+}
+
+func MakeMarketStatusEndpoint(svc markets.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyMarketStatusRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		var at = time.Time{}
+		if req.At != "" {
+			at, _ = time.Parse(time.RFC3339, req.At)
+		}
+
+		out, err := svc.GetStatus(ctx, &markets.GetStatusInput{
+			Exchange: req.Exchange,
+			At:       at,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &MarketStatusResponse{
+			Exchange:  out.Exchange,
+			Name:      out.Name,
+			Location:  out.Location,
+			Open:      out.Open,
+			NextOpen:  out.NextOpen,
+			NextClose: out.NextClose,
+		}, nil
+	}
+}
+
+func verifyMarketStatusRequest(request interface{}) (*MarketStatusRequest, error) {
+	req, ok := request.(*MarketStatusRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if req.Exchange == "" {
+		badParams["exchange"] = "required"
+	}
+	if req.At != "" {
+		if _, err := time.Parse(time.RFC3339, req.At); err != nil {
+			badParams["at"] = fmt.Sprintf("invalid format %s: %s", req.At, err.Error())
+		}
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,69 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/markets"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"testing"
+	"time"
+)
+
+func TestEndpoint_MarketStatus(t *testing.T) {
+	var called *markets.GetStatusInput
+
+	svc := markets.NewMockService()
+	svc.(*markets.MockService).GetStatusFunc = func(ctx context.Context, in *markets.GetStatusInput) (*markets.GetStatusOutput, error) {
+		called = in
+		return &markets.GetStatusOutput{
+			Exchange: "NYSE",
+			Location: time.UTC,
+			Open:     true,
+		}, nil
+	}
+
+	resp, err := MakeMarketStatusEndpoint(svc)(context.Background(), &MarketStatusRequest{
+		Exchange: "nyse",
+		At:       "2024-07-02T15:00:00Z",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	res, ok := resp.(*MarketStatusResponse)
+	if !ok || res == nil {
+		t.Errorf("expected response to be *MarketStatusResponse, got %T", resp)
+		return
+	}
+
+	if !res.Open || res.Exchange != "NYSE" {
+		t.Errorf("expected open NYSE response, got %+v", res)
+	}
+	if called == nil || called.Exchange != "nyse" || called.At.IsZero() {
+		t.Errorf("expected service to be called with exchange and time, got %+v", called)
+	}
+}
+
+func TestEndpoint_MarketStatus_Invalid(t *testing.T) {
+	svc := markets.NewMockService()
+
+	_, err := MakeMarketStatusEndpoint(svc)(context.Background(), &MarketStatusRequest{
+		At: "yesterday",
+	})
+
+	var badRequest *kit.BadRequestError
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be *kit.BadRequestError, got %T", err)
+		return
+	}
+
+	if _, ok := badRequest.Params["exchange"]; !ok {
+		t.Errorf("expected exchange param error")
+	}
+	if _, ok := badRequest.Params["at"]; !ok {
+		t.Errorf("expected at param error")
+	}
+}
@@ -0,0 +1,33 @@
+package markets
+
+import (
+	"context"
+	"time"
+)
+
+var _ Service = (*service)(nil)
+
+type Service interface {
+	GetStatus(ctx context.Context, in *GetStatusInput) (*GetStatusOutput, error)
+}
+
+type Config struct {
+	// Now is the clock used when no time is requested, defaults to time.Now
+	Now func() time.Time
+}
+
+func New(cfg *Config) (Service, error) {
+	s := &service{
+		now: time.Now,
+	}
+
+	if cfg != nil && cfg.Now != nil {
+		s.now = cfg.Now
+	}
+
+	return s, nil
+}
+
+type service struct {
+	now func() time.Time
+}
@@ -0,0 +1,27 @@
+//go:build test
+
+package markets
+
+import (
+	"context"
+	"errors"
+)
+
+var _ Service = (*MockService)(nil)
+var ErrMockUncalledFor = errors.New("uncalled for")
+
+func NewMockService() Service {
+	return &MockService{
+		GetStatusFunc: func(ctx context.Context, in *GetStatusInput) (*GetStatusOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+	}
+}
+
+type MockService struct {
+	GetStatusFunc func(ctx context.Context, in *GetStatusInput) (*GetStatusOutput, error)
+}
+
+func (m *MockService) GetStatus(ctx context.Context, in *GetStatusInput) (*GetStatusOutput, error) {
+	return m.GetStatusFunc(ctx, in)
+}
@@ -0,0 +1,56 @@
+package markets
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/calendar"
+	"time"
+)
+
+type GetStatusInput struct {
+	Exchange string
+	// At defaults to now
+	At time.Time
+}
+
+type GetStatusOutput struct {
+	Exchange string
+	Name     string
+	Location *time.Location
+
+	Open bool
+	// NextOpen and NextClose are in the exchange timezone
+	NextOpen  time.Time
+	NextClose time.Time
+}
+
+func (s *service) GetStatus(_ context.Context, in *GetStatusInput) (*GetStatusOutput, error) {
+	cal, err := calendar.Get(in.Exchange)
+	if err != nil {
+		return nil, err
+	}
+
+	at := in.At
+	if at.IsZero() {
+		at = s.now()
+	}
+
+	out := &GetStatusOutput{
+		Exchange: cal.Exchange,
+		Name:     cal.Name,
+		Location: cal.Location,
+	}
+
+	open, close := cal.NextSession(at)
+
+	if !at.Before(open) {
+		// in session, the next open is the following session
+		out.Open = true
+		out.NextClose = close
+		out.NextOpen, _ = cal.NextSession(close)
+	} else {
+		out.NextOpen = open
+		out.NextClose = close
+	}
+
+	return out, nil
+}
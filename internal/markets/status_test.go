@@ -0,0 +1,103 @@
+package markets
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/calendar"
+	"testing"
+	"time"
+)
+
+func TestService_GetStatus(t *testing.T) {
+	cases := []struct {
+		name      string
+		at        string
+		open      bool
+		nextOpen  string
+		nextClose string
+	}{
+		{
+			name:      "open",
+			at:        "2024-07-02T15:00:00Z",
+			open:      true,
+			nextOpen:  "2024-07-03T09:30:00-04:00",
+			nextClose: "2024-07-02T16:00:00-04:00",
+		},
+		{
+			name:      "before open",
+			at:        "2024-07-02T12:00:00Z",
+			open:      false,
+			nextOpen:  "2024-07-02T09:30:00-04:00",
+			nextClose: "2024-07-02T16:00:00-04:00",
+		},
+		{
+			name:      "half day closes early and skips the holiday",
+			at:        "2024-07-03T18:00:00Z",
+			open:      false,
+			nextOpen:  "2024-07-05T09:30:00-04:00",
+			nextClose: "2024-07-05T16:00:00-04:00",
+		},
+		{
+			name:      "weekend",
+			at:        "2024-07-06T15:00:00Z",
+			open:      false,
+			nextOpen:  "2024-07-08T09:30:00-04:00",
+			nextClose: "2024-07-08T16:00:00-04:00",
+		},
+	}
+
+	svc, _ := New(&Config{})
+
+	for _, c := range cases {
+		at, _ := time.Parse(time.RFC3339, c.at)
+
+		out, err := svc.GetStatus(context.Background(), &GetStatusInput{
+			Exchange: "nyse",
+			At:       at,
+		})
+		if err != nil {
+			t.Errorf("%s: expected error to be nil, got %v", c.name, err)
+			continue
+		}
+
+		if out.Exchange != "NYSE" {
+			t.Errorf("%s: expected exchange NYSE, got %s", c.name, out.Exchange)
+		}
+		if out.Open != c.open {
+			t.Errorf("%s: expected open to be %v, got %v", c.name, c.open, out.Open)
+		}
+		if s := out.NextOpen.In(out.Location).Format(time.RFC3339); s != c.nextOpen {
+			t.Errorf("%s: expected next open %s, got %s", c.name, c.nextOpen, s)
+		}
+		if s := out.NextClose.In(out.Location).Format(time.RFC3339); s != c.nextClose {
+			t.Errorf("%s: expected next close %s, got %s", c.name, c.nextClose, s)
+		}
+	}
+}
+
+func TestService_GetStatus_Now(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2024-07-02T15:00:00Z")
+	svc, _ := New(&Config{Now: func() time.Time { return now }})
+
+	out, err := svc.GetStatus(context.Background(), &GetStatusInput{Exchange: "NYSE"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if !out.Open {
+		t.Errorf("expected market to be open at %s", now)
+	}
+}
+
+func TestService_GetStatus_NotFound(t *testing.T) {
+	svc, _ := New(&Config{})
+
+	_, err := svc.GetStatus(context.Background(), &GetStatusInput{Exchange: "XXX"})
+	var errNotFound *calendar.ErrExchangeNotFound
+	if !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be %T, got %T", errNotFound, err)
+	} else if errNotFound.Exchange != "XXX" {
+		t.Errorf("expected exchange to be XXX, got %s", errNotFound.Exchange)
+	}
+}
@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/markets/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"time"
+)
+
+func MarketStatusRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	return &endpoint.MarketStatusRequest{
+		Exchange: chi.URLParam(r, "exchange"),
+		At:       r.URL.Query().Get("at"),
+	}, nil
+}
+
+func MarketStatusResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.MarketStatusResponse)
+
+	// times are in the exchange timezone, the offset keeps them unambiguous
+	return kit.EncodeResponse(ctx, w, http.StatusOK, map[string]interface{}{
+		"exchange":   res.Exchange,
+		"name":       res.Name,
+		"timezone":   res.Location.String(),
+		"open":       res.Open,
+		"next_open":  res.NextOpen.In(res.Location).Format(time.RFC3339),
+		"next_close": res.NextClose.In(res.Location).Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,77 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/markets/endpoint"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMarketStatus_RequestDecoder(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/markets/nyse/status?at=2024-07-02T15:00:00Z", nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("exchange", "nyse")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	out, err := MarketStatusRequestDecoder(context.Background(), r)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	req, ok := out.(*endpoint.MarketStatusRequest)
+	if !ok || req == nil {
+		t.Errorf("expected request to be of type MarketStatusRequest, got %T", out)
+		return
+	}
+
+	if req.Exchange != "nyse" {
+		t.Errorf("expected exchange to be nyse, got %s", req.Exchange)
+	}
+	if req.At != "2024-07-02T15:00:00Z" {
+		t.Errorf("expected at to be set, got %s", req.At)
+	}
+}
+
+func TestMarketStatus_ResponseEncoder(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	open, _ := time.Parse(time.RFC3339, "2024-07-03T13:30:00Z")
+	close, _ := time.Parse(time.RFC3339, "2024-07-02T20:00:00Z")
+
+	w := httptest.NewRecorder()
+	err := MarketStatusResponseEncoder(context.Background(), w, &endpoint.MarketStatusResponse{
+		Exchange:  "NYSE",
+		Name:      "New York Stock Exchange",
+		Location:  loc,
+		Open:      true,
+		NextOpen:  open,
+		NextClose: close,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected content type application/json, got %s", w.Header().Get("Content-Type"))
+	}
+
+	var body map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	if body["timezone"] != "America/New_York" {
+		t.Errorf("expected timezone America/New_York, got %v", body["timezone"])
+	}
+	if body["open"] != true {
+		t.Errorf("expected open to be true, got %v", body["open"])
+	}
+	if body["next_open"] != "2024-07-03T09:30:00-04:00" {
+		t.Errorf("expected next_open in exchange time, got %v", body["next_open"])
+	}
+	if body["next_close"] != "2024-07-02T16:00:00-04:00" {
+		t.Errorf("expected next_close in exchange time, got %v", body["next_close"])
+	}
+}
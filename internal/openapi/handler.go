@@ -0,0 +1,44 @@
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// swaggerUI are the assets of swagger-ui-dist 5.18.2 (Apache 2.0, see swagger-ui/LICENSE) the docs
+// page loads, served by the binary so the page works offline, update by replacing the files
+//
+//go:embed swagger-ui/swagger-ui-bundle.js swagger-ui/swagger-ui.css
+var swaggerUI embed.FS
+
+// SpecHandler serves doc as JSON
+func SpecHandler(doc *Document) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		_ = json.NewEncoder(w).Encode(doc)
+	})
+}
+
+// DocsHandler serves the Swagger UI page of the document at /openapi.json
+func DocsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+
+		_, _ = w.Write(docsPage)
+	})
+}
+
+// AssetsHandler serves the Swagger UI assets of the docs page under prefix
+func AssetsHandler(prefix string) http.Handler {
+	assets, _ := fs.Sub(swaggerUI, "swagger-ui")
+
+	return http.StripPrefix(prefix, http.FileServer(http.FS(assets)))
+}
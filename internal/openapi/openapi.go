@@ -0,0 +1,175 @@
+// Package openapi builds the OpenAPI 3.1 document of the http api from its router, every registered route
+// must be described by an Operation and every Operation must match a route.
+package openapi
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path by lower case method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// pathParam matches the chi url parameters, regexp constraints are not part of the OpenAPI path
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// New returns a document without paths, the HttpErrorBody schema and the basic auth scheme
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				ErrorSchemaName: errorSchema(),
+			},
+			SecuritySchemes: map[string]*SecurityScheme{
+				SecuritySchemeName: {
+					Type:        "http",
+					Scheme:      "basic",
+					Description: "The token returned by POST /login is the username, the password is empty: `Authorization: Basic base64(token + \":\")`.",
+				},
+			},
+		},
+	}
+}
+
+// AddRoutes adds the routes of router described by operations, keyed by "METHOD /path" as registered.
+// Undescribed routes and operations without route are an error, the document always matches the router.
+func (d *Document) AddRoutes(router chi.Routes, operations map[string]*Operation) error {
+	seen := map[string]bool{}
+	var missing []string
+
+	err := chi.Walk(router, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		key := method + " " + route
+
+		op, ok := operations[key]
+		if !ok {
+			missing = append(missing, key)
+			return nil
+		}
+		seen[key] = true
+
+		path := pathParam.ReplaceAllString(route, "{$1}")
+		addPathParameters(op, path)
+
+		item, ok := d.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			d.Paths[path] = item
+		}
+		(*item)[strings.ToLower(method)] = op
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var unknown []string
+	for key := range operations {
+		if !seen[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(missing) > 0 || len(unknown) > 0 {
+		sort.Strings(missing)
+		sort.Strings(unknown)
+
+		return fmt.Errorf("openapi: routes without operation %v, operations without route %v", missing, unknown)
+	}
+
+	return nil
+}
+
+// addPathParameters declares the parameters of path the operation does not describe
+func addPathParameters(op *Operation, path string) {
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		declared := false
+		for _, p := range op.Parameters {
+			if p.In == "path" && p.Name == m[1] {
+				p.Required = true
+				declared = true
+			}
+		}
+
+		if !declared {
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name:     m[1],
+				In:       "path",
+				Required: true,
+				Schema:   String(),
+			})
+		}
+	}
+}
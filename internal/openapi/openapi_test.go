@@ -0,0 +1,139 @@
+package openapi
+
+import (
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchemaOf(t *testing.T) {
+	s := SchemaOf(struct {
+		Name     string            `json:"name"`
+		Price    decimal.Decimal   `json:"price"`
+		At       time.Time         `json:"at"`
+		Tags     []string          `json:"tags,omitempty"`
+		Params   map[string]string `json:"params,omitempty"`
+		Internal string            `json:"-"`
+		Untagged int
+	}{})
+
+	if s.Type != "object" {
+		t.Fatalf("expected object, got %v", s.Type)
+	}
+
+	expected := map[string]string{
+		"name":     "string",
+		"price":    "number",
+		"at":       "string",
+		"tags":     "array",
+		"params":   "object",
+		"Untagged": "integer",
+	}
+	if len(s.Properties) != len(expected) {
+		t.Errorf("expected %d properties, got %d", len(expected), len(s.Properties))
+	}
+	for name, typ := range expected {
+		if p, ok := s.Properties[name]; !ok || p.Type != typ {
+			t.Errorf("expected %s to be %s, got %+v", name, typ, p)
+		}
+	}
+
+	if s.Properties["at"].Format != "date-time" {
+		t.Errorf("expected at to be date-time, got %s", s.Properties["at"].Format)
+	}
+
+	required := []string{"Untagged", "at", "name", "price"}
+	if !reflect.DeepEqual(s.Required, required) {
+		t.Errorf("expected required %v, got %v", required, s.Required)
+	}
+}
+
+func TestDocument_AddRoutes(t *testing.T) {
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	router := chi.NewRouter()
+	router.Method("GET", "/items/{id}", handler)
+	router.Method("POST", "/items", handler)
+
+	doc := New(Info{Title: "test", Version: "1"})
+	err := doc.AddRoutes(router, map[string]*Operation{
+		"GET /items/{id}": Op("items", "Get an item", "").Authenticated(),
+		"POST /items":     Op("items", "Create an item", ""),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	op := (*doc.Paths["/items/{id}"])["get"]
+	if op == nil {
+		t.Fatalf("expected get /items/{id}, got %v", doc.Paths)
+	}
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "id" || !op.Parameters[0].Required {
+		t.Errorf("expected the required id path parameter, got %+v", op.Parameters)
+	}
+	if _, ok := op.Responses["401"]; !ok {
+		t.Errorf("expected 401 response of authenticated operation, got %v", op.Responses)
+	}
+}
+
+func TestOperation_Returns(t *testing.T) {
+	op := Op("items", "Download items", "").
+		Returns(http.StatusOK, "The items", "text/csv", String()).
+		Returns(http.StatusOK, "", "application/json", ArrayOf(String()))
+
+	res := op.Responses["200"]
+	if res == nil || res.Description != "The items" || len(res.Content) != 2 {
+		t.Errorf("expected a response with both content types, got %+v", res)
+	}
+}
+
+func TestOperation_Enveloped(t *testing.T) {
+	op := Op("items", "List items", "").
+		Returns(http.StatusOK, "The items", "application/json", ArrayOf(String()).Nullable()).
+		Returns(http.StatusOK, "", "text/csv", String()).
+		Errors(http.StatusNotFound)
+
+	v2 := op.Clone().Enveloped()
+
+	if op.Responses["200"].Content["application/json"].Schema.Properties["data"] != nil {
+		t.Fatalf("expected the clone not to change the operation")
+	}
+
+	data := v2.Responses["200"].Content["application/json"].Schema.Properties["data"]
+	if data == nil || data.Type != "array" {
+		t.Errorf("expected a non null array as data, got %+v", data)
+	}
+	if v2.Responses["200"].Content["text/csv"].Schema.Type != "string" {
+		t.Errorf("expected csv not to be enveloped")
+	}
+	if v2.Responses["404"].Content["application/json"].Schema.Properties["data"] != nil {
+		t.Errorf("expected errors not to be enveloped")
+	}
+
+	op.Deprecate("/v1/items")
+	if !op.Deprecated || op.Responses["200"].Headers["Sunset"] == nil || v2.Responses["200"].Headers["Sunset"] != nil {
+		t.Errorf("expected only the operation to be deprecated")
+	}
+}
+
+func TestDocument_AddRoutes_Drift(t *testing.T) {
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	router := chi.NewRouter()
+	router.Method("GET", "/items", handler)
+
+	err := New(Info{}).AddRoutes(router, map[string]*Operation{
+		"DELETE /items": Op("items", "Delete items", ""),
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "GET /items") || !strings.Contains(err.Error(), "DELETE /items") {
+		t.Errorf("expected the undocumented route and the unknown operation, got %v", err)
+	}
+}
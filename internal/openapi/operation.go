@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ErrorSchemaName    = "HttpErrorBody"
+	SecuritySchemeName = "token"
+)
+
+func errorSchema() *Schema {
+	s := SchemaOf(kit.HttpErrorBody{})
+	s.Description = "Body of every error response, `code` is stable and meant for clients, `params` lists the invalid parameters of `bad_request` errors."
+
+	return s
+}
+
+// Authenticated requires the token of POST /login
+func (o *Operation) Authenticated() *Operation {
+	o.Security = []map[string][]string{{SecuritySchemeName: {}}}
+	if _, ok := o.Responses["401"]; !ok {
+		o.Errors(http.StatusUnauthorized)
+	}
+
+	return o
+}
+
+// Query adds an optional query parameter
+func (o *Operation) Query(name string, description string, schema *Schema) *Operation {
+	o.Parameters = append(o.Parameters, &Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      schema,
+	})
+
+	return o
+}
+
+// Path describes a parameter of the path
+func (o *Operation) Path(name string, description string) *Operation {
+	o.Parameters = append(o.Parameters, &Parameter{
+		Name:        name,
+		In:          "path",
+		Description: description,
+		Required:    true,
+		Schema:      String(),
+	})
+
+	return o
+}
+
+// Header adds an optional request header
+func (o *Operation) Header(name string, description string) *Operation {
+	o.Parameters = append(o.Parameters, &Parameter{
+		Name:        name,
+		In:          "header",
+		Description: description,
+		Schema:      String(),
+	})
+
+	return o
+}
+
+// Body sets a required JSON request body
+func (o *Operation) Body(schema *Schema) *Operation {
+	o.RequestBody = &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			"application/json": {Schema: schema},
+		},
+	}
+
+	return o
+}
+
+// Returns adds a successful response of status, a nil schema is a response without body,
+// returning the same status again adds another content type to its response
+func (o *Operation) Returns(status int, description string, contentType string, schema *Schema) *Operation {
+	if res, ok := o.Responses[strconv.Itoa(status)]; ok && res.Content != nil && schema != nil {
+		res.Content[contentType] = &MediaType{Schema: schema}
+		return o
+	}
+
+	res := &Response{Description: description}
+	if schema != nil {
+		res.Content = map[string]*MediaType{
+			contentType: {Schema: schema},
+		}
+	}
+
+	o.response(status, res)
+
+	return o
+}
+
+// Errors adds the HttpErrorBody responses of the statuses
+func (o *Operation) Errors(statuses ...int) *Operation {
+	for _, status := range statuses {
+		o.response(status, &Response{
+			Description: http.StatusText(status),
+			Content: map[string]*MediaType{
+				"application/json": {Schema: Ref(ErrorSchemaName)},
+			},
+		})
+	}
+
+	return o
+}
+
+func (o *Operation) response(status int, res *Response) {
+	if o.Responses == nil {
+		o.Responses = map[string]*Response{}
+	}
+
+	o.Responses[strconv.Itoa(status)] = res
+}
+
+// Negotiated documents the media types of kit.EncodeResponse for the JSON responses described so far,
+// the format query parameter overriding the Accept header and the 406 of unsupported types
+func (o *Operation) Negotiated() *Operation {
+	for _, res := range o.Responses {
+		media, ok := res.Content[kit.MediaTypeJSON]
+		if !ok {
+			continue
+		}
+
+		// CSV and NDJSON have a row per element of an array
+		row := media.Schema
+		if row.Items != nil {
+			row = row.Items
+		}
+
+		res.Content[kit.MediaTypeCSV] = &MediaType{Schema: String().Describe("a header and a row per element, nested values are JSON")}
+		res.Content[kit.MediaTypeNDJSON] = &MediaType{Schema: row}
+		res.Content[kit.MediaTypeMsgpack] = &MediaType{Schema: media.Schema}
+	}
+
+	return o.
+		Query("format", "Media type of the response, overrides the Accept header", Enum("json", "csv", "ndjson", "msgpack")).
+		Errors(http.StatusNotAcceptable)
+}
+
+// Op starts the description of an operation
+func Op(tag string, summary string, description string) *Operation {
+	return &Operation{
+		Summary:     summary,
+		Description: description,
+		Tags:        []string{tag},
+		Responses:   map[string]*Response{},
+	}
+}
+
+// Clone returns a copy of o that can be changed without changing o, schemas are shared
+func (o *Operation) Clone() *Operation {
+	c := *o
+	c.Tags = append([]string(nil), o.Tags...)
+	c.Parameters = nil
+	for _, p := range o.Parameters {
+		param := *p
+		c.Parameters = append(c.Parameters, &param)
+	}
+
+	c.Responses = make(map[string]*Response, len(o.Responses))
+	for status, res := range o.Responses {
+		r := *res
+		r.Headers = make(map[string]*Header, len(res.Headers))
+		for name, h := range res.Headers {
+			r.Headers[name] = h
+		}
+		r.Content = make(map[string]*MediaType, len(res.Content))
+		for contentType, media := range res.Content {
+			r.Content[contentType] = media
+		}
+		c.Responses[status] = &r
+	}
+
+	return &c
+}
+
+// Enveloped documents the kit.Envelope of APIVersion2 around the JSON and MessagePack bodies of
+// the successful responses
+func (o *Operation) Enveloped() *Operation {
+	for status, res := range o.Responses {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+
+		for _, contentType := range []string{kit.MediaTypeJSON, kit.MediaTypeMsgpack} {
+			if media, ok := res.Content[contentType]; ok {
+				res.Content[contentType] = &MediaType{Schema: envelopeSchema(media.Schema)}
+			}
+		}
+	}
+
+	return o
+}
+
+func envelopeSchema(data *Schema) *Schema {
+	// arrays of an envelope are never null
+	if t, ok := data.Type.([]string); ok && len(t) == 2 && t[0] == "array" {
+		array := *data
+		array.Type = "array"
+		data = &array
+	}
+
+	links := Object(map[string]*Schema{"self": String().Describe("the requested url")})
+	links.AdditionalProperties = String()
+
+	return Object(map[string]*Schema{
+		"data":  data,
+		"meta":  &Schema{Type: "object", Description: "count of the elements of arrays and what describes data"},
+		"links": links,
+	})
+}
+
+// Deprecate marks o as deprecated in favor of the path successor, its responses carry the
+// headers of kit.Deprecated
+func (o *Operation) Deprecate(successor string) *Operation {
+	o.Deprecated = true
+
+	note := "Deprecated alias of `" + successor + "`."
+	if o.Description != "" {
+		note += " " + o.Description
+	}
+	o.Description = note
+
+	for _, res := range o.Responses {
+		if res.Headers == nil {
+			res.Headers = map[string]*Header{}
+		}
+
+		res.Headers["Deprecation"] = &Header{Description: "Date of the deprecation, `@` unix seconds", Schema: String()}
+		res.Headers["Sunset"] = &Header{Description: "Date the route is removed", Schema: String().WithFormat("http-date")}
+		res.Headers["Link"] = &Header{Description: "The `successor-version` route", Schema: String()}
+	}
+
+	return o
+}
@@ -0,0 +1,6 @@
+// Package pb holds the protobuf messages and gRPC services of the api,
+// the code is generated from the .proto files next to it
+package pb
+
+// requires buf, protoc-gen-go and protoc-gen-go-grpc in PATH
+//go:generate buf generate --template buf.gen.yaml .
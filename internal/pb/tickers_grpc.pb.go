@@ -0,0 +1,285 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: tickers.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	TickersService_GetTickers_FullMethodName       = "/richerage.v1.TickersService/GetTickers"
+	TickersService_GetTickerHistory_FullMethodName = "/richerage.v1.TickersService/GetTickerHistory"
+	TickersService_StreamHistory_FullMethodName    = "/richerage.v1.TickersService/StreamHistory"
+	TickersService_WatchPrices_FullMethodName      = "/richerage.v1.TickersService/WatchPrices"
+)
+
+// TickersServiceClient is the client API for TickersService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TickersServiceClient interface {
+	// GetTickers lists the latest price of every ticker held by the user
+	GetTickers(ctx context.Context, in *GetTickersRequest, opts ...grpc.CallOption) (*GetTickersResponse, error)
+	// GetTickerHistory lists the bars of a symbol, newest first
+	GetTickerHistory(ctx context.Context, in *GetTickerHistoryRequest, opts ...grpc.CallOption) (*GetTickerHistoryResponse, error)
+	// StreamHistory sends the bars of a symbol newest first, in chunks as they are read from the storage
+	StreamHistory(ctx context.Context, in *StreamHistoryRequest, opts ...grpc.CallOption) (TickersService_StreamHistoryClient, error)
+	// WatchPrices sends live price updates until the client cancels, it ends with UNAVAILABLE
+	// when the client falls behind, resume with the id of the last update received
+	WatchPrices(ctx context.Context, in *WatchPricesRequest, opts ...grpc.CallOption) (TickersService_WatchPricesClient, error)
+}
+
+type tickersServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTickersServiceClient(cc grpc.ClientConnInterface) TickersServiceClient {
+	return &tickersServiceClient{cc}
+}
+
+func (c *tickersServiceClient) GetTickers(ctx context.Context, in *GetTickersRequest, opts ...grpc.CallOption) (*GetTickersResponse, error) {
+	out := new(GetTickersResponse)
+	err := c.cc.Invoke(ctx, TickersService_GetTickers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tickersServiceClient) GetTickerHistory(ctx context.Context, in *GetTickerHistoryRequest, opts ...grpc.CallOption) (*GetTickerHistoryResponse, error) {
+	out := new(GetTickerHistoryResponse)
+	err := c.cc.Invoke(ctx, TickersService_GetTickerHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tickersServiceClient) StreamHistory(ctx context.Context, in *StreamHistoryRequest, opts ...grpc.CallOption) (TickersService_StreamHistoryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TickersService_ServiceDesc.Streams[0], TickersService_StreamHistory_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tickersServiceStreamHistoryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TickersService_StreamHistoryClient interface {
+	Recv() (*StreamHistoryResponse, error)
+	grpc.ClientStream
+}
+
+type tickersServiceStreamHistoryClient struct {
+	grpc.ClientStream
+}
+
+func (x *tickersServiceStreamHistoryClient) Recv() (*StreamHistoryResponse, error) {
+	m := new(StreamHistoryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tickersServiceClient) WatchPrices(ctx context.Context, in *WatchPricesRequest, opts ...grpc.CallOption) (TickersService_WatchPricesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TickersService_ServiceDesc.Streams[1], TickersService_WatchPrices_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tickersServiceWatchPricesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TickersService_WatchPricesClient interface {
+	Recv() (*PriceUpdate, error)
+	grpc.ClientStream
+}
+
+type tickersServiceWatchPricesClient struct {
+	grpc.ClientStream
+}
+
+func (x *tickersServiceWatchPricesClient) Recv() (*PriceUpdate, error) {
+	m := new(PriceUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TickersServiceServer is the server API for TickersService service.
+// All implementations must embed UnimplementedTickersServiceServer
+// for forward compatibility
+type TickersServiceServer interface {
+	// GetTickers lists the latest price of every ticker held by the user
+	GetTickers(context.Context, *GetTickersRequest) (*GetTickersResponse, error)
+	// GetTickerHistory lists the bars of a symbol, newest first
+	GetTickerHistory(context.Context, *GetTickerHistoryRequest) (*GetTickerHistoryResponse, error)
+	// StreamHistory sends the bars of a symbol newest first, in chunks as they are read from the storage
+	StreamHistory(*StreamHistoryRequest, TickersService_StreamHistoryServer) error
+	// WatchPrices sends live price updates until the client cancels, it ends with UNAVAILABLE
+	// when the client falls behind, resume with the id of the last update received
+	WatchPrices(*WatchPricesRequest, TickersService_WatchPricesServer) error
+	mustEmbedUnimplementedTickersServiceServer()
+}
+
+// UnimplementedTickersServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedTickersServiceServer struct {
+}
+
+func (UnimplementedTickersServiceServer) GetTickers(context.Context, *GetTickersRequest) (*GetTickersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTickers not implemented")
+}
+func (UnimplementedTickersServiceServer) GetTickerHistory(context.Context, *GetTickerHistoryRequest) (*GetTickerHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTickerHistory not implemented")
+}
+func (UnimplementedTickersServiceServer) StreamHistory(*StreamHistoryRequest, TickersService_StreamHistoryServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamHistory not implemented")
+}
+func (UnimplementedTickersServiceServer) WatchPrices(*WatchPricesRequest, TickersService_WatchPricesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPrices not implemented")
+}
+func (UnimplementedTickersServiceServer) mustEmbedUnimplementedTickersServiceServer() {}
+
+// UnsafeTickersServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TickersServiceServer will
+// result in compilation errors.
+type UnsafeTickersServiceServer interface {
+	mustEmbedUnimplementedTickersServiceServer()
+}
+
+func RegisterTickersServiceServer(s grpc.ServiceRegistrar, srv TickersServiceServer) {
+	s.RegisterService(&TickersService_ServiceDesc, srv)
+}
+
+func _TickersService_GetTickers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTickersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TickersServiceServer).GetTickers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TickersService_GetTickers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TickersServiceServer).GetTickers(ctx, req.(*GetTickersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TickersService_GetTickerHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTickerHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TickersServiceServer).GetTickerHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TickersService_GetTickerHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TickersServiceServer).GetTickerHistory(ctx, req.(*GetTickerHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TickersService_StreamHistory_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamHistoryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TickersServiceServer).StreamHistory(m, &tickersServiceStreamHistoryServer{stream})
+}
+
+type TickersService_StreamHistoryServer interface {
+	Send(*StreamHistoryResponse) error
+	grpc.ServerStream
+}
+
+type tickersServiceStreamHistoryServer struct {
+	grpc.ServerStream
+}
+
+func (x *tickersServiceStreamHistoryServer) Send(m *StreamHistoryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TickersService_WatchPrices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPricesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TickersServiceServer).WatchPrices(m, &tickersServiceWatchPricesServer{stream})
+}
+
+type TickersService_WatchPricesServer interface {
+	Send(*PriceUpdate) error
+	grpc.ServerStream
+}
+
+type tickersServiceWatchPricesServer struct {
+	grpc.ServerStream
+}
+
+func (x *tickersServiceWatchPricesServer) Send(m *PriceUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// TickersService_ServiceDesc is the grpc.ServiceDesc for TickersService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TickersService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "richerage.v1.TickersService",
+	HandlerType: (*TickersServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTickers",
+			Handler:    _TickersService_GetTickers_Handler,
+		},
+		{
+			MethodName: "GetTickerHistory",
+			Handler:    _TickersService_GetTickerHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamHistory",
+			Handler:       _TickersService_StreamHistory_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchPrices",
+			Handler:       _TickersService_WatchPrices_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tickers.proto",
+}
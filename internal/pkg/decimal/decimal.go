@@ -0,0 +1,378 @@
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxScale bounds the digits after the point, 10^18 is the largest power of ten in an int64
+const MaxScale = 18
+
+// maxDigits is the number of digits of the largest int64
+const maxDigits = 19
+
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+// Decimal is a fixed-point number of units * 10^-scale, the zero value is 0
+//
+// arithmetic is exact: Add, Sub and Mul never round, Div, MulRound and Round round half away from
+// zero to an explicit scale. The scale is kept as given and is part of the text and JSON output,
+// 12.30 and 12.3 are equal but print differently.
+// Results that don't fit an int64 of units panic, like an integer division by zero.
+type Decimal struct {
+	units int64
+	scale int32
+}
+
+// New is units * 10^-scale
+func New(units int64, scale int32) Decimal {
+	if scale < 0 || scale > MaxScale {
+		panic(fmt.Sprintf("decimal: scale %d out of range", scale))
+	}
+
+	return Decimal{units: units, scale: scale}
+}
+
+func NewFromInt(v int64) Decimal {
+	return Decimal{units: v}
+}
+
+// NewFromFloat rounds f to scale, NaN and infinities are 0
+func NewFromFloat(f float64, scale int32) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return New(0, scale)
+	}
+
+	// the shortest text that round trips is exactly what the float was meant to be
+	d, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return New(0, scale)
+	}
+
+	return d.Round(scale)
+}
+
+// Parse reads a decimal number with an optional sign, fraction and exponent: -12.30, 1.5e3
+func Parse(s string) (Decimal, error) {
+	text := s
+
+	exp := 0
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		e, err := strconv.Atoi(text[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+
+		exp = e
+		text = text[:i]
+	}
+
+	sign := ""
+	if text != "" && (text[0] == '-' || text[0] == '+') {
+		sign, text = text[:1], text[1:]
+	}
+
+	intPart, fracPart := text, ""
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		intPart, fracPart = text[:i], text[i+1:]
+	}
+
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+
+	digits := intPart + fracPart
+
+	// bound the exponent and the digits before any of them is expanded, an int64 has at most 19 digits
+	if exp < -MaxScale || len(fracPart)-exp > MaxScale {
+		return Decimal{}, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidDecimal, s, MaxScale)
+	}
+
+	significant := len(strings.TrimLeft(digits, "0"))
+	if exp > len(fracPart) && (exp-len(fracPart) > maxDigits || significant+exp-len(fracPart) > maxDigits) || significant > maxDigits {
+		return Decimal{}, fmt.Errorf("%w: %q out of range", ErrInvalidDecimal, s)
+	}
+
+	scale := len(fracPart) - exp
+	if scale < 0 {
+		digits += strings.Repeat("0", -scale)
+		scale = 0
+	}
+
+	n, ok := new(big.Int).SetString(sign+digits, 10)
+	if !ok || !n.IsInt64() {
+		return Decimal{}, fmt.Errorf("%w: %q out of range", ErrInvalidDecimal, s)
+	}
+
+	return Decimal{units: n.Int64(), scale: int32(scale)}, nil
+}
+
+// MustParse is Parse for constants, it panics on invalid input
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// Sum adds values exactly, the scale is the largest of them
+func Sum(values ...Decimal) Decimal {
+	var total Decimal
+	for _, v := range values {
+		total = total.Add(v)
+	}
+
+	return total
+}
+
+func (d Decimal) Units() int64 {
+	return d.units
+}
+
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Add is exact, the scale is the larger of both
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+
+	return fromBig(a.Add(a, b), scale)
+}
+
+// Sub is exact, the scale is the larger of both
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+
+	return fromBig(a.Sub(a, b), scale)
+}
+
+func (d Decimal) Neg() Decimal {
+	return fromBig(new(big.Int).Neg(big.NewInt(d.units)), d.scale)
+}
+
+// Mul is exact, the scale is the sum of both, use Round to bring it back
+func (d Decimal) Mul(o Decimal) Decimal {
+	n := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(o.units))
+	scale := d.scale + o.scale
+
+	if scale > MaxScale {
+		return fromBig(quoRound(n, pow10(scale-MaxScale)), MaxScale)
+	}
+
+	return fromBig(n, scale)
+}
+
+// MulRound rounds d * o to scale, half away from zero. The exact product is rounded before it is
+// narrowed, so it only panics when the rounded result overflows, unlike Mul followed by Round
+func (d Decimal) MulRound(o Decimal, scale int32) Decimal {
+	if scale < 0 || scale > MaxScale {
+		panic(fmt.Sprintf("decimal: scale %d out of range", scale))
+	}
+
+	n := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(o.units))
+
+	switch product := d.scale + o.scale; {
+	case scale > product:
+		n.Mul(n, pow10(scale-product))
+	case scale < product:
+		n = quoRound(n, pow10(product-scale))
+	}
+
+	return fromBig(n, scale)
+}
+
+// Div rounds d / o to scale, it panics when o is zero
+func (d Decimal) Div(o Decimal, scale int32) Decimal {
+	if o.units == 0 {
+		panic("decimal: division by zero")
+	}
+
+	// d.units * 10^(scale + o.scale - d.scale) / o.units has the requested scale
+	n := big.NewInt(d.units)
+	q := big.NewInt(o.units)
+
+	if shift := scale + o.scale - d.scale; shift >= 0 {
+		n.Mul(n, pow10(shift))
+	} else {
+		q.Mul(q, pow10(-shift))
+	}
+
+	return fromBig(quoRound(n, q), scale)
+}
+
+// Round rescales to scale, rounding half away from zero when digits are dropped
+func (d Decimal) Round(scale int32) Decimal {
+	if scale < 0 || scale > MaxScale {
+		panic(fmt.Sprintf("decimal: scale %d out of range", scale))
+	}
+
+	n := big.NewInt(d.units)
+
+	switch {
+	case scale > d.scale:
+		n.Mul(n, pow10(scale-d.scale))
+	case scale < d.scale:
+		n = quoRound(n, pow10(d.scale-scale))
+	}
+
+	return fromBig(n, scale)
+}
+
+// Cmp returns -1, 0 or +1 as d is less, equal or greater than o regardless of scale
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+
+	return a.Cmp(b)
+}
+
+// Equal compares values, 1.50 equals 1.5
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// Float64 is the closest float to d, for math that is approximate anyway
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+
+	return f
+}
+
+// String prints every digit of the scale: New(1230, 2) is 12.30
+func (d Decimal) String() string {
+	digits := strconv.FormatInt(d.units, 10)
+
+	sign := ""
+	if d.units < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	if d.scale == 0 {
+		return sign + digits
+	}
+
+	if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+
+	point := len(digits) - int(d.scale)
+
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalJSON emits a JSON number with the exact scale, never a float approximation
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a string holding one, the scale is kept as written
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+
+	if len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"' {
+		text = text[1 : len(text)-1]
+	}
+
+	v, err := Parse(text)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+
+	return nil
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(data []byte) error {
+	v, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+
+	*d = v
+
+	return nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// align returns the units of both at the larger scale
+func align(a Decimal, b Decimal) (*big.Int, *big.Int, int32) {
+	x, y := big.NewInt(a.units), big.NewInt(b.units)
+
+	switch {
+	case a.scale > b.scale:
+		y.Mul(y, pow10(a.scale-b.scale))
+		return x, y, a.scale
+	case b.scale > a.scale:
+		x.Mul(x, pow10(b.scale-a.scale))
+		return x, y, b.scale
+	default:
+		return x, y, a.scale
+	}
+}
+
+// quoRound is n / q rounded half away from zero
+func quoRound(n *big.Int, q *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(n, q, new(big.Int))
+
+	// |2 * rem| >= |q| rounds away from zero
+	rem.Abs(rem).Lsh(rem, 1)
+	if rem.Cmp(new(big.Int).Abs(q)) >= 0 {
+		if n.Sign()*q.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	return quo
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func fromBig(n *big.Int, scale int32) Decimal {
+	if !n.IsInt64() {
+		panic(fmt.Sprintf("decimal: %se-%d overflows", n.String(), scale))
+	}
+
+	return Decimal{units: n.Int64(), scale: scale}
+}
@@ -0,0 +1,192 @@
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDecimal_Parse(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected string
+	}{
+		{in: "0", expected: "0"},
+		{in: "12.30", expected: "12.30"},
+		{in: "-0.05", expected: "-0.05"},
+		{in: "+7", expected: "7"},
+		{in: ".5", expected: "0.5"},
+		{in: "1.", expected: "1"},
+		{in: "1.5e3", expected: "1500"},
+		{in: "125e-2", expected: "1.25"},
+		{in: "1E-1", expected: "0.1"},
+		{in: "9.223372036854775807e18", expected: "9223372036854775807"},
+		{in: "1e-18", expected: "0.000000000000000001"},
+		{in: "0001e18", expected: "1000000000000000000"},
+	}
+
+	for _, c := range cases {
+		d, err := Parse(c.in)
+		if err != nil {
+			t.Errorf("%s: expected error to be nil, got %v", c.in, err)
+			continue
+		}
+
+		if d.String() != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.in, c.expected, d.String())
+		}
+	}
+
+	for _, in := range []string{"", "-", ".", "1.2.3", "abc", "1e", "0x10", "1.0000000000000000001", "99999999999999999999"} {
+		if _, err := Parse(in); !errors.Is(err, ErrInvalidDecimal) {
+			t.Errorf("%q: expected error to be ErrInvalidDecimal, got %v", in, err)
+		}
+	}
+}
+
+func TestDecimal_Parse_Exponent(t *testing.T) {
+	// exponents are bounded before the digits are expanded, these neither allocate nor panic
+	for _, in := range []string{"1e1000000", "1e9223372036854775807", "1e-9223372036854775808", "1e-19", "1e19", "0e20", "1.5e-18"} {
+		start := time.Now()
+
+		if _, err := Parse(in); !errors.Is(err, ErrInvalidDecimal) {
+			t.Errorf("%q: expected error to be ErrInvalidDecimal, got %v", in, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Millisecond*10 {
+			t.Errorf("%q: expected to be rejected right away, took %v", in, elapsed)
+		}
+	}
+
+	var v struct {
+		Price Decimal `json:"price"`
+	}
+	if err := json.Unmarshal([]byte(`{"price": 1e1000000}`), &v); !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("expected error to be ErrInvalidDecimal, got %v", err)
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	cases := []struct {
+		name     string
+		got      Decimal
+		expected string
+	}{
+		{name: "add", got: MustParse("0.1").Add(MustParse("0.2")), expected: "0.3"},
+		{name: "add scale", got: MustParse("1.5").Add(MustParse("0.25")), expected: "1.75"},
+		{name: "sub", got: MustParse("1.00").Sub(MustParse("1.01")), expected: "-0.01"},
+		{name: "neg", got: MustParse("2.50").Neg(), expected: "-2.50"},
+		{name: "mul", got: MustParse("102.50").Mul(MustParse("0.25")), expected: "25.6250"},
+		{name: "div", got: MustParse("1").Div(MustParse("3"), 4), expected: "0.3333"},
+		{name: "div up", got: MustParse("2").Div(MustParse("3"), 4), expected: "0.6667"},
+		{name: "div negative", got: MustParse("-2").Div(MustParse("3"), 2), expected: "-0.67"},
+		{name: "div scale", got: MustParse("1.04").Div(MustParse("104"), 4), expected: "0.0100"},
+		{name: "round half up", got: MustParse("1.005").Round(2), expected: "1.01"},
+		{name: "round half negative", got: MustParse("-1.005").Round(2), expected: "-1.01"},
+		{name: "round down", got: MustParse("1.0049").Round(2), expected: "1.00"},
+		{name: "round up scale", got: MustParse("1.5").Round(4), expected: "1.5000"},
+		{name: "from float", got: NewFromFloat(0.1+0.2, 2), expected: "0.30"},
+		{name: "from float half", got: NewFromFloat(2.675, 2), expected: "2.68"},
+		{name: "new", got: New(-5, 3), expected: "-0.005"},
+		{name: "mul round", got: MustParse("102.50").MulRound(MustParse("0.25"), 2), expected: "25.63"},
+		{name: "mul round up scale", got: MustParse("1.5").MulRound(MustParse("2"), 3), expected: "3.000"},
+		// the products of large prices overflow int64 at the sum of the scales
+		{name: "mul round large fx", got: MustParse("70000.00").MulRound(New(1500000000000, 10), 4), expected: "10500000.0000"},
+		{name: "mul round large factor", got: MustParse("150000.00").MulRound(New(500000000000, 12), 4), expected: "75000.0000"},
+		{name: "mul round max price", got: MustParse("999999999.9999").MulRound(New(1500000000000, 10), 4), expected: "149999999999.9850"},
+	}
+
+	for _, c := range cases {
+		if c.got.String() != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, c.got.String())
+		}
+	}
+}
+
+func TestDecimal_Sum(t *testing.T) {
+	// the float sum drifts to 0.9999999999999999
+	var values []Decimal
+	var float float64
+	for i := 0; i < 10; i++ {
+		values = append(values, MustParse("0.1"))
+		float += 0.1
+	}
+
+	if float == 1 {
+		t.Errorf("expected float sum to drift")
+	}
+
+	if sum := Sum(values...); !sum.Equal(NewFromInt(1)) || sum.String() != "1.0" {
+		t.Errorf("expected sum to be 1.0, got %s", sum.String())
+	}
+}
+
+func TestDecimal_Cmp(t *testing.T) {
+	if !MustParse("1.50").Equal(MustParse("1.5")) {
+		t.Errorf("expected 1.50 to equal 1.5")
+	}
+	if MustParse("1.49").Cmp(MustParse("1.5")) != -1 {
+		t.Errorf("expected 1.49 to be less than 1.5")
+	}
+	if MustParse("-1").Cmp(MustParse("-1.01")) != 1 {
+		t.Errorf("expected -1 to be greater than -1.01")
+	}
+	if MustParse("-0.01").Sign() != -1 || MustParse("0.00").Sign() != 0 || !MustParse("0.00").IsZero() {
+		t.Errorf("expected signs of -0.01 and 0.00")
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var v struct {
+		Price Decimal `json:"price"`
+		Ratio Decimal `json:"ratio"`
+	}
+
+	// numbers and strings, the scale is kept as written
+	err := json.Unmarshal([]byte(`{"price": 12.30, "ratio": "0.250"}`), &v)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	out, _ := json.Marshal(v)
+	if string(out) != `{"price":12.30,"ratio":0.250}` {
+		t.Errorf("expected exact scale, got %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"price": "abc"}`), &v); !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("expected error to be ErrInvalidDecimal, got %v", err)
+	}
+
+	// map keys and text encoders
+	text, _ := json.Marshal(map[Decimal]int{MustParse("1.10"): 1})
+	if string(text) != `{"1.10":1}` {
+		t.Errorf("expected text key 1.10, got %s", text)
+	}
+}
+
+func TestDecimal_Overflow(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected overflow to panic")
+		}
+	}()
+
+	New(1<<62, 0).Add(New(1<<62, 0))
+}
+
+func TestDecimal_MulRound_Overflow(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected overflow of the rounded product to panic")
+		}
+	}()
+
+	New(1<<62, 0).MulRound(NewFromInt(4), 0)
+}
+
+func TestDecimal_Float64(t *testing.T) {
+	if f := MustParse("12.34").Float64(); f != 12.34 {
+		t.Errorf("expected 12.34, got %v", f)
+	}
+}
@@ -0,0 +1,59 @@
+package hasher
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+var _ Signer = (*hmacSigner)(nil)
+
+type ConfigHMACSigner struct {
+	Secret []byte
+}
+
+func NewHMACSigner(cfg *ConfigHMACSigner) Signer {
+	return &hmacSigner{
+		secret: cfg.Secret,
+	}
+}
+
+type hmacSigner struct {
+	secret []byte
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of data
+func (s *hmacSigner) Sign(_ context.Context, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, &ErrEmptyData{}
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+
+	_, err := mac.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := mac.Sum(nil)
+	out := make([]byte, hex.EncodedLen(len(sig)))
+	hex.Encode(out, sig)
+
+	return out, nil
+}
+
+func (s *hmacSigner) Verify(ctx context.Context, data []byte, signature []byte) error {
+	sig, err := s.Sign(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(sig, signature) {
+		return &ErrInvalidToken{
+			Message: "invalid signature",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package hasher
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestHmacSigner(t *testing.T) {
+	ctx := context.Background()
+
+	signer := NewHMACSigner(&ConfigHMACSigner{
+		Secret: []byte("secret"),
+	})
+
+	data := []byte(`{"type":"ping"}`)
+
+	sig, err := signer.Sign(ctx, data)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+		return
+	}
+
+	// known HMAC-SHA256 of data with "secret"
+	expect := "ef9c85680c299afa94246e60325ec1a8fc10a7fdcc17ff2600c19a3cd7dac2c5"
+	if string(sig) != expect {
+		t.Errorf("expected signature to be %s, got %s", expect, sig)
+	}
+
+	err = signer.Verify(ctx, data, sig)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T %s", err, err.Error())
+	}
+}
+
+func TestHmacSigner_Invalid(t *testing.T) {
+	ctx := context.Background()
+
+	signer := NewHMACSigner(&ConfigHMACSigner{
+		Secret: []byte("secret"),
+	})
+
+	_, err := signer.Sign(ctx, nil)
+
+	var errEmptyData *ErrEmptyData
+	if !errors.As(err, &errEmptyData) {
+		t.Errorf("expected error to be %T, got %T", errEmptyData, err)
+	}
+
+	sig, err := signer.Sign(ctx, []byte("data"))
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+		return
+	}
+
+	var errInvalidToken *ErrInvalidToken
+
+	// tampered payload
+	err = signer.Verify(ctx, []byte("data2"), sig)
+	if !errors.As(err, &errInvalidToken) {
+		t.Errorf("expected error to be %T, got %T", errInvalidToken, err)
+	}
+
+	// different secret
+	other := NewHMACSigner(&ConfigHMACSigner{
+		Secret: []byte("other"),
+	})
+
+	errInvalidToken = nil
+	err = other.Verify(ctx, []byte("data"), sig)
+	if !errors.As(err, &errInvalidToken) {
+		t.Errorf("expected error to be %T, got %T", errInvalidToken, err)
+	}
+}
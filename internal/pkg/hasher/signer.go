@@ -0,0 +1,11 @@
+package hasher
+
+import "context"
+
+// Signer produces detached signatures for arbitrary payloads,
+// unlike Hasher it does not embed the data or an expiration in its output
+type Signer interface {
+	Sign(ctx context.Context, data []byte) ([]byte, error)
+
+	Verify(ctx context.Context, data []byte, signature []byte) error
+}
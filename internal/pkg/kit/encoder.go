@@ -0,0 +1,500 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	MediaTypeJSON    = "application/json"
+	MediaTypeCSV     = "text/csv"
+	MediaTypeNDJSON  = "application/x-ndjson"
+	MediaTypeMsgpack = "application/msgpack"
+)
+
+// MediaTypes are the negotiable media types, preferred first
+var MediaTypes = []string{MediaTypeJSON, MediaTypeCSV, MediaTypeNDJSON, MediaTypeMsgpack}
+
+// formats are the values of the format query parameter, it overrides the Accept header
+var formats = map[string]string{
+	"json":    MediaTypeJSON,
+	"csv":     MediaTypeCSV,
+	"ndjson":  MediaTypeNDJSON,
+	"msgpack": MediaTypeMsgpack,
+}
+
+// aliases of the negotiable media types
+var mediaTypeAliases = map[string]string{
+	"application/vnd.msgpack": MediaTypeMsgpack,
+	"application/x-msgpack":   MediaTypeMsgpack,
+	"application/jsonl":       MediaTypeNDJSON,
+}
+
+type ErrNotAcceptable struct {
+	Accept string
+}
+
+func (e *ErrNotAcceptable) HttpCode() int {
+	return 406
+}
+
+func (e *ErrNotAcceptable) Code() string {
+	return "not_acceptable"
+}
+
+func (e *ErrNotAcceptable) Error() string {
+	return fmt.Sprintf("%s is not supported, expected one of %s", e.Accept, strings.Join(MediaTypes, ", "))
+}
+
+// negotiation is the outcome of NegotiateRequest
+type negotiation struct {
+	mediaType string
+	err       error
+}
+
+// Negotiate picks the media type of the response to r: the format query parameter, otherwise the
+// acceptable type of the Accept header with the highest quality, JSON when there is none
+func Negotiate(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if mediaType, ok := formats[strings.ToLower(format)]; ok {
+			return mediaType, nil
+		}
+
+		return "", &ErrNotAcceptable{Accept: "format " + format}
+	}
+
+	accept := strings.TrimSpace(r.Header.Get("Accept"))
+	if accept == "" {
+		return MediaTypeJSON, nil
+	}
+
+	type ranged struct {
+		mediaType string
+		q         float64
+	}
+
+	var ranges []ranged
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		if q > 0 {
+			ranges = append(ranges, ranged{mediaType: mediaType, q: q})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	for _, rng := range ranges {
+		if alias, ok := mediaTypeAliases[rng.mediaType]; ok {
+			return alias, nil
+		}
+
+		for _, mediaType := range MediaTypes {
+			if matchMediaRange(rng.mediaType, mediaType) {
+				return mediaType, nil
+			}
+		}
+	}
+
+	return "", &ErrNotAcceptable{Accept: accept}
+}
+
+func matchMediaRange(mediaRange string, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	return strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*"))
+}
+
+// NegotiateRequest keeps the media type of the response for EncodeResponse, see kithttp.ServerBefore
+func NegotiateRequest(ctx context.Context, r *http.Request) context.Context {
+	mediaType, err := Negotiate(r)
+
+	return context.WithValue(ctx, "response_media_type", &negotiation{mediaType: mediaType, err: err})
+}
+
+// Negotiated fails the requests of unsupported media types before dec, the endpoint does not run
+// for a response that can not be encoded
+func Negotiated(dec kithttp.DecodeRequestFunc) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		if n, ok := ctx.Value("response_media_type").(*negotiation); ok && n.err != nil {
+			return nil, n.err
+		}
+
+		return dec(ctx, r)
+	}
+}
+
+// EncodeResponse writes v with status in the media type of NegotiateRequest, JSON without it.
+// v is converted through its JSON encoding so every media type has the same fields: arrays are the
+// rows of CSV and the lines of NDJSON, a CSV row has a column per field of the first object. The
+// elements of arrays are encoded and written one at a time, the body is never held in memory.
+//
+// JSON and MessagePack responses of APIVersion2 requests are an Envelope of v completed by opts,
+// CSV and NDJSON stay the rows of v.
+func EncodeResponse(ctx context.Context, w http.ResponseWriter, status int, v interface{}, opts ...EnvelopeOption) error {
+	mediaType := MediaTypeJSON
+	n, negotiated := ctx.Value("response_media_type").(*negotiation)
+	if negotiated {
+		if n.err != nil {
+			return n.err
+		}
+
+		mediaType = n.mediaType
+		w.Header().Add("Vary", "Accept")
+	}
+
+	if mediaType == MediaTypeJSON || mediaType == MediaTypeMsgpack {
+		v = envelope(ctx, v, opts)
+	}
+
+	if mediaType == MediaTypeJSON {
+		w.Header().Set("Content-Type", MediaTypeJSON)
+		w.WriteHeader(status)
+
+		return json.NewEncoder(w).Encode(v)
+	}
+
+	// the status is only sent with the first byte, an error before it is still answered as one
+	body := &statusWriter{w: w, status: status}
+
+	var err error
+	switch mediaType {
+	case MediaTypeCSV:
+		w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+		err = encodeCSV(body, v)
+	case MediaTypeNDJSON:
+		w.Header().Set("Content-Type", mediaType)
+		err = encodeNDJSON(body, v)
+	case MediaTypeMsgpack:
+		w.Header().Set("Content-Type", mediaType)
+		err = encodeMsgpack(body, v)
+	}
+	if err != nil {
+		if !body.written {
+			w.Header().Del("Content-Type")
+		}
+
+		return err
+	}
+
+	body.writeHeader()
+
+	return nil
+}
+
+// statusWriter writes the status before the first byte of the body
+type statusWriter struct {
+	w       http.ResponseWriter
+	status  int
+	written bool
+}
+
+func (w *statusWriter) writeHeader() {
+	if !w.written {
+		w.written = true
+		w.w.WriteHeader(w.status)
+	}
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.writeHeader()
+
+	return w.w.Write(b)
+}
+
+// arrayValue is v as a slice or an array whose elements are encoded one at a time, false for
+// other values, byte slices and types with their own JSON encoding
+func arrayValue(v interface{}) (reflect.Value, bool) {
+	if _, ok := v.(json.Marshaler); ok {
+		return reflect.Value{}, false
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return reflect.Value{}, false
+	}
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return reflect.Value{}, false
+	}
+
+	return rv, true
+}
+
+// eachRow calls fn with the JSON encoding of each row of v: the elements of arrays, v itself
+// otherwise and none for null
+func eachRow(v interface{}, fn func(row json.RawMessage) error) error {
+	if rv, ok := arrayValue(v); ok {
+		for i := 0; i < rv.Len(); i++ {
+			row, err := json.Marshal(rv.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	rows, err := rows(b)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rows are the elements of an array, a single row otherwise and none for null
+func rows(b []byte) ([]json.RawMessage, error) {
+	b = bytes.TrimSpace(b)
+
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		return nil, nil
+	case len(b) > 0 && b[0] == '[':
+		var rows []json.RawMessage
+		err := json.Unmarshal(b, &rows)
+
+		return rows, err
+	}
+
+	return []json.RawMessage{b}, nil
+}
+
+func encodeNDJSON(w io.Writer, v interface{}) error {
+	var line bytes.Buffer
+
+	return eachRow(v, func(row json.RawMessage) error {
+		line.Reset()
+		if err := json.Compact(&line, row); err != nil {
+			return err
+		}
+		line.WriteByte('\n')
+
+		_, err := w.Write(line.Bytes())
+
+		return err
+	})
+}
+
+func encodeCSV(w io.Writer, v interface{}) error {
+	cw := csv.NewWriter(w)
+
+	var header []string
+	err := eachRow(v, func(row json.RawMessage) error {
+		fields, err := objectFields(row)
+		if err != nil {
+			return err
+		}
+
+		// rows of scalars have a single value column
+		if fields == nil {
+			fields = []field{{name: "value", value: row}}
+		}
+
+		if header == nil {
+			for _, f := range fields {
+				header = append(header, f.name)
+			}
+
+			if err := cw.Write(header); err != nil {
+				return err
+			}
+		}
+
+		values := make(map[string]json.RawMessage, len(fields))
+		for _, f := range fields {
+			values[f.name] = f.value
+		}
+
+		record := make([]string, len(header))
+		for j, name := range header {
+			record[j] = csvValue(values[name])
+		}
+
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+type field struct {
+	name  string
+	value json.RawMessage
+}
+
+// objectFields keeps the order of the fields of a JSON object, nil when b is not an object
+func objectFields(b json.RawMessage) ([]field, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil
+	}
+
+	fields := []field{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field{name: tok.(string), value: value})
+	}
+
+	return fields, nil
+}
+
+// csvValue is the text of strings, empty for null and missing values and JSON otherwise
+func csvValue(v json.RawMessage) string {
+	if len(v) == 0 || bytes.Equal(v, []byte("null")) {
+		return ""
+	}
+
+	var s string
+	if v[0] == '"' && json.Unmarshal(v, &s) == nil {
+		return s
+	}
+
+	return string(v)
+}
+
+// encodeMsgpack writes v through its JSON encoding, the elements of arrays one at a time. Integers
+// are kept as integers, other numbers are strings of their exact decimal text so prices are not
+// rounded to doubles
+func encodeMsgpack(w io.Writer, v interface{}) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetSortMapKeys(true)
+
+	return encodeMsgpackValue(enc, v)
+}
+
+func encodeMsgpackValue(enc *msgpack.Encoder, v interface{}) error {
+	// the keys in the order SetSortMapKeys writes them
+	if e, ok := v.(*Envelope); ok {
+		if err := enc.EncodeMapLen(3); err != nil {
+			return err
+		}
+
+		for _, kv := range []struct {
+			key   string
+			value interface{}
+		}{{"data", e.Data}, {"links", e.Links}, {"meta", e.Meta}} {
+			if err := enc.EncodeString(kv.key); err != nil {
+				return err
+			}
+			if err := encodeMsgpackValue(enc, kv.value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if rv, ok := arrayValue(v); ok && !(rv.Kind() == reflect.Slice && rv.IsNil()) {
+		if err := enc.EncodeArrayLen(rv.Len()); err != nil {
+			return err
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			if err := encodeMsgpackJSON(enc, rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return encodeMsgpackJSON(enc, v)
+}
+
+// encodeMsgpackJSON writes the JSON encoding of v
+func encodeMsgpackJSON(enc *msgpack.Encoder, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+
+	return enc.Encode(msgpackValue(value))
+}
+
+func msgpackValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+
+		return t.String()
+	case []interface{}:
+		for i := range t {
+			t[i] = msgpackValue(t[i])
+		}
+	case map[string]interface{}:
+		for k := range t {
+			t[k] = msgpackValue(t[k])
+		}
+	}
+
+	return v
+}
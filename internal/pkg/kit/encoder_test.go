@@ -0,0 +1,196 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/vmihailenco/msgpack/v5"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		url      string
+		accept   string
+		expected string
+	}{
+		{url: "/", accept: "", expected: MediaTypeJSON},
+		{url: "/", accept: "*/*", expected: MediaTypeJSON},
+		{url: "/", accept: "text/csv", expected: MediaTypeCSV},
+		{url: "/", accept: "text/*", expected: MediaTypeCSV},
+		{url: "/", accept: "application/xml, application/x-ndjson;q=0.5", expected: MediaTypeNDJSON},
+		{url: "/", accept: "application/json;q=0.2, application/msgpack", expected: MediaTypeMsgpack},
+		{url: "/", accept: "application/vnd.msgpack", expected: MediaTypeMsgpack},
+		{url: "/?format=csv", accept: "application/json", expected: MediaTypeCSV},
+		{url: "/?format=NDJSON", accept: "", expected: MediaTypeNDJSON},
+		{url: "/", accept: "application/xml", expected: ""},
+		{url: "/", accept: "text/csv;q=0", expected: ""},
+		{url: "/?format=xml", accept: "", expected: ""},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", c.url, nil)
+		r.Header.Set("Accept", c.accept)
+
+		mediaType, err := Negotiate(r)
+		if c.expected == "" {
+			if _, ok := err.(*ErrNotAcceptable); !ok {
+				t.Errorf("%s %q: expected ErrNotAcceptable, got %q %v", c.url, c.accept, mediaType, err)
+			}
+			continue
+		}
+
+		if err != nil || mediaType != c.expected {
+			t.Errorf("%s %q: expected %s, got %q %v", c.url, c.accept, c.expected, mediaType, err)
+		}
+	}
+}
+
+type encoderRecord struct {
+	Symbol string      `json:"symbol"`
+	Price  float64     `json:"price"`
+	Tags   []string    `json:"tags"`
+	Note   interface{} `json:"note"`
+}
+
+func encode(t *testing.T, format string, v interface{}) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/?format="+format, nil)
+	ctx := NegotiateRequest(context.Background(), r)
+
+	rec := httptest.NewRecorder()
+	if err := EncodeResponse(ctx, rec, http.StatusOK, v); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	return rec
+}
+
+func TestEncodeResponse(t *testing.T) {
+	records := []encoderRecord{
+		{Symbol: "AAPL", Price: 190.12, Tags: []string{"tech"}},
+		{Symbol: "KO", Price: 60, Note: "a, b"},
+	}
+
+	rec := encode(t, "csv", records)
+	expected := "symbol,price,tags,note\nAAPL,190.12,\"[\"\"tech\"\"]\",\nKO,60,,\"a, b\"\n"
+	if rec.Body.String() != expected {
+		t.Errorf("expected csv %q, got %q", expected, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("expected text/csv content type, got %s", ct)
+	}
+
+	rec = encode(t, "ndjson", records)
+	expected = "{\"symbol\":\"AAPL\",\"price\":190.12,\"tags\":[\"tech\"],\"note\":null}\n{\"symbol\":\"KO\",\"price\":60,\"tags\":null,\"note\":\"a, b\"}\n"
+	if rec.Body.String() != expected {
+		t.Errorf("expected ndjson %q, got %q", expected, rec.Body.String())
+	}
+
+	rec = encode(t, "msgpack", records)
+	var decoded []map[string]interface{}
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if len(decoded) != 2 || decoded[0]["symbol"] != "AAPL" || decoded[0]["price"] != "190.12" || decoded[1]["price"] != int64(60) {
+		t.Errorf("expected the records, got %v", decoded)
+	}
+
+	// empty responses have no rows
+	rec = encode(t, "csv", []encoderRecord(nil))
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected empty csv, got %q", rec.Body.String())
+	}
+
+	// single objects are a single row
+	rec = encode(t, "csv", map[string]interface{}{"open": true})
+	if rec.Body.String() != "open\ntrue\n" {
+		t.Errorf("expected a single row, got %q", rec.Body.String())
+	}
+}
+
+func TestEncodeResponse_MsgpackDecimals(t *testing.T) {
+	// more digits than a double keeps
+	rows := []map[string]json.RawMessage{
+		{"price": json.RawMessage("0.10000000000000000555"), "volume": json.RawMessage("9007199254740993")},
+		{"price": json.RawMessage("123456789012345678901234567890"), "volume": json.RawMessage("1")},
+	}
+
+	rec := encode(t, "msgpack", rows)
+
+	var decoded []map[string]interface{}
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	if len(decoded) != 2 || decoded[0]["price"] != "0.10000000000000000555" || decoded[0]["volume"] != int64(9007199254740993) {
+		t.Errorf("expected the exact decimal and integer, got %v", decoded)
+	}
+	if decoded[1]["price"] != "123456789012345678901234567890" {
+		t.Errorf("expected integers beyond int64 as text, got %v", decoded[1]["price"])
+	}
+
+	// null stays null
+	rec = encode(t, "msgpack", []encoderRecord(nil))
+	if !bytes.Equal(rec.Body.Bytes(), []byte{0xc0}) {
+		t.Errorf("expected msgpack nil, got %x", rec.Body.Bytes())
+	}
+}
+
+// countingWriter counts the writes of the body
+type countingWriter struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.writes++
+
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestEncodeResponse_Stream(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?format=ndjson", nil)
+	ctx := NegotiateRequest(context.Background(), r)
+
+	records := make([]encoderRecord, 100)
+	for i := range records {
+		records[i] = encoderRecord{Symbol: "AAPL", Price: float64(i)}
+	}
+
+	// a line per write, the body is not buffered
+	w := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+	if err := EncodeResponse(ctx, w, http.StatusOK, records); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if w.writes != len(records) || strings.Count(w.Body.String(), "\n") != len(records) {
+		t.Errorf("expected %d lines written one at a time, got %d writes", len(records), w.writes)
+	}
+
+	// an error before the first row leaves the response to the error handler
+	rec := httptest.NewRecorder()
+	err := EncodeResponse(ctx, rec, http.StatusOK, []interface{}{func() {}})
+	if err == nil || rec.Body.Len() != 0 || rec.Header().Get("Content-Type") != "" {
+		t.Errorf("expected an error and nothing written, got %v %q", err, rec.Body.String())
+	}
+}
+
+func TestEncodeResponse_NotAcceptable(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/xml")
+	ctx := NegotiateRequest(context.Background(), r)
+
+	_, err := Negotiated(func(context.Context, *http.Request) (interface{}, error) {
+		t.Error("expected the decoder not to run")
+		return nil, nil
+	})(ctx, r)
+	if _, ok := err.(*ErrNotAcceptable); !ok {
+		t.Errorf("expected ErrNotAcceptable, got %v", err)
+	}
+
+	if err, ok := err.(HttpError); !ok || err.HttpCode() != http.StatusNotAcceptable {
+		t.Errorf("expected http code 406, got %v", err)
+	}
+}
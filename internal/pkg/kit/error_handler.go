@@ -3,6 +3,7 @@ package kit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"go.uber.org/zap"
 	"net/http"
 )
@@ -23,6 +24,12 @@ func (th *ErrorHandler) Handle(_ context.Context, err error) {
 		return
 	}
 
+	// streams end once the client is gone
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		th.Logger.Info("http request: canceled", zap.Error(err))
+		return
+	}
+
 	th.Logger.Error("http request: unexpected error", zap.Error(err))
 }
 
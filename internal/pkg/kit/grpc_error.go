@@ -0,0 +1,97 @@
+package kit
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+)
+
+// GRPCError converts err into a gRPC status error, the counterpart of ErrorHandler.ErrorEncoder
+//
+// coded errors keep their message, their code is the reason of an ErrorInfo detail and the params
+// of a BadRequestError are field violations, any other error is internal without details
+func GRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	cErr, ok := err.(CodedError)
+	if !ok {
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	st := status.New(GRPCCode(err), cErr.Error())
+
+	// details only fail to marshal on an OK status, never the case here
+	info := &errdetails.ErrorInfo{Reason: cErr.Code()}
+	if withInfo, err := st.WithDetails(info); err == nil {
+		st = withInfo
+	}
+
+	if bErr, ok := err.(*BadRequestError); ok && len(bErr.Params) > 0 {
+		fields := make([]string, 0, len(bErr.Params))
+		for field := range bErr.Params {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		violations := &errdetails.BadRequest{}
+		for _, field := range fields {
+			violations.FieldViolations = append(violations.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field,
+				Description: bErr.Params[field],
+			})
+		}
+
+		if withViolations, err := st.WithDetails(violations); err == nil {
+			st = withViolations
+		}
+	}
+
+	return st.Err()
+}
+
+// GRPCCode maps the http status of a coded error to the closest gRPC code
+func GRPCCode(err error) codes.Code {
+	switch getStatusCode(err) {
+	case 400, 422:
+		return codes.InvalidArgument
+	case 401:
+		return codes.Unauthenticated
+	case 403:
+		return codes.PermissionDenied
+	case 404:
+		return codes.NotFound
+	case 409:
+		return codes.AlreadyExists
+	case 412:
+		return codes.FailedPrecondition
+	case 429:
+		return codes.ResourceExhausted
+	case 501:
+		return codes.Unimplemented
+	case 503:
+		return codes.Unavailable
+	case 504:
+		return codes.DeadlineExceeded
+	}
+
+	if code := getStatusCode(err); code >= 400 && code < 500 {
+		return codes.FailedPrecondition
+	}
+
+	return codes.Internal
+}
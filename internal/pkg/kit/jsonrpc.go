@@ -0,0 +1,252 @@
+package kit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-kit/kit/transport/http/jsonrpc"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// JSONRPCRequestID keeps the id of the request for the error encoder, see jsonrpc.ServerBeforeCodec
+func JSONRPCRequestID(ctx context.Context, _ *http.Request, req jsonrpc.Request) context.Context {
+	return context.WithValue(ctx, "jsonrpc_id", req.ID)
+}
+
+// DecodeJSONRPCParams decodes the params of a call into v, params are passed by name or omitted
+func DecodeJSONRPCParams(params json.RawMessage, v interface{}) error {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		return nil
+	}
+
+	if params[0] != '{' {
+		return &BadRequestError{
+			Message: "invalid params: expected an object",
+		}
+	}
+
+	if err := json.Unmarshal(params, v); err != nil {
+		return &BadRequestError{
+			Message: "invalid params: " + err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// JSONRPCErrorEncoder writes err as the error object of a JSON-RPC response, the api error code and the
+// invalid parameters of coded errors are its data
+func (th *ErrorHandler) JSONRPCErrorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
+	// errors of the jsonrpc transport are invalid calls, not failures of the server
+	if coder, ok := err.(jsonrpc.ErrorCoder); ok {
+		th.Logger.Warn("jsonrpc request: invalid call", zap.String("message", err.Error()), zap.Int("code", coder.ErrorCode()))
+	} else {
+		th.Handle(ctx, err)
+	}
+
+	id, _ := ctx.Value("jsonrpc_id").(*jsonrpc.RequestID)
+
+	w.Header().Set("Content-Type", jsonrpc.ContentType)
+	w.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(w).Encode(jsonrpc.Response{
+		JSONRPC: jsonrpc.Version,
+		Error:   JSONRPCError(err),
+		ID:      id,
+	})
+}
+
+// JSONRPCError converts err into a JSON-RPC error object, errors of the jsonrpc transport keep their code
+func JSONRPCError(err error) *jsonrpc.Error {
+	if coder, ok := err.(jsonrpc.ErrorCoder); ok {
+		return &jsonrpc.Error{
+			Code:    coder.ErrorCode(),
+			Message: err.Error(),
+		}
+	}
+
+	cErr, ok := err.(CodedError)
+	if !ok {
+		return &jsonrpc.Error{
+			Code:    jsonrpc.InternalError,
+			Message: "internal server error",
+			Data: map[string]interface{}{
+				"code": "internal_server_error",
+			},
+		}
+	}
+
+	data := map[string]interface{}{
+		"code": cErr.Code(),
+	}
+	if bErr, ok := err.(*BadRequestError); ok && len(bErr.Params) > 0 {
+		data["params"] = bErr.Params
+	}
+
+	return &jsonrpc.Error{
+		Code:    JSONRPCCode(getStatusCode(err)),
+		Message: cErr.Error(),
+		Data:    data,
+	}
+}
+
+// JSONRPCCode maps the http status of a coded error into a JSON-RPC error code:
+//   - 400, 422: invalid params
+//   - other 4xx: -32000 minus the last two digits of the status, 401 is -32001 and 404 is -32004
+//   - 5xx: -32000, the generic server error
+func JSONRPCCode(httpCode int) int {
+	switch {
+	case httpCode == 400 || httpCode == 422:
+		return jsonrpc.InvalidParamsError
+	case httpCode > 400 && httpCode < 500:
+		return -32000 - (httpCode - 400)
+	default:
+		return -32000
+	}
+}
+
+// JSONRPCBatchHandler serves the JSON-RPC batches of up to maxBatch calls with h, a server of single
+// requests such as jsonrpc.Server: the calls of a batch run concurrently and their responses are sent
+// in an array in the order of the calls; notifications, the calls without id, get no response.
+// Bodies larger than maxBytes are invalid requests
+func JSONRPCBatchHandler(h http.Handler, maxBatch int, maxBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+
+		var errTooLarge *http.MaxBytesError
+		if errors.As(err, &errTooLarge) {
+			writeJSONRPC(w, jsonRPCErrorResponse(jsonrpc.InvalidRequestError, fmt.Sprintf("body exceeds the limit of %d bytes", maxBytes)))
+			return
+		} else if err != nil {
+			writeJSONRPC(w, jsonRPCErrorResponse(jsonrpc.ParseError, "body could not be read: "+err.Error()))
+			return
+		}
+
+		body = bytes.TrimSpace(body)
+
+		if len(body) == 0 || body[0] != '[' {
+			response := serveJSONRPCCall(h, r, body)
+			if response == nil {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			writeJSONRPC(w, response)
+			return
+		}
+
+		var calls []json.RawMessage
+		if err := json.Unmarshal(body, &calls); err != nil {
+			writeJSONRPC(w, jsonRPCErrorResponse(jsonrpc.ParseError, "JSON could not be decoded: "+err.Error()))
+			return
+		}
+
+		if len(calls) == 0 {
+			writeJSONRPC(w, jsonRPCErrorResponse(jsonrpc.InvalidRequestError, "empty batch"))
+			return
+		} else if maxBatch > 0 && len(calls) > maxBatch {
+			writeJSONRPC(w, jsonRPCErrorResponse(jsonrpc.InvalidRequestError, fmt.Sprintf("batch of %d calls exceeds the limit of %d", len(calls), maxBatch)))
+			return
+		}
+
+		responses := make([][]byte, len(calls))
+
+		var wg sync.WaitGroup
+		for i, call := range calls {
+			wg.Add(1)
+
+			go func(i int, call []byte) {
+				defer wg.Done()
+				responses[i] = serveJSONRPCCall(h, r, call)
+			}(i, call)
+		}
+
+		wg.Wait()
+
+		var batch [][]byte
+		for _, response := range responses {
+			if response != nil {
+				batch = append(batch, response)
+			}
+		}
+
+		// a batch of notifications
+		if len(batch) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeJSONRPC(w, append(append([]byte("["), bytes.Join(batch, []byte(","))...), ']'))
+	})
+}
+
+// serveJSONRPCCall serves a single call with h, the response is nil for notifications
+func serveJSONRPCCall(h http.Handler, r *http.Request, call []byte) []byte {
+	var envelope struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		ID      json.RawMessage `json:"id"`
+	}
+
+	if !json.Valid(call) {
+		return jsonRPCErrorResponse(jsonrpc.ParseError, "JSON could not be decoded")
+	}
+
+	if err := json.Unmarshal(call, &envelope); err != nil || envelope.JSONRPC != jsonrpc.Version || envelope.Method == "" {
+		// invalid calls are answered even without id, it can not be told apart from a notification
+		return jsonRPCErrorResponse(jsonrpc.InvalidRequestError, `expected an object with "jsonrpc": "2.0" and a method`)
+	}
+
+	sub := r.Clone(r.Context())
+	sub.Body = io.NopCloser(bytes.NewReader(call))
+	sub.ContentLength = int64(len(call))
+
+	rec := &jsonRPCRecorder{header: http.Header{}}
+	h.ServeHTTP(rec, sub)
+
+	if envelope.ID == nil {
+		return nil
+	}
+
+	return bytes.TrimSpace(rec.body.Bytes())
+}
+
+func jsonRPCErrorResponse(code int, message string) []byte {
+	b, _ := json.Marshal(jsonrpc.Response{
+		JSONRPC: jsonrpc.Version,
+		Error: &jsonrpc.Error{
+			Code:    code,
+			Message: message,
+		},
+	})
+
+	return b
+}
+
+func writeJSONRPC(w http.ResponseWriter, b []byte) {
+	w.Header().Set("Content-Type", jsonrpc.ContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+}
+
+// jsonRPCRecorder buffers the response of a single call
+type jsonRPCRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *jsonRPCRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *jsonRPCRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *jsonRPCRecorder) WriteHeader(int) {}
@@ -3,6 +3,8 @@ package kit
 import (
 	"context"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"net/http"
 )
 
@@ -28,3 +30,27 @@ func (h *LoggerHandler) After(ctx context.Context, _ http.ResponseWriter) contex
 
 	return ctx
 }
+
+func (h *LoggerHandler) GRPCBefore(ctx context.Context, md metadata.MD) context.Context {
+	var reqID string
+	if v := md.Get("x-request-id"); len(v) > 0 {
+		reqID = v[0]
+	}
+
+	method, _ := grpc.Method(ctx)
+	logger := h.Logger.
+		With(zap.String("request_id", reqID)).
+		With(zap.String("method", method))
+
+	ctx = context.WithValue(ctx, "request_id", reqID)
+
+	logger.Info("grpc: request received")
+
+	return ctx
+}
+
+func (h *LoggerHandler) GRPCAfter(ctx context.Context, _ *metadata.MD, _ *metadata.MD) context.Context {
+	h.Logger.Info("grpc: request processed")
+
+	return ctx
+}
@@ -0,0 +1,121 @@
+package kit
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// APIVersion is the version of the route group serving a request
+type APIVersion int
+
+const (
+	APIVersion1 APIVersion = 1
+	// APIVersion2 envelopes the JSON and MessagePack responses of EncodeResponse, see Envelope
+	APIVersion2 APIVersion = 2
+)
+
+// Prefix of the routes of the version
+func (v APIVersion) Prefix() string {
+	return "/v" + strconv.Itoa(int(v))
+}
+
+// versionedRequest is what WithAPIVersion keeps for EncodeResponse
+type versionedRequest struct {
+	version APIVersion
+	self    string
+}
+
+// WithAPIVersion is a middleware marking the requests of the route group of version v
+func WithAPIVersion(v APIVersion) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), "api_version", &versionedRequest{
+				version: v,
+				self:    r.URL.RequestURI(),
+			})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequestAPIVersion is the version of the request of ctx, version 1 outside of a route group
+func RequestAPIVersion(ctx context.Context) APIVersion {
+	if v, ok := ctx.Value("api_version").(*versionedRequest); ok {
+		return v.version
+	}
+
+	return APIVersion1
+}
+
+// Deprecated is a middleware announcing that the routes it wraps are deprecated since deprecation
+// and removed at sunset (RFC 9745 and RFC 8594), the successor of a route is its path under prefix
+func Deprecated(deprecation time.Time, sunset time.Time, prefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "@"+strconv.FormatInt(deprecation.Unix(), 10))
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Add("Link", "<"+prefix+r.URL.Path+`>; rel="successor-version"`)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Envelope is the body of the responses of APIVersion2 and later
+type Envelope struct {
+	Data interface{} `json:"data"`
+	// Meta describes Data, count is the number of elements of arrays
+	Meta map[string]interface{} `json:"meta"`
+	// Links are urls related to Data by name, self is the requested one
+	Links map[string]string `json:"links"`
+}
+
+// EnvelopeOption adds to the meta or links of an enveloped response, responses without envelope ignore it
+type EnvelopeOption func(e *Envelope)
+
+func WithMeta(key string, value interface{}) EnvelopeOption {
+	return func(e *Envelope) {
+		e.Meta[key] = value
+	}
+}
+
+func WithLink(rel string, href string) EnvelopeOption {
+	return func(e *Envelope) {
+		e.Links[rel] = href
+	}
+}
+
+// envelope wraps v when the request of ctx is of a version with envelopes, v otherwise
+func envelope(ctx context.Context, v interface{}, opts []EnvelopeOption) interface{} {
+	req, ok := ctx.Value("api_version").(*versionedRequest)
+	if !ok || req.version < APIVersion2 {
+		return v
+	}
+
+	e := &Envelope{
+		Data:  v,
+		Meta:  map[string]interface{}{},
+		Links: map[string]string{"self": req.self},
+	}
+
+	// arrays are never null, v1 kept the null of empty results
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			e.Data = []interface{}{}
+		}
+
+		e.Meta["count"] = rv.Len()
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
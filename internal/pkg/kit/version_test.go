@@ -0,0 +1,91 @@
+package kit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/vmihailenco/msgpack/v5"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEncodeResponse_Envelope(t *testing.T) {
+	var ctx context.Context
+	handler := WithAPIVersion(APIVersion2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v2/tickers?currency=EUR", nil))
+
+	if v := RequestAPIVersion(ctx); v != APIVersion2 {
+		t.Errorf("expected version 2, got %d", v)
+	}
+
+	rec := httptest.NewRecorder()
+	err := EncodeResponse(ctx, rec, http.StatusOK, []encoderRecord(nil), WithMeta("currency", "EUR"), WithLink("docs", "/docs"))
+	if err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	expected := `{"data":[],"meta":{"count":0,"currency":"EUR"},"links":{"docs":"/docs","self":"/v2/tickers?currency=EUR"}}` + "\n"
+	if rec.Body.String() != expected {
+		t.Errorf("expected %s, got %s", expected, rec.Body.String())
+	}
+
+	// and the same in MessagePack
+	r := httptest.NewRequest("GET", "/v2/tickers?format=msgpack", nil)
+	mctx := NegotiateRequest(ctx, r)
+
+	rec = httptest.NewRecorder()
+	_ = EncodeResponse(mctx, rec, http.StatusOK, []encoderRecord{{Symbol: "AAPL", Price: 190.12}})
+
+	var decoded map[string]interface{}
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	data, _ := decoded["data"].([]interface{})
+	meta, _ := decoded["meta"].(map[string]interface{})
+	if len(data) != 1 || data[0].(map[string]interface{})["price"] != "190.12" || fmt.Sprint(meta["count"]) != "1" {
+		t.Errorf("expected the enveloped msgpack, got %v", decoded)
+	}
+
+	// objects have no count
+	rec = httptest.NewRecorder()
+	_ = EncodeResponse(ctx, rec, http.StatusOK, map[string]interface{}{"open": true})
+
+	var e Envelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+	if _, ok := e.Meta["count"]; ok || e.Data.(map[string]interface{})["open"] != true {
+		t.Errorf("expected the object without count, got %s", rec.Body.String())
+	}
+
+	// version 1 is never enveloped
+	rec = httptest.NewRecorder()
+	_ = EncodeResponse(context.Background(), rec, http.StatusOK, []encoderRecord(nil), WithMeta("currency", "EUR"))
+	if rec.Body.String() != "null\n" {
+		t.Errorf("expected null, got %s", rec.Body.String())
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	deprecation := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC)
+
+	handler := Deprecated(deprecation, sunset, "/v1")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/tickers/AAPL?currency=EUR", nil))
+
+	if v := rec.Header().Get("Deprecation"); v != "@1792368000" {
+		t.Errorf("expected Deprecation @1792368000, got %s", v)
+	}
+	if v := rec.Header().Get("Sunset"); v != "Mon, 19 Apr 2027 00:00:00 GMT" {
+		t.Errorf("expected the sunset http date, got %s", v)
+	}
+	if v := rec.Header().Get("Link"); v != `</v1/tickers/AAPL>; rel="successor-version"` {
+		t.Errorf("expected the successor link, got %s", v)
+	}
+}
@@ -0,0 +1,181 @@
+package prices
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ Publisher = (*Broker)(nil)
+
+type BrokerConfig struct {
+	// Buffer is the amount of past updates kept for resuming subscribers
+	Buffer int
+	// SubscriberBuffer is the amount of pending updates a subscriber can fall behind
+	SubscriberBuffer int
+	// Epoch prefixes the update ids, the unix time of creation when zero so the ids of a process
+	// started later are always greater and a client resuming across a restart is not confused
+	Epoch uint32
+}
+
+func NewBroker(cfg *BrokerConfig) *Broker {
+	b := &Broker{
+		latest:      map[string]types.PriceUpdate{},
+		subscribers: map[*subscriber]struct{}{},
+	}
+
+	epoch := uint32(time.Now().Unix())
+
+	if cfg != nil {
+		b.size = cfg.Buffer
+		b.subBuffer = cfg.SubscriberBuffer
+
+		if cfg.Epoch > 0 {
+			epoch = cfg.Epoch
+		}
+	}
+	if b.size <= 0 {
+		b.size = 1024
+	}
+	if b.subBuffer <= 0 {
+		b.subBuffer = 64
+	}
+
+	b.ring = make([]types.PriceUpdate, 0, b.size)
+	// ids are the epoch in the upper 32 bits and a sequence starting at 1 in the lower ones
+	b.lastID = uint64(epoch) << 32
+
+	return b
+}
+
+// Broker fans out published updates to subscribers,
+// it is the building block for Publisher implementations
+type Broker struct {
+	mu sync.Mutex
+
+	size      int
+	subBuffer int
+
+	lastID uint64
+	// ring holds the last size updates, next is the index to overwrite once full
+	ring []types.PriceUpdate
+	next int
+
+	latest      map[string]types.PriceUpdate
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	symbols map[string]struct{}
+	ch      chan types.PriceUpdate
+	once    sync.Once
+}
+
+func (s *subscriber) close() {
+	s.once.Do(func() {
+		close(s.ch)
+	})
+}
+
+func (b *Broker) Publish(symbol string, price decimal.Decimal, at time.Time) types.PriceUpdate {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	update := types.PriceUpdate{
+		ID:     b.lastID,
+		Symbol: symbol,
+		Price:  price,
+		Time:   at,
+	}
+
+	if len(b.ring) < b.size {
+		b.ring = append(b.ring, update)
+	} else {
+		b.ring[b.next] = update
+		b.next = (b.next + 1) % b.size
+	}
+
+	b.latest[symbol] = update
+
+	for sub := range b.subscribers {
+		if _, ok := sub.symbols[symbol]; !ok {
+			continue
+		}
+
+		select {
+		case sub.ch <- update:
+		default:
+			// never block the publisher on a slow subscriber, drop it instead
+			delete(b.subscribers, sub)
+			sub.close()
+		}
+	}
+
+	return update
+}
+
+func (b *Broker) Subscribe(ctx context.Context, symbols []string, lastID uint64) (<-chan types.PriceUpdate, error) {
+	b.mu.Lock()
+
+	sub := &subscriber{
+		symbols: map[string]struct{}{},
+	}
+	for _, s := range symbols {
+		sub.symbols[s] = struct{}{}
+	}
+
+	var pending []types.PriceUpdate
+
+	// an id of an earlier process is older than every buffered update, one this broker never
+	// published (a later process or a clock set back) only gets the latest prices
+	if lastID > 0 && lastID <= b.lastID {
+		for _, u := range b.ordered() {
+			if _, ok := sub.symbols[u.Symbol]; ok && u.ID > lastID {
+				pending = append(pending, u)
+			}
+		}
+	} else {
+		for s := range sub.symbols {
+			if u, ok := b.latest[s]; ok {
+				pending = append(pending, u)
+			}
+		}
+
+		sort.Slice(pending, func(i, j int) bool {
+			return pending[i].ID < pending[j].ID
+		})
+	}
+
+	sub.ch = make(chan types.PriceUpdate, len(pending)+b.subBuffer)
+	for _, u := range pending {
+		sub.ch <- u
+	}
+
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subscribers, sub)
+		b.mu.Unlock()
+
+		sub.close()
+	}()
+
+	return sub.ch, nil
+}
+
+// ordered returns the buffered updates oldest first, caller must hold the lock
+func (b *Broker) ordered() []types.PriceUpdate {
+	out := make([]types.PriceUpdate, 0, len(b.ring))
+	out = append(out, b.ring[b.next:]...)
+	out = append(out, b.ring[:b.next]...)
+
+	return out
+}
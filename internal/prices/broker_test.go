@@ -0,0 +1,191 @@
+package prices
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"testing"
+	"time"
+)
+
+func TestBroker_Subscribe_Snapshot(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBroker(&BrokerConfig{Epoch: 1})
+	now := time.Now()
+	base := uint64(1) << 32
+
+	b.Publish("AAPL", decimal.MustParse("100"), now)
+	b.Publish("MSFT", decimal.MustParse("200"), now)
+	b.Publish("AAPL", decimal.MustParse("101"), now)
+
+	// without a last id only the latest price per symbol is sent
+	ch, err := b.Subscribe(ctx, []string{"AAPL", "MSFT"}, 0)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	first := <-ch
+	second := <-ch
+
+	if first.Symbol != "MSFT" || !first.Price.Equal(decimal.MustParse("200")) {
+		t.Errorf("expected MSFT 200, got %s %v", first.Symbol, first.Price)
+	}
+	if second.Symbol != "AAPL" || !second.Price.Equal(decimal.MustParse("101")) || second.ID != base+3 {
+		t.Errorf("expected AAPL 101 with id %d, got %s %v %d", base+3, second.Symbol, second.Price, second.ID)
+	}
+
+	// live updates follow, other symbols are filtered out
+	b.Publish("GOOG", decimal.MustParse("300"), now)
+	b.Publish("MSFT", decimal.MustParse("201"), now)
+
+	update := <-ch
+	if update.Symbol != "MSFT" || update.ID != base+5 {
+		t.Errorf("expected MSFT with id %d, got %s %d", base+5, update.Symbol, update.ID)
+	}
+}
+
+func TestBroker_Subscribe_Resume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBroker(&BrokerConfig{Epoch: 1})
+	now := time.Now()
+	base := uint64(1) << 32
+
+	for i := 0; i < 5; i++ {
+		b.Publish("AAPL", decimal.NewFromInt(int64(100+i)), now)
+	}
+
+	ch, _ := b.Subscribe(ctx, []string{"AAPL"}, base+3)
+
+	for _, id := range []uint64{base + 4, base + 5} {
+		select {
+		case update := <-ch:
+			if update.ID != id {
+				t.Errorf("expected id %d, got %d", id, update.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected update %d to be replayed", id)
+		}
+	}
+
+	select {
+	case update := <-ch:
+		t.Errorf("expected no more updates, got %d", update.ID)
+	default:
+	}
+}
+
+func TestBroker_Subscribe_RingBuffer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBroker(&BrokerConfig{Buffer: 3, Epoch: 1})
+	now := time.Now()
+	base := uint64(1) << 32
+
+	for i := 0; i < 10; i++ {
+		b.Publish("AAPL", decimal.NewFromInt(int64(100+i)), now)
+	}
+
+	// only the buffered updates can be replayed
+	ch, _ := b.Subscribe(ctx, []string{"AAPL"}, base+1)
+
+	for _, id := range []uint64{base + 8, base + 9, base + 10} {
+		if update := <-ch; update.ID != id {
+			t.Errorf("expected id %d, got %d", id, update.ID)
+		}
+	}
+}
+
+func TestBroker_Subscribe_Restart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+
+	before := NewBroker(&BrokerConfig{Epoch: 1})
+	last := before.Publish("AAPL", decimal.MustParse("100"), now)
+
+	// a later process starts a new epoch, its ids follow the ids of the previous one
+	after := NewBroker(&BrokerConfig{Epoch: 2})
+	first := after.Publish("AAPL", decimal.MustParse("101"), now)
+	after.Publish("AAPL", decimal.MustParse("102"), now)
+
+	if first.ID <= last.ID {
+		t.Fatalf("expected ids to grow across restarts, got %d after %d", first.ID, last.ID)
+	}
+
+	// everything published since the restart was missed
+	ch, _ := after.Subscribe(ctx, []string{"AAPL"}, last.ID)
+	for _, price := range []string{"101", "102"} {
+		if update := <-ch; !update.Price.Equal(decimal.MustParse(price)) {
+			t.Errorf("expected AAPL %s, got %v", price, update.Price)
+		}
+	}
+
+	// an id this process never published gets the latest price only
+	ch, _ = before.Subscribe(ctx, []string{"AAPL"}, first.ID)
+	if update := <-ch; update.ID != last.ID {
+		t.Errorf("expected the latest update %d, got %d", last.ID, update.ID)
+	}
+
+	// the default epoch is the time of creation
+	if id := NewBroker(nil).Publish("AAPL", decimal.MustParse("100"), now).ID; id>>32 < uint64(now.Unix()) {
+		t.Errorf("expected the epoch to be the unix time, got %d", id>>32)
+	}
+}
+
+func TestBroker_Subscribe_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := NewBroker(nil)
+	ch, _ := b.Subscribe(ctx, []string{"AAPL"}, 0)
+
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Errorf("expected channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected channel to be closed on cancel")
+	}
+
+	// publishing after the subscriber is gone must not panic
+	b.Publish("AAPL", decimal.MustParse("100"), time.Now())
+}
+
+func TestBroker_Subscribe_SlowConsumer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBroker(&BrokerConfig{SubscriberBuffer: 2})
+	ch, _ := b.Subscribe(ctx, []string{"AAPL"}, 0)
+
+	done := make(chan struct{})
+	go func() {
+		// the publisher must not block on the subscriber
+		for i := 0; i < 10; i++ {
+			b.Publish("AAPL", decimal.NewFromInt(int64(i)), time.Now())
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected publisher to not be blocked")
+	}
+
+	var received int
+	for range ch {
+		received++
+	}
+
+	if received != 2 {
+		t.Errorf("expected the 2 buffered updates before being dropped, got %d", received)
+	}
+}
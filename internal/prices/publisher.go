@@ -0,0 +1,16 @@
+package prices
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+)
+
+// Publisher is the source of live prices, it can be backed by a simulation or a market data feed
+type Publisher interface {
+	// Subscribe streams updates for the given symbols until ctx is done, the channel is then closed.
+	// When lastID is set the buffered updates after it are replayed first,
+	// otherwise the latest known price of every symbol is sent as a snapshot.
+	// The channel is also closed when the subscriber falls too far behind,
+	// it should resubscribe with the last received ID.
+	Subscribe(ctx context.Context, symbols []string, lastID uint64) (<-chan types.PriceUpdate, error)
+}
@@ -0,0 +1,259 @@
+package snapshot
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"io"
+	"sort"
+	"time"
+)
+
+type ExportInput struct {
+	// Writer receives the zip archive
+	Writer io.Writer
+	// Users are exported with their holdings besides the ones the backend lists,
+	// the only way to export holdings of a backend that cannot list its users
+	Users []string
+}
+
+type ExportOutput struct {
+	Manifest *Manifest
+}
+
+// Export writes every symbol, daily bar, holding and watchlist into a zip archive, the manifest goes last
+func (s *Snapshotter) Export(ctx context.Context, in *ExportInput) (*ExportOutput, error) {
+	if s.storage == nil || s.symbols == nil {
+		return nil, errors.New("no storage backend or symbol master configured")
+	}
+
+	now := s.now().UTC()
+	manifest := &Manifest{
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     now,
+	}
+
+	zw := zip.NewWriter(in.Writer)
+
+	symbols, err := s.symbols.ListSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := writeEntry(zw, SymbolsName, now, func(w *recordWriter) error {
+		for _, v := range symbols {
+			if err := w.write(newSymbolRecord(v)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest.Entries = append(manifest.Entries, *entry)
+
+	// bars are stored under the symbol of their date, renamed instruments have several
+	var priceSymbols []string
+	seen := map[string]bool{}
+	for _, v := range symbols {
+		for _, l := range v.Listings {
+			if !seen[l.Symbol] {
+				seen[l.Symbol] = true
+				priceSymbols = append(priceSymbols, l.Symbol)
+			}
+		}
+	}
+	sort.Strings(priceSymbols)
+
+	entry, err = writeEntry(zw, PricesName, now, func(w *recordWriter) error {
+		for _, symbol := range priceSymbols {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			history, err := s.storage.GetHistory(ctx, symbol, time.Time{})
+
+			var errNotFound *types.ErrTickerNotFound
+			if errors.As(err, &errNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			// history is newest first, the archive oldest first
+			for i := len(history) - 1; i >= 0; i-- {
+				if err := w.write(newPriceRecord(symbol, history[i])); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest.Entries = append(manifest.Entries, *entry)
+
+	holdings, err := s.holdings(ctx, in.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make([]string, 0, len(holdings))
+	for username := range holdings {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	entry, err = writeEntry(zw, HoldingsName, now, func(w *recordWriter) error {
+		for _, username := range usernames {
+			if err := w.write(&holdingRecord{Username: username, Symbols: holdings[username]}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest.Entries = append(manifest.Entries, *entry)
+
+	watchlists, err := s.watchlists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames = usernames[:0]
+	for username := range watchlists {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	entry, err = writeEntry(zw, WatchlistsName, now, func(w *recordWriter) error {
+		for _, username := range usernames {
+			for _, v := range watchlists[username] {
+				if err := w.write(&watchlistRecord{Username: username, Name: v.Name, Symbols: v.Symbols}); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest.Entries = append(manifest.Entries, *entry)
+
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: ManifestName, Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return nil, err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &ExportOutput{
+		Manifest: manifest,
+	}, nil
+}
+
+// holdings lists the holdings of every user the backend knows and of users, without empty ones
+func (s *Snapshotter) holdings(ctx context.Context, users []string) (map[string][]string, error) {
+	holdings := map[string][]string{}
+
+	if l, ok := s.storage.(storage.HoldingsLister); ok {
+		listed, err := l.ListHoldings(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for username, symbols := range listed {
+			if len(symbols) > 0 {
+				holdings[username] = symbols
+			}
+		}
+	}
+
+	for _, username := range users {
+		if _, ok := holdings[username]; ok {
+			continue
+		}
+
+		tickers, err := s.storage.GetByUser(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tickers {
+			holdings[username] = append(holdings[username], t.Symbol)
+		}
+	}
+
+	return holdings, nil
+}
+
+// watchlists lists the watchlists of every user, none when the backend does not store them
+func (s *Snapshotter) watchlists(ctx context.Context) (map[string][]storage.Watchlist, error) {
+	w, ok := s.storage.(storage.WatchlistStorage)
+	if !ok {
+		return nil, nil
+	}
+
+	return w.ListWatchlists(ctx)
+}
+
+// recordWriter writes a record per line, hashing and counting them
+type recordWriter struct {
+	enc     *json.Encoder
+	records int
+}
+
+func (w *recordWriter) write(v interface{}) error {
+	if err := w.enc.Encode(v); err != nil {
+		return err
+	}
+
+	w.records++
+
+	return nil
+}
+
+// writeEntry adds the file name to the archive with the records written by fn
+func writeEntry(zw *zip.Writer, name string, modified time.Time, fn func(w *recordWriter) error) (*Entry, error) {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	w := &recordWriter{
+		enc: json.NewEncoder(io.MultiWriter(f, h)),
+	}
+
+	if err := fn(w); err != nil {
+		return nil, err
+	}
+
+	return &Entry{
+		Name:    name,
+		Records: w.records,
+		SHA256:  hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
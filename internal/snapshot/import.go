@@ -0,0 +1,372 @@
+package snapshot
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/storage"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"io"
+	"sort"
+	"strings"
+)
+
+// priceBatch bounds the bars upserted at once
+const priceBatch = 5000
+
+type ImportInput struct {
+	// Reader is the zip archive of Size bytes
+	Reader io.ReaderAt
+	Size   int64
+	// DryRun verifies the archive without writing
+	DryRun bool
+}
+
+type ImportOutput struct {
+	Manifest *Manifest
+
+	Symbols int
+	// Prices counts the bars in the archive, Inserted and Updated the ones written
+	Prices   int
+	Inserted int
+	Updated  int
+	// Holdings counts the users whose holdings were replaced
+	Holdings int
+	// Watchlists counts the watchlists replaced
+	Watchlists int
+}
+
+// Import restores an archive into the writer, nothing is written unless the whole archive
+// matches its manifest and every archived instrument is known to the symbol master
+//
+// bars replace stored bars of the same symbol and date, holdings replace the holdings
+// of the archived users and watchlists the archived watchlists of the same user and name,
+// nothing else in the target is removed
+func (s *Snapshotter) Import(ctx context.Context, in *ImportInput) (*ImportOutput, error) {
+	if s.writer == nil && !in.DryRun {
+		return nil, ErrNoWriter
+	}
+	if s.symbols == nil {
+		return nil, errors.New("no symbol master configured")
+	}
+
+	zr, err := zip.NewReader(in.Reader, in.Size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	manifest, err := readManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ImportOutput{
+		Manifest: manifest,
+	}
+
+	if err := s.verify(ctx, zr, manifest); err != nil {
+		return nil, err
+	}
+
+	out.Symbols = manifest.Entry(SymbolsName).Records
+	out.Prices = manifest.Entry(PricesName).Records
+
+	watchlists, _ := s.writer.(storage.WatchlistStorage)
+	if e := manifest.Entry(WatchlistsName); e != nil && e.Records > 0 && watchlists == nil && !in.DryRun {
+		return nil, errors.New("the storage backend does not store watchlists")
+	}
+
+	if in.DryRun {
+		return out, nil
+	}
+
+	// verified, records decode
+	var symbol string
+	var batch []types.TickerHistory
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		result, err := s.writer.UpsertPrices(ctx, symbol, batch)
+		if err != nil {
+			return err
+		}
+
+		out.Inserted += result.Inserted
+		out.Updated += result.Updated
+		batch = batch[:0]
+
+		return nil
+	}
+
+	_, _, err = eachRecord(zr, PricesName, func(line []byte) error {
+		r := &priceRecord{}
+		_ = json.Unmarshal(line, r)
+		bar, _ := r.history()
+
+		if r.Symbol != symbol || len(batch) >= priceBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+
+			symbol = r.Symbol
+		}
+
+		batch = append(batch, bar)
+
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	_, _, err = eachRecord(zr, HoldingsName, func(line []byte) error {
+		r := &holdingRecord{}
+		_ = json.Unmarshal(line, r)
+
+		if err := s.writer.SetHoldings(ctx, r.Username, r.Symbols); err != nil {
+			return err
+		}
+
+		out.Holdings++
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Entry(WatchlistsName) == nil {
+		return out, nil
+	}
+
+	_, _, err = eachRecord(zr, WatchlistsName, func(line []byte) error {
+		r := &watchlistRecord{}
+		_ = json.Unmarshal(line, r)
+
+		if err := watchlists.SetWatchlist(ctx, r.Username, r.Name, r.Symbols); err != nil {
+			return err
+		}
+
+		out.Watchlists++
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func readManifest(zr *zip.Reader) (*Manifest, error) {
+	f, err := zr.Open(ManifestName)
+	if err != nil {
+		return nil, &ErrCorrupted{Name: ManifestName, Message: "missing"}
+	}
+	defer f.Close()
+
+	manifest := &Manifest{}
+	if err := json.NewDecoder(f).Decode(manifest); err != nil {
+		return nil, &ErrCorrupted{Name: ManifestName, Message: err.Error()}
+	}
+
+	if manifest.SchemaVersion != SchemaVersion {
+		return nil, &ErrSchemaVersion{Version: manifest.SchemaVersion}
+	}
+
+	for _, name := range []string{SymbolsName, PricesName, HoldingsName} {
+		if manifest.Entry(name) == nil {
+			return nil, &ErrCorrupted{Name: name, Message: "missing from the manifest"}
+		}
+	}
+
+	return manifest, nil
+}
+
+// verify checks every entry against its checksum and record count, that every record decodes
+// and that the symbol master knows every archived instrument
+func (s *Snapshotter) verify(ctx context.Context, zr *zip.Reader, manifest *Manifest) error {
+	var unknown []string
+	seen := map[string]bool{}
+
+	known := func(symbol string, instrumentID string) error {
+		if seen[symbol] {
+			return nil
+		}
+		seen[symbol] = true
+
+		symbols, err := s.symbols.ResolveSymbol(ctx, symbol)
+
+		var errNotFound *symboltypes.ErrSymbolNotFound
+		if errors.As(err, &errNotFound) {
+			unknown = append(unknown, symbol)
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if instrumentID == "" {
+			return nil
+		}
+
+		for _, v := range symbols {
+			if v.InstrumentID == instrumentID {
+				return nil
+			}
+		}
+
+		unknown = append(unknown, symbol)
+
+		return nil
+	}
+
+	checks := map[string]func(line []byte) error{
+		SymbolsName: func(line []byte) error {
+			r := &symbolRecord{}
+			if err := json.Unmarshal(line, r); err != nil {
+				return &recordError{err: err}
+			}
+
+			return known(r.Symbol, r.InstrumentID)
+		},
+		PricesName: func(line []byte) error {
+			r := &priceRecord{}
+			if err := json.Unmarshal(line, r); err != nil {
+				return &recordError{err: err}
+			}
+			if _, err := r.history(); err != nil {
+				return &recordError{err: err}
+			}
+
+			return known(r.Symbol, "")
+		},
+		HoldingsName: func(line []byte) error {
+			r := &holdingRecord{}
+			if err := json.Unmarshal(line, r); err != nil {
+				return &recordError{err: err}
+			}
+			if r.Username == "" {
+				return &recordError{err: errors.New("missing username")}
+			}
+
+			for _, symbol := range r.Symbols {
+				if err := known(symbol, ""); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		WatchlistsName: func(line []byte) error {
+			r := &watchlistRecord{}
+			if err := json.Unmarshal(line, r); err != nil {
+				return &recordError{err: err}
+			}
+			if r.Username == "" || r.Name == "" {
+				return &recordError{err: errors.New("missing username or name")}
+			}
+
+			for _, symbol := range r.Symbols {
+				if err := known(symbol, ""); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	for _, name := range []string{SymbolsName, PricesName, HoldingsName, WatchlistsName} {
+		entry := manifest.Entry(name)
+		if entry == nil {
+			// optional, readManifest checked the others
+			continue
+		}
+
+		records, sum, err := eachRecord(zr, name, checks[name])
+		if err != nil {
+			return err
+		}
+
+		if sum != entry.SHA256 {
+			return &ErrCorrupted{Name: name, Message: "checksum mismatch"}
+		}
+		if records != entry.Records {
+			return &ErrCorrupted{Name: name, Message: fmt.Sprintf("%d records, the manifest lists %d", records, entry.Records)}
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		more := ""
+		if len(unknown) > 10 {
+			more = fmt.Sprintf(" and %d more", len(unknown)-10)
+			unknown = unknown[:10]
+		}
+
+		return fmt.Errorf("the symbol master does not know the archived symbols %s%s", strings.Join(unknown, ", "), more)
+	}
+
+	return nil
+}
+
+// recordError is a record that does not decode, reported with its line
+type recordError struct {
+	err error
+}
+
+func (e *recordError) Error() string {
+	return e.err.Error()
+}
+
+// eachRecord calls fn with every line of the entry name, it returns the number of lines
+// and the hex SHA-256 of the entry, a *recordError from fn corrupts the archive
+func eachRecord(zr *zip.Reader, name string, fn func(line []byte) error) (int, string, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return 0, "", &ErrCorrupted{Name: name, Message: "missing"}
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	r := bufio.NewReader(io.TeeReader(f, h))
+
+	records := 0
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			records++
+
+			if err := fn(bytes.TrimSpace(line)); err != nil {
+				var errRecord *recordError
+				if errors.As(err, &errRecord) {
+					return 0, "", &ErrCorrupted{Name: name, Message: fmt.Sprintf("line %d: %s", records, err)}
+				}
+
+				return 0, "", err
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return 0, "", &ErrCorrupted{Name: name, Message: err.Error()}
+		}
+	}
+
+	return records, hex.EncodeToString(h.Sum(nil)), nil
+}
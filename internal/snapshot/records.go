@@ -0,0 +1,123 @@
+package snapshot
+
+import (
+	"fmt"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"time"
+)
+
+// records are the lines of the archive entries, kept apart from the domain types so
+// that the archive layout only changes with SchemaVersion
+
+type symbolRecord struct {
+	InstrumentID string          `json:"instrument_id"`
+	Symbol       string          `json:"symbol"`
+	Name         string          `json:"name"`
+	Exchange     string          `json:"exchange"`
+	Sector       string          `json:"sector"`
+	Industry     string          `json:"industry"`
+	Currency     string          `json:"currency"`
+	ISIN         string          `json:"isin"`
+	FIGI         string          `json:"figi"`
+	Listed       string          `json:"listed"`
+	Delisted     string          `json:"delisted"`
+	Listings     []listingRecord `json:"listings"`
+}
+
+type listingRecord struct {
+	Symbol string `json:"symbol"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// priceRecord is a daily bar, the date is the trading day
+type priceRecord struct {
+	Symbol   string          `json:"symbol"`
+	Date     string          `json:"date"`
+	Open     decimal.Decimal `json:"open"`
+	High     decimal.Decimal `json:"high"`
+	Low      decimal.Decimal `json:"low"`
+	Close    decimal.Decimal `json:"close"`
+	Volume   int64           `json:"volume"`
+	Currency string          `json:"currency"`
+}
+
+type holdingRecord struct {
+	Username string   `json:"username"`
+	Symbols  []string `json:"symbols"`
+}
+
+type watchlistRecord struct {
+	Username string   `json:"username"`
+	Name     string   `json:"name"`
+	Symbols  []string `json:"symbols"`
+}
+
+func newSymbolRecord(v symboltypes.Symbol) *symbolRecord {
+	r := &symbolRecord{
+		InstrumentID: v.InstrumentID,
+		Symbol:       v.Symbol,
+		Name:         v.Name,
+		Exchange:     v.Exchange,
+		Sector:       v.Sector,
+		Industry:     v.Industry,
+		Currency:     v.Currency,
+		ISIN:         v.ISIN,
+		FIGI:         v.FIGI,
+		Listed:       formatDate(v.Listed),
+		Delisted:     formatDate(v.Delisted),
+		Listings:     make([]listingRecord, 0, len(v.Listings)),
+	}
+
+	for _, l := range v.Listings {
+		r.Listings = append(r.Listings, listingRecord{
+			Symbol: l.Symbol,
+			From:   formatDate(l.From),
+			To:     formatDate(l.To),
+		})
+	}
+
+	return r
+}
+
+func newPriceRecord(symbol string, v types.TickerHistory) *priceRecord {
+	return &priceRecord{
+		Symbol:   symbol,
+		Date:     formatDate(v.Date),
+		Open:     v.Open,
+		High:     v.High,
+		Low:      v.Low,
+		Close:    v.Price,
+		Volume:   v.Volume,
+		Currency: v.Currency,
+	}
+}
+
+func (r *priceRecord) history() (types.TickerHistory, error) {
+	date, err := time.Parse("2006-01-02", r.Date)
+	if err != nil {
+		return types.TickerHistory{}, fmt.Errorf("%s: invalid date %s", r.Symbol, r.Date)
+	}
+
+	return types.TickerHistory{
+		Symbol:   r.Symbol,
+		Date:     date,
+		Price:    r.Close,
+		Open:     r.Open,
+		High:     r.High,
+		Low:      r.Low,
+		Volume:   r.Volume,
+		Currency: r.Currency,
+	}, nil
+}
+
+// formatDate writes a daily date, empty when zero
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,114 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/storage"
+	"time"
+)
+
+// SchemaVersion is the version of the archive layout written by Export,
+// bump it whenever a record changes in a way older versions cannot read
+const SchemaVersion = 1
+
+// entries of an archive, each one JSON-lines with a record per line
+const (
+	ManifestName = "manifest.json"
+	SymbolsName  = "symbols.jsonl"
+	PricesName   = "prices.jsonl"
+	HoldingsName = "holdings.jsonl"
+	// WatchlistsName is missing from archives written before watchlists were stored, importing them
+	// leaves the watchlists of the target untouched
+	WatchlistsName = "watchlists.jsonl"
+)
+
+var ErrNoWriter = errors.New("no writable storage backend configured")
+
+// Manifest describes an archive, it is written last and checked before anything is restored
+type Manifest struct {
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Entries       []Entry   `json:"entries"`
+}
+
+// Entry is a file of the archive with the hex SHA-256 of its uncompressed content
+type Entry struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// Entry finds the entry named name, nil when the archive does not have it
+func (m *Manifest) Entry(name string) *Entry {
+	for i := range m.Entries {
+		if m.Entries[i].Name == name {
+			return &m.Entries[i]
+		}
+	}
+
+	return nil
+}
+
+// ErrSchemaVersion is an archive written by another version of the schema
+type ErrSchemaVersion struct {
+	Version int
+}
+
+func (e *ErrSchemaVersion) Error() string {
+	if e.Version > SchemaVersion {
+		return fmt.Sprintf("archive schema version %d is newer than the supported %d, upgrade to import it", e.Version, SchemaVersion)
+	}
+
+	return fmt.Sprintf("unsupported archive schema version %d, expected %d", e.Version, SchemaVersion)
+}
+
+// ErrCorrupted is an archive whose content does not match its manifest
+type ErrCorrupted struct {
+	Name    string
+	Message string
+}
+
+func (e *ErrCorrupted) Error() string {
+	return fmt.Sprintf("corrupted archive: %s: %s", e.Name, e.Message)
+}
+
+type Config struct {
+	// Storage is read by Export
+	Storage storage.Storage
+	// Writer receives the data restored by Import, only dry runs are possible without it
+	Writer storage.WritableStorage
+	// Symbols is the symbol master, exported as is and checked on import
+	Symbols storage.SymbolStorage
+
+	// Now stamps the manifest, defaults to time.Now
+	Now func() time.Time
+}
+
+// Snapshotter exports every entity of a storage backend into an archive and restores one into any other
+//
+// the symbol master is reference data shipped with the binary, it is exported so an archive is
+// self-describing but not written on import, the target must know every archived instrument
+type Snapshotter struct {
+	storage storage.Storage
+	writer  storage.WritableStorage
+	symbols storage.SymbolStorage
+	now     func() time.Time
+}
+
+func New(cfg *Config) *Snapshotter {
+	s := &Snapshotter{
+		now: time.Now,
+	}
+
+	if cfg != nil {
+		s.storage = cfg.Storage
+		s.writer = cfg.Writer
+		s.symbols = cfg.Symbols
+
+		if cfg.Now != nil {
+			s.now = cfg.Now
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,331 @@
+//go:build test
+
+package snapshot
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/storage"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func testBar(date string, price string, volume int64) types.TickerHistory {
+	d, _ := time.Parse("2006-01-02", date)
+	p := decimal.MustParse(price)
+
+	return types.TickerHistory{Date: d, Price: p, Open: p, High: p, Low: p, Volume: volume, Currency: "USD"}
+}
+
+// testArchive exports a memory backend holding bars of AAPL and of META under both its symbols
+func testArchive(t *testing.T) []byte {
+	ctx := context.Background()
+
+	source := storage.NewMemory()
+	_, _ = source.UpsertPrices(ctx, "AAPL", []types.TickerHistory{testBar("2024-01-02", "185.64", 82488700), testBar("2024-01-03", "184.25", 58414500)})
+	_, _ = source.UpsertPrices(ctx, "FB", []types.TickerHistory{testBar("2021-01-04", "268.94", 15106100)})
+	_, _ = source.UpsertPrices(ctx, "META", []types.TickerHistory{testBar("2024-01-02", "346.29", 11737400)})
+	_ = source.SetHoldings(ctx, "test", []string{"META", "AAPL"})
+	_ = source.SetHoldings(ctx, "other", []string{"NFLX"})
+	_ = source.(storage.WatchlistStorage).SetWatchlist(ctx, "test", "tech", []string{"MSFT", "META"})
+	_ = source.(storage.WatchlistStorage).SetWatchlist(ctx, "test", "media", []string{"NFLX"})
+
+	var buf bytes.Buffer
+	out, err := New(&Config{
+		Storage: source,
+		Symbols: storage.NewEmbeddedSymbols(),
+		Now:     func() time.Time { return testNow },
+	}).Export(ctx, &ExportInput{Writer: &buf})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if out.Manifest.SchemaVersion != SchemaVersion || !out.Manifest.CreatedAt.Equal(testNow) {
+		t.Errorf("unexpected manifest %+v", out.Manifest)
+	}
+	if e := out.Manifest.Entry(PricesName); e == nil || e.Records != 4 || len(e.SHA256) != 64 {
+		t.Errorf("expected 4 prices with a checksum, got %+v", e)
+	}
+	if e := out.Manifest.Entry(HoldingsName); e == nil || e.Records != 2 {
+		t.Errorf("expected 2 users, got %+v", e)
+	}
+	if e := out.Manifest.Entry(WatchlistsName); e == nil || e.Records != 2 {
+		t.Errorf("expected 2 watchlists, got %+v", e)
+	}
+
+	return buf.Bytes()
+}
+
+// rewrite copies the archive replacing the content of the entry name with fn of it
+func rewrite(t *testing.T, archive []byte, name string, fn func(b []byte) []byte) []byte {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, f := range zr.File {
+		r, _ := f.Open()
+		b, _ := io.ReadAll(r)
+		_ = r.Close()
+
+		if f.Name == name {
+			b = fn(b)
+		}
+
+		w, _ := zw.Create(f.Name)
+		_, _ = w.Write(b)
+	}
+	_ = zw.Close()
+
+	return buf.Bytes()
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	archive := testArchive(t)
+
+	target := storage.NewMemory()
+	_ = target.SetHoldings(ctx, "kept", []string{"MSFT"})
+	_ = target.(storage.WatchlistStorage).SetWatchlist(ctx, "test", "kept", []string{"AAPL"})
+
+	out, err := New(&Config{
+		Writer:  target,
+		Symbols: storage.NewEmbeddedSymbols(),
+	}).Import(ctx, &ImportInput{Reader: bytes.NewReader(archive), Size: int64(len(archive))})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if out.Prices != 4 || out.Inserted != 4 || out.Updated != 0 || out.Holdings != 2 || out.Watchlists != 2 || out.Symbols == 0 {
+		t.Errorf("unexpected output %+v", out)
+	}
+
+	history, _ := target.GetHistory(ctx, "AAPL", time.Time{})
+	if len(history) != 2 || !history[0].Price.Equal(decimal.MustParse("184.25")) || history[0].Volume != 58414500 || history[0].Currency != "USD" {
+		t.Errorf("expected AAPL bars restored newest first, got %+v", history)
+	}
+	if history, _ := target.GetHistory(ctx, "FB", time.Time{}); len(history) != 1 {
+		t.Errorf("expected the FB bar restored under FB, got %+v", history)
+	}
+
+	holdings, _ := target.(storage.HoldingsLister).ListHoldings(ctx)
+	if strings.Join(holdings["test"], ",") != "META,AAPL" || strings.Join(holdings["other"], ",") != "NFLX" {
+		t.Errorf("expected holdings restored in order, got %v", holdings)
+	}
+	if len(holdings["kept"]) != 1 {
+		t.Errorf("expected holdings of users not archived to be kept, got %v", holdings)
+	}
+
+	watchlists, _ := target.(storage.WatchlistStorage).GetWatchlists(ctx, "test")
+	if len(watchlists) != 3 || watchlists[0].Name != "kept" || watchlists[1].Name != "media" || strings.Join(watchlists[2].Symbols, ",") != "MSFT,META" {
+		t.Errorf("expected the tech and media watchlists restored next to kept, got %+v", watchlists)
+	}
+
+	// restoring again only updates
+	out, _ = New(&Config{
+		Writer:  target,
+		Symbols: storage.NewEmbeddedSymbols(),
+	}).Import(ctx, &ImportInput{Reader: bytes.NewReader(archive), Size: int64(len(archive))})
+	if out == nil || out.Inserted != 0 || out.Updated != 4 {
+		t.Errorf("expected 4 updated, got %+v", out)
+	}
+}
+
+func TestSnapshot_Import_NoWatchlists(t *testing.T) {
+	ctx := context.Background()
+
+	// archives written before watchlists were stored
+	archive := rewrite(t, testArchive(t), ManifestName, func(b []byte) []byte {
+		m := &Manifest{}
+		_ = json.Unmarshal(b, m)
+		m.Entries = m.Entries[:3]
+		b, _ = json.Marshal(m)
+		return b
+	})
+
+	target := storage.NewMemory()
+	_ = target.(storage.WatchlistStorage).SetWatchlist(ctx, "test", "tech", []string{"AAPL"})
+
+	out, err := New(&Config{
+		Writer:  target,
+		Symbols: storage.NewEmbeddedSymbols(),
+	}).Import(ctx, &ImportInput{Reader: bytes.NewReader(archive), Size: int64(len(archive))})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if out.Holdings != 2 || out.Watchlists != 0 {
+		t.Errorf("expected holdings restored without watchlists, got %+v", out)
+	}
+
+	if watchlists, _ := target.(storage.WatchlistStorage).GetWatchlists(ctx, "test"); len(watchlists) != 1 || watchlists[0].Symbols[0] != "AAPL" {
+		t.Errorf("expected the watchlists of the target untouched, got %+v", watchlists)
+	}
+}
+
+func TestSnapshot_Import_DryRun(t *testing.T) {
+	archive := testArchive(t)
+
+	// no writer is needed
+	out, err := New(&Config{
+		Symbols: storage.NewEmbeddedSymbols(),
+	}).Import(context.Background(), &ImportInput{Reader: bytes.NewReader(archive), Size: int64(len(archive)), DryRun: true})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if out.Prices != 4 || out.Inserted != 0 || out.Holdings != 0 {
+		t.Errorf("expected 4 prices not written, got %+v", out)
+	}
+
+	_, err = New(&Config{
+		Symbols: storage.NewEmbeddedSymbols(),
+	}).Import(context.Background(), &ImportInput{Reader: bytes.NewReader(archive), Size: int64(len(archive))})
+	if !errors.Is(err, ErrNoWriter) {
+		t.Errorf("expected error to be ErrNoWriter, got %v", err)
+	}
+}
+
+func TestSnapshot_Import_Corrupted(t *testing.T) {
+	ctx := context.Background()
+	archive := testArchive(t)
+
+	tests := map[string][]byte{
+		"checksum": rewrite(t, archive, PricesName, func(b []byte) []byte {
+			return bytes.Replace(b, []byte("184.25"), []byte("999.99"), 1)
+		}),
+		"records": rewrite(t, archive, HoldingsName, func(b []byte) []byte {
+			return b[:bytes.IndexByte(b, '\n')+1]
+		}),
+		"manifest": rewrite(t, archive, ManifestName, func(b []byte) []byte {
+			return []byte("{")
+		}),
+		"entry": rewrite(t, archive, ManifestName, func(b []byte) []byte {
+			m := &Manifest{}
+			_ = json.Unmarshal(b, m)
+			m.Entries = m.Entries[:2]
+			b, _ = json.Marshal(m)
+			return b
+		}),
+	}
+
+	for name, archive := range tests {
+		target := storage.NewMemory()
+
+		_, err := New(&Config{
+			Writer:  target,
+			Symbols: storage.NewEmbeddedSymbols(),
+		}).Import(ctx, &ImportInput{Reader: bytes.NewReader(archive), Size: int64(len(archive))})
+
+		var errCorrupted *ErrCorrupted
+		if !errors.As(err, &errCorrupted) {
+			t.Errorf("%s: expected error to be ErrCorrupted, got %v", name, err)
+		}
+
+		if _, err := target.GetHistory(ctx, "AAPL", time.Time{}); err == nil {
+			t.Errorf("%s: expected nothing written", name)
+		}
+	}
+
+	if _, err := New(&Config{Writer: storage.NewMemory(), Symbols: storage.NewEmbeddedSymbols()}).Import(ctx, &ImportInput{
+		Reader: strings.NewReader("not a zip"),
+		Size:   9,
+	}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestSnapshot_Import_SchemaVersion(t *testing.T) {
+	archive := rewrite(t, testArchive(t), ManifestName, func(b []byte) []byte {
+		return bytes.Replace(b, []byte(`"schema_version": 1`), []byte(`"schema_version": 2`), 1)
+	})
+
+	_, err := New(&Config{
+		Writer:  storage.NewMemory(),
+		Symbols: storage.NewEmbeddedSymbols(),
+	}).Import(context.Background(), &ImportInput{Reader: bytes.NewReader(archive), Size: int64(len(archive))})
+
+	var errVersion *ErrSchemaVersion
+	if !errors.As(err, &errVersion) || errVersion.Version != 2 {
+		t.Errorf("expected error to be ErrSchemaVersion 2, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "newer") {
+		t.Errorf("expected a newer archive to ask for an upgrade, got %s", err)
+	}
+}
+
+func TestSnapshot_Import_UnknownSymbols(t *testing.T) {
+	ctx := context.Background()
+	archive := testArchive(t)
+
+	// a symbol master without META nor NFLX
+	symbols, _ := storage.NewEmbeddedSymbols().ListSymbols(ctx)
+	var kept []symboltypes.Symbol
+	for _, v := range symbols {
+		if v.Symbol != "META" && v.Symbol != "NFLX" {
+			kept = append(kept, v)
+		}
+	}
+
+	target := storage.NewMemory()
+	_, err := New(&Config{
+		Writer:  target,
+		Symbols: storage.NewMemorySymbols(kept),
+	}).Import(ctx, &ImportInput{Reader: bytes.NewReader(archive), Size: int64(len(archive))})
+	if err == nil || !strings.Contains(err.Error(), "FB, META, NFLX") {
+		t.Errorf("expected FB, META and NFLX to be unknown, got %v", err)
+	}
+
+	if _, err := target.GetHistory(ctx, "AAPL", time.Time{}); err == nil {
+		t.Errorf("expected nothing written")
+	}
+}
+
+func TestSnapshot_Export_Seeded(t *testing.T) {
+	ctx := context.Background()
+	symbols := storage.NewEmbeddedSymbols()
+
+	var buf bytes.Buffer
+	out, err := New(&Config{
+		Storage: storage.NewSeeded(&storage.SeededConfig{Symbols: symbols}),
+		Symbols: symbols,
+	}).Export(ctx, &ExportInput{Writer: &buf, Users: []string{"test"}})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	// the seeded storage cannot list users, only the given ones are exported
+	if e := out.Manifest.Entry(HoldingsName); e.Records != 1 {
+		t.Errorf("expected 1 user, got %d", e.Records)
+	}
+	if e := out.Manifest.Entry(PricesName); e.Records == 0 {
+		t.Errorf("expected generated prices, got none")
+	}
+
+	// and restores into another backend
+	target := storage.NewMemory()
+	_, err = New(&Config{
+		Writer:  target,
+		Symbols: symbols,
+	}).Import(ctx, &ImportInput{Reader: bytes.NewReader(buf.Bytes()), Size: int64(buf.Len())})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	seeded, _ := storage.NewSeeded(&storage.SeededConfig{Symbols: symbols}).GetByUser(ctx, "test")
+	restored, _ := target.GetByUser(ctx, "test")
+	if len(restored) != len(seeded) || restored[0].Symbol != seeded[0].Symbol || !restored[0].Price.Equal(seeded[0].Price) {
+		t.Errorf("expected restored tickers %+v, got %+v", seeded, restored)
+	}
+}
@@ -0,0 +1,461 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"go.etcd.io/bbolt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var _ WritableStorage = (*boltStorage)(nil)
+var _ HoldingsLister = (*boltStorage)(nil)
+var _ WatchlistStorage = (*boltStorage)(nil)
+
+var (
+	boltPrices   = []byte("prices")
+	boltHoldings = []byte("holdings")
+	// watchlists has a bucket per user, the symbols keyed by watchlist name
+	boltWatchlists = []byte("watchlists")
+)
+
+// DefaultBoltTimeout is how long opening the file waits for another process to release it
+const DefaultBoltTimeout = time.Second * 5
+
+type BoltConfig struct {
+	// Path of the file, created with its parent directory missing
+	Path string
+	// Timeout waits for the lock of another process, DefaultBoltTimeout when zero
+	Timeout time.Duration
+}
+
+// NewBolt stores daily bars, holdings and watchlists in a bbolt file that outlives the process
+//
+// the file is opened by the first operation and held, locked, until Close: one process uses it at
+// a time, while the server runs prices and holdings are written through its admin api
+func NewBolt(cfg *BoltConfig) (WritableStorage, error) {
+	s := &boltStorage{
+		path:    cfg.Path,
+		timeout: cfg.Timeout,
+	}
+
+	if s.path == "" {
+		return nil, errors.New("bolt storage: path is required")
+	}
+	if s.timeout <= 0 {
+		s.timeout = DefaultBoltTimeout
+	}
+
+	return s, nil
+}
+
+// boltStorage only stores daily bars, intraday resolutions are always empty
+type boltStorage struct {
+	path    string
+	timeout time.Duration
+
+	mu sync.Mutex
+	db *bbolt.DB
+}
+
+// open returns the database, opening the file and creating its buckets on first use,
+// commands that never touch the storage do not lock it
+func (s *boltStorage) open() (*bbolt.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db != nil {
+		return s.db, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return nil, err
+	}
+
+	db, err := bbolt.Open(s.path, 0o600, &bbolt.Options{Timeout: s.timeout})
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return nil, fmt.Errorf("bolt storage: %s is locked by another process, use the admin api of a running server: %w", s.path, err)
+	} else if err != nil {
+		return nil, fmt.Errorf("bolt storage: %w", err)
+	}
+
+	// readers expect the buckets
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{boltPrices, boltHoldings, boltWatchlists} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	s.db = db
+
+	return db, nil
+}
+
+// Close releases the file, a later operation opens it again
+func (s *boltStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db == nil {
+		return nil
+	}
+
+	err := s.db.Close()
+	s.db = nil
+
+	return err
+}
+
+func (s *boltStorage) view(fn func(tx *bbolt.Tx) error) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+
+	return db.View(fn)
+}
+
+func (s *boltStorage) update(fn func(tx *bbolt.Tx) error) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+
+	return db.Update(fn)
+}
+
+func (s *boltStorage) GetByUser(_ context.Context, username string) ([]types.Ticker, error) {
+	var tickers []types.Ticker
+
+	err := s.view(func(tx *bbolt.Tx) error {
+		var symbols []string
+		if err := getJSON(tx.Bucket(boltHoldings), []byte(username), &symbols); err != nil {
+			return err
+		}
+
+		tickers = make([]types.Ticker, 0, len(symbols))
+
+		for _, symbol := range symbols {
+			// a holding without prices has no ticker yet
+			prices := tx.Bucket(boltPrices).Bucket([]byte(symbol))
+			if prices == nil {
+				continue
+			}
+
+			_, v := prices.Cursor().Last()
+			if v == nil {
+				continue
+			}
+
+			var latest types.TickerHistory
+			if err := json.Unmarshal(v, &latest); err != nil {
+				return err
+			}
+
+			tickers = append(tickers, types.Ticker{
+				Symbol:   symbol,
+				Price:    latest.Price,
+				Currency: latest.Currency,
+			})
+		}
+
+		return nil
+	})
+
+	return tickers, err
+}
+
+func (s *boltStorage) GetHistory(_ context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+	history := []types.TickerHistory{}
+
+	err := s.view(func(tx *bbolt.Tx) error {
+		prices := tx.Bucket(boltPrices).Bucket([]byte(symbol))
+		if prices == nil {
+			return &types.ErrTickerNotFound{Symbol: symbol}
+		}
+
+		// keys sort by date, newest first is walking backwards
+		c := prices.Cursor()
+		k, v := c.Last()
+		if !before.IsZero() {
+			// the first bar after before, or none, then the one before it
+			if k, _ = c.Seek(boltDateKey(before.Add(time.Nanosecond))); k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+
+		for ; k != nil; k, v = c.Prev() {
+			var bar types.TickerHistory
+			if err := json.Unmarshal(v, &bar); err != nil {
+				return err
+			}
+
+			history = append(history, bar)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (s *boltStorage) GetBars(ctx context.Context, symbol string, resolution types.Resolution, before time.Time) ([]types.TickerHistory, error) {
+	history, err := s.GetHistory(ctx, symbol, before)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolution.Intraday() {
+		return history[:0], nil
+	}
+
+	return history, nil
+}
+
+func (s *boltStorage) UpsertPrices(_ context.Context, symbol string, prices []types.TickerHistory) (*UpsertResult, error) {
+	result := &UpsertResult{}
+
+	err := s.update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.Bucket(boltPrices).CreateBucketIfNotExists([]byte(symbol))
+		if err != nil {
+			return err
+		}
+
+		for _, v := range prices {
+			v.Symbol = symbol
+
+			key := boltDateKey(v.Date)
+			if bucket.Get(key) != nil {
+				result.Updated++
+			} else {
+				result.Inserted++
+			}
+
+			if err := putJSON(bucket, key, v); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *boltStorage) DeletePrices(_ context.Context, symbol string, from time.Time, to time.Time) (int, error) {
+	deleted := 0
+
+	err := s.update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltPrices).Bucket([]byte(symbol))
+		if bucket == nil {
+			return nil
+		}
+
+		// keys are collected first, deleting moves the cursor
+		var keys [][]byte
+
+		c := bucket.Cursor()
+		k, _ := c.First()
+		if !from.IsZero() {
+			k, _ = c.Seek(boltDateKey(from))
+		}
+
+		for ; k != nil; k, _ = c.Next() {
+			if !to.IsZero() && bytes.Compare(k, boltDateKey(to)) > 0 {
+				break
+			}
+
+			keys = append(keys, append([]byte(nil), k...))
+		}
+
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		deleted = len(keys)
+
+		if k, _ := c.First(); k == nil {
+			return tx.Bucket(boltPrices).DeleteBucket([]byte(symbol))
+		}
+
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (s *boltStorage) SetHoldings(_ context.Context, username string, symbols []string) error {
+	return s.update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltHoldings)
+
+		if len(symbols) == 0 {
+			return bucket.Delete([]byte(username))
+		}
+
+		return putJSON(bucket, []byte(username), uniqueSymbols(symbols))
+	})
+}
+
+func (s *boltStorage) ListHoldings(_ context.Context) (map[string][]string, error) {
+	holdings := map[string][]string{}
+
+	err := s.view(func(tx *bbolt.Tx) error {
+		return tx.Bucket(boltHoldings).ForEach(func(k, v []byte) error {
+			var symbols []string
+			if err := json.Unmarshal(v, &symbols); err != nil {
+				return err
+			}
+
+			holdings[string(k)] = symbols
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return holdings, nil
+}
+
+func (s *boltStorage) SetWatchlist(_ context.Context, username string, name string, symbols []string) error {
+	return s.update(func(tx *bbolt.Tx) error {
+		watchlists := tx.Bucket(boltWatchlists)
+
+		if len(symbols) == 0 {
+			bucket := watchlists.Bucket([]byte(username))
+			if bucket == nil {
+				return nil
+			}
+
+			if err := bucket.Delete([]byte(name)); err != nil {
+				return err
+			}
+			if k, _ := bucket.Cursor().First(); k == nil {
+				return watchlists.DeleteBucket([]byte(username))
+			}
+
+			return nil
+		}
+
+		bucket, err := watchlists.CreateBucketIfNotExists([]byte(username))
+		if err != nil {
+			return err
+		}
+
+		return putJSON(bucket, []byte(name), uniqueSymbols(symbols))
+	})
+}
+
+func (s *boltStorage) GetWatchlists(_ context.Context, username string) ([]Watchlist, error) {
+	watchlists := []Watchlist{}
+
+	err := s.view(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(boltWatchlists).Bucket([]byte(username))
+		if bucket == nil {
+			return nil
+		}
+
+		var err error
+		watchlists, err = boltWatchlistsOf(bucket)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return watchlists, nil
+}
+
+func (s *boltStorage) ListWatchlists(_ context.Context) (map[string][]Watchlist, error) {
+	watchlists := map[string][]Watchlist{}
+
+	err := s.view(func(tx *bbolt.Tx) error {
+		users := tx.Bucket(boltWatchlists)
+
+		return users.ForEach(func(k, _ []byte) error {
+			lists, err := boltWatchlistsOf(users.Bucket(k))
+			if err != nil {
+				return err
+			}
+
+			watchlists[string(k)] = lists
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return watchlists, nil
+}
+
+// boltWatchlistsOf reads the watchlists of a user bucket, keys sort by name
+func boltWatchlistsOf(bucket *bbolt.Bucket) ([]Watchlist, error) {
+	watchlists := []Watchlist{}
+
+	err := bucket.ForEach(func(k, v []byte) error {
+		w := Watchlist{Name: string(k)}
+		if err := json.Unmarshal(v, &w.Symbols); err != nil {
+			return err
+		}
+
+		watchlists = append(watchlists, w)
+
+		return nil
+	})
+
+	return watchlists, err
+}
+
+// boltDateKey sorts as the dates do, the sign bit is flipped for dates before 1970
+func boltDateKey(date time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(date.UnixNano())^(1<<63))
+
+	return key
+}
+
+func getJSON(bucket *bbolt.Bucket, key []byte, v interface{}) error {
+	b := bucket.Get(key)
+	if b == nil {
+		return nil
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func putJSON(bucket *bbolt.Bucket, key []byte, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(key, b)
+}
@@ -0,0 +1,149 @@
+//go:build test
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"go.etcd.io/bbolt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestBolt(t *testing.T, path string) WritableStorage {
+	s, err := NewBolt(&BoltConfig{Path: path, Timeout: time.Millisecond * 100})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	t.Cleanup(func() { _ = s.(io.Closer).Close() })
+
+	return s
+}
+
+func TestBoltStorage_Prices(t *testing.T) {
+	ctx := context.Background()
+	s := newTestBolt(t, filepath.Join(t.TempDir(), "data", "richerage.db"))
+
+	var errNotFound *types.ErrTickerNotFound
+	if _, err := s.GetHistory(ctx, "AAPL", time.Time{}); !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be ErrTickerNotFound, got %v", err)
+	}
+
+	_, _ = s.UpsertPrices(ctx, "AAPL", []types.TickerHistory{
+		memoryBar("2024-01-02", "185.64"),
+		memoryBar("2024-01-04", "181.91"),
+		memoryBar("2024-01-03", "184.25"),
+	})
+
+	result, err := s.UpsertPrices(ctx, "AAPL", []types.TickerHistory{
+		memoryBar("2024-01-04", "181.90"),
+		memoryBar("2024-01-05", "181.18"),
+	})
+	if err != nil || result.Inserted != 1 || result.Updated != 1 {
+		t.Errorf("expected 1 inserted 1 updated, got %+v %v", result, err)
+	}
+
+	history, _ := s.GetHistory(ctx, "AAPL", time.Time{})
+	expected := []string{"2024-01-05 181.18", "2024-01-04 181.90", "2024-01-03 184.25", "2024-01-02 185.64"}
+
+	if len(history) != len(expected) {
+		t.Fatalf("expected %d bars, got %d", len(expected), len(history))
+	}
+	for i, v := range history {
+		if got := v.Date.Format("2006-01-02") + " " + v.Price.String(); got != expected[i] || v.Symbol != "AAPL" {
+			t.Errorf("expected %s of AAPL, got %s of %s", expected[i], got, v.Symbol)
+		}
+	}
+
+	for before, n := range map[string]int{"2024-01-03": 2, "2024-01-01": 0, "2024-02-01": 4} {
+		date, _ := time.Parse("2006-01-02", before)
+		if history, _ := s.GetHistory(ctx, "AAPL", date); len(history) != n {
+			t.Errorf("expected %d bars not after %s, got %d", n, before, len(history))
+		}
+	}
+
+	from, _ := time.Parse("2006-01-02", "2024-01-03")
+	to, _ := time.Parse("2006-01-02", "2024-01-04")
+
+	if deleted, _ := s.DeletePrices(ctx, "AAPL", from, to); deleted != 2 {
+		t.Errorf("expected 2 deleted, got %d", deleted)
+	}
+	if deleted, _ := s.DeletePrices(ctx, "AAPL", time.Time{}, time.Time{}); deleted != 2 {
+		t.Errorf("expected 2 deleted, got %d", deleted)
+	}
+	if _, err := s.GetHistory(ctx, "AAPL", time.Time{}); !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be ErrTickerNotFound once empty, got %v", err)
+	}
+}
+
+func TestBoltStorage_Persistent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "richerage.db")
+
+	// an ingest process writes, then ends
+	s := newTestBolt(t, path)
+	_, _ = s.UpsertPrices(ctx, "AAPL", []types.TickerHistory{memoryBar("2024-01-02", "185.64")})
+	_ = s.SetHoldings(ctx, "test", []string{"AAPL", "AAPL", "NFLX"})
+	if err := s.(io.Closer).Close(); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	// the server opens the same file
+	s = newTestBolt(t, path)
+
+	tickers, err := s.GetByUser(ctx, "test")
+	if err != nil || len(tickers) != 1 || tickers[0].Symbol != "AAPL" || !tickers[0].Price.Equal(decimal.MustParse("185.64")) {
+		t.Errorf("expected AAPL at 185.64, got %+v %v", tickers, err)
+	}
+
+	holdings, _ := s.(HoldingsLister).ListHoldings(ctx)
+	if len(holdings) != 1 || strings.Join(holdings["test"], ",") != "AAPL,NFLX" {
+		t.Errorf("expected AAPL and NFLX held by test, got %v", holdings)
+	}
+
+	history, _ := s.GetHistory(ctx, "AAPL", time.Time{})
+	if len(history) != 1 || history[0].Currency != "USD" || !history[0].Date.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the bar of 2024-01-02 in USD, got %+v", history)
+	}
+}
+
+func TestBoltStorage_Locked(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "richerage.db")
+
+	s := newTestBolt(t, path)
+
+	// another process holding the file for writing
+	db, err := bbolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	_, err = s.UpsertPrices(ctx, "AAPL", []types.TickerHistory{memoryBar("2024-01-02", "185.64")})
+	if !errors.Is(err, bbolt.ErrTimeout) {
+		t.Errorf("expected a lock timeout, got %v", err)
+	}
+	_ = db.Close()
+
+	// the file stays locked by its first operation until Close
+	if _, err := s.UpsertPrices(ctx, "AAPL", []types.TickerHistory{memoryBar("2024-01-02", "185.64")}); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if _, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Millisecond * 100}); !errors.Is(err, bbolt.ErrTimeout) {
+		t.Errorf("expected a lock timeout, got %v", err)
+	}
+
+	if err := s.(io.Closer).Close(); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	db, err = bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Millisecond * 100})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	_ = db.Close()
+}
@@ -0,0 +1,11 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+)
+
+type CorporateActionStorage interface {
+	// GetCorporateActions returns the splits and dividends of symbol sorted by ex-date, oldest first
+	GetCorporateActions(ctx context.Context, symbol string) ([]types.CorporateAction, error)
+}
@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"time"
+)
+
+// FXRateScale is the precision of cross rates derived from two USD rates
+const FXRateScale = 10
+
+type FXStorage interface {
+	// GetRate returns how many units of to one unit of from buys on date
+	GetRate(ctx context.Context, from string, to string, date time.Time) (decimal.Decimal, error)
+}
@@ -0,0 +1,102 @@
+//go:build test
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+	"time"
+)
+
+func TestStorageMemory_FX(t *testing.T) {
+	ctx := context.Background()
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	s := NewMemoryFX([]types.FXRate{
+		{Currency: "EUR", Date: date("2023-01-05"), Rate: decimal.MustParse("0.8")},
+		{Currency: "EUR", Date: date("2023-01-03"), Rate: decimal.MustParse("0.5")},
+		{Currency: "GBP", Date: date("2023-01-03"), Rate: decimal.MustParse("0.4")},
+	})
+
+	cases := []struct {
+		from     string
+		to       string
+		date     string
+		expected string
+	}{
+		{from: "USD", to: "USD", date: "2023-01-04", expected: "1"},
+		{from: "USD", to: "EUR", date: "2023-01-03", expected: "0.5"},
+		{from: "USD", to: "EUR", date: "2023-01-04", expected: "0.5"},
+		{from: "USD", to: "EUR", date: "2023-01-05", expected: "0.8"},
+		// before the first rate
+		{from: "USD", to: "EUR", date: "2022-12-30", expected: "0.5"},
+		{from: "EUR", to: "USD", date: "2023-01-05", expected: "1.25"},
+		{from: "GBP", to: "EUR", date: "2023-01-05", expected: "2"},
+	}
+
+	for _, c := range cases {
+		rate, err := s.GetRate(ctx, c.from, c.to, date(c.date))
+		if err != nil {
+			t.Errorf("%s%s: expected error to be nil, got %v", c.from, c.to, err)
+			continue
+		}
+
+		if !rate.Equal(decimal.MustParse(c.expected)) {
+			t.Errorf("%s%s on %s: expected %v, got %v", c.from, c.to, c.date, c.expected, rate)
+		}
+	}
+
+	var errCurrency *types.ErrCurrencyNotSupported
+	if _, err := s.GetRate(ctx, "USD", "JPY", date("2023-01-05")); !errors.As(err, &errCurrency) {
+		t.Errorf("expected error to be %T, got %v", errCurrency, err)
+	}
+}
+
+func TestStorageSeeder_FX(t *testing.T) {
+	ctx := context.Background()
+	s := NewSeededFX()
+
+	date, _ := time.Parse("2006-01-02", "2024-03-15")
+
+	for currency, base := range seededFXBase {
+		rate, err := s.GetRate(ctx, "USD", currency, date)
+		if err != nil {
+			t.Errorf("%s: expected error to be nil, got %v", currency, err)
+			continue
+		}
+
+		// deterministic per date
+		again, _ := s.GetRate(ctx, "USD", currency, date)
+		if !rate.Equal(again) {
+			t.Errorf("%s: expected the same rate, got %v and %v", currency, rate, again)
+		}
+
+		if f := rate.Float64(); f < base*0.9 || f > base*1.1 {
+			t.Errorf("%s: expected rate near %v, got %v", currency, base, rate)
+		}
+
+		// the inverse pair multiplies to one
+		inverse, _ := s.GetRate(ctx, currency, "USD", date)
+		if product := rate.Mul(inverse).Float64(); product < 0.999999 || product > 1.000001 {
+			t.Errorf("%s: expected inverse rates, got %v and %v", currency, rate, inverse)
+		}
+	}
+
+	// rates move between dates
+	a, _ := s.GetRate(ctx, "USD", "EUR", date)
+	b, _ := s.GetRate(ctx, "USD", "EUR", date.AddDate(0, 3, 0))
+	if a.Equal(b) {
+		t.Errorf("expected rates to change over time, got %v", a)
+	}
+
+	var errCurrency *types.ErrCurrencyNotSupported
+	if _, err := s.GetRate(ctx, "XYZ", "USD", date); !errors.As(err, &errCurrency) {
+		t.Errorf("expected error to be %T, got %v", errCurrency, err)
+	}
+}
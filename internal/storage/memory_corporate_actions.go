@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"sort"
+)
+
+var _ CorporateActionStorage = (*memoryCorporateActions)(nil)
+
+// NewMemoryCorporateActions is a read only corporate actions store over the given actions
+func NewMemoryCorporateActions(actions []types.CorporateAction) CorporateActionStorage {
+	s := &memoryCorporateActions{
+		actions: map[string][]types.CorporateAction{},
+	}
+
+	for _, a := range actions {
+		s.actions[a.Symbol] = append(s.actions[a.Symbol], a)
+	}
+
+	for _, list := range s.actions {
+		sortCorporateActions(list)
+	}
+
+	return s
+}
+
+type memoryCorporateActions struct {
+	actions map[string][]types.CorporateAction
+}
+
+func (s *memoryCorporateActions) GetCorporateActions(_ context.Context, symbol string) ([]types.CorporateAction, error) {
+	actions := make([]types.CorporateAction, len(s.actions[symbol]))
+	copy(actions, s.actions[symbol])
+
+	return actions, nil
+}
+
+func sortCorporateActions(actions []types.CorporateAction) {
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].ExDate.Before(actions[j].ExDate)
+	})
+}
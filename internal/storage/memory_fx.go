@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"sort"
+	"time"
+)
+
+var _ FXStorage = (*memoryFX)(nil)
+
+// NewMemoryFX is a read only rate store over the given USD rates,
+// a date without a rate uses the closest earlier one, or the earliest known
+func NewMemoryFX(rates []types.FXRate) FXStorage {
+	s := &memoryFX{
+		rates: map[string][]types.FXRate{},
+	}
+
+	for _, r := range rates {
+		s.rates[r.Currency] = append(s.rates[r.Currency], r)
+	}
+
+	for _, list := range s.rates {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Date.Before(list[j].Date)
+		})
+	}
+
+	return s
+}
+
+type memoryFX struct {
+	rates map[string][]types.FXRate
+}
+
+func (s *memoryFX) GetRate(_ context.Context, from string, to string, date time.Time) (decimal.Decimal, error) {
+	fromRate, err := s.usdRate(from, date)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	toRate, err := s.usdRate(to, date)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	return toRate.Div(fromRate, FXRateScale), nil
+}
+
+func (s *memoryFX) usdRate(currency string, date time.Time) (decimal.Decimal, error) {
+	if currency == "USD" {
+		return decimal.NewFromInt(1), nil
+	}
+
+	rates := s.rates[currency]
+	if len(rates) == 0 {
+		return decimal.Decimal{}, &types.ErrCurrencyNotSupported{
+			Currency: currency,
+		}
+	}
+
+	i := sort.Search(len(rates), func(i int) bool {
+		return rates[i].Date.After(date)
+	})
+	if i == 0 {
+		return rates[0].Rate, nil
+	}
+
+	return rates[i-1].Rate, nil
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ WritableStorage = (*memoryStorage)(nil)
+var _ HoldingsLister = (*memoryStorage)(nil)
+var _ WatchlistStorage = (*memoryStorage)(nil)
+
+func NewMemory() WritableStorage {
+	return &memoryStorage{
+		prices:     map[string][]types.TickerHistory{},
+		holdings:   map[string][]string{},
+		watchlists: map[string]map[string][]string{},
+	}
+}
+
+// memoryStorage keeps daily bars, holdings and watchlists in process memory, everything is lost on restart
+//
+// only daily bars are stored, intraday resolutions are always empty
+type memoryStorage struct {
+	mu sync.RWMutex
+
+	// prices are newest first per symbol
+	prices   map[string][]types.TickerHistory
+	holdings map[string][]string
+	// watchlists are the symbols by watchlist name per user
+	watchlists map[string]map[string][]string
+}
+
+func (s *memoryStorage) GetByUser(_ context.Context, username string) ([]types.Ticker, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tickers := make([]types.Ticker, 0, len(s.holdings[username]))
+
+	for _, symbol := range s.holdings[username] {
+		// a holding without prices has no ticker yet
+		prices := s.prices[symbol]
+		if len(prices) == 0 {
+			continue
+		}
+
+		tickers = append(tickers, types.Ticker{
+			Symbol:   symbol,
+			Price:    prices[0].Price,
+			Currency: prices[0].Currency,
+		})
+	}
+
+	return tickers, nil
+}
+
+func (s *memoryStorage) GetHistory(_ context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prices, ok := s.prices[symbol]
+	if !ok {
+		return nil, &types.ErrTickerNotFound{Symbol: symbol}
+	}
+
+	history := make([]types.TickerHistory, 0, len(prices))
+
+	for _, v := range prices {
+		if !before.IsZero() && v.Date.After(before) {
+			continue
+		}
+
+		history = append(history, v)
+	}
+
+	return history, nil
+}
+
+func (s *memoryStorage) GetBars(ctx context.Context, symbol string, resolution types.Resolution, before time.Time) ([]types.TickerHistory, error) {
+	history, err := s.GetHistory(ctx, symbol, before)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolution.Intraday() {
+		return history[:0], nil
+	}
+
+	return history, nil
+}
+
+func (s *memoryStorage) UpsertPrices(_ context.Context, symbol string, prices []types.TickerHistory) (*UpsertResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &UpsertResult{}
+
+	stored := map[int64]int{}
+	for i, v := range s.prices[symbol] {
+		stored[v.Date.UnixNano()] = i
+	}
+
+	for _, v := range prices {
+		v.Symbol = symbol
+
+		if i, ok := stored[v.Date.UnixNano()]; ok {
+			s.prices[symbol][i] = v
+			result.Updated++
+			continue
+		}
+
+		stored[v.Date.UnixNano()] = len(s.prices[symbol])
+		s.prices[symbol] = append(s.prices[symbol], v)
+		result.Inserted++
+	}
+
+	sortHistory(s.prices[symbol])
+
+	return result, nil
+}
+
+func (s *memoryStorage) DeletePrices(_ context.Context, symbol string, from time.Time, to time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prices, ok := s.prices[symbol]
+	if !ok {
+		return 0, nil
+	}
+
+	kept := make([]types.TickerHistory, 0, len(prices))
+	for _, v := range prices {
+		if (from.IsZero() || !v.Date.Before(from)) && (to.IsZero() || !v.Date.After(to)) {
+			continue
+		}
+
+		kept = append(kept, v)
+	}
+
+	deleted := len(prices) - len(kept)
+
+	if len(kept) == 0 {
+		delete(s.prices, symbol)
+	} else {
+		s.prices[symbol] = kept
+	}
+
+	return deleted, nil
+}
+
+func (s *memoryStorage) SetHoldings(_ context.Context, username string, symbols []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(symbols) == 0 {
+		delete(s.holdings, username)
+		return nil
+	}
+
+	s.holdings[username] = uniqueSymbols(symbols)
+
+	return nil
+}
+
+func (s *memoryStorage) ListHoldings(_ context.Context) (map[string][]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	holdings := make(map[string][]string, len(s.holdings))
+	for username, symbols := range s.holdings {
+		holdings[username] = append([]string(nil), symbols...)
+	}
+
+	return holdings, nil
+}
+
+func (s *memoryStorage) SetWatchlist(_ context.Context, username string, name string, symbols []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(symbols) == 0 {
+		delete(s.watchlists[username], name)
+		if len(s.watchlists[username]) == 0 {
+			delete(s.watchlists, username)
+		}
+
+		return nil
+	}
+
+	if s.watchlists[username] == nil {
+		s.watchlists[username] = map[string][]string{}
+	}
+
+	s.watchlists[username][name] = uniqueSymbols(symbols)
+
+	return nil
+}
+
+func (s *memoryStorage) GetWatchlists(_ context.Context, username string) ([]Watchlist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.userWatchlists(username), nil
+}
+
+func (s *memoryStorage) ListWatchlists(_ context.Context) (map[string][]Watchlist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	watchlists := make(map[string][]Watchlist, len(s.watchlists))
+	for username := range s.watchlists {
+		watchlists[username] = s.userWatchlists(username)
+	}
+
+	return watchlists, nil
+}
+
+func (s *memoryStorage) userWatchlists(username string) []Watchlist {
+	watchlists := make([]Watchlist, 0, len(s.watchlists[username]))
+	for name, symbols := range s.watchlists[username] {
+		watchlists = append(watchlists, Watchlist{
+			Name:    name,
+			Symbols: append([]string(nil), symbols...),
+		})
+	}
+
+	sort.Slice(watchlists, func(i, j int) bool {
+		return watchlists[i].Name < watchlists[j].Name
+	})
+
+	return watchlists
+}
+
+// sortHistory sorts newest first
+func sortHistory(history []types.TickerHistory) {
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Date.After(history[j].Date)
+	})
+}
@@ -0,0 +1,127 @@
+//go:build test
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+	"time"
+)
+
+func memoryBar(date string, price string) types.TickerHistory {
+	d, _ := time.Parse("2006-01-02", date)
+	p := decimal.MustParse(price)
+
+	return types.TickerHistory{Date: d, Price: p, Open: p, High: p, Low: p, Currency: "USD"}
+}
+
+func TestMemoryStorage_Prices(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+
+	var errNotFound *types.ErrTickerNotFound
+	if _, err := s.GetHistory(ctx, "AAPL", time.Time{}); !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be ErrTickerNotFound, got %v", err)
+	}
+
+	result, err := s.UpsertPrices(ctx, "AAPL", []types.TickerHistory{
+		memoryBar("2024-01-02", "185.64"),
+		memoryBar("2024-01-04", "181.91"),
+		memoryBar("2024-01-03", "184.25"),
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if result.Inserted != 3 || result.Updated != 0 {
+		t.Errorf("expected 3 inserted, got %+v", result)
+	}
+
+	result, _ = s.UpsertPrices(ctx, "AAPL", []types.TickerHistory{
+		memoryBar("2024-01-04", "181.90"),
+		memoryBar("2024-01-05", "181.18"),
+	})
+	if result.Inserted != 1 || result.Updated != 1 {
+		t.Errorf("expected 1 inserted 1 updated, got %+v", result)
+	}
+
+	history, _ := s.GetHistory(ctx, "AAPL", time.Time{})
+	expected := []string{"2024-01-05 181.18", "2024-01-04 181.90", "2024-01-03 184.25", "2024-01-02 185.64"}
+
+	if len(history) != len(expected) {
+		t.Fatalf("expected %d bars, got %d", len(expected), len(history))
+	}
+	for i, v := range history {
+		if got := v.Date.Format("2006-01-02") + " " + v.Price.String(); got != expected[i] || v.Symbol != "AAPL" {
+			t.Errorf("expected %s of AAPL, got %s of %s", expected[i], got, v.Symbol)
+		}
+	}
+
+	before, _ := time.Parse("2006-01-02", "2024-01-03")
+	if history, _ := s.GetHistory(ctx, "AAPL", before); len(history) != 2 {
+		t.Errorf("expected 2 bars not after 2024-01-03, got %d", len(history))
+	}
+
+	if bars, err := s.GetBars(ctx, "AAPL", types.Resolution5m, time.Time{}); err != nil || len(bars) != 0 {
+		t.Errorf("expected no intraday bars, got %v %v", bars, err)
+	}
+
+	// from 2024-01-03 to 2024-01-04 included
+	from, _ := time.Parse("2006-01-02", "2024-01-03")
+	to, _ := time.Parse("2006-01-02", "2024-01-04")
+
+	deleted, _ := s.DeletePrices(ctx, "AAPL", from, to)
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted, got %d", deleted)
+	}
+
+	deleted, _ = s.DeletePrices(ctx, "AAPL", time.Time{}, time.Time{})
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted, got %d", deleted)
+	}
+	if _, err := s.GetHistory(ctx, "AAPL", time.Time{}); !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be ErrTickerNotFound once empty, got %v", err)
+	}
+}
+
+func TestMemoryStorage_Holdings(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+
+	_, _ = s.UpsertPrices(ctx, "AAPL", []types.TickerHistory{memoryBar("2024-01-02", "185.64"), memoryBar("2024-01-03", "184.25")})
+	_, _ = s.UpsertPrices(ctx, "MSFT", []types.TickerHistory{memoryBar("2024-01-02", "370.87")})
+
+	if err := s.SetHoldings(ctx, "test", []string{"MSFT", "AAPL", "MSFT", "NFLX"}); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	tickers, _ := s.GetByUser(ctx, "test")
+
+	// in order, without duplicates nor symbols without prices
+	if len(tickers) != 2 || tickers[0].Symbol != "MSFT" || tickers[1].Symbol != "AAPL" {
+		t.Fatalf("expected MSFT and AAPL, got %+v", tickers)
+	}
+	if !tickers[1].Price.Equal(decimal.MustParse("184.25")) || tickers[1].Currency != "USD" {
+		t.Errorf("expected the latest AAPL price 184.25 USD, got %+v", tickers[1])
+	}
+
+	if tickers, _ := s.GetByUser(ctx, "other"); tickers == nil || len(tickers) != 0 {
+		t.Errorf("expected no tickers for other, got %v", tickers)
+	}
+
+	// holdings without prices are listed too
+	holdings, _ := s.(HoldingsLister).ListHoldings(ctx)
+	if len(holdings) != 1 || len(holdings["test"]) != 3 || holdings["test"][2] != "NFLX" {
+		t.Errorf("expected MSFT, AAPL and NFLX held by test, got %v", holdings)
+	}
+
+	_ = s.SetHoldings(ctx, "test", nil)
+	if tickers, _ := s.GetByUser(ctx, "test"); len(tickers) != 0 {
+		t.Errorf("expected holdings to be cleared, got %v", tickers)
+	}
+	if holdings, _ := s.(HoldingsLister).ListHoldings(ctx); len(holdings) != 0 {
+		t.Errorf("expected no holdings listed, got %v", holdings)
+	}
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"sort"
+	"time"
+)
+
+var _ SymbolStorage = (*memorySymbols)(nil)
+
+//go:embed data/symbols.json
+var symbolsData []byte
+
+// NewEmbeddedSymbols is the symbol master shipped with the binary in ./data/symbols.json
+func NewEmbeddedSymbols() SymbolStorage {
+	symbols, err := parseSymbols(symbolsData)
+	if err != nil {
+		panic(fmt.Sprintf("storage: data/symbols.json: %s", err))
+	}
+
+	return NewMemorySymbols(symbols)
+}
+
+// NewMemorySymbols is a read only symbol master over the given symbols
+func NewMemorySymbols(symbols []types.Symbol) SymbolStorage {
+	s := &memorySymbols{
+		symbols: make([]types.Symbol, len(symbols)),
+		index:   make(map[string]int, len(symbols)),
+		history: map[string][]int{},
+	}
+
+	copy(s.symbols, symbols)
+	sort.Slice(s.symbols, func(i, j int) bool {
+		return s.symbols[i].Symbol < s.symbols[j].Symbol
+	})
+
+	for i, v := range s.symbols {
+		if len(v.Listings) == 0 {
+			// never renamed, a single listing under the current symbol
+			s.symbols[i].Listings = []types.Listing{
+				{Symbol: v.Symbol, From: v.Listed, To: v.Delisted},
+			}
+		}
+
+		s.index[v.Symbol] = i
+
+		for _, l := range s.symbols[i].Listings {
+			h := s.history[l.Symbol]
+			if len(h) == 0 || h[len(h)-1] != i {
+				s.history[l.Symbol] = append(h, i)
+			}
+		}
+	}
+
+	return s
+}
+
+// memorySymbols is never written after creation, no locking required
+type memorySymbols struct {
+	symbols []types.Symbol
+	// index is the current symbol of each instrument
+	index map[string]int
+	// history is every instrument that ever traded under a symbol
+	history map[string][]int
+}
+
+func (s *memorySymbols) ListSymbols(_ context.Context) ([]types.Symbol, error) {
+	symbols := make([]types.Symbol, 0, len(s.symbols))
+	for _, v := range s.symbols {
+		symbols = append(symbols, copySymbol(v))
+	}
+
+	return symbols, nil
+}
+
+func (s *memorySymbols) GetSymbol(_ context.Context, symbol string) (*types.Symbol, error) {
+	i, ok := s.index[symbol]
+	if !ok {
+		return nil, &types.ErrSymbolNotFound{
+			Symbol: symbol,
+		}
+	}
+
+	v := copySymbol(s.symbols[i])
+
+	return &v, nil
+}
+
+func (s *memorySymbols) ResolveSymbol(_ context.Context, symbol string) ([]types.Symbol, error) {
+	h, ok := s.history[symbol]
+	if !ok {
+		return nil, &types.ErrSymbolNotFound{
+			Symbol: symbol,
+		}
+	}
+
+	symbols := make([]types.Symbol, 0, len(h))
+	for _, i := range h {
+		symbols = append(symbols, copySymbol(s.symbols[i]))
+	}
+
+	return symbols, nil
+}
+
+func copySymbol(v types.Symbol) types.Symbol {
+	listings := make([]types.Listing, len(v.Listings))
+	copy(listings, v.Listings)
+	v.Listings = listings
+
+	return v
+}
+
+type symbolRecord struct {
+	ID       string `json:"id"`
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Exchange string `json:"exchange"`
+	Sector   string `json:"sector"`
+	Industry string `json:"industry"`
+	Currency string `json:"currency"`
+	ISIN     string `json:"isin"`
+	FIGI     string `json:"figi"`
+	Listed   string `json:"listed"`
+	Delisted string `json:"delisted"`
+
+	// Previous are the symbols before renames, oldest first
+	Previous []struct {
+		Symbol string `json:"symbol"`
+		From   string `json:"from"`
+		To     string `json:"to"`
+	} `json:"previous"`
+}
+
+func parseSymbols(b []byte) ([]types.Symbol, error) {
+	var records []symbolRecord
+
+	err := json.Unmarshal(b, &records)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]types.Symbol, 0, len(records))
+
+	for _, r := range records {
+		v := types.Symbol{
+			InstrumentID: r.ID,
+			Symbol:       r.Symbol,
+			Name:         r.Name,
+			Exchange:     r.Exchange,
+			Sector:       r.Sector,
+			Industry:     r.Industry,
+			Currency:     r.Currency,
+			ISIN:         r.ISIN,
+			FIGI:         r.FIGI,
+		}
+
+		if r.Listed != "" {
+			v.Listed, err = time.Parse("2006-01-02", r.Listed)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", r.Symbol, err)
+			}
+		}
+		if r.Delisted != "" {
+			v.Delisted, err = time.Parse("2006-01-02", r.Delisted)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", r.Symbol, err)
+			}
+		}
+
+		if len(r.Previous) > 0 {
+			for _, p := range r.Previous {
+				l := types.Listing{Symbol: p.Symbol}
+
+				if l.From, err = time.Parse("2006-01-02", p.From); err != nil {
+					return nil, fmt.Errorf("%s: %w", r.Symbol, err)
+				}
+				if l.To, err = time.Parse("2006-01-02", p.To); err != nil {
+					return nil, fmt.Errorf("%s: %w", r.Symbol, err)
+				}
+
+				v.Listings = append(v.Listings, l)
+			}
+
+			// the current symbol starts where the last rename ends
+			v.Listings = append(v.Listings, types.Listing{
+				Symbol: v.Symbol,
+				From:   v.Listings[len(v.Listings)-1].To,
+				To:     v.Delisted,
+			})
+		}
+
+		symbols = append(symbols, v)
+	}
+
+	return symbols, nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"testing"
+)
+
+func TestEmbeddedSymbols(t *testing.T) {
+	ctx := context.Background()
+	s := NewEmbeddedSymbols()
+
+	symbols, err := s.ListSymbols(ctx)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	seen := map[string]bool{}
+	ids := map[string]bool{}
+	for _, v := range symbols {
+		if seen[v.Symbol] {
+			t.Errorf("expected symbol %s to be unique", v.Symbol)
+		}
+		seen[v.Symbol] = true
+
+		if v.InstrumentID == "" || ids[v.InstrumentID] {
+			t.Errorf("expected instrument id of %s to be set and unique, got %q", v.Symbol, v.InstrumentID)
+		}
+		ids[v.InstrumentID] = true
+
+		if v.Name == "" || v.Exchange == "" || v.Currency == "" || v.ISIN == "" || v.Listed.IsZero() {
+			t.Errorf("expected %s to have name, exchange, currency, isin and listing date, got %+v", v.Symbol, v)
+		}
+	}
+
+	if seen["FB"] {
+		t.Errorf("expected FB not to be a symbol")
+	}
+
+	meta, err := s.GetSymbol(ctx, "META")
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+	if meta.ISIN != "US30303M1027" || meta.Exchange != "NASDAQ" || meta.Currency != "USD" {
+		t.Errorf("unexpected META reference data %+v", meta)
+	}
+
+	twtr, _ := s.GetSymbol(ctx, "TWTR")
+	if twtr == nil || twtr.Delisted.Format("2006-01-02") != "2022-11-08" {
+		t.Errorf("expected TWTR to be delisted on 2022-11-08, got %+v", twtr)
+	}
+}
+
+func TestEmbeddedSymbols_NotFound(t *testing.T) {
+	_, err := NewEmbeddedSymbols().GetSymbol(context.Background(), "INVALID")
+
+	var errNotFound *types.ErrSymbolNotFound
+	if !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be %T, got %T", errNotFound, err)
+	} else if errNotFound.Symbol != "INVALID" {
+		t.Errorf("expected symbol to be INVALID, got %s", errNotFound.Symbol)
+	}
+}
+
+func TestParseSymbols_InvalidDate(t *testing.T) {
+	_, err := parseSymbols([]byte(`[{"symbol": "X", "listed": "yesterday"}]`))
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestEmbeddedSymbols_Resolve(t *testing.T) {
+	ctx := context.Background()
+	s := NewEmbeddedSymbols()
+
+	// FB is not a current symbol but resolves to the renamed instrument
+	if _, err := s.GetSymbol(ctx, "FB"); err == nil {
+		t.Errorf("expected FB not to be a current symbol")
+	}
+
+	symbols, err := s.ResolveSymbol(ctx, "FB")
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if len(symbols) != 1 || symbols[0].Symbol != "META" {
+		t.Errorf("expected FB to resolve to META, got %+v", symbols)
+		return
+	}
+
+	listings := symbols[0].Listings
+	if len(listings) != 2 || listings[0].Symbol != "FB" || listings[1].Symbol != "META" {
+		t.Errorf("expected FB then META listings, got %+v", listings)
+	} else if listings[0].To != listings[1].From || !listings[1].To.IsZero() {
+		t.Errorf("expected contiguous listings, got %+v", listings)
+	}
+
+	// a symbol never renamed has a single listing
+	aapl, _ := s.GetSymbol(ctx, "AAPL")
+	if len(aapl.Listings) != 1 || aapl.Listings[0].From != aapl.Listed || aapl.InstrumentID == "" {
+		t.Errorf("expected a single AAPL listing since %v, got %+v", aapl.Listed, aapl.Listings)
+	}
+
+	_, err = s.ResolveSymbol(ctx, "INVALID")
+	var errNotFound *types.ErrSymbolNotFound
+	if !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be %T, got %T", errNotFound, err)
+	}
+}
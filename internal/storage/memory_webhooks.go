@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"sort"
+	"sync"
+)
+
+var _ WebhookStorage = (*memoryWebhooks)(nil)
+
+func NewMemoryWebhooks() WebhookStorage {
+	return &memoryWebhooks{
+		subscriptions: map[string]types.Subscription{},
+		deliveries:    map[string]types.Delivery{},
+	}
+}
+
+// memoryWebhooks keeps subscriptions and the delivery log in process memory,
+// everything is lost on restart
+type memoryWebhooks struct {
+	mu sync.RWMutex
+
+	subscriptions map[string]types.Subscription
+	deliveries    map[string]types.Delivery
+}
+
+func (s *memoryWebhooks) CreateSubscription(_ context.Context, sub *types.Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions[sub.ID] = copySubscription(*sub)
+
+	return nil
+}
+
+func (s *memoryWebhooks) GetSubscription(_ context.Context, id string) (*types.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return nil, &types.ErrSubscriptionNotFound{ID: id}
+	}
+
+	sub = copySubscription(sub)
+
+	return &sub, nil
+}
+
+func (s *memoryWebhooks) ListSubscriptions(_ context.Context, username string) ([]types.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subs := make([]types.Subscription, 0)
+
+	for _, sub := range s.subscriptions {
+		if sub.Username == username {
+			subs = append(subs, copySubscription(sub))
+		}
+	}
+
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+	})
+
+	return subs, nil
+}
+
+func (s *memoryWebhooks) DeleteSubscription(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscriptions[id]; !ok {
+		return &types.ErrSubscriptionNotFound{ID: id}
+	}
+
+	delete(s.subscriptions, id)
+
+	// the delivery log goes away with its subscription
+	for k, d := range s.deliveries {
+		if d.SubscriptionID == id {
+			delete(s.deliveries, k)
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryWebhooks) SaveDelivery(_ context.Context, delivery *types.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the delivery log was purged with its subscription
+	if _, ok := s.subscriptions[delivery.SubscriptionID]; !ok {
+		return nil
+	}
+
+	s.deliveries[delivery.ID] = copyDelivery(*delivery)
+
+	return nil
+}
+
+func (s *memoryWebhooks) GetDelivery(_ context.Context, id string) (*types.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	d, ok := s.deliveries[id]
+	if !ok {
+		return nil, &types.ErrDeliveryNotFound{ID: id}
+	}
+
+	d = copyDelivery(d)
+
+	return &d, nil
+}
+
+func (s *memoryWebhooks) ListDeliveries(_ context.Context, subscriptionID string) ([]types.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := make([]types.Delivery, 0)
+
+	for _, d := range s.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, copyDelivery(d))
+		}
+	}
+
+	// newest first, same as history
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+
+	return deliveries, nil
+}
+
+func copySubscription(sub types.Subscription) types.Subscription {
+	sub.Events = append([]string(nil), sub.Events...)
+
+	return sub
+}
+
+func copyDelivery(d types.Delivery) types.Delivery {
+	d.Attempts = append([]types.DeliveryAttempt(nil), d.Attempts...)
+
+	return d
+}
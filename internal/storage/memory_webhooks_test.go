@@ -0,0 +1,38 @@
+//go:build test
+
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"testing"
+)
+
+func TestMemoryWebhooks_SaveDelivery_Deleted(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryWebhooks()
+
+	_ = s.CreateSubscription(ctx, &types.Subscription{ID: "sub", Username: "test"})
+
+	delivery := &types.Delivery{ID: "delivery", SubscriptionID: "sub", Status: types.DeliveryPending}
+	if err := s.SaveDelivery(ctx, delivery); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if err := s.DeleteSubscription(ctx, "sub"); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	// an attempt in flight during the delete
+	delivery.Status = types.DeliveryRetrying
+	if err := s.SaveDelivery(ctx, delivery); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if _, err := s.GetDelivery(ctx, "delivery"); err == nil {
+		t.Errorf("expected no delivery of a deleted subscription")
+	}
+	if deliveries, _ := s.ListDeliveries(ctx, "sub"); len(deliveries) != 0 {
+		t.Errorf("expected no deliveries, got %d", len(deliveries))
+	}
+}
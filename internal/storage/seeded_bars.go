@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"math"
+	"time"
+)
+
+// maxIntradayBars bounds intraday history, sessions are walked back until it is reached
+const maxIntradayBars = 1000
+
+// minutesPerSession scales the annualized volatility to a minute of a regular session
+const minutesPerSession = 390
+
+// seededBar is an OHLCV bar in cents
+type seededBar struct {
+	start  time.Time
+	open   int64
+	high   int64
+	low    int64
+	close  int64
+	volume int64
+}
+
+func (b seededBar) history(date time.Time, currency string) types.TickerHistory {
+	return types.TickerHistory{
+		Date:     date,
+		Price:    decimal.New(b.close, 2),
+		Open:     decimal.New(b.open, 2),
+		High:     decimal.New(b.high, 2),
+		Low:      decimal.New(b.low, 2),
+		Volume:   b.volume,
+		Currency: currency,
+	}
+}
+
+// minuteBars of the session of day, the first opens at the open of day and the last closes at its close
+//
+// the path is a brownian bridge in log price: a random walk pinned to both ends,
+// so every coarser resolution rolls up to the same daily bar
+func (w *seededWalk) minuteBars(day seededDay) []seededBar {
+	open, close, ok := w.cal.Session(day.date)
+	if !ok {
+		return nil
+	}
+
+	n := int(close.Sub(open) / time.Minute)
+	if n <= 0 {
+		return nil
+	}
+
+	rnd := getRandForString(w.symbol + ":" + day.date.Format("2006-01-02"))
+	sigma := w.volatility / math.Sqrt(tradingDaysPerYear*minutesPerSession)
+
+	walk := make([]float64, n+1)
+	for k := 1; k <= n; k++ {
+		walk[k] = walk[k-1] + sigma*rnd.NormFloat64()
+	}
+
+	openCents := day.open.Round(2).Units()
+	closeCents := day.close.Round(2).Units()
+	from := math.Log(float64(openCents))
+	to := math.Log(float64(closeCents))
+
+	bars := make([]seededBar, n)
+	prev := openCents
+
+	for k := 1; k <= n; k++ {
+		t := float64(k) / float64(n)
+
+		c := closeCents
+		if k < n {
+			c = centsOf(math.Exp(from + t*(to-from) + walk[k] - t*walk[n]))
+		}
+
+		b := seededBar{
+			start: open.Add(time.Duration(k-1) * time.Minute),
+			open:  prev,
+			high:  prev,
+			low:   prev,
+			close: c,
+		}
+
+		if c > b.high {
+			b.high = c
+		}
+		if c < b.low {
+			b.low = c
+		}
+
+		// wicks beyond the open and close
+		b.high += int64(math.Round(math.Abs(rnd.NormFloat64()) * sigma * float64(b.high) / 2))
+		if wick := int64(math.Round(math.Abs(rnd.NormFloat64()) * sigma * float64(b.low) / 2)); b.low-wick >= 1 {
+			b.low -= wick
+		}
+
+		// busier around the open and the close
+		b.volume = int64(float64(w.volume) * (0.5 + rnd.Float64()) * (1 + 3*(2*t-1)*(2*t-1)))
+
+		bars[k-1] = b
+		prev = c
+	}
+
+	return bars
+}
+
+// dailyBar is the roll up of the session of day, its close is the close of the walk
+func (w *seededWalk) dailyBar(day seededDay) seededBar {
+	bars := w.minuteBars(day)
+	if len(bars) > 0 {
+		return rollupBars(bars)
+	}
+
+	// no session hours, a bar from the open to the close
+	b := seededBar{
+		open:  day.open.Round(2).Units(),
+		close: day.close.Round(2).Units(),
+	}
+	b.high, b.low = b.open, b.close
+	if b.close > b.high {
+		b.high, b.low = b.close, b.open
+	}
+
+	return b
+}
+
+// intradayBars of resolution newest first, starting not after before when set,
+// only minutes that have ended by now are part of the bars
+func (w *seededWalk) intradayBars(resolution types.Resolution, before time.Time) []types.TickerHistory {
+	history := make([]types.TickerHistory, 0)
+
+	for _, day := range w.days {
+		if len(history) >= maxIntradayBars {
+			break
+		}
+
+		bars := groupBars(minuteBarsUntil(w.minuteBars(day), w.now), resolution.Duration())
+
+		for i := len(bars) - 1; i >= 0 && len(history) < maxIntradayBars; i-- {
+			if !before.IsZero() && bars[i].start.After(before) {
+				continue
+			}
+
+			history = append(history, bars[i].history(bars[i].start, w.currency))
+		}
+	}
+
+	return history
+}
+
+// minuteBarsUntil drops the minute bars sorted oldest first that have not ended at now,
+// all of them when the session has not opened yet
+func minuteBarsUntil(bars []seededBar, now time.Time) []seededBar {
+	n := len(bars)
+	for n > 0 && bars[n-1].start.Add(time.Minute).After(now) {
+		n--
+	}
+
+	return bars[:n]
+}
+
+// groupBars rolls up consecutive bars sorted oldest first into bars of size aligned to the first start
+func groupBars(bars []seededBar, size time.Duration) []seededBar {
+	if len(bars) == 0 {
+		return nil
+	}
+
+	first := bars[0].start
+	grouped := make([]seededBar, 0, len(bars))
+
+	for start := 0; start < len(bars); {
+		bucket := bars[start].start.Sub(first) / size
+
+		end := start + 1
+		for end < len(bars) && bars[end].start.Sub(first)/size == bucket {
+			end++
+		}
+
+		b := rollupBars(bars[start:end])
+		b.start = first.Add(bucket * size)
+
+		grouped = append(grouped, b)
+		start = end
+	}
+
+	return grouped
+}
+
+// rollupBars merges bars sorted oldest first into one
+func rollupBars(bars []seededBar) seededBar {
+	if len(bars) == 0 {
+		return seededBar{}
+	}
+
+	b := bars[0]
+
+	for _, v := range bars[1:] {
+		if v.high > b.high {
+			b.high = v.high
+		}
+		if v.low < b.low {
+			b.low = v.low
+		}
+
+		b.close = v.close
+		b.volume += v.volume
+	}
+
+	return b
+}
+
+func centsOf(price float64) int64 {
+	cents := int64(math.Round(price))
+	if cents < 1 {
+		return 1
+	}
+
+	return cents
+}
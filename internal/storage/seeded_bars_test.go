@@ -0,0 +1,192 @@
+//go:build test
+
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/calendar"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+	"time"
+)
+
+func TestStorageSeeder_Bars_Rollup(t *testing.T) {
+	ctx := context.Background()
+	s := NewSeeded(&SeededConfig{})
+
+	for _, symbol := range []string{"AAPL", "AZN"} {
+		daily, err := s.GetHistory(ctx, symbol, time.Time{})
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+			return
+		}
+
+		days := map[string]types.TickerHistory{}
+		for _, v := range daily {
+			if v.Low.Cmp(v.Open) > 0 || v.Low.Cmp(v.Price) > 0 || v.High.Cmp(v.Open) < 0 || v.High.Cmp(v.Price) < 0 {
+				t.Errorf("%s: expected low <= open, close <= high, got %+v", symbol, v)
+			}
+
+			days[v.Date.Format("2006-01-02")] = v
+		}
+
+		for _, resolution := range []types.Resolution{types.Resolution1m, types.Resolution5m, types.Resolution15m, types.Resolution1h} {
+			bars, err := s.(*seededStorage).GetBars(ctx, symbol, resolution, time.Time{})
+			if err != nil {
+				t.Errorf("expected error to be nil, got %v", err)
+				return
+			}
+
+			if len(bars) == 0 || len(bars) > maxIntradayBars {
+				t.Errorf("%s %s: expected 1 to %d bars, got %d", symbol, resolution, maxIntradayBars, len(bars))
+				continue
+			}
+
+			// bars are newest first, roll them up per session oldest first
+			rolled := map[string]*types.TickerHistory{}
+			var order []string
+
+			for i := len(bars) - 1; i >= 0; i-- {
+				v := bars[i]
+				if i > 0 && !bars[i-1].Date.After(v.Date) {
+					t.Errorf("%s %s: expected bars newest first, got %v before %v", symbol, resolution, bars[i-1].Date, v.Date)
+				}
+
+				day := v.Date.Format("2006-01-02")
+				r, ok := rolled[day]
+				if !ok {
+					copied := v
+					rolled[day] = &copied
+					order = append(order, day)
+					continue
+				}
+
+				if v.High.Cmp(r.High) > 0 {
+					r.High = v.High
+				}
+				if v.Low.Cmp(r.Low) < 0 {
+					r.Low = v.Low
+				}
+				r.Price = v.Price
+				r.Volume += v.Volume
+			}
+
+			// the oldest session may be cut by the bar limit, the current one by now
+			for _, day := range order[1:] {
+				r, d := rolled[day], days[day]
+				if _, close, _ := seededCalendar(symbolExchange(t, symbol)).Session(d.Date); close.After(time.Now()) {
+					continue
+				}
+
+				if !r.Open.Equal(d.Open) || !r.High.Equal(d.High) || !r.Low.Equal(d.Low) || !r.Price.Equal(d.Price) || r.Volume != d.Volume {
+					t.Errorf("%s %s on %s: expected bars to roll up to %v %v %v %v %d, got %v %v %v %v %d", symbol, resolution, day,
+						d.Open, d.High, d.Low, d.Price, d.Volume, r.Open, r.High, r.Low, r.Price, r.Volume)
+				}
+			}
+		}
+	}
+}
+
+func TestStorageSeeder_Bars_TradingHours(t *testing.T) {
+	ctx := context.Background()
+	s := NewSeeded(&SeededConfig{}).(*seededStorage)
+	cal, _ := calendar.Get("NYSE")
+
+	for _, resolution := range []types.Resolution{types.Resolution1m, types.Resolution1h} {
+		bars, _ := s.GetBars(ctx, "MSFT", resolution, time.Time{})
+
+		for _, v := range bars {
+			open, close, ok := cal.Session(v.Date.In(cal.Location))
+			if !ok {
+				t.Errorf("%s: expected bar on a trading day, got %v", resolution, v.Date)
+				continue
+			}
+
+			if v.Date.Before(open) || !v.Date.Before(close) {
+				t.Errorf("%s: expected bar within %v and %v, got %v", resolution, open, close, v.Date)
+			}
+			if v.Date.Sub(open)%resolution.Duration() != 0 {
+				t.Errorf("%s: expected bar aligned to the open, got %v", resolution, v.Date)
+			}
+		}
+	}
+}
+
+func TestStorageSeeder_Bars_Before(t *testing.T) {
+	ctx := context.Background()
+	s := NewSeeded(&SeededConfig{}).(*seededStorage)
+
+	bars, _ := s.GetBars(ctx, "MSFT", types.Resolution5m, time.Time{})
+	if len(bars) < 10 {
+		t.Fatalf("expected at least 10 bars, got %d", len(bars))
+	}
+
+	// deterministic
+	again, _ := s.GetBars(ctx, "MSFT", types.Resolution5m, time.Time{})
+	for i := range bars {
+		if !bars[i].Price.Equal(again[i].Price) || bars[i].Volume != again[i].Volume {
+			t.Errorf("expected the same bars, got %+v and %+v", bars[i], again[i])
+			break
+		}
+	}
+
+	before := bars[5].Date
+	filtered, _ := s.GetBars(ctx, "MSFT", types.Resolution5m, before)
+
+	if len(filtered) == 0 || !filtered[0].Date.Equal(before) {
+		t.Errorf("expected the first bar at %v, got %v", before, filtered)
+	}
+}
+
+func TestStorageSeeder_Bars_Now(t *testing.T) {
+	ctx := context.Background()
+	s := NewSeeded(&SeededConfig{}).(*seededStorage)
+
+	w, err := s.walk(ctx, "MSFT", time.Time{})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	open, _, ok := w.cal.Session(w.days[0].date)
+	if !ok {
+		t.Fatalf("expected a session on %v", w.days[0].date)
+	}
+
+	// 90 minutes and a half into the session, the minute in progress is not a bar yet
+	w.now = open.Add(time.Minute*90 + time.Second*30)
+
+	bars := w.intradayBars(types.Resolution1m, time.Time{})
+	if len(bars) == 0 || !bars[0].Date.Equal(open.Add(time.Minute*89)) {
+		t.Errorf("expected the last bar at %v, got %v", open.Add(time.Minute*89), bars)
+	}
+
+	// the hour in progress rolls up the minutes ended so far
+	bars = w.intradayBars(types.Resolution1h, time.Time{})
+	if len(bars) == 0 || !bars[0].Date.Equal(open.Add(time.Hour)) {
+		t.Errorf("expected the last bar at %v, got %v", open.Add(time.Hour), bars)
+	}
+
+	minutes := minuteBarsUntil(w.minuteBars(w.days[0]), w.now)
+	if b := rollupBars(minutes[60:]); len(bars) > 0 && (bars[0].Price.Units() != b.close || bars[0].Volume != b.volume) {
+		t.Errorf("expected the hour to close at %d with volume %d, got %v", b.close, b.volume, bars[0])
+	}
+
+	// before the open, nothing of the session
+	w.now = open.Add(-time.Minute)
+
+	bars = w.intradayBars(types.Resolution1m, time.Time{})
+	for _, v := range bars {
+		if !v.Date.Before(w.days[0].date) {
+			t.Fatalf("expected no bars of a session not opened yet, got %v", v.Date)
+		}
+	}
+}
+
+func symbolExchange(t *testing.T, symbol string) string {
+	v, err := NewEmbeddedSymbols().GetSymbol(context.Background(), symbol)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	return v.Exchange
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"math"
+	"time"
+)
+
+var _ CorporateActionStorage = (*seededStorage)(nil)
+
+//go:embed data/splits.json
+var splitsData []byte
+
+// embeddedSplits are the historical splits shipped with the binary in ./data/splits.json
+var embeddedSplits = func() map[string][]types.CorporateAction {
+	splits, err := parseSplits(splitsData)
+	if err != nil {
+		panic(fmt.Sprintf("storage: data/splits.json: %s", err))
+	}
+
+	return splits
+}()
+
+// NewEmbeddedSplits is a read only store of the historical splits in ./data/splits.json,
+// without the dividends generated for the seeded prices
+func NewEmbeddedSplits() CorporateActionStorage {
+	var actions []types.CorporateAction
+	for _, splits := range embeddedSplits {
+		actions = append(actions, splits...)
+	}
+
+	return NewMemoryCorporateActions(actions)
+}
+
+func (s *seededStorage) GetCorporateActions(ctx context.Context, symbol string) ([]types.CorporateAction, error) {
+	actions := make([]types.CorporateAction, 0, len(embeddedSplits[symbol]))
+	actions = append(actions, embeddedSplits[symbol]...)
+
+	// previous and delisted symbols have no generated prices to pay dividends on
+	if isValidSymbol(ctx, s.symbols, symbol, time.Time{}) {
+		listed, err := listedSymbols(ctx, s.symbols)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, v := range generatedTickers(getRandForString("tickers"), listed) {
+			if v.Symbol == symbol {
+				actions = append(actions, seededDividends(symbol, listed[i].Exchange, v.Price)...)
+				break
+			}
+		}
+	}
+
+	sortCorporateActions(actions)
+
+	return actions, nil
+}
+
+// seededDividends are quarterly cash dividends over the last two years,
+// roughly 60% of symbols pay with a yield between 0.5% and 3% of price
+func seededDividends(symbol string, exchange string, price decimal.Decimal) []types.CorporateAction {
+	rnd := getRandForString("dividends:" + symbol)
+	if rnd.Float64() >= 0.6 {
+		return nil
+	}
+
+	yield := 0.005 + rnd.Float64()*0.025
+	amount := decimal.NewFromFloat(math.Max(0.01, price.Float64()*yield/4), 2)
+	// first month of the quarter and day of the month the dividend goes ex
+	month := 1 + rnd.Intn(3)
+	day := 1 + rnd.Intn(20)
+
+	cal := seededCalendar(exchange)
+	last := cal.PreviousTradingDay(time.Now().Truncate(time.Hour * 24))
+	first := last.AddDate(-2, 0, 0)
+
+	var dividends []types.CorporateAction
+
+	for year := first.Year(); year <= last.Year(); year++ {
+		for m := month; m <= 12; m += 3 {
+			exDate := cal.NextTradingDay(time.Date(year, time.Month(m), day, 0, 0, 0, 0, last.Location()))
+			if exDate.Before(first) || exDate.After(last) {
+				continue
+			}
+
+			dividends = append(dividends, types.CorporateAction{
+				Symbol: symbol,
+				Type:   types.CorporateActionDividend,
+				ExDate: exDate,
+				Amount: amount,
+			})
+		}
+	}
+
+	return dividends
+}
+
+func parseSplits(data []byte) (map[string][]types.CorporateAction, error) {
+	var records []struct {
+		Symbol string          `json:"symbol"`
+		ExDate string          `json:"ex_date"`
+		Ratio  decimal.Decimal `json:"ratio"`
+	}
+
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	splits := map[string][]types.CorporateAction{}
+
+	for _, r := range records {
+		exDate, err := time.Parse("2006-01-02", r.ExDate)
+		if err != nil {
+			return nil, fmt.Errorf("%s: ex_date: %w", r.Symbol, err)
+		}
+		if r.Ratio.Sign() <= 0 {
+			return nil, fmt.Errorf("%s: ratio must be positive", r.Symbol)
+		}
+
+		splits[r.Symbol] = append(splits[r.Symbol], types.CorporateAction{
+			Symbol: r.Symbol,
+			Type:   types.CorporateActionSplit,
+			ExDate: exDate,
+			Ratio:  r.Ratio,
+		})
+	}
+
+	return splits, nil
+}
@@ -0,0 +1,139 @@
+//go:build test
+
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/calendar"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+	"time"
+)
+
+func TestStorageSeeder_CorporateActions_Splits(t *testing.T) {
+	s := NewSeededCorporateActions(&SeededConfig{})
+
+	actions, err := s.GetCorporateActions(context.Background(), "AAPL")
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	var splits []types.CorporateAction
+	for _, a := range actions {
+		if a.Type == types.CorporateActionSplit {
+			splits = append(splits, a)
+		}
+	}
+
+	expected := []string{"2014-06-09 7", "2020-08-31 4"}
+	if len(splits) != len(expected) {
+		t.Errorf("expected %d AAPL splits, got %d", len(expected), len(splits))
+		return
+	}
+
+	for i, v := range splits {
+		if got := v.ExDate.Format("2006-01-02") + " " + v.Ratio.String(); got != expected[i] {
+			t.Errorf("expected split %s, got %s", expected[i], got)
+		}
+	}
+
+	// previous symbols keep the embedded splits only
+	actions, err = s.GetCorporateActions(context.Background(), "INVALID")
+	if err != nil || len(actions) != 0 {
+		t.Errorf("expected no actions for INVALID, got %v %v", actions, err)
+	}
+}
+
+func TestStorageSeeder_CorporateActions_Dividends(t *testing.T) {
+	ctx := context.Background()
+	s := NewSeededCorporateActions(&SeededConfig{})
+
+	symbols := NewEmbeddedSymbols()
+	listed, _ := listedSymbols(ctx, symbols)
+
+	var payers int
+
+	for _, symbol := range listed {
+		cal, _ := calendar.Get(symbol.Exchange)
+		last := cal.PreviousTradingDay(time.Now().Truncate(time.Hour * 24))
+
+		actions, err := s.GetCorporateActions(ctx, symbol.Symbol)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+			return
+		}
+
+		again, _ := s.GetCorporateActions(ctx, symbol.Symbol)
+		if len(again) != len(actions) {
+			t.Errorf("expected %s actions to be deterministic", symbol.Symbol)
+		}
+
+		var dividends int
+
+		for i, a := range actions {
+			if i > 0 && a.ExDate.Before(actions[i-1].ExDate) {
+				t.Errorf("expected %s actions sorted by ex-date, got %v after %v", symbol.Symbol, a.ExDate, actions[i-1].ExDate)
+			}
+
+			if a.Type != types.CorporateActionDividend {
+				continue
+			}
+			dividends++
+
+			if a.Amount.Sign() <= 0 {
+				t.Errorf("expected %s dividend to be above 0, got %v", symbol.Symbol, a.Amount)
+			}
+			if !cal.IsTradingDay(a.ExDate) {
+				t.Errorf("expected %s ex-date to be a trading day, got %v", symbol.Symbol, a.ExDate)
+			}
+			if a.ExDate.After(last) || a.ExDate.Before(last.AddDate(-2, 0, 0)) {
+				t.Errorf("expected %s ex-date within the last two years, got %v", symbol.Symbol, a.ExDate)
+			}
+		}
+
+		if dividends > 0 {
+			payers++
+
+			// quarterly for two years
+			if dividends < 7 || dividends > 9 {
+				t.Errorf("expected %s to pay quarterly dividends, got %d", symbol.Symbol, dividends)
+			}
+		}
+	}
+
+	if payers == 0 || payers == len(listed) {
+		t.Errorf("expected some symbols to pay dividends, got %d of %d", payers, len(listed))
+	}
+}
+
+func TestStorageMemory_CorporateActions(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	s := NewMemoryCorporateActions([]types.CorporateAction{
+		{Symbol: "AAPL", Type: types.CorporateActionSplit, ExDate: date("2020-08-31"), Ratio: decimal.MustParse("4")},
+		{Symbol: "MSFT", Type: types.CorporateActionDividend, ExDate: date("2021-01-04"), Amount: decimal.MustParse("0.56")},
+		{Symbol: "AAPL", Type: types.CorporateActionSplit, ExDate: date("2014-06-09"), Ratio: decimal.MustParse("7")},
+	})
+
+	actions, _ := s.GetCorporateActions(context.Background(), "AAPL")
+	if len(actions) != 2 {
+		t.Errorf("expected 2 actions, got %d", len(actions))
+		return
+	}
+
+	if !actions[0].Ratio.Equal(decimal.MustParse("7")) || !actions[1].Ratio.Equal(decimal.MustParse("4")) {
+		t.Errorf("expected actions sorted by ex-date, got %v", actions)
+	}
+
+	// callers can't modify the store
+	actions[0].Ratio = decimal.NewFromInt(1)
+	actions, _ = s.GetCorporateActions(context.Background(), "AAPL")
+	if !actions[0].Ratio.Equal(decimal.MustParse("7")) {
+		t.Errorf("expected ratio to be 7, got %v", actions[0].Ratio)
+	}
+}
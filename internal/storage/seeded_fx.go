@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"math"
+	"time"
+)
+
+var _ FXStorage = (*seededFX)(nil)
+
+// seededFXBase is the mid USD rate each seeded currency oscillates around
+var seededFXBase = map[string]float64{
+	"USD": 1,
+	"EUR": 0.92,
+	"GBP": 0.79,
+	"CHF": 0.88,
+	"JPY": 150,
+	"CAD": 1.36,
+	"AUD": 1.52,
+	"HKD": 7.8,
+	"CNY": 7.2,
+}
+
+// NewSeededFX generates a deterministic rate per currency and date,
+// a slow cycle of +-5% around the base rate plus a small daily noise
+func NewSeededFX() FXStorage {
+	return &seededFX{}
+}
+
+type seededFX struct{}
+
+func (s *seededFX) GetRate(_ context.Context, from string, to string, date time.Time) (decimal.Decimal, error) {
+	fromRate, err := seededUSDRate(from, date)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	toRate, err := seededUSDRate(to, date)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	return toRate.Div(fromRate, FXRateScale), nil
+}
+
+func seededUSDRate(currency string, date time.Time) (decimal.Decimal, error) {
+	base, ok := seededFXBase[currency]
+	if !ok {
+		return decimal.Decimal{}, &types.ErrCurrencyNotSupported{
+			Currency: currency,
+		}
+	}
+	if currency == "USD" {
+		return decimal.NewFromInt(1), nil
+	}
+
+	day := date.Format("2006-01-02")
+	phase := getRandForString("fx:"+currency).Float64() * 2 * math.Pi
+	noise := getRandForString("fx:"+currency+":"+day).NormFloat64() * 0.002
+
+	// a cycle of roughly a year
+	days := float64(date.Unix()) / 86400
+	rate := base * (1 + 0.05*math.Sin(days/58+phase) + noise)
+
+	return decimal.NewFromFloat(rate, 6), nil
+}
@@ -4,21 +4,56 @@ import (
 	"context"
 	"crypto/sha1"
 	"encoding/binary"
+	"github.com/falmar/richerage-api/internal/calendar"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
 	"github.com/falmar/richerage-api/internal/tickers/types"
+	"math"
 	"math/rand"
 	"time"
 )
 
 var _ Storage = (*seededStorage)(nil)
 
-func NewSeeded() Storage {
-	return &seededStorage{}
+type SeededConfig struct {
+	// Symbols is the universe of the seeded data, defaults to the embedded symbol master
+	Symbols SymbolStorage
 }
 
-type seededStorage struct{}
+func NewSeeded(cfg *SeededConfig) Storage {
+	return newSeeded(cfg)
+}
+
+// NewSeededCorporateActions are the embedded splits plus quarterly dividends generated per symbol,
+// consistent with the prices of NewSeeded
+func NewSeededCorporateActions(cfg *SeededConfig) CorporateActionStorage {
+	return newSeeded(cfg)
+}
+
+func newSeeded(cfg *SeededConfig) *seededStorage {
+	s := &seededStorage{}
+
+	if cfg != nil {
+		s.symbols = cfg.Symbols
+	}
+	if s.symbols == nil {
+		s.symbols = NewEmbeddedSymbols()
+	}
+
+	return s
+}
 
-func (s *seededStorage) GetByUser(_ context.Context, username string) ([]types.Ticker, error) {
-	genTickers := generatedTickers(getRandForString("tickers"))
+type seededStorage struct {
+	symbols SymbolStorage
+}
+
+func (s *seededStorage) GetByUser(ctx context.Context, username string) ([]types.Ticker, error) {
+	listed, err := listedSymbols(ctx, s.symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	genTickers := generatedTickers(getRandForString("tickers"), listed)
 	rnd := getRandForString(username)
 	maxTickers := rnd.Intn(len(genTickers))
 
@@ -54,20 +89,90 @@ func (s *seededStorage) GetByUser(_ context.Context, username string) ([]types.T
 	return tickers, nil
 }
 
-func (s *seededStorage) GetHistory(_ context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
-	if !isValidSymbol(types.ValidTickers(), symbol) {
+func (s *seededStorage) GetHistory(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+	return s.GetBars(ctx, symbol, types.Resolution1d, before)
+}
+
+func (s *seededStorage) GetBars(ctx context.Context, symbol string, resolution types.Resolution, before time.Time) ([]types.TickerHistory, error) {
+	w, err := s.walk(ctx, symbol, before)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolution.Intraday() {
+		return w.intradayBars(resolution, before), nil
+	}
+
+	// allocate records of history
+	history := make([]types.TickerHistory, 0, len(w.days))
+
+	for _, day := range w.days {
+		history = append(history, w.dailyBar(day).history(day.date, w.currency))
+	}
+
+	if !before.IsZero() {
+		// history is newest first, skip all dates after before
+		for i, v := range history {
+			if !v.Date.After(before) {
+				return history[i:], nil
+			}
+		}
+
+		return history[:0], nil
+	}
+
+	return history, nil
+}
+
+// seededWalk is the daily closes of a symbol, newest first
+type seededWalk struct {
+	symbol     string
+	cal        *calendar.Calendar
+	currency   string
+	volatility float64
+	// volume is the average volume of a minute
+	volume int64
+	days   []seededDay
+	// now bounds intraday bars, no bar is generated past it
+	now time.Time
+}
+
+type seededDay struct {
+	date time.Time
+	// open is the previous close, in terms of any corporate action taking effect on date
+	open  decimal.Decimal
+	close decimal.Decimal
+}
+
+// walk generates the closes of symbol when it traded at before (or now when zero),
+// the walk of a delisted symbol ends on its last trading day
+func (s *seededStorage) walk(ctx context.Context, symbol string, before time.Time) (*seededWalk, error) {
+	if !isValidSymbol(ctx, s.symbols, symbol, before) {
 		return nil, &types.ErrTickerNotFound{
 			Symbol: symbol,
 		}
 	}
 
-	genTickers := generatedTickers(getRandForString("tickers"))
+	v, err := s.symbols.GetSymbol(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
 
-	// obtain a deterministic random number for price and date given the symbol
-	rnd := getRandForString(symbol)
+	listed, err := listedSymbols(ctx, s.symbols)
+	if err != nil {
+		return nil, err
+	}
 
-	// start from today at 00:00:00
-	date := time.Now().Truncate(time.Hour * 24)
+	// walk the trading days of the exchange the symbol is listed on
+	w := &seededWalk{
+		symbol:   symbol,
+		cal:      seededCalendar(v.Exchange),
+		currency: v.Currency,
+		now:      time.Now(),
+	}
+
+	// obtain a deterministic random number for the walk given the symbol
+	rnd := getRandForString(symbol)
 
 	// up to 100 records
 	records := rnd.Intn(100)
@@ -76,80 +181,101 @@ func (s *seededStorage) GetHistory(_ context.Context, symbol string, before time
 		records = 1
 	}
 
-	// allocate records of history
-	history := make([]types.TickerHistory, 0, records)
-
-	// add the current price for today
-	for _, v := range genTickers {
-		if v.Symbol == symbol {
-			history = append(history, types.TickerHistory{
-				Date:  date,
-				Price: v.Price,
-			})
+	// annualized drift and volatility, fixed per symbol
+	drift := -0.1 + rnd.Float64()*0.3
+	w.volatility = 0.15 + rnd.Float64()*0.45
+	w.volume = 100 + int64(getRandForString("volume:"+symbol).Intn(10000))
+
+	// start from the last trading day at 00:00:00, the day before the delisting when delisted
+	last := w.now.Truncate(time.Hour * 24)
+	if !v.Delisted.IsZero() && v.Delisted.Before(last) {
+		last = v.Delisted.Add(-time.Hour * 24)
+	}
+	date := w.cal.PreviousTradingDay(last)
+
+	// delisted symbols are not part of the generated tickers, their last price is seeded on its own
+	price := generatedTickers(getRandForString("tickers:"+symbol), []symboltypes.Symbol{*v})[0].Price
+	for _, t := range generatedTickers(getRandForString("tickers"), listed) {
+		if t.Symbol == symbol {
+			price = t.Price
 			break
 		}
 	}
 
-	for i := 0; i < records; i++ {
-		base := rnd.Intn(1000)
-		decimals := rnd.Intn(100)
+	actions, err := s.GetCorporateActions(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
 
-		// from today, down to 90 days ago
+	exDates := make(map[string]types.CorporateAction, len(actions))
+	for _, a := range actions {
+		exDates[a.ExDate.Format("2006-01-02")] = a
+	}
 
-		var historyDate time.Time
-		var try int
+	w.days = make([]seededDay, 0, records)
 
-	dateLoop:
-		for {
-			if try >= 5 {
-				break
-			}
+	// the walk goes back in time from the current price so the latest record matches GetByUser
+	for i := 0; i < records; i++ {
+		day := seededDay{
+			date:  date,
+			close: price,
+		}
 
-			try++
-			historyDate = date.Add(-time.Hour * 24 * time.Duration(rnd.Intn(100)+1))
+		price = previousGBMPrice(price, drift, w.volatility, rnd.NormFloat64())
+		day.open = price
 
-			// check if the date is already in the history
-			for _, v := range history {
-				if v.Date == historyDate {
-					continue dateLoop
-				}
+		// the previous close is from before the corporate action taking effect on date
+		if a, ok := exDates[date.Format("2006-01-02")]; ok {
+			switch a.Type {
+			case types.CorporateActionSplit:
+				price = price.MulRound(a.Ratio, 2)
+			case types.CorporateActionDividend:
+				price = price.Add(a.Amount).Round(2)
 			}
-
-			break dateLoop
 		}
 
-		history = append(history, types.TickerHistory{
-			Price: float64(base) + (float64(decimals) / 100),
-			Date:  historyDate,
-		})
+		w.days = append(w.days, day)
+		date = w.cal.PreviousTradingDay(date.Add(-time.Hour * 24))
 	}
 
-	if !before.IsZero() {
-		// remove all dates after before
-		for i, v := range history {
-			if v.Date.After(before) {
-				history = history[:i]
-				break
-			}
-		}
-	}
+	return w, nil
+}
 
-	return history, nil
+// tradingDaysPerYear converts the annualized drift and volatility into daily steps
+const tradingDaysPerYear = 252
+
+// previousGBMPrice undoes one daily step of geometric brownian motion,
+// price(t) = price(t-1) * exp((drift - volatility^2/2)dt + volatility*sqrt(dt)*z)
+func previousGBMPrice(price decimal.Decimal, drift float64, volatility float64, z float64) decimal.Decimal {
+	dt := 1.0 / tradingDaysPerYear
+	step := (drift-volatility*volatility/2)*dt + volatility*math.Sqrt(dt)*z
+
+	// round to cents, never below one
+	return decimal.NewFromFloat(math.Max(0.01, price.Float64()/math.Exp(step)), 2)
 }
 
-func generatedTickers(rnd *rand.Rand) []types.Ticker {
-	validTickers := types.ValidTickers()
+// seededCalendar falls back to NYSE for exchanges without a calendar
+func seededCalendar(exchange string) *calendar.Calendar {
+	cal, err := calendar.Get(exchange)
+	if err != nil {
+		cal, _ = calendar.Get("NYSE")
+	}
+
+	return cal
+}
 
+func generatedTickers(rnd *rand.Rand, symbols []symboltypes.Symbol) []types.Ticker {
 	// allocate
-	tickers := make([]types.Ticker, 0, len(validTickers))
+	tickers := make([]types.Ticker, 0, len(symbols))
 
-	for i := 0; i < len(validTickers); i++ {
+	for i := 0; i < len(symbols); i++ {
 		base := rnd.Intn(1000)
 		decimals := rnd.Intn(100)
 
 		tickers = append(tickers, types.Ticker{
-			Symbol: validTickers[i],
-			Price:  float64(base) + (float64(decimals) / 100),
+			Symbol:   symbols[i].Symbol,
+			Price:    decimal.New(int64(base*100+decimals), 2),
+			Currency: symbols[i].Currency,
 		})
 	}
 
@@ -5,7 +5,10 @@ package storage
 import (
 	"context"
 	"errors"
+	"github.com/falmar/richerage-api/internal/calendar"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
 	"github.com/falmar/richerage-api/internal/tickers/types"
+	"math"
 	"testing"
 	"time"
 )
@@ -26,22 +29,26 @@ func TestStorageSeeder_RandForString(t *testing.T) {
 }
 
 func TestStorageSeeder_GenValidTickers(t *testing.T) {
+	ctx := context.Background()
+	symbols := NewEmbeddedSymbols()
+	validTickers, _ := listedSymbols(ctx, symbols)
+
 	tickers := generatedTickers(
 		getRandForString("test"),
+		validTickers,
 	)
 	tickers2 := generatedTickers(
 		getRandForString("test"),
+		validTickers,
 	)
 
-	validTickers := types.ValidTickers()
-
 	if len(tickers) != len(validTickers) {
 		t.Errorf("expected %d tickers, got %d", len(validTickers), len(tickers))
 		return
 	}
 
 	for _, ticker := range tickers {
-		if !isValidSymbol(validTickers, ticker.Symbol) {
+		if !isValidSymbol(ctx, symbols, ticker.Symbol, time.Time{}) {
 			t.Errorf("expected ticker %s to be valid", ticker.Symbol)
 		}
 	}
@@ -49,20 +56,21 @@ func TestStorageSeeder_GenValidTickers(t *testing.T) {
 	for i := 0; i < len(validTickers); i++ {
 		if tickers[i].Symbol != tickers2[i].Symbol {
 			t.Errorf("expected ticker %s to be equal to %s", tickers[i].Symbol, tickers2[i].Symbol)
-		} else if tickers[i].Price != tickers2[i].Price {
-			t.Errorf("expected ticker %f to be equal to %f", tickers[i].Price, tickers2[i].Price)
+		} else if !tickers[i].Price.Equal(tickers2[i].Price) {
+			t.Errorf("expected ticker %s to be equal to %s", tickers[i].Price, tickers2[i].Price)
 		}
 	}
 
 	tickers2 = generatedTickers(
 		getRandForString("test2"),
+		validTickers,
 	)
 
 	for i := 0; i < len(validTickers); i++ {
 		if tickers[i].Symbol != tickers2[i].Symbol {
 			t.Errorf("expected ticker %s to be equal to %s", tickers[i].Symbol, tickers2[i].Symbol)
-		} else if tickers[i].Price == tickers2[i].Price {
-			t.Errorf("expected ticker %f to be different to %f", tickers[i].Price, tickers2[i].Price)
+		} else if tickers[i].Price.Equal(tickers2[i].Price) {
+			t.Errorf("expected ticker %s to be different to %s", tickers[i].Price, tickers2[i].Price)
 		}
 	}
 }
@@ -71,7 +79,7 @@ func TestStorageSeeder_History(t *testing.T) {
 	ctx := context.Background()
 
 	// test GetHistory is deterministic on the same seeded_storage
-	s1 := NewSeeded()
+	s1 := NewSeeded(&SeededConfig{})
 
 	h1, err := s1.GetHistory(ctx, "AAPL", time.Time{})
 	if err != nil {
@@ -93,13 +101,13 @@ func TestStorageSeeder_History(t *testing.T) {
 	for i := 0; i < len(h1); i++ {
 		if h1[i].Date != h2[i].Date {
 			t.Errorf("expected history to be equal, got different dates")
-		} else if h1[i].Price != h2[i].Price {
+		} else if !h1[i].Price.Equal(h2[i].Price) {
 			t.Errorf("expected history to be equal, got different prices")
 		}
 	}
 
 	// test GetHistory is deterministic on different seeded_storage
-	s2 := NewSeeded()
+	s2 := NewSeeded(&SeededConfig{})
 
 	h3, err := s2.GetHistory(ctx, "AAPL", time.Time{})
 	if err != nil {
@@ -115,24 +123,181 @@ func TestStorageSeeder_History(t *testing.T) {
 	for i := 0; i < len(h1); i++ {
 		if h1[i].Date != h3[i].Date {
 			t.Errorf("expected history to be equal, got different dates")
-		} else if h1[i].Price != h3[i].Price {
+		} else if !h1[i].Price.Equal(h3[i].Price) {
 			t.Errorf("expected history to be equal, got different prices")
 		}
 	}
 }
 
+func TestStorageSeeder_History_Continuity(t *testing.T) {
+	ctx := context.Background()
+	s1 := NewSeeded(&SeededConfig{})
+
+	symbols := NewEmbeddedSymbols()
+	listed, _ := listedSymbols(ctx, symbols)
+	genTickers := generatedTickers(getRandForString("tickers"), listed)
+
+	for _, ticker := range genTickers {
+		symbol, _ := symbols.GetSymbol(ctx, ticker.Symbol)
+		cal, _ := calendar.Get(symbol.Exchange)
+
+		h, err := s1.GetHistory(ctx, ticker.Symbol, time.Time{})
+		if err != nil {
+			t.Errorf("expected error to be nil, got %T", err)
+			return
+		}
+
+		if len(h) < 1 || len(h) > 100 {
+			t.Errorf("expected 1 to 100 records for %s, got %d", ticker.Symbol, len(h))
+			continue
+		}
+
+		// latest record is the current price
+		if !h[0].Price.Equal(ticker.Price) {
+			t.Errorf("expected %s latest price to be %v, got %v", ticker.Symbol, ticker.Price, h[0].Price)
+		}
+
+		seen := map[time.Time]bool{}
+
+		for i, v := range h {
+			if seen[v.Date] {
+				t.Errorf("expected %s dates to be unique, got %v twice", ticker.Symbol, v.Date)
+			}
+			seen[v.Date] = true
+
+			if !cal.IsTradingDay(v.Date) {
+				t.Errorf("expected %s dates to be trading days, got %v", ticker.Symbol, v.Date)
+			}
+			if v.Price.Sign() <= 0 {
+				t.Errorf("expected %s price to be above 0, got %v", ticker.Symbol, v.Price)
+			}
+
+			if i == 0 {
+				continue
+			}
+
+			// consecutive trading days, newest first
+			prev := h[i-1]
+			if cal.PreviousTradingDay(prev.Date.Add(-time.Hour*24)) != v.Date {
+				t.Errorf("expected %s date after %v to be the previous trading day, got %v", ticker.Symbol, prev.Date, v.Date)
+			}
+
+			// daily moves of a random walk stay small, the old generator jumped between 0 and 1000
+			if move := math.Abs(math.Log(prev.Price.Float64() / v.Price.Float64())); move > 0.25 {
+				t.Errorf("expected %s daily move to be below 25%%, got %.2f%% on %v", ticker.Symbol, move*100, v.Date)
+			}
+		}
+	}
+}
+
+func TestStorageSeeder_History_Before(t *testing.T) {
+	ctx := context.Background()
+	s1 := NewSeeded(&SeededConfig{})
+
+	h, err := s1.GetHistory(ctx, "AAPL", time.Time{})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+		return
+	}
+	if len(h) < 3 {
+		t.Skipf("need at least 3 records, got %d", len(h))
+	}
+
+	before := h[2].Date
+
+	hb, err := s1.GetHistory(ctx, "AAPL", before)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+		return
+	}
+
+	if len(hb) != len(h)-2 {
+		t.Errorf("expected %d records, got %d", len(h)-2, len(hb))
+	} else if hb[0].Date != before || !hb[0].Price.Equal(h[2].Price) {
+		t.Errorf("expected first record to be %v, got %v", h[2], hb[0])
+	}
+
+	// before the whole series
+	hb, _ = s1.GetHistory(ctx, "AAPL", h[len(h)-1].Date.Add(-time.Hour*24))
+	if len(hb) != 0 {
+		t.Errorf("expected no records, got %d", len(hb))
+	}
+}
+
+func TestStorageSeeder_PreviousGBMPrice(t *testing.T) {
+	// without noise the step only depends on the drift
+	p := previousGBMPrice(decimal.MustParse("100"), 0, 0, 0)
+	if p.String() != "100.00" {
+		t.Errorf("expected 100, got %v", p)
+	}
+
+	// positive drift means prices were lower in the past
+	p = previousGBMPrice(decimal.MustParse("100"), 0.5, 0, 0)
+	if p.Cmp(decimal.MustParse("100")) >= 0 {
+		t.Errorf("expected price below 100, got %v", p)
+	}
+
+	// never below one cent
+	p = previousGBMPrice(decimal.MustParse("0.01"), 0, 0.5, 10)
+	if p.String() != "0.01" {
+		t.Errorf("expected 0.01, got %v", p)
+	}
+}
+
 func TestStorageSeeder_History_Invalid(t *testing.T) {
-	s1 := NewSeeded()
+	s1 := NewSeeded(&SeededConfig{})
 
-	_, err := s1.GetHistory(context.Background(), "INVALID", time.Time{})
+	// unknown symbols and symbols delisted by now have no seeded history
+	for _, symbol := range []string{"INVALID", "TWTR"} {
+		_, err := s1.GetHistory(context.Background(), symbol, time.Time{})
 
-	var errNotFound *types.ErrTickerNotFound
+		var errNotFound *types.ErrTickerNotFound
+
+		if !errors.As(err, &errNotFound) {
+			t.Errorf("expected error to be ErrInvalidSymbol, got %T", err)
+			return
+		} else if errNotFound.Symbol != symbol {
+			t.Errorf("expected error to be ErrInvalidSymbol, got %s", errNotFound.Symbol)
+		}
+	}
+}
 
+func TestStorageSeeder_History_Delisted(t *testing.T) {
+	ctx := context.Background()
+	s1 := NewSeeded(&SeededConfig{})
+
+	// TWTR was delisted on 2022-11-08, its walk ends on the last trading day
+	delisted := time.Date(2022, 11, 8, 0, 0, 0, 0, time.UTC)
+
+	h, err := s1.GetHistory(ctx, "TWTR", delisted.Add(-time.Hour*24))
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(h) == 0 {
+		t.Fatalf("expected history, got none")
+	}
+
+	if h[0].Date.Format("2006-01-02") != "2022-11-07" {
+		t.Errorf("expected first record on 2022-11-07, got %v", h[0].Date)
+	}
+	for _, v := range h {
+		if !v.Date.Before(delisted) {
+			t.Errorf("expected records before the delisting, got %v", v.Date)
+		}
+	}
+
+	// deterministic
+	h2, _ := s1.GetHistory(ctx, "TWTR", delisted.Add(-time.Hour*24))
+	if len(h) != len(h2) || !h[0].Price.Equal(h2[0].Price) {
+		t.Errorf("expected history to be equal, got %v and %v", h[0], h2[0])
+	}
+
+	// on the delisting date
+	_, err = s1.GetHistory(ctx, "TWTR", delisted)
+
+	var errNotFound *types.ErrTickerNotFound
 	if !errors.As(err, &errNotFound) {
-		t.Errorf("expected error to be ErrInvalidSymbol, got %T", err)
-		return
-	} else if errNotFound.Symbol != "INVALID" {
-		t.Errorf("expected error to be ErrInvalidSymbol, got %s", errNotFound.Symbol)
+		t.Errorf("expected error to be ErrTickerNotFound, got %T", err)
 	}
 }
 
@@ -140,7 +305,7 @@ func TestStorageSeeder_GenTickers(t *testing.T) {
 	ctx := context.Background()
 
 	// test GetByUser is deterministic on the same seeded_storage
-	s1 := NewSeeded()
+	s1 := NewSeeded(&SeededConfig{})
 
 	t1, err := s1.GetByUser(ctx, "test")
 	if err != nil {
@@ -162,13 +327,13 @@ func TestStorageSeeder_GenTickers(t *testing.T) {
 	for i := 0; i < len(t1); i++ {
 		if t1[i].Symbol != t2[i].Symbol {
 			t.Errorf("expected tickers to be equal, got different symbols")
-		} else if t1[i].Price != t2[i].Price {
+		} else if !t1[i].Price.Equal(t2[i].Price) {
 			t.Errorf("expected tickers to be equal, got different prices")
 		}
 	}
 
 	// test GetByUser is deterministic on different seeded_storage
-	s2 := NewSeeded()
+	s2 := NewSeeded(&SeededConfig{})
 
 	t3, err := s2.GetByUser(ctx, "test")
 	if err != nil {
@@ -184,7 +349,7 @@ func TestStorageSeeder_GenTickers(t *testing.T) {
 	for i := 0; i < len(t1); i++ {
 		if t1[i].Symbol != t3[i].Symbol {
 			t.Errorf("expected tickers to be equal, got different symbols")
-		} else if t1[i].Price != t3[i].Price {
+		} else if !t1[i].Price.Equal(t3[i].Price) {
 			t.Errorf("expected tickers to be equal, got different prices")
 		}
 	}
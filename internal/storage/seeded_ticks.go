@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/prices"
+	"math"
+	"time"
+)
+
+var _ prices.Publisher = (*SeededTicker)(nil)
+
+type SeededTickerConfig struct {
+	Interval time.Duration
+	Broker   *prices.Broker
+	// Symbols defaults to the embedded symbol master
+	Symbols SymbolStorage
+}
+
+func NewSeededTicker(cfg *SeededTickerConfig) *SeededTicker {
+	t := &SeededTicker{
+		Broker:   cfg.Broker,
+		interval: cfg.Interval,
+		symbols:  cfg.Symbols,
+	}
+
+	if t.Broker == nil {
+		t.Broker = prices.NewBroker(nil)
+	}
+	if t.symbols == nil {
+		t.symbols = NewEmbeddedSymbols()
+	}
+	if t.interval <= 0 {
+		t.interval = time.Second
+	}
+
+	return t
+}
+
+// SeededTicker simulates live prices for the seeded storage,
+// every symbol starts at the price returned by GetByUser and takes a small random step on each tick
+type SeededTicker struct {
+	*prices.Broker
+
+	interval time.Duration
+	symbols  SymbolStorage
+}
+
+// Run publishes ticks until ctx is cancelled
+func (t *SeededTicker) Run(ctx context.Context) {
+	// same seed on every start, the walk is only deterministic in the number of ticks
+	rnd := getRandForString("ticks")
+	listed, err := listedSymbols(ctx, t.symbols)
+	if err != nil {
+		return
+	}
+
+	current := generatedTickers(getRandForString("tickers"), listed)
+
+	now := time.Now().UTC()
+	for _, v := range current {
+		t.Publish(v.Symbol, v.Price, now)
+	}
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for i := range current {
+				// up to ~0.2% per tick, never below one cent
+				price := current[i].Price.Float64() * (1 + rnd.NormFloat64()*0.002)
+				next := decimal.NewFromFloat(math.Max(0.01, price), 2)
+
+				if next.Equal(current[i].Price) {
+					continue
+				}
+
+				current[i].Price = next
+				t.Publish(current[i].Symbol, next, now.UTC())
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+//go:build test
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStorageSeeder_Ticker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ticker := NewSeededTicker(&SeededTickerConfig{
+		Interval: time.Millisecond,
+	})
+
+	listed, _ := listedSymbols(ctx, NewEmbeddedSymbols())
+	genTickers := generatedTickers(getRandForString("tickers"), listed)
+
+	// subscribe before running to get every tick
+	ch, err := ticker.Subscribe(ctx, []string{genTickers[0].Symbol}, 0)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	go ticker.Run(ctx)
+
+	// first update is the same price as the seeded storage
+	first := <-ch
+	if !first.Price.Equal(genTickers[0].Price) {
+		t.Errorf("expected first price to be %v, got %v", genTickers[0].Price, first.Price)
+	}
+
+	second := <-ch
+	if second.ID <= first.ID {
+		t.Errorf("expected ids to increase, got %d after %d", second.ID, first.ID)
+	}
+	if second.Price.Sign() <= 0 || second.Price.Equal(first.Price) {
+		t.Errorf("expected price to move, got %v after %v", second.Price, first.Price)
+	}
+}
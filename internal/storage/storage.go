@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
 	"github.com/falmar/richerage-api/internal/tickers/types"
 	"time"
 )
@@ -13,15 +14,47 @@ import (
 type Storage interface {
 	GetByUser(ctx context.Context, username string) ([]types.Ticker, error)
 
+	// GetHistory returns daily bars newest first, dates not after before when set
 	GetHistory(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error)
+	// GetBars returns bars of resolution newest first, starting not after before when set,
+	// intraday bars are bounded to the most recent sessions
+	GetBars(ctx context.Context, symbol string, resolution types.Resolution, before time.Time) ([]types.TickerHistory, error)
 }
 
-func isValidSymbol(tickers []string, symbol string) bool {
-	for _, v := range tickers {
-		if v == symbol {
+// isValidSymbol reports whether the symbol is known to the symbol master and traded under it at date,
+// now when date is zero
+func isValidSymbol(ctx context.Context, symbols SymbolStorage, symbol string, date time.Time) bool {
+	v, err := symbols.GetSymbol(ctx, symbol)
+	if err != nil {
+		return false
+	}
+
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	for _, l := range v.Listings {
+		if l.Symbol == symbol && l.Covers(date) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// listedSymbols returns the symbols still trading, sorted by symbol
+func listedSymbols(ctx context.Context, symbols SymbolStorage) ([]symboltypes.Symbol, error) {
+	all, err := symbols.ListSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make([]symboltypes.Symbol, 0, len(all))
+	for _, v := range all {
+		if v.Delisted.IsZero() {
+			listed = append(listed, v)
+		}
+	}
+
+	return listed, nil
+}
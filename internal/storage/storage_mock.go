@@ -20,12 +20,16 @@ func NewMock() Storage {
 		GetHistoryFunc: func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
 			return nil, ErrMockUncalledFor
 		},
+		GetBarsFunc: func(ctx context.Context, symbol string, resolution types.Resolution, before time.Time) ([]types.TickerHistory, error) {
+			return nil, ErrMockUncalledFor
+		},
 	}
 }
 
 type MockStorage struct {
 	GetByUserFunc  func(ctx context.Context, username string) ([]types.Ticker, error)
 	GetHistoryFunc func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error)
+	GetBarsFunc    func(ctx context.Context, symbol string, resolution types.Resolution, before time.Time) ([]types.TickerHistory, error)
 }
 
 func (m *MockStorage) GetByUser(ctx context.Context, username string) ([]types.Ticker, error) {
@@ -35,3 +39,56 @@ func (m *MockStorage) GetByUser(ctx context.Context, username string) ([]types.T
 func (m *MockStorage) GetHistory(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
 	return m.GetHistoryFunc(ctx, symbol, before)
 }
+
+func (m *MockStorage) GetBars(ctx context.Context, symbol string, resolution types.Resolution, before time.Time) ([]types.TickerHistory, error) {
+	return m.GetBarsFunc(ctx, symbol, resolution, before)
+}
+
+var _ PriceWriter = (*MockPriceWriter)(nil)
+
+func NewMockPriceWriter() PriceWriter {
+	return &MockPriceWriter{
+		UpsertPricesFunc: func(ctx context.Context, symbol string, prices []types.TickerHistory) (*UpsertResult, error) {
+			return nil, ErrMockUncalledFor
+		},
+	}
+}
+
+type MockPriceWriter struct {
+	UpsertPricesFunc func(ctx context.Context, symbol string, prices []types.TickerHistory) (*UpsertResult, error)
+}
+
+func (m *MockPriceWriter) UpsertPrices(ctx context.Context, symbol string, prices []types.TickerHistory) (*UpsertResult, error) {
+	return m.UpsertPricesFunc(ctx, symbol, prices)
+}
+
+var _ WritableStorage = (*MockWritableStorage)(nil)
+
+func NewMockWritable() WritableStorage {
+	return &MockWritableStorage{
+		MockStorage:     NewMock().(*MockStorage),
+		MockPriceWriter: NewMockPriceWriter().(*MockPriceWriter),
+		DeletePricesFunc: func(ctx context.Context, symbol string, from time.Time, to time.Time) (int, error) {
+			return 0, ErrMockUncalledFor
+		},
+		SetHoldingsFunc: func(ctx context.Context, username string, symbols []string) error {
+			return ErrMockUncalledFor
+		},
+	}
+}
+
+type MockWritableStorage struct {
+	*MockStorage
+	*MockPriceWriter
+
+	DeletePricesFunc func(ctx context.Context, symbol string, from time.Time, to time.Time) (int, error)
+	SetHoldingsFunc  func(ctx context.Context, username string, symbols []string) error
+}
+
+func (m *MockWritableStorage) DeletePrices(ctx context.Context, symbol string, from time.Time, to time.Time) (int, error) {
+	return m.DeletePricesFunc(ctx, symbol, from, to)
+}
+
+func (m *MockWritableStorage) SetHoldings(ctx context.Context, username string, symbols []string) error {
+	return m.SetHoldingsFunc(ctx, username, symbols)
+}
@@ -3,23 +3,72 @@
 package storage
 
 import (
-	"github.com/falmar/richerage-api/internal/tickers/types"
+	"context"
 	"testing"
+	"time"
 )
 
 func TestStorage_ValidSymbol(t *testing.T) {
-	v := isValidSymbol(types.ValidTickers(), "AAPL")
+	ctx := context.Background()
+	symbols := NewEmbeddedSymbols()
+
+	v := isValidSymbol(ctx, symbols, "AAPL", time.Time{})
+	if !v {
+		t.Errorf("expected true, got %v", v)
+	}
+
+	v = isValidSymbol(ctx, symbols, "INVALID", time.Time{})
+	if v {
+		t.Errorf("expected false, got %v", v)
+	}
+
+	// delisted on 2022-11-08
+	v = isValidSymbol(ctx, symbols, "TWTR", time.Time{})
+	if v {
+		t.Errorf("expected false, got %v", v)
+	}
+
+	v = isValidSymbol(ctx, symbols, "TWTR", time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC))
 	if !v {
 		t.Errorf("expected true, got %v", v)
 	}
 
-	v = isValidSymbol(types.ValidTickers(), "INVALID")
+	v = isValidSymbol(ctx, symbols, "TWTR", time.Date(2022, 11, 8, 0, 0, 0, 0, time.UTC))
 	if v {
 		t.Errorf("expected false, got %v", v)
 	}
 
-	v = isValidSymbol([]string{}, "AAPL")
+	// before the listing
+	v = isValidSymbol(ctx, symbols, "AAPL", time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC))
 	if v {
 		t.Errorf("expected false, got %v", v)
 	}
+
+	// previous symbols are resolved by the symbol master, not traded under the current instrument
+	v = isValidSymbol(ctx, symbols, "FB", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+	if v {
+		t.Errorf("expected false, got %v", v)
+	}
+
+	v = isValidSymbol(ctx, NewMemorySymbols(nil), "AAPL", time.Time{})
+	if v {
+		t.Errorf("expected false, got %v", v)
+	}
+}
+
+func TestStorage_ListedSymbols(t *testing.T) {
+	listed, err := listedSymbols(context.Background(), NewEmbeddedSymbols())
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	for i, v := range listed {
+		if !v.Delisted.IsZero() {
+			t.Errorf("expected %s to be listed", v.Symbol)
+		}
+		if i > 0 && listed[i-1].Symbol >= v.Symbol {
+			t.Errorf("expected symbols to be sorted, got %s before %s", listed[i-1].Symbol, v.Symbol)
+		}
+	}
 }
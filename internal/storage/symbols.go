@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+)
+
+// SymbolStorage is the symbol master, the reference data of every known instrument
+type SymbolStorage interface {
+	// ListSymbols returns every symbol including delisted ones, sorted by symbol
+	ListSymbols(ctx context.Context) ([]types.Symbol, error)
+	// GetSymbol finds the instrument currently trading or last traded under symbol
+	GetSymbol(ctx context.Context, symbol string) (*types.Symbol, error)
+
+	// ResolveSymbol returns every instrument that ever traded under symbol, including renamed ones
+	ResolveSymbol(ctx context.Context, symbol string) ([]types.Symbol, error)
+}
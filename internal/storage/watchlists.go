@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+)
+
+// Watchlist is a named list of symbols a user follows, unlike holdings it carries no position
+type Watchlist struct {
+	Name    string
+	Symbols []string
+}
+
+// WatchlistStorage is implemented by backends that keep the watchlists of their users,
+// the seeded storage has none
+type WatchlistStorage interface {
+	// SetWatchlist replaces the symbols of the watchlist name of username, no symbols removes it
+	SetWatchlist(ctx context.Context, username string, name string, symbols []string) error
+	// GetWatchlists returns the watchlists of username sorted by name
+	GetWatchlists(ctx context.Context, username string) ([]Watchlist, error)
+	// ListWatchlists returns the watchlists of each user with any, sorted by name
+	ListWatchlists(ctx context.Context) (map[string][]Watchlist, error)
+}
+
+// uniqueSymbols drops repeated symbols keeping the first occurrence
+func uniqueSymbols(symbols []string) []string {
+	unique := make([]string, 0, len(symbols))
+	seen := map[string]bool{}
+
+	for _, symbol := range symbols {
+		if seen[symbol] {
+			continue
+		}
+
+		seen[symbol] = true
+		unique = append(unique, symbol)
+	}
+
+	return unique
+}
@@ -0,0 +1,56 @@
+//go:build test
+
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWatchlistStorage(t *testing.T) {
+	backends := map[string]func(t *testing.T) WatchlistStorage{
+		"memory": func(t *testing.T) WatchlistStorage {
+			return NewMemory().(WatchlistStorage)
+		},
+		"bolt": func(t *testing.T) WatchlistStorage {
+			return newTestBolt(t, filepath.Join(t.TempDir(), "richerage.db")).(WatchlistStorage)
+		},
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			s := backend(t)
+
+			if watchlists, err := s.GetWatchlists(ctx, "test"); err != nil || watchlists == nil || len(watchlists) != 0 {
+				t.Errorf("expected no watchlists, got %v %v", watchlists, err)
+			}
+
+			_ = s.SetWatchlist(ctx, "test", "tech", []string{"MSFT", "AAPL", "MSFT"})
+			_ = s.SetWatchlist(ctx, "test", "media", []string{"NFLX"})
+			_ = s.SetWatchlist(ctx, "other", "tech", []string{"META"})
+
+			// sorted by name, in order without duplicates
+			watchlists, _ := s.GetWatchlists(ctx, "test")
+			if len(watchlists) != 2 || watchlists[0].Name != "media" || watchlists[1].Name != "tech" || strings.Join(watchlists[1].Symbols, ",") != "MSFT,AAPL" {
+				t.Errorf("expected media and tech, got %+v", watchlists)
+			}
+
+			listed, _ := s.ListWatchlists(ctx)
+			if len(listed) != 2 || len(listed["test"]) != 2 || listed["other"][0].Symbols[0] != "META" {
+				t.Errorf("expected the watchlists of test and other, got %+v", listed)
+			}
+
+			// no symbols removes the watchlist, and the user with its last one
+			_ = s.SetWatchlist(ctx, "other", "tech", nil)
+			_ = s.SetWatchlist(ctx, "test", "media", nil)
+
+			listed, _ = s.ListWatchlists(ctx)
+			if len(listed) != 1 || len(listed["test"]) != 1 || listed["test"][0].Name != "tech" {
+				t.Errorf("expected only the tech watchlist of test, got %+v", listed)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+)
+
+type WebhookStorage interface {
+	CreateSubscription(ctx context.Context, sub *types.Subscription) error
+	GetSubscription(ctx context.Context, id string) (*types.Subscription, error)
+	ListSubscriptions(ctx context.Context, username string) ([]types.Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+
+	// SaveDelivery inserts or replaces the delivery with the same ID, deliveries of a deleted
+	// subscription are ignored so an attempt finishing after the delete leaves nothing behind
+	SaveDelivery(ctx context.Context, delivery *types.Delivery) error
+	GetDelivery(ctx context.Context, id string) (*types.Delivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID string) ([]types.Delivery, error)
+}
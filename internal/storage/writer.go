@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"time"
+)
+
+// PriceWriter is implemented by backends that can be populated with daily bars
+type PriceWriter interface {
+	// UpsertPrices inserts the daily bars of symbol, replacing stored bars of the same date
+	UpsertPrices(ctx context.Context, symbol string, prices []types.TickerHistory) (*UpsertResult, error)
+}
+
+type UpsertResult struct {
+	Inserted int
+	Updated  int
+}
+
+// WritableStorage is a Storage that can be populated through the app, the seeded storage is read-only
+type WritableStorage interface {
+	Storage
+	PriceWriter
+
+	// DeletePrices removes the daily bars of symbol dated from from to to, both included,
+	// a zero bound is open, it returns the number of bars removed
+	DeletePrices(ctx context.Context, symbol string, from time.Time, to time.Time) (int, error)
+	// SetHoldings replaces the symbols held by username, GetByUser returns them with their latest price
+	SetHoldings(ctx context.Context, username string, symbols []string) error
+}
+
+// HoldingsLister is implemented by backends that know every user, the seeded storage
+// makes up holdings for any username and cannot list them
+type HoldingsLister interface {
+	// ListHoldings returns the symbols held by each user with any
+	ListHoldings(ctx context.Context) (map[string][]string, error)
+}
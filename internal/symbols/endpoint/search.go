@@ -0,0 +1,74 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/symbols"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	"strconv"
+	"strings"
+)
+
+type SearchSymbolsRequest struct {
+	Query string
+	Limit string
+}
+
+type SearchSymbolsResponse struct {
+	Symbols []types.Symbol
+}
+
+func MakeSearchSymbolsEndpoint(svc symbols.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifySearchSymbolsRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		limit, _ := strconv.Atoi(req.Limit)
+
+		out, err := svc.SearchSymbols(ctx, &symbols.SearchSymbolsInput{
+			Query: req.Query,
+			Limit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &SearchSymbolsResponse{
+			Symbols: out.Symbols,
+		}, nil
+	}
+}
+
+func verifySearchSymbolsRequest(request interface{}) (*SearchSymbolsRequest, error) {
+	req, ok := request.(*SearchSymbolsRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if strings.TrimSpace(req.Query) == "" {
+		badParams["q"] = "required"
+	}
+	if req.Limit != "" {
+		limit, err := strconv.Atoi(req.Limit)
+		if err != nil || limit < 1 || limit > symbols.MaxSearchLimit {
+			badParams["limit"] = fmt.Sprintf("must be between 1 and %d", symbols.MaxSearchLimit)
+		}
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,66 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/symbols"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"testing"
+)
+
+func TestEndpoint_SearchSymbols(t *testing.T) {
+	var called *symbols.SearchSymbolsInput
+
+	svc := symbols.NewMockService()
+	svc.(*symbols.MockService).SearchSymbolsFunc = func(ctx context.Context, in *symbols.SearchSymbolsInput) (*symbols.SearchSymbolsOutput, error) {
+		called = in
+		return &symbols.SearchSymbolsOutput{
+			Symbols: []types.Symbol{{Symbol: "AAPL"}},
+		}, nil
+	}
+
+	resp, err := MakeSearchSymbolsEndpoint(svc)(context.Background(), &SearchSymbolsRequest{
+		Query: "app",
+		Limit: "5",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	res, ok := resp.(*SearchSymbolsResponse)
+	if !ok || res == nil || len(res.Symbols) != 1 {
+		t.Errorf("expected response with 1 symbol, got %+v", resp)
+	}
+
+	if called == nil || called.Query != "app" || called.Limit != 5 {
+		t.Errorf("expected service to be called with query and limit, got %+v", called)
+	}
+}
+
+func TestEndpoint_SearchSymbols_Invalid(t *testing.T) {
+	cases := []struct {
+		req   *SearchSymbolsRequest
+		param string
+	}{
+		{req: &SearchSymbolsRequest{}, param: "q"},
+		{req: &SearchSymbolsRequest{Query: " "}, param: "q"},
+		{req: &SearchSymbolsRequest{Query: "app", Limit: "0"}, param: "limit"},
+		{req: &SearchSymbolsRequest{Query: "app", Limit: "51"}, param: "limit"},
+		{req: &SearchSymbolsRequest{Query: "app", Limit: "ten"}, param: "limit"},
+	}
+
+	for _, c := range cases {
+		_, err := MakeSearchSymbolsEndpoint(symbols.NewMockService())(context.Background(), c.req)
+
+		var badRequest *kit.BadRequestError
+		if !errors.As(err, &badRequest) {
+			t.Errorf("%+v: expected error to be *kit.BadRequestError, got %T", c.req, err)
+		} else if _, ok := badRequest.Params[c.param]; !ok {
+			t.Errorf("%+v: expected %s param error, got %v", c.req, c.param, badRequest.Params)
+		}
+	}
+}
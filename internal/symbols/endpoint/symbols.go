@@ -0,0 +1,122 @@
+package endpoint
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/symbols"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	"strconv"
+)
+
+type ListSymbolsRequest struct {
+	Exchange string
+	Sector   string
+	Delisted string
+}
+
+type ListSymbolsResponse struct {
+	Symbols []types.Symbol
+}
+
+func MakeListSymbolsEndpoint(svc symbols.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyListSymbolsRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		includeDelisted, _ := strconv.ParseBool(req.Delisted)
+
+		out, err := svc.ListSymbols(ctx, &symbols.ListSymbolsInput{
+			Exchange:        req.Exchange,
+			Sector:          req.Sector,
+			IncludeDelisted: includeDelisted,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &ListSymbolsResponse{
+			Symbols: out.Symbols,
+		}, nil
+	}
+}
+
+func verifyListSymbolsRequest(request interface{}) (*ListSymbolsRequest, error) {
+	req, ok := request.(*ListSymbolsRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if req.Delisted != "" {
+		if _, err := strconv.ParseBool(req.Delisted); err != nil {
+			badParams["delisted"] = "must be true or false"
+		}
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
+
+type GetSymbolRequest struct {
+	Symbol string
+}
+
+type GetSymbolResponse struct {
+	Symbol *types.Symbol
+}
+
+func MakeGetSymbolEndpoint(svc symbols.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyGetSymbolRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := svc.GetSymbol(ctx, &symbols.GetSymbolInput{
+			Symbol: req.Symbol,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &GetSymbolResponse{
+			Symbol: out.Symbol,
+		}, nil
+	}
+}
+
+func verifyGetSymbolRequest(request interface{}) (*GetSymbolRequest, error) {
+	req, ok := request.(*GetSymbolRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if req.Symbol == "" {
+		badParams["symbol"] = "required"
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,91 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/symbols"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"testing"
+)
+
+func TestEndpoint_ListSymbols(t *testing.T) {
+	var called *symbols.ListSymbolsInput
+
+	svc := symbols.NewMockService()
+	svc.(*symbols.MockService).ListSymbolsFunc = func(ctx context.Context, in *symbols.ListSymbolsInput) (*symbols.ListSymbolsOutput, error) {
+		called = in
+		return &symbols.ListSymbolsOutput{
+			Symbols: []types.Symbol{{Symbol: "AAPL"}},
+		}, nil
+	}
+
+	resp, err := MakeListSymbolsEndpoint(svc)(context.Background(), &ListSymbolsRequest{
+		Exchange: "NASDAQ",
+		Delisted: "true",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	res, ok := resp.(*ListSymbolsResponse)
+	if !ok || res == nil || len(res.Symbols) != 1 {
+		t.Errorf("expected response with 1 symbol, got %+v", resp)
+	}
+
+	if called == nil || called.Exchange != "NASDAQ" || !called.IncludeDelisted {
+		t.Errorf("expected service to be called with filters, got %+v", called)
+	}
+}
+
+func TestEndpoint_ListSymbols_Invalid(t *testing.T) {
+	_, err := MakeListSymbolsEndpoint(symbols.NewMockService())(context.Background(), &ListSymbolsRequest{
+		Delisted: "maybe",
+	})
+
+	var badRequest *kit.BadRequestError
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be *kit.BadRequestError, got %T", err)
+	} else if _, ok := badRequest.Params["delisted"]; !ok {
+		t.Errorf("expected delisted param error")
+	}
+}
+
+func TestEndpoint_GetSymbol(t *testing.T) {
+	svc := symbols.NewMockService()
+	svc.(*symbols.MockService).GetSymbolFunc = func(ctx context.Context, in *symbols.GetSymbolInput) (*symbols.GetSymbolOutput, error) {
+		return &symbols.GetSymbolOutput{
+			Symbol: &types.Symbol{Symbol: in.Symbol},
+		}, nil
+	}
+
+	resp, err := MakeGetSymbolEndpoint(svc)(context.Background(), &GetSymbolRequest{Symbol: "AAPL"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	res, ok := resp.(*GetSymbolResponse)
+	if !ok || res == nil || res.Symbol.Symbol != "AAPL" {
+		t.Errorf("expected AAPL response, got %+v", resp)
+	}
+}
+
+func TestEndpoint_GetSymbol_Error(t *testing.T) {
+	svc := symbols.NewMockService()
+
+	_, err := MakeGetSymbolEndpoint(svc)(context.Background(), &GetSymbolRequest{})
+
+	var badRequest *kit.BadRequestError
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be *kit.BadRequestError, got %T", err)
+	}
+
+	_, err = MakeGetSymbolEndpoint(svc)(context.Background(), &GetSymbolRequest{Symbol: "AAPL"})
+	if !errors.Is(err, symbols.ErrMockUncalledFor) {
+		t.Errorf("expected service error to be returned, got %v", err)
+	}
+}
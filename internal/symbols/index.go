@@ -0,0 +1,444 @@
+package symbols
+
+import (
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// maxTokenLen bounds the edit distance matrix, longer words are truncated
+const maxTokenLen = 32
+
+// index answers autocomplete queries over the symbol universe, it is immutable once built
+//
+// results are ranked in tiers:
+//   - exact symbol match
+//   - symbol prefix, shorter symbols first
+//   - name match, every query word must prefix a word of the name within maxEdits typos,
+//     fewer typos first, then matches on an earlier word, then the closest word length
+//     (position and length are taken from the longest query word)
+//
+// typos are not tolerated on the first letter of a word, name words are bucketed by it
+// and each bucket is sorted so the scan can share work between words with a common prefix
+type index struct {
+	symbols []types.Symbol
+	// keys are the upper case symbols sorted, parallel to symbols
+	keys []string
+	// lengths are the keys grouped by length, each group sorted, in the order prefix matches rank
+	lengths []keyGroup
+	// names are the normalized words of each symbol name
+	names [][]string
+	// words are the unique name words bucketed by first byte
+	words map[byte][]word
+
+	// marks are scratch space of one byte per symbol for multi word queries
+	marks sync.Pool
+}
+
+type keyGroup struct {
+	keys    []string
+	symbols []int32
+}
+
+type word struct {
+	text     string
+	postings []posting
+}
+
+type posting struct {
+	symbol int32
+	// pos is the position of the word in the name
+	pos int
+}
+
+func newIndex(symbols []types.Symbol) *index {
+	idx := &index{
+		symbols: make([]types.Symbol, len(symbols)),
+		keys:    make([]string, len(symbols)),
+		names:   make([][]string, len(symbols)),
+		words:   map[byte][]word{},
+	}
+
+	copy(idx.symbols, symbols)
+	sort.Slice(idx.symbols, func(i, j int) bool {
+		return strings.ToUpper(idx.symbols[i].Symbol) < strings.ToUpper(idx.symbols[j].Symbol)
+	})
+
+	postings := map[string][]posting{}
+
+	for i, v := range idx.symbols {
+		idx.keys[i] = strings.ToUpper(v.Symbol)
+		idx.names[i] = tokenize(v.Name)
+
+		for len(idx.lengths) <= len(idx.keys[i]) {
+			idx.lengths = append(idx.lengths, keyGroup{})
+		}
+		g := &idx.lengths[len(idx.keys[i])]
+		g.keys = append(g.keys, idx.keys[i])
+		g.symbols = append(g.symbols, int32(i))
+
+		for pos, w := range idx.names[i] {
+			p := postings[w]
+			// a word repeated in the same name is only posted once
+			if len(p) == 0 || p[len(p)-1].symbol != int32(i) {
+				postings[w] = append(p, posting{symbol: int32(i), pos: pos})
+			}
+		}
+	}
+
+	for text, p := range postings {
+		idx.words[text[0]] = append(idx.words[text[0]], word{text: text, postings: p})
+	}
+	for b := range idx.words {
+		bucket := idx.words[b]
+		sort.Slice(bucket, func(i, j int) bool {
+			return bucket[i].text < bucket[j].text
+		})
+	}
+
+	n := len(idx.symbols)
+	idx.marks.New = func() interface{} {
+		marks := make([]uint8, n)
+		return &marks
+	}
+
+	return idx
+}
+
+type hit struct {
+	symbol int32
+	tier   int
+	dist   int
+	// pos is the name word the longest query word matched
+	pos int
+	// extra is how many letters the matched word has beyond the longest query word
+	extra int
+}
+
+const (
+	tierExact = iota
+	tierPrefix
+	tierName
+)
+
+func (idx *index) search(query string, limit int) []types.Symbol {
+	if limit <= 0 {
+		return []types.Symbol{}
+	}
+
+	key := strings.ToUpper(strings.TrimSpace(query))
+	top := make([]hit, 0, limit+1)
+
+	// symbol prefix, shorter keys rank first and matches are contiguous in each sorted group,
+	// so the scan stops as soon as limit matches are found
+	if key != "" {
+		for n := len(key); n < len(idx.lengths) && len(top) < limit; n++ {
+			g := idx.lengths[n]
+
+			for i := sort.SearchStrings(g.keys, key); i < len(g.keys) && strings.HasPrefix(g.keys[i], key) && len(top) < limit; i++ {
+				h := hit{symbol: g.symbols[i], tier: tierPrefix}
+				if g.keys[i] == key {
+					h.tier = tierExact
+				}
+
+				top = idx.insert(top, h, limit)
+			}
+		}
+	}
+
+	// name matches rank below symbol matches, there is nothing to scan for once those fill the limit
+	terms := tokenize(query)
+	if len(terms) > 0 && len(top) < limit {
+		top = idx.searchNames(top, key, terms, limit)
+	}
+
+	result := make([]types.Symbol, 0, len(top))
+	for _, h := range top {
+		result = append(result, idx.symbols[h.symbol])
+	}
+
+	return result
+}
+
+func (idx *index) searchNames(top []hit, key string, terms []string, limit int) []hit {
+	// the longest query word is the most selective to generate candidates from
+	gen := 0
+	for i, t := range terms {
+		if len(t) > len(terms[gen]) {
+			gen = i
+		}
+	}
+
+	// the other query words are resolved up front into the best distance + 1 per symbol, 0 is no match
+	others := make([][]uint8, 0, len(terms)-1)
+	for i, t := range terms {
+		if i == gen {
+			continue
+		}
+
+		marksPtr := idx.marks.Get().(*[]uint8)
+		defer idx.putMarks(marksPtr)
+
+		marks := *marksPtr
+		scanWords(idx.words[t[0]], t, maxEdits(t), func(w *word, dist int) {
+			for _, p := range w.postings {
+				if v := marks[p.symbol]; v == 0 || int(v) > dist+1 {
+					marks[p.symbol] = uint8(dist + 1)
+				}
+			}
+		})
+
+		others = append(others, marks)
+	}
+
+	term := terms[gen]
+
+	scanWords(idx.words[term[0]], term, maxEdits(term), func(w *word, dist int) {
+		extra := 0
+		if len(w.text) > len(term) {
+			extra = len(w.text) - len(term)
+		}
+
+	postings:
+		for _, p := range w.postings {
+			// already ranked higher by symbol
+			if key != "" && strings.HasPrefix(idx.keys[p.symbol], key) {
+				continue
+			}
+
+			h := hit{symbol: p.symbol, tier: tierName, dist: dist, pos: p.pos, extra: extra}
+
+			for _, marks := range others {
+				v := marks[p.symbol]
+				if v == 0 {
+					continue postings
+				}
+
+				h.dist += int(v) - 1
+			}
+
+			top = idx.insert(top, h, limit)
+		}
+	})
+
+	return top
+}
+
+func (idx *index) putMarks(marksPtr *[]uint8) {
+	marks := *marksPtr
+	for i := range marks {
+		marks[i] = 0
+	}
+
+	idx.marks.Put(marksPtr)
+}
+
+// scanWords calls fn for every word of the sorted bucket within k of term as a prefix,
+// the bucket is walked like a trie: rows of the edit distance matrix are kept per letter of the word
+// and reused by the next word with the same prefix, and a prefix without any match skips all words under it
+func scanWords(bucket []word, term string, k int, fn func(w *word, dist int)) {
+	m := len(term)
+	if m > maxTokenLen {
+		m = maxTokenLen
+	}
+	// longer word prefixes are always further away
+	depth := m + k
+	if depth > maxTokenLen {
+		depth = maxTokenLen
+	}
+
+	// rows[j][i] is the distance between term[:i] and word[:j], best[j] is the closest prefix up to j
+	var rows [maxTokenLen + 1][maxTokenLen + 1]int
+	var best [maxTokenLen + 1]int
+
+	for i := 0; i <= m; i++ {
+		rows[0][i] = i
+	}
+	best[0] = m
+
+	prev := ""
+	valid := 0
+
+	for wi := 0; wi < len(bucket); {
+		w := bucket[wi].text
+
+		shared := commonPrefix(prev, w)
+		if shared > valid {
+			shared = valid
+		}
+
+		n := len(w)
+		if n > depth {
+			n = depth
+		}
+
+		pruned := 0
+		for j := shared + 1; j <= n; j++ {
+			row, up := &rows[j], &rows[j-1]
+			row[0] = j
+			rowMin := j
+
+			for i := 1; i <= m; i++ {
+				cost := 1
+				if term[i-1] == w[j-1] {
+					cost = 0
+				}
+
+				v := up[i-1] + cost
+				if up[i]+1 < v {
+					v = up[i] + 1
+				}
+				if row[i-1]+1 < v {
+					v = row[i-1] + 1
+				}
+				// transposition
+				if i > 1 && j > 1 && term[i-1] == w[j-2] && term[i-2] == w[j-1] && rows[j-2][i-2]+1 < v {
+					v = rows[j-2][i-2] + 1
+				}
+
+				row[i] = v
+				if v < rowMin {
+					rowMin = v
+				}
+			}
+
+			best[j] = best[j-1]
+			if row[m] < best[j] {
+				best[j] = row[m]
+			}
+
+			// every word under w[:j] is too far
+			if rowMin > k {
+				pruned = j
+				break
+			}
+		}
+
+		prev = w
+
+		if pruned > 0 {
+			valid = pruned - 1
+
+			if best[pruned-1] <= k {
+				// a shorter prefix already matched
+				fn(&bucket[wi], best[pruned-1])
+			}
+
+			// words sharing a prefix are contiguous in the sorted bucket
+			prefix := w[:pruned]
+			rest := bucket[wi:]
+			skip := sort.Search(len(rest), func(x int) bool {
+				return !strings.HasPrefix(rest[x].text, prefix)
+			})
+
+			if best[pruned-1] <= k {
+				// the shorter prefix matches all of them too
+				for x := 1; x < skip; x++ {
+					fn(&rest[x], best[pruned-1])
+				}
+			}
+
+			wi += skip
+			continue
+		}
+
+		valid = n
+		if best[n] <= k {
+			fn(&bucket[wi], best[n])
+		}
+
+		wi++
+	}
+}
+
+func commonPrefix(a string, b string) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return n
+}
+
+// insert keeps top sorted, bounded to limit and with a single hit per symbol
+func (idx *index) insert(top []hit, h hit, limit int) []hit {
+	i := sort.Search(len(top), func(i int) bool {
+		return idx.less(h, top[i])
+	})
+	if i >= limit {
+		return top
+	}
+
+	for j := range top {
+		if top[j].symbol != h.symbol {
+			continue
+		}
+
+		// the symbol is already ranked at least as high
+		if j < i {
+			return top
+		}
+
+		top = append(top[:j], top[j+1:]...)
+		break
+	}
+
+	top = append(top, hit{})
+	copy(top[i+1:], top[i:])
+	top[i] = h
+
+	if len(top) > limit {
+		top = top[:limit]
+	}
+
+	return top
+}
+
+func (idx *index) less(a hit, b hit) bool {
+	if a.tier != b.tier {
+		return a.tier < b.tier
+	}
+	if a.dist != b.dist {
+		return a.dist < b.dist
+	}
+	if a.pos != b.pos {
+		return a.pos < b.pos
+	}
+	if a.extra != b.extra {
+		return a.extra < b.extra
+	}
+
+	ka, kb := idx.keys[a.symbol], idx.keys[b.symbol]
+	if len(ka) != len(kb) {
+		return len(ka) < len(kb)
+	}
+
+	return ka < kb
+}
+
+// maxEdits is the typo tolerance for a query word, short words must match exactly
+func maxEdits(term string) int {
+	switch {
+	case len(term) < 3:
+		return 0
+	case len(term) < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// tokenize lower cases and splits on anything that is not a letter or digit
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
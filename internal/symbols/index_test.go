@@ -0,0 +1,294 @@
+package symbols
+
+import (
+	"fmt"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getTestIndex() *index {
+	return newIndex([]types.Symbol{
+		{Symbol: "AAPL", Name: "Apple Inc."},
+		{Symbol: "APP", Name: "AppLovin Corporation"},
+		{Symbol: "APPN", Name: "Appian Corporation"},
+		{Symbol: "AMAT", Name: "Applied Materials, Inc."},
+		{Symbol: "MSFT", Name: "Microsoft Corporation"},
+		{Symbol: "META", Name: "Meta Platforms, Inc."},
+		{Symbol: "BAC", Name: "Bank of America Corporation"},
+		{Symbol: "BK", Name: "The Bank of New York Mellon Corporation"},
+	})
+}
+
+func symbolsOf(result []types.Symbol) []string {
+	symbols := make([]string, 0, len(result))
+	for _, v := range result {
+		symbols = append(symbols, v.Symbol)
+	}
+
+	return symbols
+}
+
+func TestIndex_Search(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected []string
+	}{
+		// exact symbol, then symbol prefix shortest first, then name
+		{query: "app", expected: []string{"APP", "APPN", "AAPL", "AMAT"}},
+		{query: "AAPL", expected: []string{"AAPL", "AMAT", "APP"}},
+		{query: "apple", expected: []string{"AAPL", "AMAT", "APP"}},
+		// one typo
+		{query: "appel", expected: []string{"AAPL", "AMAT", "APP"}},
+		{query: "mircosoft", expected: []string{"MSFT"}},
+		{query: "microsfot corp", expected: []string{"MSFT"}},
+		// every word must match
+		{query: "bank america", expected: []string{"BAC"}},
+		{query: "bank of", expected: []string{"BAC", "BK"}},
+		{query: "mellon", expected: []string{"BK"}},
+		// first letter typos are not tolerated
+		{query: "xicrosoft", expected: []string{}},
+		{query: "  ", expected: []string{}},
+	}
+
+	idx := getTestIndex()
+
+	for _, c := range cases {
+		got := symbolsOf(idx.search(c.query, 10))
+
+		if strings.Join(got, ",") != strings.Join(c.expected, ",") {
+			t.Errorf("%q: expected %v, got %v", c.query, c.expected, got)
+		}
+	}
+}
+
+func TestIndex_Search_Limit(t *testing.T) {
+	idx := getTestIndex()
+
+	got := symbolsOf(idx.search("a", 2))
+	if strings.Join(got, ",") != "APP,AAPL" {
+		t.Errorf("expected [APP AAPL], got %v", got)
+	}
+
+	got = symbolsOf(idx.search("a", 0))
+	if len(got) != 0 {
+		t.Errorf("expected no results, got %v", got)
+	}
+}
+
+func TestPrefixDistance(t *testing.T) {
+	cases := []struct {
+		q, t     string
+		k        int
+		expected int
+	}{
+		{"app", "apple", 1, 0},
+		{"appel", "apple", 1, 1},
+		{"aple", "apple", 1, 1},
+		{"applle", "apple", 2, 1},
+		{"mircosoft", "microsoft", 2, 1},
+		{"banana", "apple", 2, 3},
+		{"apple", "app", 2, 2},
+		{"apple", "ap", 2, 3},
+	}
+
+	for _, c := range cases {
+		if d := prefixDistance(c.q, c.t, c.k); d != c.expected {
+			t.Errorf("prefixDistance(%q, %q, %d): expected %d, got %d", c.q, c.t, c.k, c.expected, d)
+		}
+	}
+}
+
+// prefixDistance is the reference for scanWords, the optimal string alignment distance between q and the closest prefix of t,
+// anything above k is reported as k+1
+func prefixDistance(q string, t string, k int) int {
+	m, n := len(q), len(t)
+	if m > maxTokenLen {
+		m = maxTokenLen
+	}
+	// prefixes longer than q+k are always further away
+	if n > m+k {
+		n = m + k
+	}
+	if n > maxTokenLen {
+		n = maxTokenLen
+	}
+
+	// rows over q, columns over t, only the last three rows are needed
+	var rows [3][maxTokenLen + 1]int
+	prev2, prev, cur := &rows[0], &rows[1], &rows[2]
+
+	for j := 0; j <= n; j++ {
+		prev[j] = j
+	}
+
+	for i := 1; i <= m; i++ {
+		cur[0] = i
+		rowMin := i
+
+		for j := 1; j <= n; j++ {
+			cost := 1
+			if q[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			v := prev[j-1] + cost
+			if prev[j]+1 < v {
+				v = prev[j] + 1
+			}
+			if cur[j-1]+1 < v {
+				v = cur[j-1] + 1
+			}
+			// transposition
+			if i > 1 && j > 1 && q[i-1] == t[j-2] && q[i-2] == t[j-1] && prev2[j-2]+1 < v {
+				v = prev2[j-2] + 1
+			}
+
+			cur[j] = v
+			if v < rowMin {
+				rowMin = v
+			}
+		}
+
+		if rowMin > k {
+			return k + 1
+		}
+
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	best := k + 1
+	for j := 0; j <= n; j++ {
+		if prev[j] < best {
+			best = prev[j]
+		}
+	}
+
+	return best
+}
+
+// benchmarkSymbols generates a universe with random symbols and names made of random syllables
+func benchmarkSymbols(n int) []types.Symbol {
+	rnd := rand.New(rand.NewSource(1))
+	syllables := []string{"ap", "ple", "mi", "cro", "soft", "ban", "ka", "me", "ta", "tel", "net", "ron", "gen", "dyn", "ix", "lo", "vin", "cor", "tech", "sys"}
+	suffixes := []string{"Inc.", "Corporation", "Holdings", "Group", "PLC", "Ltd."}
+
+	word := func() string {
+		var b strings.Builder
+		for i := 0; i < 2+rnd.Intn(3); i++ {
+			b.WriteString(syllables[rnd.Intn(len(syllables))])
+		}
+
+		s := b.String()
+		return strings.ToUpper(s[:1]) + s[1:]
+	}
+
+	symbols := make([]types.Symbol, 0, n)
+	for i := 0; i < n; i++ {
+		var symbol strings.Builder
+		for j := 0; j < 1+rnd.Intn(5); j++ {
+			symbol.WriteByte(byte('A' + rnd.Intn(26)))
+		}
+
+		symbols = append(symbols, types.Symbol{
+			Symbol: fmt.Sprintf("%s%d", symbol.String(), i),
+			Name:   fmt.Sprintf("%s %s %s", word(), word(), suffixes[rnd.Intn(len(suffixes))]),
+		})
+	}
+
+	return symbols
+}
+
+func BenchmarkIndex_Search(b *testing.B) {
+	idx := newIndex(benchmarkSymbols(50000))
+
+	for _, query := range []string{"a", "app", "apple", "mircosoft", "bank tech"} {
+		b.Run(query, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				idx.search(query, DefaultSearchLimit)
+			}
+		})
+	}
+}
+
+func TestIndex_Search_PrefixOrder(t *testing.T) {
+	symbols := benchmarkSymbols(5000)
+	idx := newIndex(symbols)
+
+	for _, key := range []string{"A", "AB", "Z1"} {
+		// every symbol with the prefix, shorter first
+		var expected []string
+		for _, v := range symbols {
+			if strings.HasPrefix(v.Symbol, key) {
+				expected = append(expected, v.Symbol)
+			}
+		}
+		sort.Slice(expected, func(i, j int) bool {
+			if len(expected[i]) != len(expected[j]) {
+				return len(expected[i]) < len(expected[j])
+			}
+			return expected[i] < expected[j]
+		})
+		if len(expected) > DefaultSearchLimit {
+			expected = expected[:DefaultSearchLimit]
+		}
+
+		got := symbolsOf(idx.search(key, DefaultSearchLimit))
+		if len(got) < len(expected) || strings.Join(got[:len(expected)], ",") != strings.Join(expected, ",") {
+			t.Errorf("%s: expected %v first, got %v", key, expected, got)
+		}
+	}
+}
+
+// TestIndex_Search_Budget fails when a query over 50k symbols takes more than a millisecond,
+// the best of a few rounds is taken so a busy machine does not fail it
+func TestIndex_Search_Budget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test")
+	}
+
+	idx := newIndex(benchmarkSymbols(50000))
+	const budget = time.Millisecond
+
+	for _, query := range []string{"a", "app", "apple", "mircosoft", "bank tech"} {
+		best := time.Duration(-1)
+
+		for round := 0; round < 5; round++ {
+			start := time.Now()
+			for i := 0; i < 10; i++ {
+				idx.search(query, DefaultSearchLimit)
+			}
+
+			if elapsed := time.Since(start) / 10; best < 0 || elapsed < best {
+				best = elapsed
+			}
+		}
+
+		if best > budget {
+			t.Errorf("%q: expected a search within %v, took %v", query, budget, best)
+		}
+	}
+}
+
+func TestScanWords(t *testing.T) {
+	bucket := []word{{text: "apple"}, {text: "applied"}, {text: "applovin"}, {text: "apt"}, {text: "azure"}}
+
+	got := map[string]int{}
+	scanWords(bucket, "appel", 1, func(w *word, dist int) {
+		got[w.text] = dist
+	})
+
+	// every word agrees with the standalone distance
+	for _, w := range bucket {
+		d, ok := got[w.text]
+		expected := prefixDistance("appel", w.text, 1)
+		if (expected <= 1) != ok || (ok && d != expected) {
+			t.Errorf("%s: expected distance %d, got %d (found %v)", w.text, expected, d, ok)
+		}
+	}
+}
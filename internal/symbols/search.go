@@ -0,0 +1,35 @@
+package symbols
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+)
+
+const (
+	DefaultSearchLimit = 10
+	MaxSearchLimit     = 50
+)
+
+type SearchSymbolsInput struct {
+	Query string
+	// Limit defaults to DefaultSearchLimit
+	Limit int
+}
+
+type SearchSymbolsOutput struct {
+	// Symbols are sorted by relevance
+	Symbols []types.Symbol
+}
+
+func (s *service) SearchSymbols(_ context.Context, in *SearchSymbolsInput) (*SearchSymbolsOutput, error) {
+	limit := in.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	} else if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	return &SearchSymbolsOutput{
+		Symbols: s.index.search(in.Query, limit),
+	}, nil
+}
@@ -0,0 +1,51 @@
+package symbols
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+)
+
+var ErrInvalidConfig = errors.New("invalid symbols service config")
+
+var _ Service = (*service)(nil)
+
+type Service interface {
+	ListSymbols(ctx context.Context, in *ListSymbolsInput) (*ListSymbolsOutput, error)
+	GetSymbol(ctx context.Context, in *GetSymbolInput) (*GetSymbolOutput, error)
+	SearchSymbols(ctx context.Context, in *SearchSymbolsInput) (*SearchSymbolsOutput, error)
+}
+
+type Config struct {
+	Storage storage.SymbolStorage
+}
+
+func New(cfg *Config) (Service, error) {
+	if cfg == nil || cfg.Storage == nil {
+		return nil, ErrInvalidConfig
+	}
+
+	all, err := cfg.Storage.ListSymbols(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	// only symbols still trading are suggested
+	listed := make([]types.Symbol, 0, len(all))
+	for _, v := range all {
+		if v.Delisted.IsZero() {
+			listed = append(listed, v)
+		}
+	}
+
+	return &service{
+		storage: cfg.Storage,
+		index:   newIndex(listed),
+	}, nil
+}
+
+type service struct {
+	storage storage.SymbolStorage
+	index   *index
+}
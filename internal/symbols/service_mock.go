@@ -0,0 +1,43 @@
+//go:build test
+
+package symbols
+
+import (
+	"context"
+	"errors"
+)
+
+var _ Service = (*MockService)(nil)
+var ErrMockUncalledFor = errors.New("uncalled for")
+
+func NewMockService() Service {
+	return &MockService{
+		ListSymbolsFunc: func(ctx context.Context, in *ListSymbolsInput) (*ListSymbolsOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		GetSymbolFunc: func(ctx context.Context, in *GetSymbolInput) (*GetSymbolOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		SearchSymbolsFunc: func(ctx context.Context, in *SearchSymbolsInput) (*SearchSymbolsOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+	}
+}
+
+type MockService struct {
+	ListSymbolsFunc   func(ctx context.Context, in *ListSymbolsInput) (*ListSymbolsOutput, error)
+	GetSymbolFunc     func(ctx context.Context, in *GetSymbolInput) (*GetSymbolOutput, error)
+	SearchSymbolsFunc func(ctx context.Context, in *SearchSymbolsInput) (*SearchSymbolsOutput, error)
+}
+
+func (m *MockService) ListSymbols(ctx context.Context, in *ListSymbolsInput) (*ListSymbolsOutput, error) {
+	return m.ListSymbolsFunc(ctx, in)
+}
+
+func (m *MockService) GetSymbol(ctx context.Context, in *GetSymbolInput) (*GetSymbolOutput, error) {
+	return m.GetSymbolFunc(ctx, in)
+}
+
+func (m *MockService) SearchSymbols(ctx context.Context, in *SearchSymbolsInput) (*SearchSymbolsOutput, error) {
+	return m.SearchSymbolsFunc(ctx, in)
+}
@@ -0,0 +1,93 @@
+package symbols
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"strings"
+	"time"
+)
+
+type ListSymbolsInput struct {
+	// Exchange and Sector filter when set, case-insensitive
+	Exchange string
+	Sector   string
+
+	IncludeDelisted bool
+}
+
+type ListSymbolsOutput struct {
+	Symbols []types.Symbol
+}
+
+func (s *service) ListSymbols(ctx context.Context, in *ListSymbolsInput) (*ListSymbolsOutput, error) {
+	all, err := s.storage.ListSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]types.Symbol, 0, len(all))
+
+	for _, v := range all {
+		if !in.IncludeDelisted && !v.Delisted.IsZero() {
+			continue
+		}
+		if in.Exchange != "" && !strings.EqualFold(v.Exchange, in.Exchange) {
+			continue
+		}
+		if in.Sector != "" && !strings.EqualFold(v.Sector, in.Sector) {
+			continue
+		}
+
+		symbols = append(symbols, v)
+	}
+
+	return &ListSymbolsOutput{
+		Symbols: symbols,
+	}, nil
+}
+
+type GetSymbolInput struct {
+	Symbol string
+}
+
+type GetSymbolOutput struct {
+	Symbol *types.Symbol
+}
+
+func (s *service) GetSymbol(ctx context.Context, in *GetSymbolInput) (*GetSymbolOutput, error) {
+	key := strings.ToUpper(in.Symbol)
+
+	symbol, err := s.storage.GetSymbol(ctx, key)
+	var errNotFound *types.ErrSymbolNotFound
+	if errors.As(err, &errNotFound) {
+		// a previous symbol resolves to the last instrument that used it
+		candidates, rErr := s.storage.ResolveSymbol(ctx, key)
+		if rErr != nil {
+			return nil, err
+		}
+
+		symbol = lastToUse(candidates, key)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &GetSymbolOutput{
+		Symbol: symbol,
+	}, nil
+}
+
+func lastToUse(candidates []types.Symbol, symbol string) *types.Symbol {
+	var last *types.Symbol
+	var from time.Time
+
+	for i, c := range candidates {
+		for _, l := range c.Listings {
+			if l.Symbol == symbol && (last == nil || l.From.After(from)) {
+				last, from = &candidates[i], l.From
+			}
+		}
+	}
+
+	return last
+}
@@ -0,0 +1,155 @@
+package symbols
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"testing"
+	"time"
+)
+
+func getTestService() Service {
+	delisted, _ := time.Parse("2006-01-02", "2022-11-08")
+
+	svc, _ := New(&Config{
+		Storage: storage.NewMemorySymbols([]types.Symbol{
+			{Symbol: "AAPL", Exchange: "NASDAQ", Sector: "Information Technology"},
+			{Symbol: "JPM", Exchange: "NYSE", Sector: "Financials"},
+			{Symbol: "TWTR", Exchange: "NYSE", Sector: "Communication Services", Delisted: delisted},
+		}),
+	})
+
+	return svc
+}
+
+func TestService_New(t *testing.T) {
+	_, err := New(nil)
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected error to be ErrInvalidConfig, got %v", err)
+	}
+
+	_, err = New(&Config{})
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected error to be ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestService_ListSymbols(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    *ListSymbolsInput
+		count int
+	}{
+		{name: "listed only", in: &ListSymbolsInput{}, count: 2},
+		{name: "include delisted", in: &ListSymbolsInput{IncludeDelisted: true}, count: 3},
+		{name: "exchange", in: &ListSymbolsInput{Exchange: "nyse"}, count: 1},
+		{name: "exchange with delisted", in: &ListSymbolsInput{Exchange: "NYSE", IncludeDelisted: true}, count: 2},
+		{name: "sector", in: &ListSymbolsInput{Sector: "financials"}, count: 1},
+		{name: "no match", in: &ListSymbolsInput{Exchange: "LSE"}, count: 0},
+	}
+
+	svc := getTestService()
+
+	for _, c := range cases {
+		out, err := svc.ListSymbols(context.Background(), c.in)
+		if err != nil {
+			t.Errorf("%s: expected error to be nil, got %v", c.name, err)
+			continue
+		}
+
+		if len(out.Symbols) != c.count {
+			t.Errorf("%s: expected %d symbols, got %d", c.name, c.count, len(out.Symbols))
+		}
+	}
+}
+
+func TestService_GetSymbol(t *testing.T) {
+	svc := getTestService()
+
+	out, err := svc.GetSymbol(context.Background(), &GetSymbolInput{Symbol: "aapl"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if out.Symbol.Symbol != "AAPL" {
+		t.Errorf("expected symbol AAPL, got %s", out.Symbol.Symbol)
+	}
+
+	_, err = svc.GetSymbol(context.Background(), &GetSymbolInput{Symbol: "NOPE"})
+
+	var errNotFound *types.ErrSymbolNotFound
+	if !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be %T, got %T", errNotFound, err)
+	}
+}
+
+func TestService_SearchSymbols(t *testing.T) {
+	svc := getTestService()
+
+	out, err := svc.SearchSymbols(context.Background(), &SearchSymbolsInput{Query: "a"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if len(out.Symbols) != 1 || out.Symbols[0].Symbol != "AAPL" {
+		t.Errorf("expected [AAPL], got %v", out.Symbols)
+	}
+
+	// delisted symbols are not suggested
+	out, _ = svc.SearchSymbols(context.Background(), &SearchSymbolsInput{Query: "TWTR"})
+	if len(out.Symbols) != 0 {
+		t.Errorf("expected no symbols, got %v", out.Symbols)
+	}
+}
+
+func TestService_SearchSymbols_Limit(t *testing.T) {
+	all := make([]types.Symbol, 0, 100)
+	for i := 0; i < 100; i++ {
+		all = append(all, types.Symbol{Symbol: fmt.Sprintf("A%d", i)})
+	}
+
+	svc, _ := New(&Config{Storage: storage.NewMemorySymbols(all)})
+
+	out, _ := svc.SearchSymbols(context.Background(), &SearchSymbolsInput{Query: "A"})
+	if len(out.Symbols) != DefaultSearchLimit {
+		t.Errorf("expected %d symbols, got %d", DefaultSearchLimit, len(out.Symbols))
+	}
+
+	out, _ = svc.SearchSymbols(context.Background(), &SearchSymbolsInput{Query: "A", Limit: 1000})
+	if len(out.Symbols) != MaxSearchLimit {
+		t.Errorf("expected %d symbols, got %d", MaxSearchLimit, len(out.Symbols))
+	}
+}
+
+func TestService_GetSymbol_Renamed(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	svc, _ := New(&Config{
+		Storage: storage.NewMemorySymbols([]types.Symbol{
+			{
+				Symbol: "META",
+				Listings: []types.Listing{
+					{Symbol: "FB", From: date("2012-05-18"), To: date("2022-06-09")},
+					{Symbol: "META", From: date("2022-06-09")},
+				},
+			},
+		}),
+	})
+
+	out, err := svc.GetSymbol(context.Background(), &GetSymbolInput{Symbol: "fb"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if out.Symbol.Symbol != "META" {
+		t.Errorf("expected FB to resolve to META, got %s", out.Symbol.Symbol)
+	}
+}
@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/symbols/endpoint"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"time"
+)
+
+func ListSymbolsRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+
+	return &endpoint.ListSymbolsRequest{
+		Exchange: q.Get("exchange"),
+		Sector:   q.Get("sector"),
+		Delisted: q.Get("delisted"),
+	}, nil
+}
+
+func ListSymbolsResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.ListSymbolsResponse)
+
+	symbols := make([]interface{}, 0, len(res.Symbols))
+	for _, v := range res.Symbols {
+		symbols = append(symbols, encodeSymbol(v))
+	}
+
+	return kit.EncodeResponse(ctx, w, http.StatusOK, symbols)
+}
+
+func GetSymbolRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	return &endpoint.GetSymbolRequest{
+		Symbol: chi.URLParam(r, "symbol"),
+	}, nil
+}
+
+func GetSymbolResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.GetSymbolResponse)
+
+	return kit.EncodeResponse(ctx, w, http.StatusOK, encodeSymbol(*res.Symbol))
+}
+
+// encodeSymbol formats dates at transport output, delisted and listing ends are null while trading
+func encodeSymbol(v types.Symbol) map[string]interface{} {
+	listings := make([]interface{}, 0, len(v.Listings))
+	for _, l := range v.Listings {
+		listings = append(listings, map[string]interface{}{
+			"symbol": l.Symbol,
+			"from":   formatDate(l.From),
+			"to":     formatDate(l.To),
+		})
+	}
+
+	return map[string]interface{}{
+		"instrument_id": v.InstrumentID,
+		"symbol":        v.Symbol,
+		"name":          v.Name,
+		"exchange":      v.Exchange,
+		"sector":        v.Sector,
+		"industry":      v.Industry,
+		"currency":      v.Currency,
+		"isin":          v.ISIN,
+		"figi":          v.FIGI,
+		"listed":        formatDate(v.Listed),
+		"delisted":      formatDate(v.Delisted),
+		"listings":      listings,
+	}
+}
+
+func formatDate(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.Format("2006-01-02")
+}
+
+func SearchSymbolsRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+
+	return &endpoint.SearchSymbolsRequest{
+		Query: q.Get("q"),
+		Limit: q.Get("limit"),
+	}, nil
+}
+
+func SearchSymbolsResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.SearchSymbolsResponse)
+
+	symbols := make([]interface{}, 0, len(res.Symbols))
+	for _, v := range res.Symbols {
+		symbols = append(symbols, encodeSymbol(v))
+	}
+
+	return kit.EncodeResponse(ctx, w, http.StatusOK, symbols)
+}
@@ -0,0 +1,137 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/symbols/endpoint"
+	"github.com/falmar/richerage-api/internal/symbols/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListSymbols_RequestDecoder(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/symbols?exchange=NYSE&sector=Financials&delisted=true", nil)
+
+	out, err := ListSymbolsRequestDecoder(context.Background(), r)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	req, ok := out.(*endpoint.ListSymbolsRequest)
+	if !ok || req == nil {
+		t.Errorf("expected request to be of type ListSymbolsRequest, got %T", out)
+		return
+	}
+
+	if req.Exchange != "NYSE" || req.Sector != "Financials" || req.Delisted != "true" {
+		t.Errorf("expected filters to be decoded, got %+v", req)
+	}
+}
+
+func TestListSymbols_ResponseEncoder(t *testing.T) {
+	listed, _ := time.Parse("2006-01-02", "2013-11-07")
+	delisted, _ := time.Parse("2006-01-02", "2022-11-08")
+
+	w := httptest.NewRecorder()
+	err := ListSymbolsResponseEncoder(context.Background(), w, &endpoint.ListSymbolsResponse{
+		Symbols: []types.Symbol{
+			{Symbol: "AAPL", Listed: listed},
+			{Symbol: "TWTR", Listed: listed, Delisted: delisted},
+		},
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	var body []map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	if len(body) != 2 {
+		t.Errorf("expected 2 symbols, got %d", len(body))
+		return
+	}
+
+	if body[0]["listed"] != "2013-11-07" {
+		t.Errorf("expected listed date to be formatted, got %v", body[0]["listed"])
+	}
+	if body[0]["delisted"] != nil {
+		t.Errorf("expected delisted to be null, got %v", body[0]["delisted"])
+	}
+	if body[1]["delisted"] != "2022-11-08" {
+		t.Errorf("expected delisted date to be formatted, got %v", body[1]["delisted"])
+	}
+}
+
+func TestGetSymbol_ResponseEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := GetSymbolResponseEncoder(context.Background(), w, &endpoint.GetSymbolResponse{
+		Symbol: &types.Symbol{
+			InstrumentID: "INS1",
+			Symbol:       "META",
+			ISIN:         "US30303M1027",
+			Listings: []types.Listing{
+				{Symbol: "FB", From: time.Date(2012, 5, 18, 0, 0, 0, 0, time.UTC), To: time.Date(2022, 6, 9, 0, 0, 0, 0, time.UTC)},
+				{Symbol: "META", From: time.Date(2022, 6, 9, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	var body map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	if body["symbol"] != "META" || body["isin"] != "US30303M1027" || body["instrument_id"] != "INS1" {
+		t.Errorf("unexpected body %v", body)
+	}
+
+	listings, _ := body["listings"].([]interface{})
+	if len(listings) != 2 {
+		t.Errorf("expected 2 listings, got %v", body["listings"])
+		return
+	}
+
+	if fb := listings[0].(map[string]interface{}); fb["symbol"] != "FB" || fb["to"] != "2022-06-09" {
+		t.Errorf("unexpected FB listing %v", fb)
+	}
+	if meta := listings[1].(map[string]interface{}); meta["from"] != "2022-06-09" || meta["to"] != nil {
+		t.Errorf("unexpected META listing %v", meta)
+	}
+}
+
+func TestSearchSymbols_RequestDecoder(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/symbols/search?q=app&limit=5", nil)
+
+	out, err := SearchSymbolsRequestDecoder(context.Background(), r)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	req, ok := out.(*endpoint.SearchSymbolsRequest)
+	if !ok || req == nil {
+		t.Errorf("expected request to be of type SearchSymbolsRequest, got %T", out)
+		return
+	}
+
+	if req.Query != "app" || req.Limit != "5" {
+		t.Errorf("expected query and limit to be decoded, got %+v", req)
+	}
+}
+
+func TestSearchSymbols_ResponseEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := SearchSymbolsResponseEncoder(context.Background(), w, &endpoint.SearchSymbolsResponse{
+		Symbols: []types.Symbol{},
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	// no matches is an empty list, not null
+	if w.Body.String() != "[]\n" {
+		t.Errorf("expected empty list, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,19 @@
+package types
+
+import "fmt"
+
+type ErrSymbolNotFound struct {
+	Symbol string
+}
+
+func (e *ErrSymbolNotFound) HttpCode() int {
+	return 404
+}
+
+func (e *ErrSymbolNotFound) Code() string {
+	return "symbol_not_found"
+}
+
+func (e *ErrSymbolNotFound) Error() string {
+	return fmt.Sprintf("symbol %s not found", e.Symbol)
+}
@@ -0,0 +1,42 @@
+package types
+
+import "time"
+
+// Symbol is the reference data of an instrument under its current symbol
+type Symbol struct {
+	// InstrumentID is stable across renames, Symbol is not
+	InstrumentID string
+
+	Symbol   string
+	Name     string
+	Exchange string
+	Sector   string
+	Industry string
+	Currency string
+	ISIN     string
+	FIGI     string
+
+	Listed time.Time
+	// Delisted is zero while the symbol is still trading
+	Delisted time.Time
+
+	// Listings are the symbols the instrument traded under, oldest first, the last one is Symbol
+	Listings []Listing
+}
+
+// Listing is a symbol an instrument traded under between From and To
+type Listing struct {
+	Symbol string
+	From   time.Time
+	// To is the first day under the next symbol or the delisting date, zero while trading
+	To time.Time
+}
+
+// Covers reports whether the listing was valid on date
+func (l Listing) Covers(date time.Time) bool {
+	if date.Before(l.From) {
+		return false
+	}
+
+	return l.To.IsZero() || date.Before(l.To)
+}
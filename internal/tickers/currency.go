@@ -0,0 +1,86 @@
+package tickers
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"time"
+)
+
+// minPriceScale is the least precision of derived prices: adjusted or converted
+const minPriceScale = 2
+
+// factorScale is the precision of intermediate adjustment factors
+const factorScale = 12
+
+// defaultCurrency is assumed for prices of symbols without a known currency
+const defaultCurrency = "USD"
+
+// symbolCurrency is the currency symbol trades in according to the symbol master
+func (s *service) symbolCurrency(ctx context.Context, symbol string) string {
+	v, err := s.symbols.GetSymbol(ctx, symbol)
+	if err != nil || v.Currency == "" {
+		return defaultCurrency
+	}
+
+	return v.Currency
+}
+
+// checkCurrency fails for currencies without rates, before any work is done for them
+func (s *service) checkCurrency(ctx context.Context, currency string) error {
+	if currency == "" {
+		return nil
+	}
+
+	_, err := s.fx.GetRate(ctx, defaultCurrency, currency, time.Now())
+
+	return err
+}
+
+// convert price from one currency to another with the rate of date, see scalePrice
+func (s *service) convert(ctx context.Context, price decimal.Decimal, from string, to string, date time.Time) (decimal.Decimal, error) {
+	if from == to {
+		return price, nil
+	}
+
+	rate, err := s.fx.GetRate(ctx, from, to, date)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	return scalePrice(price, rate), nil
+}
+
+// convertBar converts every price of the bar with the rate of its date
+func (s *service) convertBar(ctx context.Context, v types.TickerHistory, to string) (types.TickerHistory, error) {
+	if v.Currency == to {
+		return v, nil
+	}
+
+	rate, err := s.fx.GetRate(ctx, v.Currency, to, v.Date)
+	if err != nil {
+		return v, err
+	}
+
+	for _, p := range []*decimal.Decimal{&v.Price, &v.Open, &v.High, &v.Low} {
+		*p = scalePrice(*p, rate)
+	}
+	v.Currency = to
+
+	return v, nil
+}
+
+// scalePrice multiplies price by factor, a factor of 1 keeps price as it is, otherwise the result
+// is rounded to the scale of price and at least minPriceScale
+func scalePrice(price decimal.Decimal, factor decimal.Decimal) decimal.Decimal {
+	if factor.Cmp(decimal.NewFromInt(1)) == 0 {
+		return price
+	}
+
+	scale := price.Scale()
+	if scale < minPriceScale {
+		scale = minPriceScale
+	}
+
+	return price.MulRound(factor, scale)
+}
@@ -0,0 +1,197 @@
+//go:build test
+
+package tickers
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+	"time"
+)
+
+func getFixtureFX() storage.FXStorage {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	return storage.NewMemoryFX([]types.FXRate{
+		{Currency: "EUR", Date: date("2023-01-03"), Rate: decimal.MustParse("0.5")},
+		{Currency: "EUR", Date: date("2023-01-05"), Rate: decimal.MustParse("0.8")},
+		{Currency: "GBP", Date: date("2023-01-03"), Rate: decimal.MustParse("0.4")},
+	})
+}
+
+func TestTickers_Tickers_Currency(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	st.(*storage.MockStorage).GetByUserFunc = func(ctx context.Context, username string) ([]types.Ticker, error) {
+		return []types.Ticker{
+			{Symbol: "AAPL", Price: decimal.MustParse("100"), Currency: "USD"},
+			{Symbol: "BP", Price: decimal.MustParse("4")},
+		}, nil
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+		FX:      getFixtureFX(),
+	})
+
+	// trading currency, missing currencies come from the symbol master
+	out, err := svc.GetTickers(ctx, &GetTickersInput{Username: "test"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if out.Tickers[0].Currency != "USD" || out.Tickers[1].Currency != "GBP" {
+		t.Errorf("expected USD and GBP, got %s and %s", out.Tickers[0].Currency, out.Tickers[1].Currency)
+	}
+
+	// the latest rate: 0.8 EUR per USD, 0.8 / 0.4 EUR per GBP
+	out, err = svc.GetTickers(ctx, &GetTickersInput{Username: "test", Currency: "EUR"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	expected := []string{"80.00", "8.00"}
+	for i, v := range out.Tickers {
+		if v.Price.String() != expected[i] || v.Currency != "EUR" {
+			t.Errorf("expected %s to be %v EUR, got %v %s", v.Symbol, expected[i], v.Price, v.Currency)
+		}
+	}
+}
+
+func TestTickers_History_Currency(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		var history []types.TickerHistory
+		for _, d := range []string{"2023-01-03", "2023-01-04", "2023-01-05", "2023-01-06"} {
+			date, _ := time.Parse("2006-01-02", d)
+			history = append(history, types.TickerHistory{Date: date, Price: decimal.MustParse("100")})
+		}
+
+		return history, nil
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+		FX:      getFixtureFX(),
+	})
+
+	out, err := svc.GetTickerHistory(ctx, &GetTickerHistoryInput{
+		Symbol:   "AAPL",
+		Currency: "EUR",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	// each date uses its own rate, dates without one the previous rate
+	expected := []string{"2023-01-06 80.00 EUR", "2023-01-05 80.00 EUR", "2023-01-04 50.00 EUR", "2023-01-03 50.00 EUR"}
+	if len(out.History) != len(expected) {
+		t.Errorf("expected %d records, got %d", len(expected), len(out.History))
+		return
+	}
+
+	for i, v := range out.History {
+		if got := v.Date.Format("2006-01-02") + " " + v.Price.String() + " " + v.Currency; got != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], got)
+		}
+	}
+}
+
+func TestTickers_History_Currency_LargePrice(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	date, _ := time.Parse("2006-01-02", "2023-01-03")
+
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		return []types.TickerHistory{{Date: date, Price: decimal.MustParse("70000.00"), Currency: "USD"}}, nil
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+		// rates are stored with 10 decimals, 70000.00 * 150 at scale 12 overflows an int64
+		FX: storage.NewMemoryFX([]types.FXRate{
+			{Currency: "JPY", Date: date, Rate: decimal.New(1500000000000, 10)},
+		}),
+	})
+
+	in := GetTickerHistoryInput{Symbol: "AAPL", Currency: "JPY"}
+
+	out, err := svc.GetTickerHistory(ctx, &in)
+	if err != nil || len(out.History) != 1 {
+		t.Errorf("expected a record and error to be nil, got %v", err)
+		return
+	}
+	if v := out.History[0].Price.String(); v != "10500000.00" {
+		t.Errorf("expected 10500000.00 JPY, got %s", v)
+	}
+
+	stream, err := svc.StreamTickerHistory(ctx, &StreamTickerHistoryInput{GetTickerHistoryInput: in})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	for chunk := range stream.Chunks {
+		if chunk.Err != nil || chunk.History[0].Price.String() != "10500000.00" {
+			t.Errorf("expected 10500000.00 JPY, got %v %v", chunk.History, chunk.Err)
+		}
+	}
+}
+
+func TestTickers_Currency_NotSupported(t *testing.T) {
+	ctx := context.Background()
+
+	// no storage call for an unsupported currency
+	svc, _ := New(&Config{
+		Storage: storage.NewMock(),
+		FX:      getFixtureFX(),
+	})
+
+	var errCurrency *types.ErrCurrencyNotSupported
+
+	_, err := svc.GetTickers(ctx, &GetTickersInput{Username: "test", Currency: "JPY"})
+	if !errors.As(err, &errCurrency) || errCurrency.Currency != "JPY" {
+		t.Errorf("expected error to be %T, got %v", errCurrency, err)
+	}
+
+	_, err = svc.GetTickerHistory(ctx, &GetTickerHistoryInput{Symbol: "AAPL", Currency: "JPY"})
+	if !errors.As(err, &errCurrency) || errCurrency.Code() != "currency_not_supported" {
+		t.Errorf("expected error to be %T, got %v", errCurrency, err)
+	}
+}
+
+func TestTickers_ScalePrice(t *testing.T) {
+	cases := []struct {
+		price    string
+		factor   string
+		expected string
+	}{
+		// no factor, the stored scale is kept
+		{price: "556.97", factor: "1", expected: "556.97"},
+		{price: "100", factor: "1.000000000000", expected: "100"},
+		// at least 2 decimals once a factor applies
+		{price: "100", factor: "0.5", expected: "50.00"},
+		{price: "102.5", factor: "0.333333333333", expected: "34.17"},
+		// a finer stored scale is kept
+		{price: "556.975", factor: "0.5", expected: "278.488"},
+	}
+
+	for _, c := range cases {
+		if got := scalePrice(decimal.MustParse(c.price), decimal.MustParse(c.factor)).String(); got != c.expected {
+			t.Errorf("%s * %s: expected %s, got %s", c.price, c.factor, c.expected, got)
+		}
+	}
+}
@@ -8,18 +8,24 @@ import (
 	"github.com/falmar/richerage-api/internal/tickers"
 	"github.com/falmar/richerage-api/internal/tickers/types"
 	kitendpoint "github.com/go-kit/kit/endpoint"
+	"strings"
 	"time"
 )
 
 type TickerHistoryRequest struct {
 	Username string
 
-	Symbol string
-	Before string
+	Symbol     string
+	Before     string
+	Adjusted   string
+	Currency   string
+	Resolution string
 }
 
 type TickerHistoryResponse struct {
 	Tickers []types.TickerHistory
+	// Resolution of the bars, the transport formats dates by it
+	Resolution types.Resolution
 }
 
 func MakeTickerHistoryEndpoint(svc tickers.Service) kitendpoint.Endpoint {
@@ -29,22 +35,16 @@ func MakeTickerHistoryEndpoint(svc tickers.Service) kitendpoint.Endpoint {
 			return nil, err
 		}
 
-		var before = time.Time{}
-		if req.Before != "" {
-			before, _ = time.Parse(time.RFC3339, req.Before)
-		}
-
-		out, err := svc.GetTickerHistory(ctx, &tickers.GetTickerHistoryInput{
-			Symbol: req.Symbol,
-			Before: before,
-		})
+		in := tickerHistoryInput(req)
 
+		out, err := svc.GetTickerHistory(ctx, in)
 		if err != nil {
 			return nil, err
 		}
 
 		return &TickerHistoryResponse{
-			Tickers: out.History,
+			Tickers:    out.History,
+			Resolution: in.Resolution,
 		}, nil
 	}
 }
@@ -77,6 +77,41 @@ func verifyTickerHistoryRequest(request interface{}) (*TickerHistoryRequest, err
 		}
 	}
 
+	badParams := tickerHistoryParams(req)
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
+
+// tickerHistoryInput converts a verified request into the service input
+func tickerHistoryInput(req *TickerHistoryRequest) *tickers.GetTickerHistoryInput {
+	resolution := types.Resolution1d
+	if req.Resolution != "" {
+		resolution = types.Resolution(req.Resolution)
+	}
+
+	var before = time.Time{}
+	if req.Before != "" {
+		before, _ = time.Parse(time.RFC3339, req.Before)
+	}
+
+	return &tickers.GetTickerHistoryInput{
+		Symbol:     req.Symbol,
+		Before:     before,
+		Adjusted:   types.Adjustment(req.Adjusted),
+		Currency:   strings.ToUpper(req.Currency),
+		Resolution: resolution,
+	}
+}
+
+// tickerHistoryParams returns the invalid or missing parameters of req
+func tickerHistoryParams(req *TickerHistoryRequest) map[string]string {
 	badParams := map[string]string{}
 
 	if req.Username == "" {
@@ -91,12 +126,18 @@ func verifyTickerHistoryRequest(request interface{}) (*TickerHistoryRequest, err
 		}
 	}
 
-	if len(badParams) > 0 {
-		return nil, &kit.BadRequestError{
-			Message: "one or more parameters are invalid or missing",
-			Params:  badParams,
-		}
+	switch types.Adjustment(req.Adjusted) {
+	case "", types.AdjustmentNone, types.AdjustmentSplit, types.AdjustmentTotal:
+	default:
+		badParams["adjusted"] = fmt.Sprintf("invalid value %s: expected one of none, split, total", req.Adjusted)
 	}
 
-	return req, nil
+	if req.Currency != "" && !isCurrencyCode(req.Currency) {
+		badParams["currency"] = fmt.Sprintf("invalid format %s: expected a 3 letter ISO 4217 code", req.Currency)
+	}
+	if req.Resolution != "" && !types.Resolution(req.Resolution).Valid() {
+		badParams["resolution"] = fmt.Sprintf("invalid value %s: expected one of 1m, 5m, 15m, 1h, 1d", req.Resolution)
+	}
+
+	return badParams
 }
@@ -0,0 +1,95 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/auth"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+)
+
+// maxHistoryChunkSize bounds the records a client may ask for in a single chunk
+const maxHistoryChunkSize = 5000
+
+type TickerHistoryStreamRequest struct {
+	TickerHistoryRequest
+
+	// ChunkSize of the streamed chunks, the service default when zero
+	ChunkSize int
+}
+
+type TickerHistoryStreamResponse struct {
+	Chunks <-chan types.HistoryChunk
+	// Resolution of the bars, the transport formats dates by it
+	Resolution types.Resolution
+}
+
+func MakeTickerHistoryStreamEndpoint(svc tickers.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyTickerHistoryStreamRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		in := tickerHistoryInput(&req.TickerHistoryRequest)
+
+		out, err := svc.StreamTickerHistory(ctx, &tickers.StreamTickerHistoryInput{
+			GetTickerHistoryInput: *in,
+			ChunkSize:             req.ChunkSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &TickerHistoryStreamResponse{
+			Chunks:     out.Chunks,
+			Resolution: in.Resolution,
+		}, nil
+	}
+}
+
+func MakeTickerHistoryStreamAuthEndpoint(svc auth.Service, e kitendpoint.Endpoint) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		token, _ := ctx.Value("auth_token").(string)
+
+		// assume that the username is valid and exists in the database
+		out, err := svc.VerifyToken(ctx, &auth.VerifyTokenInput{
+			Token: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if req, ok := request.(*TickerHistoryStreamRequest); ok && req != nil {
+			req.Username = out.Username
+		}
+
+		return e(ctx, request)
+	}
+}
+
+func verifyTickerHistoryStreamRequest(request interface{}) (*TickerHistoryStreamRequest, error) {
+	req, ok := request.(*TickerHistoryStreamRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := tickerHistoryParams(&req.TickerHistoryRequest)
+
+	if req.ChunkSize < 0 || req.ChunkSize > maxHistoryChunkSize {
+		badParams["chunk_size"] = fmt.Sprintf("invalid value %d: expected at most %d", req.ChunkSize, maxHistoryChunkSize)
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
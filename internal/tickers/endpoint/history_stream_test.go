@@ -0,0 +1,104 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/auth"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+)
+
+func TestEndpointHistoryStream(t *testing.T) {
+	ctx := context.Background()
+
+	chunks := make(chan types.HistoryChunk)
+
+	svc := tickers.NewMockService()
+	svc.(*tickers.MockService).StreamTickerHistoryFunc = func(ctx context.Context, in *tickers.StreamTickerHistoryInput) (*tickers.StreamTickerHistoryOutput, error) {
+		if in.Symbol != "AAPL" {
+			t.Errorf("expected symbol to be AAPL, got %s", in.Symbol)
+		}
+		if in.Before.IsZero() {
+			t.Errorf("expected before to be set")
+		}
+		if in.ChunkSize != 10 {
+			t.Errorf("expected chunk size to be 10, got %d", in.ChunkSize)
+		}
+
+		return &tickers.StreamTickerHistoryOutput{
+			Chunks: chunks,
+		}, nil
+	}
+
+	resp, err := MakeTickerHistoryStreamEndpoint(svc)(ctx, &TickerHistoryStreamRequest{
+		TickerHistoryRequest: *getDefaultTickerHistoryRequest(),
+		ChunkSize:            10,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+	}
+
+	r, ok := resp.(*TickerHistoryStreamResponse)
+	if !ok || r.Chunks == nil {
+		t.Errorf("expected response to have chunks set, got %T", resp)
+	} else if r.Resolution != types.Resolution1d {
+		t.Errorf("expected resolution to be 1d, got %s", r.Resolution)
+	}
+}
+
+func TestEndpointHistoryStream_VerifyRequest(t *testing.T) {
+	var badRequest *kit.BadRequestError
+
+	_, err := verifyTickerHistoryStreamRequest(getDefaultTickerHistoryRequest())
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be of type BadRequestError, got %T", err)
+	}
+
+	_, err = verifyTickerHistoryStreamRequest(&TickerHistoryStreamRequest{TickerHistoryRequest: *getDefaultTickerHistoryRequest()})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+	}
+
+	badRequest = nil
+	_, err = verifyTickerHistoryStreamRequest(&TickerHistoryStreamRequest{
+		TickerHistoryRequest: TickerHistoryRequest{Username: "test"},
+		ChunkSize:            maxHistoryChunkSize + 1,
+	})
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be of type BadRequestError, got %T", err)
+	} else {
+		for _, param := range []string{"symbol", "chunk_size"} {
+			if v, ok := badRequest.Params[param]; !ok || v == "" {
+				t.Errorf("expected bad request parameter %s error message, got %s", param, v)
+			}
+		}
+	}
+}
+
+func TestEndpointHistoryStream_Auth(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "auth_token", "test")
+
+	svc := auth.NewMockService()
+	svc.(*auth.MockService).VerifyTokenFunc = func(ctx context.Context, in *auth.VerifyTokenInput) (*auth.VerifyTokenOutput, error) {
+		return &auth.VerifyTokenOutput{
+			Username: "john.doe",
+		}, nil
+	}
+
+	req := &TickerHistoryStreamRequest{}
+
+	_, err := MakeTickerHistoryStreamAuthEndpoint(svc, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, nil
+	})(ctx, req)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+	}
+
+	if req.Username != "john.doe" {
+		t.Errorf("expected username to be john.doe, got %s", req.Username)
+	}
+}
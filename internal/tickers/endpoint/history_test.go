@@ -7,6 +7,7 @@ import (
 	"errors"
 	"github.com/falmar/richerage-api/internal/auth"
 	authtypes "github.com/falmar/richerage-api/internal/auth/types"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
 	"github.com/falmar/richerage-api/internal/pkg/kit"
 	"github.com/falmar/richerage-api/internal/tickers"
 	richeragetypes "github.com/falmar/richerage-api/internal/tickers/types"
@@ -33,7 +34,7 @@ func TestEndpointHistory(t *testing.T) {
 		return &tickers.GetTickerHistoryOutput{
 			History: []richeragetypes.TickerHistory{
 				{
-					Price: 100,
+					Price: decimal.MustParse("100"),
 					Date:  date,
 				},
 			},
@@ -59,8 +60,8 @@ func TestEndpointHistory(t *testing.T) {
 		return
 	}
 
-	if endRes.Tickers[0].Price != 100 {
-		t.Errorf("expected ticker price to be 100, got %f", endRes.Tickers[0].Price)
+	if !endRes.Tickers[0].Price.Equal(decimal.MustParse("100")) {
+		t.Errorf("expected ticker price to be 100, got %s", endRes.Tickers[0].Price)
 	}
 	if endRes.Tickers[0].Date != date {
 		t.Errorf("expected ticker date to be %s, got %s", date, endRes.Tickers[0].Date)
@@ -273,3 +274,99 @@ func TestEndpointHistory_Auth_Error(t *testing.T) {
 		t.Errorf("expected error to be of type ErrUnauthorized, got %T", err)
 	}
 }
+
+func TestEndpointHistory_VerifyRequest_Adjusted(t *testing.T) {
+	for _, v := range []string{"", "none", "split", "total"} {
+		req := getDefaultTickerHistoryRequest()
+		req.Adjusted = v
+
+		if _, err := verifyTickerHistoryRequest(req); err != nil {
+			t.Errorf("expected error to be nil for %q, got %T", v, err)
+		}
+	}
+
+	req := getDefaultTickerHistoryRequest()
+	req.Adjusted = "dividend"
+
+	_, err := verifyTickerHistoryRequest(req)
+
+	var badRequest *kit.BadRequestError
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be of type BadRequestError, got %T", err)
+		return
+	}
+	if v, ok := badRequest.Params["adjusted"]; !ok || v == "" {
+		t.Errorf("expected bad request parameter adjusted error message, got %s", v)
+	}
+}
+
+func TestEndpointHistory_VerifyRequest_Currency(t *testing.T) {
+	req := getDefaultTickerHistoryRequest()
+	req.Currency = "gbp"
+
+	if _, err := verifyTickerHistoryRequest(req); err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+	}
+
+	req = getDefaultTickerHistoryRequest()
+	req.Currency = "pounds"
+
+	_, err := verifyTickerHistoryRequest(req)
+
+	var badRequest *kit.BadRequestError
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be of type BadRequestError, got %T", err)
+		return
+	}
+	if v, ok := badRequest.Params["currency"]; !ok || v == "" {
+		t.Errorf("expected bad request parameter currency error message, got %s", v)
+	}
+}
+
+func TestEndpointHistory_Resolution(t *testing.T) {
+	ctx := context.Background()
+
+	svc := tickers.NewMockService()
+	svc.(*tickers.MockService).GetTickerHistoryFunc = func(ctx context.Context, in *tickers.GetTickerHistoryInput) (*tickers.GetTickerHistoryOutput, error) {
+		return &tickers.GetTickerHistoryOutput{}, nil
+	}
+
+	cases := []struct {
+		resolution string
+		expected   richeragetypes.Resolution
+	}{
+		{resolution: "", expected: richeragetypes.Resolution1d},
+		{resolution: "1d", expected: richeragetypes.Resolution1d},
+		{resolution: "1h", expected: richeragetypes.Resolution1h},
+		{resolution: "1m", expected: richeragetypes.Resolution1m},
+	}
+
+	for _, c := range cases {
+		req := getDefaultTickerHistoryRequest()
+		req.Resolution = c.resolution
+
+		resp, err := MakeTickerHistoryEndpoint(svc)(ctx, req)
+		if err != nil {
+			t.Errorf("%q: expected error to be nil, got %v", c.resolution, err)
+			continue
+		}
+
+		if res := resp.(*TickerHistoryResponse); res.Resolution != c.expected {
+			t.Errorf("%q: expected resolution %s, got %s", c.resolution, c.expected, res.Resolution)
+		}
+	}
+
+	req := getDefaultTickerHistoryRequest()
+	req.Resolution = "2m"
+
+	_, err := verifyTickerHistoryRequest(req)
+
+	var badRequest *kit.BadRequestError
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be of type BadRequestError, got %T", err)
+		return
+	}
+	if v, ok := badRequest.Params["resolution"]; !ok || v == "" {
+		t.Errorf("expected bad request parameter resolution error message, got %s", v)
+	}
+}
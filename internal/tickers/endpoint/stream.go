@@ -0,0 +1,100 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/auth"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	"strconv"
+)
+
+type TickersStreamRequest struct {
+	Username string
+
+	// Symbols defaults to the user tickers when empty
+	Symbols     []string
+	LastEventID string
+}
+
+type TickersStreamResponse struct {
+	Updates <-chan types.PriceUpdate
+}
+
+func MakeTickersStreamEndpoint(svc tickers.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyTickersStreamRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastEventID uint64
+		if req.LastEventID != "" {
+			lastEventID, _ = strconv.ParseUint(req.LastEventID, 10, 64)
+		}
+
+		out, err := svc.StreamPrices(ctx, &tickers.StreamPricesInput{
+			Username:    req.Username,
+			Symbols:     req.Symbols,
+			LastEventID: lastEventID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &TickersStreamResponse{
+			Updates: out.Updates,
+		}, nil
+	}
+}
+
+func MakeTickersStreamAuthEndpoint(svc auth.Service, e kitendpoint.Endpoint) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		token, _ := ctx.Value("auth_token").(string)
+
+		// assume that the username is valid and exists in the database
+		out, err := svc.VerifyToken(ctx, &auth.VerifyTokenInput{
+			Token: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if req, ok := request.(*TickersStreamRequest); ok && req != nil {
+			req.Username = out.Username
+		}
+
+		return e(ctx, request)
+	}
+}
+
+func verifyTickersStreamRequest(request interface{}) (*TickersStreamRequest, error) {
+	req, ok := request.(*TickersStreamRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if req.Username == "" {
+		badParams["username"] = "required"
+	}
+	if req.LastEventID != "" {
+		if _, err := strconv.ParseUint(req.LastEventID, 10, 64); err != nil {
+			badParams["last_event_id"] = fmt.Sprintf("invalid format %s: %s", req.LastEventID, err.Error())
+		}
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
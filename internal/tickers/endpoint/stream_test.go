@@ -0,0 +1,66 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+)
+
+func TestEndpointTickersStream(t *testing.T) {
+	ctx := context.Background()
+
+	updates := make(chan types.PriceUpdate)
+
+	svc := tickers.NewMockService()
+	svc.(*tickers.MockService).StreamPricesFunc = func(ctx context.Context, in *tickers.StreamPricesInput) (*tickers.StreamPricesOutput, error) {
+		if in.LastEventID != 42 {
+			t.Errorf("expected last event id to be 42, got %d", in.LastEventID)
+		}
+
+		return &tickers.StreamPricesOutput{
+			Updates: updates,
+		}, nil
+	}
+
+	resp, err := MakeTickersStreamEndpoint(svc)(ctx, &TickersStreamRequest{
+		Username:    "test",
+		LastEventID: "42",
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+	}
+
+	if r, ok := resp.(*TickersStreamResponse); !ok || r.Updates == nil {
+		t.Errorf("expected response to have updates set, got %T", resp)
+	}
+}
+
+func TestEndpointTickersStream_VerifyRequest(t *testing.T) {
+	var badRequest *kit.BadRequestError
+
+	_, err := verifyTickersStreamRequest(nil)
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be of type BadRequestError, got %T", err)
+	}
+
+	_, err = verifyTickersStreamRequest(&TickersStreamRequest{Username: "test"})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+	}
+
+	badRequest = nil
+	_, err = verifyTickersStreamRequest(&TickersStreamRequest{
+		Username:    "test",
+		LastEventID: "abc",
+	})
+	if !errors.As(err, &badRequest) {
+		t.Errorf("expected error to be of type BadRequestError, got %T", err)
+	} else if v, ok := badRequest.Params["last_event_id"]; !ok || v == "" {
+		t.Errorf("expected bad request parameter last_event_id error message, got %s", v)
+	}
+}
@@ -2,15 +2,34 @@ package endpoint
 
 import (
 	"context"
+	"fmt"
 	"github.com/falmar/richerage-api/internal/auth"
 	"github.com/falmar/richerage-api/internal/pkg/kit"
 	"github.com/falmar/richerage-api/internal/tickers"
 	"github.com/falmar/richerage-api/internal/tickers/types"
 	kitendpoint "github.com/go-kit/kit/endpoint"
+	"strings"
 )
 
+// isCurrencyCode reports whether currency looks like an ISO 4217 code, any letter case
+func isCurrencyCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+
+	for _, r := range strings.ToUpper(currency) {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
 type TickersRequest struct {
 	Username string
+
+	Currency string
 }
 
 type TickersResponse struct {
@@ -26,6 +45,7 @@ func MakeTickersEndpoint(svc tickers.Service) kitendpoint.Endpoint {
 
 		out, err := svc.GetTickers(ctx, &tickers.GetTickersInput{
 			Username: req.Username,
+			Currency: strings.ToUpper(req.Currency),
 		})
 		if err != nil {
 			return nil, err
@@ -70,6 +90,9 @@ func verifyTickersRequest(request interface{}) (*TickersRequest, error) {
 	if req.Username == "" {
 		badParams["username"] = "required"
 	}
+	if req.Currency != "" && !isCurrencyCode(req.Currency) {
+		badParams["currency"] = fmt.Sprintf("invalid format %s: expected a 3 letter ISO 4217 code", req.Currency)
+	}
 
 	if len(badParams) > 0 {
 		return nil, &kit.BadRequestError{
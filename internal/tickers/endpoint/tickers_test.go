@@ -7,6 +7,7 @@ import (
 	"errors"
 	"github.com/falmar/richerage-api/internal/auth"
 	types2 "github.com/falmar/richerage-api/internal/auth/types"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
 	"github.com/falmar/richerage-api/internal/pkg/kit"
 	"github.com/falmar/richerage-api/internal/tickers"
 	"github.com/falmar/richerage-api/internal/tickers/types"
@@ -29,7 +30,7 @@ func TestEndpointTickers(t *testing.T) {
 			Tickers: []types.Ticker{
 				{
 					Symbol: "BTC",
-					Price:  100,
+					Price:  decimal.MustParse("100"),
 				},
 			},
 		}, nil
@@ -54,8 +55,8 @@ func TestEndpointTickers(t *testing.T) {
 	if resp.(*TickersResponse).Tickers[0].Symbol != "BTC" {
 		t.Errorf("expected ticker symbol to be BTC, got %s", resp.(*TickersResponse).Tickers[0].Symbol)
 	}
-	if resp.(*TickersResponse).Tickers[0].Price != 100 {
-		t.Errorf("expected ticker price to be 100, got %f", resp.(*TickersResponse).Tickers[0].Price)
+	if !resp.(*TickersResponse).Tickers[0].Price.Equal(decimal.MustParse("100")) {
+		t.Errorf("expected ticker price to be 100, got %s", resp.(*TickersResponse).Tickers[0].Price)
 	}
 }
 
@@ -117,6 +118,31 @@ func TestEndpointTickers_VerifyRequest(t *testing.T) {
 	}
 }
 
+func TestEndpointTickers_VerifyRequest_Currency(t *testing.T) {
+	for _, v := range []string{"", "EUR", "eur"} {
+		req := getDefaultTickersRequest()
+		req.Currency = v
+
+		if _, err := verifyTickersRequest(req); err != nil {
+			t.Errorf("expected error to be nil for %q, got %T", v, err)
+		}
+	}
+
+	for _, v := range []string{"EU", "EURO", "E1R"} {
+		req := getDefaultTickersRequest()
+		req.Currency = v
+
+		_, err := verifyTickersRequest(req)
+
+		var badRequest *kit.BadRequestError
+		if !errors.As(err, &badRequest) {
+			t.Errorf("expected error to be of type BadRequestError for %q, got %T", v, err)
+		} else if badRequest.Params["currency"] == "" {
+			t.Errorf("expected bad request parameter currency error message for %q", v)
+		}
+	}
+}
+
 func TestEndpointTickers_Auth(t *testing.T) {
 	ctx := context.Background()
 
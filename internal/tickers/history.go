@@ -2,13 +2,24 @@ package tickers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
 	"github.com/falmar/richerage-api/internal/tickers/types"
+	"sort"
 	"time"
 )
 
 type GetTickerHistoryInput struct {
 	Symbol string
 	Before time.Time
+	// Adjusted back-adjusts prices for corporate actions, raw prices when empty
+	Adjusted types.Adjustment
+	// Currency converts prices with the rate of each date, prices are in the trading currency when empty
+	Currency string
+	// Resolution of the bars, daily when empty
+	Resolution types.Resolution
 }
 
 type GetTickerHistoryOutput struct {
@@ -16,24 +27,368 @@ type GetTickerHistoryOutput struct {
 }
 
 func (s *service) GetTickerHistory(ctx context.Context, in *GetTickerHistoryInput) (*GetTickerHistoryOutput, error) {
-	history, err := s.storage.GetHistory(ctx, in.Symbol, in.Before)
+	q, err := s.historyQuery(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 
+	// the storage keeps prices under the symbol traded at the time, query each listing of the instrument
+	history := make([]types.TickerHistory, 0)
+
+	for _, l := range q.instrument.Listings {
+		records, err := s.listingHistory(ctx, q, l)
+		if err != nil {
+			return nil, err
+		}
+
+		history = append(history, records...)
+	}
+
 	sortHistory(history)
 
+	if err := s.finishHistory(ctx, q, history); err != nil {
+		return nil, err
+	}
+
 	return &GetTickerHistoryOutput{
 		History: history,
 	}, nil
 }
 
-func sortHistory(history []types.TickerHistory) {
-	for i := 0; i < len(history); i++ {
-		for j := 0; j < len(history)-i-1; j++ {
-			if history[j].Date.Before(history[j+1].Date) {
-				history[j], history[j+1] = history[j+1], history[j]
+// DefaultHistoryChunkSize bounds the records of a streamed history chunk when the input leaves it empty
+const DefaultHistoryChunkSize = 500
+
+type StreamTickerHistoryInput struct {
+	GetTickerHistoryInput
+
+	// ChunkSize bounds the records of each chunk, DefaultHistoryChunkSize when zero
+	ChunkSize int
+}
+
+type StreamTickerHistoryOutput struct {
+	// Chunks are sent newest first as each listing is read from the storage, the channel is closed
+	// after the last chunk, after a chunk with an error or once ctx is done
+	Chunks <-chan types.HistoryChunk
+}
+
+func (s *service) StreamTickerHistory(ctx context.Context, in *StreamTickerHistoryInput) (*StreamTickerHistoryOutput, error) {
+	q, err := s.historyQuery(ctx, &in.GetTickerHistoryInput)
+	if err != nil {
+		return nil, err
+	}
+
+	size := in.ChunkSize
+	if size <= 0 {
+		size = DefaultHistoryChunkSize
+	}
+
+	chunks := make(chan types.HistoryChunk)
+
+	go func() {
+		defer close(chunks)
+
+		send := func(chunk types.HistoryChunk) bool {
+			select {
+			case chunks <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// a panic of this goroutine would end the process, the stream ends with an error instead
+		defer func() {
+			if v := recover(); v != nil {
+				send(types.HistoryChunk{Err: fmt.Errorf("history of %s: %v", in.Symbol, v)})
+			}
+		}()
+
+		// listings are oldest first and do not overlap, the newest listing holds the first records
+		listings := q.instrument.Listings
+		for i := len(listings) - 1; i >= 0; i-- {
+			records, err := s.listingHistory(ctx, q, listings[i])
+			if err == nil {
+				sortHistory(records)
+				err = s.finishHistory(ctx, q, records)
+			}
+			if err != nil {
+				send(types.HistoryChunk{Err: err})
+				return
+			}
+
+			for len(records) > 0 {
+				n := size
+				if n > len(records) {
+					n = len(records)
+				}
+
+				if !send(types.HistoryChunk{History: records[:n]}) {
+					return
+				}
+
+				records = records[n:]
 			}
 		}
+	}()
+
+	return &StreamTickerHistoryOutput{
+		Chunks: chunks,
+	}, nil
+}
+
+// historyQuery is a validated history request with the instrument it resolved to
+type historyQuery struct {
+	in         *GetTickerHistoryInput
+	instrument *symboltypes.Symbol
+	// adjuster is nil for raw prices, it keeps its state across chunks of a stream
+	adjuster *historyAdjuster
+}
+
+func (s *service) historyQuery(ctx context.Context, in *GetTickerHistoryInput) (*historyQuery, error) {
+	if err := s.checkCurrency(ctx, in.Currency); err != nil {
+		return nil, err
+	}
+
+	instrument, err := s.resolveInstrument(ctx, in.Symbol, in.Before)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &historyQuery{
+		in:         in,
+		instrument: instrument,
+	}
+
+	if in.Adjusted == types.AdjustmentSplit || in.Adjusted == types.AdjustmentTotal {
+		actions, err := s.corporateActions(ctx, instrument)
+		if err != nil {
+			return nil, err
+		}
+
+		q.adjuster = newHistoryAdjuster(actions, in.Adjusted)
 	}
+
+	return q, nil
+}
+
+// listingHistory reads the raw bars stored while the instrument traded under the symbol of l,
+// empty when nothing is stored under it
+func (s *service) listingHistory(ctx context.Context, q *historyQuery, l symboltypes.Listing) ([]types.TickerHistory, error) {
+	in := q.in
+
+	if !in.Before.IsZero() && in.Before.Before(l.From) {
+		return nil, nil
+	}
+
+	before := in.Before
+	if !l.To.IsZero() {
+		// last day under this symbol, last instant for intraday bars
+		last := l.To.Add(-time.Hour * 24)
+		if in.Resolution.Intraday() {
+			last = l.To.Add(-time.Nanosecond)
+		}
+
+		if before.IsZero() || before.After(last) {
+			before = last
+		}
+	}
+
+	var records []types.TickerHistory
+	var err error
+	if in.Resolution.Intraday() {
+		records, err = s.storage.GetBars(ctx, l.Symbol, in.Resolution, before)
+	} else {
+		records, err = s.storage.GetHistory(ctx, l.Symbol, before)
+	}
+
+	var errNotFound *types.ErrTickerNotFound
+	if errors.As(err, &errNotFound) {
+		// nothing stored under a previous symbol
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	history := make([]types.TickerHistory, 0, len(records))
+
+	for _, v := range records {
+		if !l.Covers(v.Date) {
+			continue
+		}
+
+		v.Symbol = l.Symbol
+		if v.Currency == "" {
+			v.Currency = q.instrument.Currency
+		}
+		if v.Currency == "" {
+			v.Currency = defaultCurrency
+		}
+
+		history = append(history, v)
+	}
+
+	return history, nil
+}
+
+// finishHistory adjusts and converts history sorted newest first in place, history follows
+// the bars finished by earlier calls of the same query
+func (s *service) finishHistory(ctx context.Context, q *historyQuery, history []types.TickerHistory) error {
+	if q.adjuster != nil {
+		q.adjuster.adjust(history)
+	}
+
+	// adjust before converting, dividends are paid in the trading currency
+	if q.in.Currency != "" {
+		var err error
+		for i, v := range history {
+			history[i], err = s.convertBar(ctx, v, q.in.Currency)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveInstrument finds the instrument that traded under symbol at before (or now when zero),
+// falling back to the last instrument that used it
+func (s *service) resolveInstrument(ctx context.Context, symbol string, before time.Time) (*symboltypes.Symbol, error) {
+	candidates, err := s.symbols.ResolveSymbol(ctx, symbol)
+	var errNotFound *symboltypes.ErrSymbolNotFound
+	if errors.As(err, &errNotFound) {
+		return nil, &types.ErrTickerNotFound{
+			Symbol: symbol,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	at := before
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var instrument *symboltypes.Symbol
+	var latest time.Time
+
+	for i, c := range candidates {
+		for _, l := range c.Listings {
+			if l.Symbol != symbol {
+				continue
+			}
+
+			if l.Covers(at) {
+				return &candidates[i], nil
+			}
+
+			if instrument == nil || l.From.After(latest) {
+				instrument, latest = &candidates[i], l.From
+			}
+		}
+	}
+
+	// no history after the instrument stopped trading
+	if !instrument.Delisted.IsZero() && !at.Before(instrument.Delisted) {
+		return nil, &types.ErrTickerNotFound{
+			Symbol:   symbol,
+			Delisted: instrument.Delisted,
+		}
+	}
+
+	return instrument, nil
+}
+
+// corporateActions of every listing of the instrument while it traded under that symbol, oldest first
+func (s *service) corporateActions(ctx context.Context, instrument *symboltypes.Symbol) ([]types.CorporateAction, error) {
+	actions := make([]types.CorporateAction, 0)
+	if s.actions == nil {
+		return actions, nil
+	}
+
+	for _, l := range instrument.Listings {
+		listing, err := s.actions.GetCorporateActions(ctx, l.Symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range listing {
+			if l.Covers(a.ExDate) {
+				actions = append(actions, a)
+			}
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].ExDate.Before(actions[j].ExDate)
+	})
+
+	return actions, nil
+}
+
+// adjustHistory back-adjusts history sorted newest first in place, every record before an ex-date
+// is multiplied by the factor of the action:
+//   - split: 1 / ratio
+//   - dividend: 1 - amount / previous close, only for AdjustmentTotal
+//
+// the previous close is the closest raw record before the ex-date, volumes are multiplied by split ratios
+func adjustHistory(history []types.TickerHistory, actions []types.CorporateAction, adjustment types.Adjustment) {
+	newHistoryAdjuster(actions, adjustment).adjust(history)
+}
+
+// historyAdjuster is adjustHistory over consecutive chunks of a history sorted newest first
+type historyAdjuster struct {
+	actions    []types.CorporateAction
+	adjustment types.Adjustment
+
+	factor       decimal.Decimal
+	volumeFactor decimal.Decimal
+	// next is the latest action not applied yet
+	next int
+}
+
+func newHistoryAdjuster(actions []types.CorporateAction, adjustment types.Adjustment) *historyAdjuster {
+	return &historyAdjuster{
+		actions:      actions,
+		adjustment:   adjustment,
+		factor:       decimal.NewFromInt(1),
+		volumeFactor: decimal.NewFromInt(1),
+		next:         len(actions) - 1,
+	}
+}
+
+// adjust back-adjusts history in place, history follows the records of the previous call
+func (a *historyAdjuster) adjust(history []types.TickerHistory) {
+	for i := range history {
+		for a.next >= 0 && history[i].Date.Before(a.actions[a.next].ExDate) {
+			action := a.actions[a.next]
+			a.next--
+
+			switch action.Type {
+			case types.CorporateActionSplit:
+				if action.Ratio.Sign() > 0 {
+					a.factor = a.factor.Div(action.Ratio, factorScale)
+					a.volumeFactor = a.volumeFactor.Mul(action.Ratio)
+				}
+			case types.CorporateActionDividend:
+				if a.adjustment == types.AdjustmentTotal && action.Amount.Cmp(history[i].Price) < 0 {
+					yield := action.Amount.Div(history[i].Price, factorScale)
+					a.factor = a.factor.MulRound(decimal.NewFromInt(1).Sub(yield), factorScale)
+				}
+			}
+		}
+
+		history[i].Price = scalePrice(history[i].Price, a.factor)
+		history[i].Open = scalePrice(history[i].Open, a.factor)
+		history[i].High = scalePrice(history[i].High, a.factor)
+		history[i].Low = scalePrice(history[i].Low, a.factor)
+		history[i].Volume = decimal.NewFromInt(history[i].Volume).MulRound(a.volumeFactor, 0).Units()
+	}
+}
+
+// sortHistory sorts newest first, records of the same date keep their order
+func sortHistory(history []types.TickerHistory) {
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date.After(history[j].Date)
+	})
 }
@@ -4,7 +4,10 @@ package tickers
 
 import (
 	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
 	"github.com/falmar/richerage-api/internal/storage"
+	symboltypes "github.com/falmar/richerage-api/internal/symbols/types"
 	"github.com/falmar/richerage-api/internal/tickers/types"
 	"testing"
 	"time"
@@ -26,7 +29,7 @@ func TestTickers_History_Sort(t *testing.T) {
 	}
 	for _, d := range dates {
 		h = append(h, types.TickerHistory{
-			Price: 0,
+			Price: decimal.MustParse("0"),
 			Date:  d,
 		})
 	}
@@ -57,7 +60,7 @@ func TestTickers_History(t *testing.T) {
 
 		return []types.TickerHistory{
 			{
-				Price: 100,
+				Price: decimal.MustParse("100"),
 				Date:  date,
 			},
 		}, nil
@@ -91,8 +94,8 @@ func TestTickers_History(t *testing.T) {
 
 	if out.History[0].Date != date {
 		t.Errorf("expected ticker date to be %v, got %v", date, out.History[0].Date)
-	} else if out.History[0].Price != 100 {
-		t.Errorf("expected ticker price to be 100, got %f", out.History[0].Price)
+	} else if !out.History[0].Price.Equal(decimal.MustParse("100")) {
+		t.Errorf("expected ticker price to be 100, got %s", out.History[0].Price)
 	}
 }
 
@@ -117,9 +120,584 @@ func TestTickers_History_Error(t *testing.T) {
 	}
 
 	_, err = svc.GetTickerHistory(ctx, &GetTickerHistoryInput{
-		Symbol: "test",
+		Symbol: "AAPL",
 	})
 	if err != stErr {
 		t.Errorf("expected error to be %T, got %T", stErr, err)
 	}
 }
+
+func getRenamedSymbols() storage.SymbolStorage {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	return storage.NewMemorySymbols([]symboltypes.Symbol{
+		{
+			InstrumentID: "INS1",
+			Symbol:       "META",
+			Listed:       date("2012-05-18"),
+			Listings: []symboltypes.Listing{
+				{Symbol: "FB", From: date("2012-05-18"), To: date("2022-06-09")},
+				{Symbol: "META", From: date("2022-06-09")},
+			},
+		},
+		{
+			InstrumentID: "INS2",
+			Symbol:       "TWTR",
+			Listed:       date("2013-11-07"),
+			Delisted:     date("2022-11-08"),
+		},
+	})
+}
+
+func TestTickers_History_Renamed(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	// prices are stored under the symbol traded at the time
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		var dates []string
+
+		switch symbol {
+		case "FB":
+			if before.Format("2006-01-02") != "2022-06-08" {
+				t.Errorf("expected FB to be queried before 2022-06-08, got %v", before)
+			}
+			dates = []string{"2022-06-07", "2022-06-08"}
+		case "META":
+			// a storage may not filter, the listing range does
+			dates = []string{"2022-06-08", "2022-06-09", "2022-06-10"}
+		default:
+			t.Errorf("unexpected symbol %s", symbol)
+		}
+
+		var history []types.TickerHistory
+		for _, d := range dates {
+			date, _ := time.Parse("2006-01-02", d)
+			history = append(history, types.TickerHistory{Date: date, Price: decimal.MustParse("100")})
+		}
+
+		return history, nil
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+		Symbols: getRenamedSymbols(),
+	})
+
+	// the old and the new symbol resolve to the same instrument
+	for _, symbol := range []string{"FB", "META"} {
+		out, err := svc.GetTickerHistory(ctx, &GetTickerHistoryInput{
+			Symbol: symbol,
+		})
+		if err != nil {
+			t.Errorf("expected error to be nil, got %v", err)
+			return
+		}
+
+		expected := []string{"2022-06-10 META", "2022-06-09 META", "2022-06-08 FB", "2022-06-07 FB"}
+		if len(out.History) != len(expected) {
+			t.Errorf("%s: expected %d records, got %d", symbol, len(expected), len(out.History))
+			continue
+		}
+
+		for i, v := range out.History {
+			if got := v.Date.Format("2006-01-02") + " " + v.Symbol; got != expected[i] {
+				t.Errorf("%s: expected %s, got %s", symbol, expected[i], got)
+			}
+		}
+	}
+}
+
+func TestTickers_History_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	svc, _ := New(&Config{
+		Storage: storage.NewMock(),
+		Symbols: getRenamedSymbols(),
+	})
+
+	cases := []struct {
+		symbol   string
+		before   string
+		delisted string
+		code     string
+	}{
+		{symbol: "INVALID", code: "ticker_not_found"},
+		{symbol: "TWTR", delisted: "2022-11-08", code: "ticker_delisted"},
+		{symbol: "TWTR", before: "2022-11-08", delisted: "2022-11-08", code: "ticker_delisted"},
+	}
+
+	for _, c := range cases {
+		var before time.Time
+		if c.before != "" {
+			before, _ = time.Parse("2006-01-02", c.before)
+		}
+
+		_, err := svc.GetTickerHistory(ctx, &GetTickerHistoryInput{
+			Symbol: c.symbol,
+			Before: before,
+		})
+
+		var errNotFound *types.ErrTickerNotFound
+		if !errors.As(err, &errNotFound) {
+			t.Errorf("%s: expected error to be %T, got %T", c.symbol, errNotFound, err)
+			continue
+		}
+
+		if errNotFound.Code() != c.code {
+			t.Errorf("%s: expected code %s, got %s", c.symbol, c.code, errNotFound.Code())
+		}
+		if c.delisted != "" && errNotFound.Delisted.Format("2006-01-02") != c.delisted {
+			t.Errorf("%s: expected delisted on %s, got %v", c.symbol, c.delisted, errNotFound.Delisted)
+		}
+	}
+}
+
+func TestTickers_History_BeforeDelisting(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		return []types.TickerHistory{{Date: before, Price: decimal.MustParse("10")}}, nil
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+		Symbols: getRenamedSymbols(),
+	})
+
+	// history while it was still trading is available
+	before, _ := time.Parse("2006-01-02", "2022-01-03")
+	out, err := svc.GetTickerHistory(ctx, &GetTickerHistoryInput{
+		Symbol: "TWTR",
+		Before: before,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	if len(out.History) != 1 || out.History[0].Symbol != "TWTR" {
+		t.Errorf("expected 1 TWTR record, got %v", out.History)
+	}
+}
+
+func TestTickers_History_Adjusted(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		return []types.TickerHistory{
+			{Date: date("2023-01-03"), Price: decimal.MustParse("400")},
+			{Date: date("2023-01-04"), Price: decimal.MustParse("410")},
+			{Date: date("2023-01-05"), Price: decimal.MustParse("102.5")},
+			{Date: date("2023-01-06"), Price: decimal.MustParse("104")},
+			{Date: date("2023-01-09"), Price: decimal.MustParse("103")},
+		}, nil
+	}
+
+	actions := storage.NewMemoryCorporateActions([]types.CorporateAction{
+		// the dividend is 1% of the previous close
+		{Symbol: "AAPL", Type: types.CorporateActionDividend, ExDate: date("2023-01-09"), Amount: decimal.MustParse("1.04")},
+		{Symbol: "AAPL", Type: types.CorporateActionSplit, ExDate: date("2023-01-05"), Ratio: decimal.MustParse("4")},
+		// after the last record, every record is adjusted
+		{Symbol: "AAPL", Type: types.CorporateActionSplit, ExDate: date("2023-02-01"), Ratio: decimal.MustParse("2")},
+		// another symbol is ignored
+		{Symbol: "MSFT", Type: types.CorporateActionSplit, ExDate: date("2023-01-06"), Ratio: decimal.MustParse("10")},
+	})
+
+	svc, _ := New(&Config{
+		Storage:          st,
+		CorporateActions: actions,
+	})
+
+	cases := []struct {
+		adjusted types.Adjustment
+		// exact scale, adjusted prices keep the scale of the stored price with at least 2 decimals
+		expected []string
+	}{
+		{adjusted: "", expected: []string{"103", "104", "102.5", "410", "400"}},
+		{adjusted: types.AdjustmentNone, expected: []string{"103", "104", "102.5", "410", "400"}},
+		{adjusted: types.AdjustmentSplit, expected: []string{"51.50", "52.00", "51.25", "51.25", "50.00"}},
+		{adjusted: types.AdjustmentTotal, expected: []string{"51.50", "51.48", "50.74", "50.74", "49.50"}},
+	}
+
+	for _, c := range cases {
+		out, err := svc.GetTickerHistory(ctx, &GetTickerHistoryInput{
+			Symbol:   "AAPL",
+			Adjusted: c.adjusted,
+		})
+		if err != nil {
+			t.Errorf("%s: expected error to be nil, got %v", c.adjusted, err)
+			continue
+		}
+
+		if len(out.History) != len(c.expected) {
+			t.Errorf("%s: expected %d records, got %d", c.adjusted, len(c.expected), len(out.History))
+			continue
+		}
+
+		for i, v := range out.History {
+			if v.Price.String() != c.expected[i] {
+				t.Errorf("%s: expected price on %s to be %v, got %v", c.adjusted, v.Date.Format("2006-01-02"), c.expected[i], v.Price)
+			}
+		}
+	}
+}
+
+func TestTickers_History_Adjusted_Renamed(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		if symbol == "FB" {
+			return []types.TickerHistory{{Date: date("2022-06-01"), Price: decimal.MustParse("200")}}, nil
+		}
+
+		return []types.TickerHistory{{Date: date("2022-06-10"), Price: decimal.MustParse("100")}}, nil
+	}
+
+	actions := storage.NewMemoryCorporateActions([]types.CorporateAction{
+		// split while trading as FB
+		{Symbol: "FB", Type: types.CorporateActionSplit, ExDate: date("2022-06-02"), Ratio: decimal.MustParse("2")},
+		// a later instrument reusing FB is not the same company
+		{Symbol: "FB", Type: types.CorporateActionSplit, ExDate: date("2023-01-03"), Ratio: decimal.MustParse("10")},
+	})
+
+	svc, _ := New(&Config{
+		Storage:          st,
+		Symbols:          getRenamedSymbols(),
+		CorporateActions: actions,
+	})
+
+	out, err := svc.GetTickerHistory(ctx, &GetTickerHistoryInput{
+		Symbol:   "META",
+		Adjusted: types.AdjustmentSplit,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	// the META record has no later split, it is kept as stored
+	expected := []string{"100", "100.00"}
+	if len(out.History) != len(expected) {
+		t.Errorf("expected %d records, got %d", len(expected), len(out.History))
+		return
+	}
+
+	for i, v := range out.History {
+		if v.Price.String() != expected[i] {
+			t.Errorf("expected price on %s to be %v, got %v", v.Date.Format("2006-01-02"), expected[i], v.Price)
+		}
+	}
+}
+
+func TestTickers_History_Resolution(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	bar := func(s string) types.TickerHistory {
+		date, _ := time.Parse(time.RFC3339, s)
+		return types.TickerHistory{Date: date, Price: decimal.MustParse("10")}
+	}
+
+	st.(*storage.MockStorage).GetBarsFunc = func(ctx context.Context, symbol string, resolution types.Resolution, before time.Time) ([]types.TickerHistory, error) {
+		if resolution != types.Resolution5m {
+			t.Errorf("expected resolution 5m, got %s", resolution)
+		}
+
+		switch symbol {
+		case "FB":
+			// the last instant under FB, not the day before
+			if !before.Equal(time.Date(2022, 6, 8, 23, 59, 59, 999999999, time.UTC)) {
+				t.Errorf("expected FB to be queried before the end of 2022-06-08, got %v", before)
+			}
+
+			return []types.TickerHistory{bar("2022-06-08T19:55:00Z"), bar("2022-06-08T13:30:00Z")}, nil
+		case "META":
+			return []types.TickerHistory{bar("2022-06-09T13:35:00Z"), bar("2022-06-09T13:30:00Z")}, nil
+		}
+
+		return nil, &types.ErrTickerNotFound{Symbol: symbol}
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+		Symbols: getRenamedSymbols(),
+	})
+
+	out, err := svc.GetTickerHistory(ctx, &GetTickerHistoryInput{
+		Symbol:     "META",
+		Resolution: types.Resolution5m,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	expected := []string{"2022-06-09T13:35:00Z META", "2022-06-09T13:30:00Z META", "2022-06-08T19:55:00Z FB", "2022-06-08T13:30:00Z FB"}
+	if len(out.History) != len(expected) {
+		t.Errorf("expected %d bars, got %d", len(expected), len(out.History))
+		return
+	}
+
+	for i, v := range out.History {
+		if got := v.Date.Format(time.RFC3339) + " " + v.Symbol; got != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], got)
+		}
+	}
+}
+
+func TestTickers_History_Adjusted_Bars(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		return []types.TickerHistory{
+			{Date: date("2023-01-04"), Open: decimal.MustParse("400"), High: decimal.MustParse("420"), Low: decimal.MustParse("398"), Price: decimal.MustParse("410"), Volume: 1000},
+			{Date: date("2023-01-05"), Open: decimal.MustParse("102.5"), High: decimal.MustParse("103"), Low: decimal.MustParse("101"), Price: decimal.MustParse("102.5"), Volume: 4100},
+		}, nil
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+		CorporateActions: storage.NewMemoryCorporateActions([]types.CorporateAction{
+			{Symbol: "AAPL", Type: types.CorporateActionSplit, ExDate: date("2023-01-05"), Ratio: decimal.MustParse("4")},
+		}),
+	})
+
+	out, err := svc.GetTickerHistory(ctx, &GetTickerHistoryInput{
+		Symbol:   "AAPL",
+		Adjusted: types.AdjustmentSplit,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	// every price of the bar is adjusted, the volume is in post split shares
+	v := out.History[1]
+	if v.Open.String() != "100.00" || v.High.String() != "105.00" || v.Low.String() != "99.50" || v.Price.String() != "102.50" || v.Volume != 4000 {
+		t.Errorf("expected 100.00 105.00 99.50 102.50 4000, got %v %v %v %v %d", v.Open, v.High, v.Low, v.Price, v.Volume)
+	}
+
+	v = out.History[0]
+	if v.Open.String() != "102.5" || v.Volume != 4100 {
+		t.Errorf("expected the latest bar to be unchanged, got %v %d", v.Open, v.Volume)
+	}
+}
+
+func TestTickers_StreamHistory(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	var queried []string
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		queried = append(queried, symbol)
+
+		if symbol == "FB" {
+			return []types.TickerHistory{
+				{Date: date("2022-05-31"), Price: decimal.MustParse("200")},
+				{Date: date("2022-06-01"), Price: decimal.MustParse("210")},
+				{Date: date("2022-06-02"), Price: decimal.MustParse("110")},
+			}, nil
+		}
+
+		return []types.TickerHistory{
+			{Date: date("2022-06-10"), Price: decimal.MustParse("100")},
+			{Date: date("2022-06-09"), Price: decimal.MustParse("104")},
+		}, nil
+	}
+
+	actions := storage.NewMemoryCorporateActions([]types.CorporateAction{
+		{Symbol: "FB", Type: types.CorporateActionSplit, ExDate: date("2022-06-02"), Ratio: decimal.MustParse("2")},
+		// applies to every record of the older listing, spread over chunks
+		{Symbol: "META", Type: types.CorporateActionDividend, ExDate: date("2022-06-10"), Amount: decimal.MustParse("1.04")},
+	})
+
+	svc, _ := New(&Config{
+		Storage:          st,
+		Symbols:          getRenamedSymbols(),
+		CorporateActions: actions,
+	})
+
+	in := GetTickerHistoryInput{Symbol: "META", Adjusted: types.AdjustmentTotal}
+
+	unary, err := svc.GetTickerHistory(ctx, &in)
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	queried = nil
+	out, err := svc.StreamTickerHistory(ctx, &StreamTickerHistoryInput{GetTickerHistoryInput: in, ChunkSize: 2})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	var sizes []int
+	var history []types.TickerHistory
+	for chunk := range out.Chunks {
+		if chunk.Err != nil {
+			t.Errorf("expected chunk error to be nil, got %v", chunk.Err)
+		}
+
+		sizes = append(sizes, len(chunk.History))
+		history = append(history, chunk.History...)
+	}
+
+	// the newest listing is read first
+	if len(queried) != 2 || queried[0] != "META" || queried[1] != "FB" {
+		t.Errorf("expected META then FB to be queried, got %v", queried)
+	}
+
+	// chunks do not span listings
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Errorf("expected chunks of 2, 2 and 1 records, got %v", sizes)
+	}
+
+	if len(history) != len(unary.History) {
+		t.Errorf("expected %d records, got %d", len(unary.History), len(history))
+		return
+	}
+
+	for i, v := range history {
+		expected := unary.History[i]
+		if !v.Date.Equal(expected.Date) || v.Symbol != expected.Symbol || v.Price.String() != expected.Price.String() {
+			t.Errorf("expected %s %s %v, got %s %s %v", expected.Date.Format("2006-01-02"), expected.Symbol, expected.Price, v.Date.Format("2006-01-02"), v.Symbol, v.Price)
+		}
+	}
+}
+
+func TestTickers_StreamHistory_Error(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMock()
+
+	errStorage := errors.New("storage error")
+
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		if symbol == "FB" {
+			return nil, errStorage
+		}
+
+		return []types.TickerHistory{{Date: time.Date(2022, 6, 10, 0, 0, 0, 0, time.UTC), Price: decimal.MustParse("100")}}, nil
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+		Symbols: getRenamedSymbols(),
+	})
+
+	// validation errors are returned before streaming
+	_, err := svc.StreamTickerHistory(ctx, &StreamTickerHistoryInput{GetTickerHistoryInput: GetTickerHistoryInput{Symbol: "NOPE"}})
+	var errNotFound *types.ErrTickerNotFound
+	if !errors.As(err, &errNotFound) {
+		t.Errorf("expected ErrTickerNotFound, got %v", err)
+	}
+
+	out, err := svc.StreamTickerHistory(ctx, &StreamTickerHistoryInput{GetTickerHistoryInput: GetTickerHistoryInput{Symbol: "META"}})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	var chunks []types.HistoryChunk
+	for chunk := range out.Chunks {
+		chunks = append(chunks, chunk)
+	}
+
+	// the records read before the error are sent
+	if len(chunks) != 2 || len(chunks[0].History) != 1 || chunks[1].Err != errStorage {
+		t.Errorf("expected a chunk then the storage error, got %v", chunks)
+	}
+}
+
+func TestTickers_StreamHistory_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	st := storage.NewMock()
+
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		return []types.TickerHistory{
+			{Date: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), Price: decimal.MustParse("100")},
+			{Date: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), Price: decimal.MustParse("100")},
+		}, nil
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+	})
+
+	out, err := svc.StreamTickerHistory(ctx, &StreamTickerHistoryInput{GetTickerHistoryInput: GetTickerHistoryInput{Symbol: "AAPL"}, ChunkSize: 1})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	<-out.Chunks
+	cancel()
+
+	// the channel is closed without a consumer for the remaining chunk
+	select {
+	case <-time.After(time.Second):
+		t.Errorf("expected chunks to be closed after cancel")
+	case _, ok := <-out.Chunks:
+		for ok {
+			_, ok = <-out.Chunks
+		}
+	}
+}
+
+func TestTickers_StreamHistory_Panic(t *testing.T) {
+	st := storage.NewMock()
+
+	st.(*storage.MockStorage).GetHistoryFunc = func(ctx context.Context, symbol string, before time.Time) ([]types.TickerHistory, error) {
+		panic("storage panic")
+	}
+
+	svc, _ := New(&Config{
+		Storage: st,
+	})
+
+	out, err := svc.StreamTickerHistory(context.Background(), &StreamTickerHistoryInput{GetTickerHistoryInput: GetTickerHistoryInput{Symbol: "AAPL"}})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+		return
+	}
+
+	// the panic ends the stream instead of the process
+	var chunks []types.HistoryChunk
+	for chunk := range out.Chunks {
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) != 1 || chunks[0].Err == nil {
+		t.Errorf("expected a chunk with the panic as error, got %v", chunks)
+	}
+}
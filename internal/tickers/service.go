@@ -3,6 +3,7 @@ package tickers
 import (
 	"context"
 	"errors"
+	"github.com/falmar/richerage-api/internal/prices"
 	"github.com/falmar/richerage-api/internal/storage"
 )
 
@@ -13,10 +14,22 @@ var _ Service = (*service)(nil)
 type Service interface {
 	GetTickers(ctx context.Context, in *GetTickersInput) (*GetTickersOutput, error)
 	GetTickerHistory(ctx context.Context, in *GetTickerHistoryInput) (*GetTickerHistoryOutput, error)
+	StreamTickerHistory(ctx context.Context, in *StreamTickerHistoryInput) (*StreamTickerHistoryOutput, error)
+
+	StreamPrices(ctx context.Context, in *StreamPricesInput) (*StreamPricesOutput, error)
 }
 
 type Config struct {
 	Storage storage.Storage
+
+	// Publisher is optional, without it StreamPrices is unavailable
+	Publisher prices.Publisher
+	// Symbols validates requested symbols, defaults to the embedded symbol master
+	Symbols storage.SymbolStorage
+	// CorporateActions is optional, without it adjusted history equals the raw prices
+	CorporateActions storage.CorporateActionStorage
+	// FX converts prices to other currencies, defaults to seeded rates
+	FX storage.FXStorage
 }
 
 func New(cfg *Config) (Service, error) {
@@ -24,11 +37,28 @@ func New(cfg *Config) (Service, error) {
 		return nil, ErrInvalidConfig
 	}
 
-	return &service{
-		storage: cfg.Storage,
-	}, nil
+	s := &service{
+		storage:   cfg.Storage,
+		publisher: cfg.Publisher,
+		symbols:   cfg.Symbols,
+		actions:   cfg.CorporateActions,
+		fx:        cfg.FX,
+	}
+
+	if s.symbols == nil {
+		s.symbols = storage.NewEmbeddedSymbols()
+	}
+	if s.fx == nil {
+		s.fx = storage.NewSeededFX()
+	}
+
+	return s, nil
 }
 
 type service struct {
-	storage storage.Storage
+	storage   storage.Storage
+	publisher prices.Publisher
+	symbols   storage.SymbolStorage
+	actions   storage.CorporateActionStorage
+	fx        storage.FXStorage
 }
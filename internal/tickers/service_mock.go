@@ -18,12 +18,20 @@ func NewMockService() Service {
 		GetTickerHistoryFunc: func(ctx context.Context, in *GetTickerHistoryInput) (*GetTickerHistoryOutput, error) {
 			return nil, ErrMockUncalledFor
 		},
+		StreamTickerHistoryFunc: func(ctx context.Context, in *StreamTickerHistoryInput) (*StreamTickerHistoryOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		StreamPricesFunc: func(ctx context.Context, in *StreamPricesInput) (*StreamPricesOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
 	}
 }
 
 type MockService struct {
-	GetTickersFunc       func(ctx context.Context, in *GetTickersInput) (*GetTickersOutput, error)
-	GetTickerHistoryFunc func(ctx context.Context, in *GetTickerHistoryInput) (*GetTickerHistoryOutput, error)
+	GetTickersFunc          func(ctx context.Context, in *GetTickersInput) (*GetTickersOutput, error)
+	GetTickerHistoryFunc    func(ctx context.Context, in *GetTickerHistoryInput) (*GetTickerHistoryOutput, error)
+	StreamTickerHistoryFunc func(ctx context.Context, in *StreamTickerHistoryInput) (*StreamTickerHistoryOutput, error)
+	StreamPricesFunc        func(ctx context.Context, in *StreamPricesInput) (*StreamPricesOutput, error)
 }
 
 func (m *MockService) GetTickers(ctx context.Context, in *GetTickersInput) (*GetTickersOutput, error) {
@@ -33,3 +41,11 @@ func (m *MockService) GetTickers(ctx context.Context, in *GetTickersInput) (*Get
 func (m *MockService) GetTickerHistory(ctx context.Context, in *GetTickerHistoryInput) (*GetTickerHistoryOutput, error) {
 	return m.GetTickerHistoryFunc(ctx, in)
 }
+
+func (m *MockService) StreamTickerHistory(ctx context.Context, in *StreamTickerHistoryInput) (*StreamTickerHistoryOutput, error) {
+	return m.StreamTickerHistoryFunc(ctx, in)
+}
+
+func (m *MockService) StreamPrices(ctx context.Context, in *StreamPricesInput) (*StreamPricesOutput, error) {
+	return m.StreamPricesFunc(ctx, in)
+}
@@ -0,0 +1,67 @@
+package tickers
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+)
+
+type StreamPricesInput struct {
+	Username string
+
+	// Symbols to stream, defaults to the user tickers when empty
+	Symbols []string
+	// LastEventID resumes the stream after the given update
+	LastEventID uint64
+}
+
+type StreamPricesOutput struct {
+	// Updates is closed once ctx is done or the consumer falls behind
+	Updates <-chan types.PriceUpdate
+}
+
+func (s *service) StreamPrices(ctx context.Context, in *StreamPricesInput) (*StreamPricesOutput, error) {
+	if s.publisher == nil {
+		return nil, &types.ErrStreamUnavailable{}
+	}
+
+	symbols := in.Symbols
+
+	if len(symbols) == 0 {
+		tickers, err := s.storage.GetByUser(ctx, in.Username)
+		if err != nil {
+			return nil, err
+		}
+
+		symbols = make([]string, 0, len(tickers))
+		for _, t := range tickers {
+			symbols = append(symbols, t.Symbol)
+		}
+	}
+
+	for _, symbol := range symbols {
+		if !s.isValidSymbol(ctx, symbol) {
+			return nil, &types.ErrTickerNotFound{
+				Symbol: symbol,
+			}
+		}
+	}
+
+	updates, err := s.publisher.Subscribe(ctx, symbols, in.LastEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamPricesOutput{
+		Updates: updates,
+	}, nil
+}
+
+// isValidSymbol only allows symbols that are still trading
+func (s *service) isValidSymbol(ctx context.Context, symbol string) bool {
+	v, err := s.symbols.GetSymbol(ctx, symbol)
+	if err != nil {
+		return false
+	}
+
+	return v.Delisted.IsZero()
+}
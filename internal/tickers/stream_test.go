@@ -0,0 +1,103 @@
+//go:build test
+
+package tickers
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/prices"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+	"time"
+)
+
+func TestTickers_StreamPrices(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	st := storage.NewMock()
+	st.(*storage.MockStorage).GetByUserFunc = func(ctx context.Context, username string) ([]types.Ticker, error) {
+		return []types.Ticker{{Symbol: "AAPL", Price: decimal.MustParse("100")}}, nil
+	}
+
+	broker := prices.NewBroker(nil)
+
+	svc, _ := New(&Config{
+		Storage:   st,
+		Publisher: broker,
+	})
+
+	out, err := svc.StreamPrices(ctx, &StreamPricesInput{
+		Username: "test",
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	// only the user tickers are streamed
+	broker.Publish("MSFT", decimal.MustParse("200"), time.Now())
+	broker.Publish("AAPL", decimal.MustParse("101"), time.Now())
+
+	update := <-out.Updates
+	if update.Symbol != "AAPL" || !update.Price.Equal(decimal.MustParse("101")) {
+		t.Errorf("expected AAPL 101, got %s %v", update.Symbol, update.Price)
+	}
+}
+
+func TestTickers_StreamPrices_Unavailable(t *testing.T) {
+	svc, _ := New(&Config{
+		Storage: storage.NewMock(),
+	})
+
+	_, err := svc.StreamPrices(context.Background(), &StreamPricesInput{
+		Username: "test",
+	})
+
+	var errUnavailable *types.ErrStreamUnavailable
+	if !errors.As(err, &errUnavailable) {
+		t.Errorf("expected error to be %T, got %T", errUnavailable, err)
+	}
+}
+
+func TestTickers_StreamPrices_Symbols(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := prices.NewBroker(nil)
+
+	// storage is not used when symbols are given
+	svc, _ := New(&Config{
+		Storage:   storage.NewMock(),
+		Publisher: broker,
+	})
+
+	out, err := svc.StreamPrices(ctx, &StreamPricesInput{
+		Username: "test",
+		Symbols:  []string{"MSFT"},
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	broker.Publish("AAPL", decimal.MustParse("101"), time.Now())
+	broker.Publish("MSFT", decimal.MustParse("200"), time.Now())
+
+	update := <-out.Updates
+	if update.Symbol != "MSFT" || !update.Price.Equal(decimal.MustParse("200")) {
+		t.Errorf("expected MSFT 200, got %s %v", update.Symbol, update.Price)
+	}
+
+	_, err = svc.StreamPrices(ctx, &StreamPricesInput{
+		Username: "test",
+		Symbols:  []string{"MSFT", "INVALID"},
+	})
+
+	var errNotFound *types.ErrTickerNotFound
+	if !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be %T, got %T", errNotFound, err)
+	} else if errNotFound.Symbol != "INVALID" {
+		t.Errorf("expected symbol to be INVALID, got %s", errNotFound.Symbol)
+	}
+}
@@ -3,10 +3,13 @@ package tickers
 import (
 	"context"
 	"github.com/falmar/richerage-api/internal/tickers/types"
+	"time"
 )
 
 type GetTickersInput struct {
 	Username string
+	// Currency converts prices with the current rate, prices are in the trading currency when empty
+	Currency string
 }
 
 type GetTickersOutput struct {
@@ -14,11 +17,33 @@ type GetTickersOutput struct {
 }
 
 func (s *service) GetTickers(ctx context.Context, in *GetTickersInput) (*GetTickersOutput, error) {
+	if err := s.checkCurrency(ctx, in.Currency); err != nil {
+		return nil, err
+	}
+
 	tickers, err := s.storage.GetByUser(ctx, in.Username)
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
+
+	for i, v := range tickers {
+		if v.Currency == "" {
+			tickers[i].Currency = s.symbolCurrency(ctx, v.Symbol)
+		}
+
+		if in.Currency == "" {
+			continue
+		}
+
+		tickers[i].Price, err = s.convert(ctx, v.Price, tickers[i].Currency, in.Currency, now)
+		if err != nil {
+			return nil, err
+		}
+		tickers[i].Currency = in.Currency
+	}
+
 	return &GetTickersOutput{
 		Tickers: tickers,
 	}, nil
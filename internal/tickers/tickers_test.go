@@ -4,6 +4,7 @@ package tickers
 
 import (
 	"context"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
 	"github.com/falmar/richerage-api/internal/storage"
 	"github.com/falmar/richerage-api/internal/tickers/types"
 	"testing"
@@ -20,7 +21,7 @@ func TestTickers_Tickers(t *testing.T) {
 
 		return []types.Ticker{
 			{
-				Price:  100,
+				Price:  decimal.MustParse("100"),
 				Symbol: "AAPL",
 			},
 		}, nil
@@ -54,8 +55,8 @@ func TestTickers_Tickers(t *testing.T) {
 
 	if out.Tickers[0].Symbol != "AAPL" {
 		t.Errorf("expected ticker symbol to be AAPL, got %s", out.Tickers[0].Symbol)
-	} else if out.Tickers[0].Price != 100 {
-		t.Errorf("expected ticker price to be 100, got %f", out.Tickers[0].Price)
+	} else if !out.Tickers[0].Price.Equal(decimal.MustParse("100")) {
+		t.Errorf("expected ticker price to be 100, got %s", out.Tickers[0].Price)
 	}
 }
 
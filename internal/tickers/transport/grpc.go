@@ -0,0 +1,196 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pb"
+	"github.com/falmar/richerage-api/internal/tickers/endpoint"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+	"strings"
+)
+
+var ErrNoGRPCStream = errors.New("no grpc stream in context")
+
+// GRPCSender is the part of a server stream used by the streaming encoders
+type GRPCSender interface {
+	SendMsg(m interface{}) error
+}
+
+// GRPCStreamContext lets the streaming encoders send their messages on stream
+func GRPCStreamContext(ctx context.Context, stream GRPCSender) context.Context {
+	return context.WithValue(ctx, "grpc_stream", stream)
+}
+
+// GRPCTokenDecoder reads the token from the "authorization" metadata, "Bearer <token>" or the bare token
+func GRPCTokenDecoder(ctx context.Context, md metadata.MD) context.Context {
+	// let endpoint handle auth checks
+	var token string
+	if v := md.Get("authorization"); len(v) > 0 {
+		token = v[0]
+		if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+			token = token[7:]
+		}
+	}
+
+	return context.WithValue(ctx, "auth_token", strings.TrimSpace(token))
+}
+
+func TickersGRPCRequestDecoder(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.GetTickersRequest)
+
+	return &endpoint.TickersRequest{
+		Currency: req.GetCurrency(),
+	}, nil
+}
+
+func TickersGRPCResponseEncoder(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(*endpoint.TickersResponse)
+
+	tickers := make([]*pb.Ticker, 0, len(res.Tickers))
+	for _, t := range res.Tickers {
+		tickers = append(tickers, &pb.Ticker{
+			Symbol:   t.Symbol,
+			Price:    t.Price.String(),
+			Currency: t.Currency,
+		})
+	}
+
+	return &pb.GetTickersResponse{
+		Tickers: tickers,
+	}, nil
+}
+
+func TickerHistoryGRPCRequestDecoder(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.GetTickerHistoryRequest)
+
+	return &endpoint.TickerHistoryRequest{
+		Symbol:     req.GetSymbol(),
+		Before:     req.GetBefore(),
+		Adjusted:   req.GetAdjusted(),
+		Currency:   req.GetCurrency(),
+		Resolution: req.GetResolution(),
+	}, nil
+}
+
+func TickerHistoryGRPCResponseEncoder(_ context.Context, response interface{}) (interface{}, error) {
+	res := response.(*endpoint.TickerHistoryResponse)
+
+	bars := make([]*pb.Bar, 0, len(res.Tickers))
+	for _, t := range res.Tickers {
+		bars = append(bars, grpcBar(t))
+	}
+
+	return &pb.GetTickerHistoryResponse{
+		Bars:       bars,
+		Resolution: string(res.Resolution),
+	}, nil
+}
+
+func grpcBar(t types.TickerHistory) *pb.Bar {
+	return &pb.Bar{
+		Date:     timestamppb.New(t.Date),
+		Open:     t.Open.String(),
+		High:     t.High.String(),
+		Low:      t.Low.String(),
+		Close:    t.Price.String(),
+		Volume:   t.Volume,
+		Symbol:   t.Symbol,
+		Currency: t.Currency,
+	}
+}
+
+func TickerHistoryStreamGRPCRequestDecoder(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.StreamHistoryRequest)
+
+	return &endpoint.TickerHistoryStreamRequest{
+		TickerHistoryRequest: endpoint.TickerHistoryRequest{
+			Symbol:     req.GetSymbol(),
+			Before:     req.GetBefore(),
+			Adjusted:   req.GetAdjusted(),
+			Currency:   req.GetCurrency(),
+			Resolution: req.GetResolution(),
+		},
+		ChunkSize: int(req.GetChunkSize()),
+	}, nil
+}
+
+// TickerHistoryStreamGRPCResponseEncoder sends every chunk on the stream of ctx, it returns
+// the error of the failed chunk or ctx.Err when the client is gone
+func TickerHistoryStreamGRPCResponseEncoder(ctx context.Context, response interface{}) (interface{}, error) {
+	res := response.(*endpoint.TickerHistoryStreamResponse)
+
+	stream, ok := ctx.Value("grpc_stream").(GRPCSender)
+	if !ok {
+		return nil, ErrNoGRPCStream
+	}
+
+	for chunk := range res.Chunks {
+		if chunk.Err != nil {
+			return nil, chunk.Err
+		}
+
+		bars := make([]*pb.Bar, 0, len(chunk.History))
+		for _, t := range chunk.History {
+			bars = append(bars, grpcBar(t))
+		}
+
+		err := stream.SendMsg(&pb.StreamHistoryResponse{
+			Bars:       bars,
+			Resolution: string(res.Resolution),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// chunks are closed early once ctx is done
+	return nil, ctx.Err()
+}
+
+func WatchPricesGRPCRequestDecoder(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.WatchPricesRequest)
+
+	var lastEventID string
+	if req.GetLastEventId() > 0 {
+		lastEventID = strconv.FormatUint(req.GetLastEventId(), 10)
+	}
+
+	return &endpoint.TickersStreamRequest{
+		Symbols:     req.GetSymbols(),
+		LastEventID: lastEventID,
+	}, nil
+}
+
+// WatchPricesGRPCResponseEncoder sends every update on the stream of ctx until the client is gone,
+// the stream ends with UNAVAILABLE when the updates are closed for a slow client
+func WatchPricesGRPCResponseEncoder(ctx context.Context, response interface{}) (interface{}, error) {
+	res := response.(*endpoint.TickersStreamResponse)
+
+	stream, ok := ctx.Value("grpc_stream").(GRPCSender)
+	if !ok {
+		return nil, ErrNoGRPCStream
+	}
+
+	for u := range res.Updates {
+		err := stream.SendMsg(&pb.PriceUpdate{
+			Id:     u.ID,
+			Symbol: u.Symbol,
+			Price:  u.Price.String(),
+			Time:   timestamppb.New(u.Time),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, status.Error(codes.Unavailable, "price updates fell behind, resume with the last event id")
+}
@@ -0,0 +1,244 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pb"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/endpoint"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestGRPCTokenDecoder(t *testing.T) {
+	tests := map[string]string{
+		"Bearer token": "token",
+		"bearer token": "token",
+		"token":        "token",
+		"":             "",
+	}
+
+	for value, expected := range tests {
+		md := metadata.MD{}
+		if value != "" {
+			md.Set("authorization", value)
+		}
+
+		token, ok := GRPCTokenDecoder(context.Background(), md).Value("auth_token").(string)
+		if !ok || token != expected {
+			t.Errorf("%q: expected token to be %q, got %q", value, expected, token)
+		}
+	}
+}
+
+func TestTickerHistory_GRPCRequestDecoder(t *testing.T) {
+	out, err := TickerHistoryGRPCRequestDecoder(context.Background(), &pb.GetTickerHistoryRequest{
+		Symbol:     "AAPL",
+		Before:     "2024-01-02T00:00:00Z",
+		Adjusted:   "split",
+		Currency:   "EUR",
+		Resolution: "15m",
+	})
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	req := out.(*endpoint.TickerHistoryRequest)
+	if req.Symbol != "AAPL" || req.Before != "2024-01-02T00:00:00Z" || req.Adjusted != "split" || req.Currency != "EUR" || req.Resolution != "15m" {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
+
+func TestTickerHistory_GRPCResponseEncoder(t *testing.T) {
+	date := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	out, err := TickerHistoryGRPCResponseEncoder(context.Background(), &endpoint.TickerHistoryResponse{
+		Tickers: []types.TickerHistory{{
+			Date:     date,
+			Price:    decimal.MustParse("185.64"),
+			Open:     decimal.MustParse("187.15"),
+			High:     decimal.MustParse("188.44"),
+			Low:      decimal.MustParse("183.89"),
+			Volume:   82488700,
+			Symbol:   "AAPL",
+			Currency: "USD",
+		}},
+		Resolution: types.Resolution1d,
+	})
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	resp := out.(*pb.GetTickerHistoryResponse)
+	if resp.GetResolution() != "1d" || len(resp.GetBars()) != 1 {
+		t.Fatalf("unexpected response %v", resp)
+	}
+
+	bar := resp.GetBars()[0]
+	if !bar.GetDate().AsTime().Equal(date) {
+		t.Errorf("expected date to be %s, got %s", date, bar.GetDate().AsTime())
+	}
+	// prices keep their scale
+	if bar.GetClose() != "185.64" || bar.GetOpen() != "187.15" || bar.GetHigh() != "188.44" || bar.GetLow() != "183.89" {
+		t.Errorf("unexpected prices %v", bar)
+	}
+	if bar.GetVolume() != 82488700 || bar.GetSymbol() != "AAPL" || bar.GetCurrency() != "USD" {
+		t.Errorf("unexpected bar %v", bar)
+	}
+}
+
+func TestTickers_GRPCResponseEncoder(t *testing.T) {
+	out, _ := TickersGRPCResponseEncoder(context.Background(), &endpoint.TickersResponse{
+		Tickers: []types.Ticker{{Symbol: "AAPL", Price: decimal.MustParse("190.10"), Currency: "USD"}},
+	})
+
+	tickers := out.(*pb.GetTickersResponse).GetTickers()
+	if len(tickers) != 1 || tickers[0].GetSymbol() != "AAPL" || tickers[0].GetPrice() != "190.10" || tickers[0].GetCurrency() != "USD" {
+		t.Errorf("unexpected tickers %v", tickers)
+	}
+}
+
+// sentMessages records the messages sent on a stream
+type sentMessages []interface{}
+
+func (s *sentMessages) SendMsg(m interface{}) error {
+	*s = append(*s, m)
+	return nil
+}
+
+func TestTickerHistoryStream_GRPCRequestDecoder(t *testing.T) {
+	out, _ := TickerHistoryStreamGRPCRequestDecoder(context.Background(), &pb.StreamHistoryRequest{
+		Symbol:    "AAPL",
+		Adjusted:  "total",
+		ChunkSize: 100,
+	})
+
+	req := out.(*endpoint.TickerHistoryStreamRequest)
+	if req.Symbol != "AAPL" || req.Adjusted != "total" || req.ChunkSize != 100 {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
+
+func TestTickerHistoryStream_GRPCResponseEncoder(t *testing.T) {
+	chunks := make(chan types.HistoryChunk, 3)
+	chunks <- types.HistoryChunk{History: []types.TickerHistory{{Price: decimal.MustParse("2")}, {Price: decimal.MustParse("1")}}}
+	chunks <- types.HistoryChunk{History: []types.TickerHistory{{Price: decimal.MustParse("0.5")}}}
+	close(chunks)
+
+	var sent sentMessages
+	ctx := GRPCStreamContext(context.Background(), &sent)
+
+	_, err := TickerHistoryStreamGRPCResponseEncoder(ctx, &endpoint.TickerHistoryStreamResponse{
+		Chunks:     chunks,
+		Resolution: types.Resolution1d,
+	})
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(sent))
+	}
+
+	first := sent[0].(*pb.StreamHistoryResponse)
+	if len(first.GetBars()) != 2 || first.GetBars()[0].GetClose() != "2" || first.GetResolution() != "1d" {
+		t.Errorf("unexpected message %v", first)
+	}
+
+	if _, err := TickerHistoryStreamGRPCResponseEncoder(context.Background(), &endpoint.TickerHistoryStreamResponse{}); !errors.Is(err, ErrNoGRPCStream) {
+		t.Errorf("expected ErrNoGRPCStream, got %v", err)
+	}
+}
+
+func TestTickerHistoryStream_GRPCResponseEncoder_Error(t *testing.T) {
+	chunkErr := errors.New("chunk error")
+
+	chunks := make(chan types.HistoryChunk, 2)
+	chunks <- types.HistoryChunk{History: []types.TickerHistory{{Price: decimal.MustParse("1")}}}
+	chunks <- types.HistoryChunk{Err: chunkErr}
+	close(chunks)
+
+	var sent sentMessages
+	_, err := TickerHistoryStreamGRPCResponseEncoder(GRPCStreamContext(context.Background(), &sent), &endpoint.TickerHistoryStreamResponse{
+		Chunks: chunks,
+	})
+	if !errors.Is(err, chunkErr) || len(sent) != 1 {
+		t.Errorf("expected the chunk error after 1 message, got %v after %d", err, len(sent))
+	}
+
+	// chunks are closed early once the client is gone
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	closed := make(chan types.HistoryChunk)
+	close(closed)
+
+	_, err = TickerHistoryStreamGRPCResponseEncoder(GRPCStreamContext(ctx, &sent), &endpoint.TickerHistoryStreamResponse{
+		Chunks: closed,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWatchPrices_GRPCRequestDecoder(t *testing.T) {
+	out, _ := WatchPricesGRPCRequestDecoder(context.Background(), &pb.WatchPricesRequest{
+		Symbols:     []string{"AAPL"},
+		LastEventId: 42,
+	})
+
+	req := out.(*endpoint.TickersStreamRequest)
+	if len(req.Symbols) != 1 || req.Symbols[0] != "AAPL" || req.LastEventID != "42" {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	out, _ = WatchPricesGRPCRequestDecoder(context.Background(), &pb.WatchPricesRequest{})
+	if req := out.(*endpoint.TickersStreamRequest); req.LastEventID != "" {
+		t.Errorf("expected last event id to be empty, got %s", req.LastEventID)
+	}
+}
+
+func TestWatchPrices_GRPCResponseEncoder(t *testing.T) {
+	date := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+
+	updates := make(chan types.PriceUpdate, 1)
+	updates <- types.PriceUpdate{ID: 7, Symbol: "AAPL", Price: decimal.MustParse("190.10"), Time: date}
+	close(updates)
+
+	var sent sentMessages
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// updates are closed for a slow client while it is still connected
+	_, err := WatchPricesGRPCResponseEncoder(GRPCStreamContext(ctx, &sent), &endpoint.TickersStreamResponse{
+		Updates: updates,
+	})
+	if st := status.Convert(err); st.Code() != codes.Unavailable {
+		t.Errorf("expected Unavailable, got %v", err)
+	}
+
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(sent))
+	}
+
+	u := sent[0].(*pb.PriceUpdate)
+	if u.GetId() != 7 || u.GetSymbol() != "AAPL" || u.GetPrice() != "190.10" || !u.GetTime().AsTime().Equal(date) {
+		t.Errorf("unexpected update %v", u)
+	}
+
+	cancel()
+
+	closed := make(chan types.PriceUpdate)
+	close(closed)
+
+	_, err = WatchPricesGRPCResponseEncoder(GRPCStreamContext(ctx, &sent), &endpoint.TickersStreamResponse{
+		Updates: closed,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -2,35 +2,66 @@ package transport
 
 import (
 	"context"
-	"encoding/json"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
 	"github.com/falmar/richerage-api/internal/tickers/endpoint"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"time"
 )
 
 func TickerHistoryRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
 	req := &endpoint.TickerHistoryRequest{
-		Symbol: chi.URLParam(r, "symbol"),
+		Symbol:     chi.URLParam(r, "symbol"),
+		Before:     r.URL.Query().Get("before"),
+		Adjusted:   r.URL.Query().Get("adjusted"),
+		Currency:   r.URL.Query().Get("currency"),
+		Resolution: r.URL.Query().Get("resolution"),
 	}
 
 	return req, nil
 }
 
-func TickerHistoryResponseEncoder(_ context.Context, w http.ResponseWriter, response interface{}) error {
+func TickerHistoryResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 	res := response.(*endpoint.TickerHistoryResponse)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	return kit.EncodeResponse(ctx, w, http.StatusOK, historyRecords(res), kit.WithMeta("resolution", res.Resolution))
+}
+
+// historyRecord is a bar as sent by the http transports, its fields are the CSV columns in order
+type historyRecord struct {
+	Date     string          `json:"date"`
+	Symbol   string          `json:"symbol"`
+	Open     decimal.Decimal `json:"open"`
+	High     decimal.Decimal `json:"high"`
+	Low      decimal.Decimal `json:"low"`
+	Price    decimal.Decimal `json:"price"`
+	Volume   int64           `json:"volume"`
+	Currency string          `json:"currency"`
+}
+
+// historyRecords formats the bars of res for the http transports
+func historyRecords(res *endpoint.TickerHistoryResponse) []historyRecord {
+	// format date at transport output, sub-daily bars need the time of day
+	dateFormat := "2006-01-02"
+	if res.Resolution.Intraday() {
+		dateFormat = time.RFC3339
+	}
 
-	// format date at transport output
-	var tickers []interface{}
+	var tickers []historyRecord
 
 	for _, ticker := range res.Tickers {
-		tickers = append(tickers, map[string]interface{}{
-			"price": ticker.Price,
-			"date":  ticker.Date.Format("2006-01-02"),
+		tickers = append(tickers, historyRecord{
+			Date:     ticker.Date.Format(dateFormat),
+			Symbol:   ticker.Symbol,
+			Open:     ticker.Open,
+			High:     ticker.High,
+			Low:      ticker.Low,
+			Price:    ticker.Price,
+			Volume:   ticker.Volume,
+			Currency: ticker.Currency,
 		})
 	}
 
-	return json.NewEncoder(w).Encode(tickers)
+	return tickers
 }
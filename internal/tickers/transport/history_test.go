@@ -3,6 +3,7 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
 	"github.com/falmar/richerage-api/internal/tickers/endpoint"
 	"github.com/falmar/richerage-api/internal/tickers/types"
 	"github.com/go-chi/chi/v5"
@@ -13,7 +14,7 @@ import (
 )
 
 func TestTickerHistory_RequestDecoder(t *testing.T) {
-	r, _ := http.NewRequest("GET", "/ticker/BTC/history", nil)
+	r, _ := http.NewRequest("GET", "/ticker/BTC/history?before=2024-01-02T00:00:00Z&adjusted=split&currency=EUR&resolution=15m", nil)
 
 	rctx := chi.NewRouteContext()
 	rctx.URLParams.Add("symbol", "BTC")
@@ -33,6 +34,18 @@ func TestTickerHistory_RequestDecoder(t *testing.T) {
 	if req.Symbol != "BTC" {
 		t.Errorf("expected symbol to be BTC, got %s", req.Symbol)
 	}
+	if req.Before != "2024-01-02T00:00:00Z" {
+		t.Errorf("expected before to be 2024-01-02T00:00:00Z, got %s", req.Before)
+	}
+	if req.Adjusted != "split" {
+		t.Errorf("expected adjusted to be split, got %s", req.Adjusted)
+	}
+	if req.Currency != "EUR" {
+		t.Errorf("expected currency to be EUR, got %s", req.Currency)
+	}
+	if req.Resolution != "15m" {
+		t.Errorf("expected resolution to be 15m, got %s", req.Resolution)
+	}
 }
 
 func TestTickerHistory_RequestDecoder_Empty(t *testing.T) {
@@ -61,8 +74,8 @@ func TestTickerHistory_ResponseEncoder(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	tickers := []types.TickerHistory{
-		{Price: 45000.0, Date: time.Date(2023, 07, 21, 0, 0, 0, 0, time.UTC)},
-		{Price: 46000.0, Date: time.Date(2023, 07, 22, 0, 0, 0, 0, time.UTC)},
+		{Price: decimal.MustParse("45000.0"), Date: time.Date(2023, 07, 21, 0, 0, 0, 0, time.UTC)},
+		{Price: decimal.MustParse("46000.0"), Date: time.Date(2023, 07, 22, 0, 0, 0, 0, time.UTC)},
 	}
 	resp := &endpoint.TickerHistoryResponse{
 		Tickers: tickers,
@@ -85,7 +98,7 @@ func TestTickerHistory_ResponseEncoder(t *testing.T) {
 
 	// important to check date format
 	for i, ticker := range tickers {
-		if got[i]["price"].(float64) != ticker.Price || got[i]["date"].(string) != ticker.Date.Format("2006-01-02") {
+		if got[i]["price"].(float64) != ticker.Price.Float64() || got[i]["date"].(string) != ticker.Date.Format("2006-01-02") {
 			t.Errorf("got ticker %+v, want ticker %+v", got[i], ticker)
 		}
 	}
@@ -113,3 +126,43 @@ func TestTickerHistory_ResponseEncoder_Empty(t *testing.T) {
 		t.Errorf("got length %d, want length %d", len(got), len(resp.Tickers))
 	}
 }
+
+func TestTickerHistory_ResponseEncoder_Intraday(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	resp := &endpoint.TickerHistoryResponse{
+		Tickers: []types.TickerHistory{
+			{
+				Date:   time.Date(2023, 07, 21, 9, 35, 0, 0, newYork),
+				Open:   decimal.MustParse("100.00"),
+				High:   decimal.MustParse("101.25"),
+				Low:    decimal.MustParse("99.50"),
+				Price:  decimal.MustParse("100.75"),
+				Volume: 1200,
+			},
+		},
+		Resolution: types.Resolution5m,
+	}
+
+	err := TickerHistoryResponseEncoder(context.Background(), w, resp)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	var got []map[string]interface{}
+	_ = json.NewDecoder(w.Body).Decode(&got)
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 bar, got %d", len(got))
+	}
+
+	// sub-daily bars carry the time of day and offset of the exchange
+	if got[0]["date"] != "2023-07-21T09:35:00-04:00" {
+		t.Errorf("expected RFC3339 date, got %v", got[0]["date"])
+	}
+	if got[0]["open"] != 100.0 || got[0]["high"] != 101.25 || got[0]["low"] != 99.5 || got[0]["price"] != 100.75 || got[0]["volume"] != 1200.0 {
+		t.Errorf("expected OHLCV of the bar, got %v", got[0])
+	}
+}
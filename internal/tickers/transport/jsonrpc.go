@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers/endpoint"
+)
+
+type tickersJSONRPCParams struct {
+	Currency string `json:"currency"`
+}
+
+type tickerHistoryJSONRPCParams struct {
+	Symbol     string `json:"symbol"`
+	Before     string `json:"before"`
+	Adjusted   string `json:"adjusted"`
+	Currency   string `json:"currency"`
+	Resolution string `json:"resolution"`
+}
+
+func TickersJSONRPCRequestDecoder(_ context.Context, params json.RawMessage) (interface{}, error) {
+	p := &tickersJSONRPCParams{}
+	if err := kit.DecodeJSONRPCParams(params, p); err != nil {
+		return nil, err
+	}
+
+	return &endpoint.TickersRequest{
+		Currency: p.Currency,
+	}, nil
+}
+
+func TickersJSONRPCResponseEncoder(_ context.Context, response interface{}) (json.RawMessage, error) {
+	res := response.(*endpoint.TickersResponse)
+
+	if res.Tickers == nil {
+		return json.RawMessage("[]"), nil
+	}
+
+	return json.Marshal(res.Tickers)
+}
+
+func TickerHistoryJSONRPCRequestDecoder(_ context.Context, params json.RawMessage) (interface{}, error) {
+	p := &tickerHistoryJSONRPCParams{}
+	if err := kit.DecodeJSONRPCParams(params, p); err != nil {
+		return nil, err
+	}
+
+	return &endpoint.TickerHistoryRequest{
+		Symbol:     p.Symbol,
+		Before:     p.Before,
+		Adjusted:   p.Adjusted,
+		Currency:   p.Currency,
+		Resolution: p.Resolution,
+	}, nil
+}
+
+func TickerHistoryJSONRPCResponseEncoder(_ context.Context, response interface{}) (json.RawMessage, error) {
+	records := historyRecords(response.(*endpoint.TickerHistoryResponse))
+
+	// the result of a call is never null
+	if records == nil {
+		return json.RawMessage("[]"), nil
+	}
+
+	return json.Marshal(records)
+}
@@ -0,0 +1,84 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers/endpoint"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"testing"
+	"time"
+)
+
+func TestTickerHistory_JSONRPCRequestDecoder(t *testing.T) {
+	params := json.RawMessage(`{"symbol": "BTC", "before": "2024-01-02", "adjusted": "split", "currency": "EUR", "resolution": "15m"}`)
+
+	out, err := TickerHistoryJSONRPCRequestDecoder(context.Background(), params)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	req, ok := out.(*endpoint.TickerHistoryRequest)
+	if !ok || req == nil {
+		t.Errorf("expected request to be of type TickerHistoryRequest, got %T", out)
+		return
+	}
+
+	if req.Symbol != "BTC" || req.Before != "2024-01-02" || req.Adjusted != "split" || req.Currency != "EUR" || req.Resolution != "15m" {
+		t.Errorf("expected the params in the request, got %+v", req)
+	}
+}
+
+func TestTickerHistory_JSONRPCRequestDecoder_Invalid(t *testing.T) {
+	for _, params := range []string{`["BTC"]`, `{"symbol": 1}`} {
+		_, err := TickerHistoryJSONRPCRequestDecoder(context.Background(), json.RawMessage(params))
+		if _, ok := err.(*kit.BadRequestError); !ok {
+			t.Errorf("%s: expected BadRequestError, got %v", params, err)
+		}
+	}
+}
+
+func TestTickers_JSONRPCRequestDecoder_NoParams(t *testing.T) {
+	out, err := TickersJSONRPCRequestDecoder(context.Background(), nil)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	if req, ok := out.(*endpoint.TickersRequest); !ok || req.Currency != "" {
+		t.Errorf("expected empty TickersRequest, got %+v", out)
+	}
+}
+
+func TestTickerHistory_JSONRPCResponseEncoder(t *testing.T) {
+	res := &endpoint.TickerHistoryResponse{
+		Tickers: []types.TickerHistory{
+			{
+				Symbol:   "BTC",
+				Price:    decimal.NewFromFloat(42.5, 2),
+				Currency: "USD",
+				Date:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		Resolution: types.Resolution1d,
+	}
+
+	b, err := TickerHistoryJSONRPCResponseEncoder(context.Background(), res)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	var bars []map[string]interface{}
+	if err := json.Unmarshal(b, &bars); err != nil {
+		t.Fatalf("unexpected error to be nil, got: %v", err)
+	}
+
+	if len(bars) != 1 || bars[0]["date"] != "2024-01-02" || bars[0]["price"] != 42.5 {
+		t.Errorf("expected the bar of 2024-01-02, got %s", b)
+	}
+
+	b, _ = TickerHistoryJSONRPCResponseEncoder(context.Background(), &endpoint.TickerHistoryResponse{})
+	if string(b) != "[]" {
+		t.Errorf("expected empty array, got %s", b)
+	}
+}
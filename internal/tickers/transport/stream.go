@@ -0,0 +1,79 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/falmar/richerage-api/internal/tickers/endpoint"
+	"net/http"
+	"time"
+)
+
+var ErrStreamingUnsupported = errors.New("response writer does not support flushing")
+
+func TickersStreamRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	return &endpoint.TickersStreamRequest{
+		LastEventID: r.Header.Get("Last-Event-ID"),
+	}, nil
+}
+
+// MakeTickersStreamResponseEncoder writes the updates as server sent events,
+// a comment is sent every heartbeat to keep proxies from closing an idle connection
+func MakeTickersStreamResponseEncoder(heartbeat time.Duration) func(context.Context, http.ResponseWriter, interface{}) error {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		res := response.(*endpoint.TickersStreamResponse)
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return ErrStreamingUnsupported
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		// tell EventSource clients how long to wait before reconnecting
+		_, err := fmt.Fprint(w, "retry: 1000\n\n")
+		if err != nil {
+			return err
+		}
+		flusher.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				_, err = fmt.Fprint(w, ": heartbeat\n\n")
+			case update, ok := <-res.Updates:
+				if !ok {
+					// publisher closed the stream, the client resumes with Last-Event-ID
+					return nil
+				}
+
+				var data []byte
+				data, err = json.Marshal(map[string]interface{}{
+					"symbol": update.Symbol,
+					"price":  update.Price,
+					"time":   update.Time.Format(time.RFC3339),
+				})
+				if err != nil {
+					return err
+				}
+
+				_, err = fmt.Fprintf(w, "id: %d\nevent: price\ndata: %s\n\n", update.ID, data)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/endpoint"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTickersStream_RequestDecoder(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/tickers/stream", nil)
+	r.Header.Set("Last-Event-ID", "42")
+
+	out, err := TickersStreamRequestDecoder(context.Background(), r)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	req, ok := out.(*endpoint.TickersStreamRequest)
+	if !ok || req == nil {
+		t.Errorf("expected request to be of type TickersStreamRequest, got %T", out)
+		return
+	}
+
+	if req.LastEventID != "42" {
+		t.Errorf("expected last event id to be 42, got %s", req.LastEventID)
+	}
+}
+
+func TestTickersStream_ResponseEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	updates := make(chan types.PriceUpdate, 1)
+	updates <- types.PriceUpdate{
+		ID:     7,
+		Symbol: "AAPL",
+		Price:  decimal.MustParse("100.5"),
+		Time:   time.Date(2023, 07, 21, 0, 0, 0, 0, time.UTC),
+	}
+	close(updates)
+
+	err := MakeTickersStreamResponseEncoder(time.Hour)(context.Background(), w, &endpoint.TickersStreamResponse{
+		Updates: updates,
+	})
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected content type to be text/event-stream, got %s", ct)
+	}
+
+	expect := "retry: 1000\n\n" +
+		"id: 7\nevent: price\ndata: {\"price\":100.5,\"symbol\":\"AAPL\",\"time\":\"2023-07-21T00:00:00Z\"}\n\n"
+
+	if got := w.Body.String(); got != expect {
+		t.Errorf("got %q, want %q", got, expect)
+	}
+}
+
+func TestTickersStream_ResponseEncoder_Heartbeat(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	err := MakeTickersStreamResponseEncoder(time.Millisecond*10)(ctx, w, &endpoint.TickersStreamResponse{
+		Updates: make(chan types.PriceUpdate),
+	})
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	if !strings.Contains(w.Body.String(), ": heartbeat\n\n") {
+		t.Errorf("expected heartbeat comment, got %q", w.Body.String())
+	}
+}
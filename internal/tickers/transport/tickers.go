@@ -2,20 +2,19 @@ package transport
 
 import (
 	"context"
-	"encoding/json"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
 	"github.com/falmar/richerage-api/internal/tickers/endpoint"
 	"net/http"
 )
 
-func TickersRequestDecoder(context.Context, *http.Request) (interface{}, error) {
-	return &endpoint.TickersRequest{}, nil
+func TickersRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	return &endpoint.TickersRequest{
+		Currency: r.URL.Query().Get("currency"),
+	}, nil
 }
 
-func TickersResponseEncoder(_ context.Context, w http.ResponseWriter, response interface{}) error {
+func TickersResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 	res := response.(*endpoint.TickersResponse)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	return json.NewEncoder(w).Encode(res.Tickers)
+	return kit.EncodeResponse(ctx, w, http.StatusOK, res.Tickers)
 }
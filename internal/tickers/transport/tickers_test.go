@@ -3,15 +3,17 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
 	"github.com/falmar/richerage-api/internal/tickers/endpoint"
 	"github.com/falmar/richerage-api/internal/tickers/types"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
 func TestTickers_RequestDecoder(t *testing.T) {
-	r, err := http.NewRequest("GET", "/tickers", nil)
+	r, err := http.NewRequest("GET", "/tickers?currency=EUR", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -21,9 +23,11 @@ func TestTickers_RequestDecoder(t *testing.T) {
 		t.Error("expected error to be nil, got", err)
 	}
 
-	_, ok := out.(*endpoint.TickersRequest)
+	req, ok := out.(*endpoint.TickersRequest)
 	if !ok {
 		t.Errorf("expected request to be of type TickersRequest, got %T", out)
+	} else if req.Currency != "EUR" {
+		t.Errorf("expected currency to be EUR, got %s", req.Currency)
 	}
 }
 
@@ -31,8 +35,8 @@ func TestTickers_ResponseEncoder(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	tickers := []types.Ticker{
-		{Symbol: "BTC", Price: 45000.0},
-		{Symbol: "ETH", Price: 3000.0},
+		{Symbol: "BTC", Price: decimal.MustParse("45000.0")},
+		{Symbol: "ETH", Price: decimal.MustParse("3000.10"), Currency: "USD"},
 	}
 	resp := &endpoint.TickersResponse{
 		Tickers: tickers,
@@ -43,6 +47,11 @@ func TestTickers_ResponseEncoder(t *testing.T) {
 		t.Error("expected error to be nil, got", err)
 	}
 
+	// prices are numbers with the exact scale, never a float approximation
+	if body := w.Body.String(); !strings.Contains(body, `"price":45000.0,`) || !strings.Contains(body, `"price":3000.10,`) {
+		t.Errorf("expected prices with exact scale, got %s", body)
+	}
+
 	var got []types.Ticker
 	err = json.NewDecoder(w.Body).Decode(&got)
 	if err != nil {
@@ -56,7 +65,7 @@ func TestTickers_ResponseEncoder(t *testing.T) {
 	}
 
 	for i, ticker := range tickers {
-		if got[i].Symbol != ticker.Symbol || got[i].Price != ticker.Price {
+		if got[i].Symbol != ticker.Symbol || !got[i].Price.Equal(ticker.Price) {
 			t.Errorf("got ticker %+v, want ticker %+v", got[i], ticker)
 		}
 	}
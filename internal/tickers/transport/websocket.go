@@ -0,0 +1,460 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	authendpoint "github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/tickers/endpoint"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// client messages
+const (
+	WebsocketSubscribe   = "subscribe"
+	WebsocketUnsubscribe = "unsubscribe"
+)
+
+// server messages
+const (
+	WebsocketSnapshot = "snapshot"
+	WebsocketUpdate   = "update"
+	WebsocketError    = "error"
+)
+
+// WebsocketMessage is the single envelope of the protocol in both directions,
+// only the fields relevant to its type are set
+type WebsocketMessage struct {
+	Type string `json:"type"`
+	// ID is an optional client correlation id echoed back on errors
+	ID string `json:"id,omitempty"`
+
+	Symbols []string `json:"symbols,omitempty"`
+
+	Symbol string           `json:"symbol,omitempty"`
+	Price  *decimal.Decimal `json:"price,omitempty"`
+	Time   string           `json:"time,omitempty"`
+
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type WebsocketConfig struct {
+	// Session authenticates the handshake, see authendpoint.MakeSessionEndpoint
+	Session kitendpoint.Endpoint
+	// Stream is called once per subscribed symbol, see endpoint.MakeTickersStreamEndpoint
+	Stream kitendpoint.Endpoint
+
+	ErrorEncoder kithttp.ErrorEncoder
+	ErrorHandler interface {
+		Handle(ctx context.Context, err error)
+	}
+
+	MaxSymbols   int
+	PingInterval time.Duration
+	WriteTimeout time.Duration
+	// MaxMessageSize bounds client messages in bytes, larger ones close the connection with 1009
+	MaxMessageSize int64
+	// MaxPending bounds the errors and notices waiting to be written, a client that lets them
+	// pile up without reading is closed with 1008
+	MaxPending int
+}
+
+func NewWebsocketHandler(cfg *WebsocketConfig) http.Handler {
+	h := &websocketHandler{
+		session:        cfg.Session,
+		stream:         cfg.Stream,
+		errorEncoder:   cfg.ErrorEncoder,
+		errorHandler:   cfg.ErrorHandler,
+		maxSymbols:     cfg.MaxSymbols,
+		pingInterval:   cfg.PingInterval,
+		writeTimeout:   cfg.WriteTimeout,
+		maxMessageSize: cfg.MaxMessageSize,
+		maxPending:     cfg.MaxPending,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+	}
+
+	if h.errorEncoder == nil {
+		h.errorEncoder = kithttp.DefaultErrorEncoder
+	}
+	if h.maxSymbols <= 0 {
+		h.maxSymbols = 50
+	}
+	if h.pingInterval <= 0 {
+		h.pingInterval = time.Second * 30
+	}
+	if h.writeTimeout <= 0 {
+		h.writeTimeout = time.Second * 10
+	}
+	if h.maxMessageSize <= 0 {
+		h.maxMessageSize = 4096
+	}
+	if h.maxPending <= 0 {
+		h.maxPending = 100
+	}
+
+	return h
+}
+
+type websocketHandler struct {
+	session      kitendpoint.Endpoint
+	stream       kitendpoint.Endpoint
+	errorEncoder kithttp.ErrorEncoder
+	errorHandler interface {
+		Handle(ctx context.Context, err error)
+	}
+
+	maxSymbols     int
+	pingInterval   time.Duration
+	writeTimeout   time.Duration
+	maxMessageSize int64
+	maxPending     int
+
+	upgrader websocket.Upgrader
+}
+
+func (h *websocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := TokenDecoder(r.Context(), r)
+
+	// browsers cannot set headers on websocket handshakes
+	if token, _ := ctx.Value("auth_token").(string); token == "" {
+		ctx = context.WithValue(ctx, "auth_token", r.URL.Query().Get("token"))
+	}
+
+	response, err := h.session(ctx, &authendpoint.SessionRequest{})
+	if err != nil {
+		if h.errorHandler != nil {
+			h.errorHandler.Handle(ctx, err)
+		}
+		h.errorEncoder(ctx, err, w)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// upgrader already replied with an http error
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s := &websocketSession{
+		handler:  h,
+		conn:     conn,
+		username: response.(*authendpoint.SessionResponse).Username,
+		cancel:   cancel,
+		subs:     map[string]context.CancelFunc{},
+		prices:   map[string]WebsocketMessage{},
+		notify:   make(chan struct{}, 1),
+	}
+
+	go s.writeLoop(ctx)
+	s.readLoop(ctx)
+}
+
+// websocketSession is a single connection, price messages are conflated per symbol
+// so a slow client only ever misses intermediate prices and never blocks the publisher
+type websocketSession struct {
+	handler  *websocketHandler
+	conn     *websocket.Conn
+	username string
+	cancel   context.CancelFunc
+
+	subsMu sync.Mutex
+	subs   map[string]context.CancelFunc
+
+	outMu sync.Mutex
+	// control messages are never dropped, prices keeps only the latest message per symbol
+	control []WebsocketMessage
+	prices  map[string]WebsocketMessage
+	order   []string
+	notify  chan struct{}
+	// closeCode is sent when the session ends, going away unless the client broke the protocol
+	closeCode int
+}
+
+func (s *websocketSession) readLoop(ctx context.Context) {
+	defer s.cancel()
+
+	// a larger message fails the read, the connection is closed with 1009
+	s.conn.SetReadLimit(s.handler.maxMessageSize)
+
+	_ = s.conn.SetReadDeadline(time.Now().Add(s.handler.pingInterval * 2))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(s.handler.pingInterval * 2))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			// closed by either side
+			return
+		}
+
+		var msg WebsocketMessage
+
+		if err := json.Unmarshal(data, &msg); err != nil {
+			// the connection is still usable
+			s.sendError("", &kit.BadRequestError{Message: "invalid message"})
+			continue
+		}
+
+		switch msg.Type {
+		case WebsocketSubscribe:
+			s.subscribe(ctx, msg)
+		case WebsocketUnsubscribe:
+			s.unsubscribe(msg)
+		default:
+			s.sendError(msg.ID, &kit.BadRequestError{Message: "unknown message type " + msg.Type})
+		}
+	}
+}
+
+func (s *websocketSession) subscribe(ctx context.Context, msg WebsocketMessage) {
+	if len(msg.Symbols) == 0 {
+		s.sendError(msg.ID, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  map[string]string{"symbols": "required"},
+		})
+		return
+	}
+
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	for _, symbol := range msg.Symbols {
+		if _, ok := s.subs[symbol]; ok {
+			continue
+		}
+
+		if len(s.subs) >= s.handler.maxSymbols {
+			s.sendError(msg.ID, &kit.BadRequestError{
+				Message: "too many subscriptions",
+				Params:  map[string]string{"symbols": "at most " + strconv.Itoa(s.handler.maxSymbols) + " per connection"},
+			})
+			return
+		}
+
+		subCtx, subCancel := context.WithCancel(ctx)
+
+		response, err := s.handler.stream(subCtx, &endpoint.TickersStreamRequest{
+			Username: s.username,
+			Symbols:  []string{symbol},
+		})
+		if err != nil {
+			subCancel()
+			s.sendError(msg.ID, err)
+			continue
+		}
+
+		s.subs[symbol] = subCancel
+
+		go s.pump(subCtx, symbol, response.(*endpoint.TickersStreamResponse))
+	}
+}
+
+func (s *websocketSession) unsubscribe(msg WebsocketMessage) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	// cancelled first, the pumps queue nothing once the prices are dropped
+	for _, symbol := range msg.Symbols {
+		if cancel, ok := s.subs[symbol]; ok {
+			cancel()
+			delete(s.subs, symbol)
+		}
+	}
+
+	// drop prices still waiting to be written, a resubscribe queues the symbol again
+	s.outMu.Lock()
+	for _, symbol := range msg.Symbols {
+		if _, ok := s.prices[symbol]; !ok {
+			continue
+		}
+
+		delete(s.prices, symbol)
+
+		for i, v := range s.order {
+			if v == symbol {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	}
+	s.outMu.Unlock()
+}
+
+// pump moves updates of a single subscription into the outbox
+func (s *websocketSession) pump(ctx context.Context, symbol string, res *endpoint.TickersStreamResponse) {
+	msgType := WebsocketSnapshot
+
+	for update := range res.Updates {
+		price := update.Price
+
+		s.queuePrice(ctx, WebsocketMessage{
+			Type:   msgType,
+			Symbol: update.Symbol,
+			Price:  &price,
+			Time:   update.Time.Format(time.RFC3339),
+		})
+
+		msgType = WebsocketUpdate
+	}
+
+	if ctx.Err() != nil {
+		// unsubscribed or disconnected
+		return
+	}
+
+	// the publisher gave up on this subscription
+	s.subsMu.Lock()
+	delete(s.subs, symbol)
+	s.subsMu.Unlock()
+
+	s.queue(WebsocketMessage{
+		Type:    WebsocketError,
+		Symbols: []string{symbol},
+		Code:    "subscription_closed",
+		Message: "subscription closed, subscribe again to resume",
+	})
+}
+
+// queuePrice keeps msg as the latest price of its symbol unless the subscription of ctx ended,
+// checked under the outbox lock so a price read before an unsubscribe is not queued after it
+func (s *websocketSession) queuePrice(ctx context.Context, msg WebsocketMessage) {
+	s.outMu.Lock()
+
+	if ctx.Err() != nil {
+		s.outMu.Unlock()
+		return
+	}
+
+	if prev, ok := s.prices[msg.Symbol]; ok {
+		// a snapshot replaced before being written is still the first price of the client
+		if prev.Type == WebsocketSnapshot {
+			msg.Type = WebsocketSnapshot
+		}
+	} else {
+		s.order = append(s.order, msg.Symbol)
+	}
+
+	s.prices[msg.Symbol] = msg
+	s.outMu.Unlock()
+
+	s.wake()
+}
+
+// queue keeps msg until it is written, the session is closed once too many are waiting
+func (s *websocketSession) queue(msg WebsocketMessage) {
+	s.outMu.Lock()
+
+	if len(s.control) >= s.handler.maxPending {
+		s.closeCode = websocket.ClosePolicyViolation
+		s.outMu.Unlock()
+
+		s.cancel()
+		return
+	}
+
+	s.control = append(s.control, msg)
+	s.outMu.Unlock()
+
+	s.wake()
+}
+
+func (s *websocketSession) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *websocketSession) sendError(id string, err error) {
+	msg := WebsocketMessage{
+		Type:    WebsocketError,
+		ID:      id,
+		Code:    "internal_server_error",
+		Message: "internal server error",
+	}
+
+	if cErr, ok := err.(kit.CodedError); ok {
+		msg.Code = cErr.Code()
+		msg.Message = cErr.Error()
+	}
+
+	s.queue(msg)
+}
+
+// drain takes every pending message, control messages first
+func (s *websocketSession) drain() []WebsocketMessage {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+
+	out := make([]WebsocketMessage, 0, len(s.control)+len(s.order))
+	out = append(out, s.control...)
+
+	for _, symbol := range s.order {
+		if msg, ok := s.prices[symbol]; ok {
+			out = append(out, msg)
+		}
+	}
+
+	s.control = nil
+	s.order = s.order[:0]
+	s.prices = map[string]WebsocketMessage{}
+
+	return out
+}
+
+func (s *websocketSession) writeLoop(ctx context.Context) {
+	defer s.conn.Close()
+
+	ping := time.NewTicker(s.handler.pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.outMu.Lock()
+			code := s.closeCode
+			s.outMu.Unlock()
+
+			if code == 0 {
+				code = websocket.CloseGoingAway
+			}
+
+			_ = s.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(code, ""),
+				time.Now().Add(s.handler.writeTimeout),
+			)
+			return
+		case <-ping.C:
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(s.handler.writeTimeout))
+			if err != nil {
+				s.cancel()
+				return
+			}
+		case <-s.notify:
+			for _, msg := range s.drain() {
+				_ = s.conn.SetWriteDeadline(time.Now().Add(s.handler.writeTimeout))
+
+				if err := s.conn.WriteJSON(msg); err != nil {
+					s.cancel()
+					return
+				}
+			}
+		}
+	}
+}
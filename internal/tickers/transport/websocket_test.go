@@ -0,0 +1,253 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	authendpoint "github.com/falmar/richerage-api/internal/auth/endpoint"
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"github.com/falmar/richerage-api/internal/tickers/endpoint"
+	"github.com/falmar/richerage-api/internal/tickers/types"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCodedError struct{}
+
+func (e *testCodedError) HttpCode() int { return 401 }
+func (e *testCodedError) Code() string  { return "unauthorized" }
+func (e *testCodedError) Error() string { return "unauthorized" }
+
+func newTestWebsocketServer(t *testing.T, updates map[string]chan types.PriceUpdate) *httptest.Server {
+	session := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		if token, _ := ctx.Value("auth_token").(string); token != "test" {
+			return nil, &testCodedError{}
+		}
+
+		return &authendpoint.SessionResponse{Username: "john.doe"}, nil
+	}
+
+	stream := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*endpoint.TickersStreamRequest)
+
+		if req.Username != "john.doe" {
+			t.Errorf("expected username to be john.doe, got %s", req.Username)
+		}
+
+		ch, ok := updates[req.Symbols[0]]
+		if !ok {
+			return nil, &types.ErrTickerNotFound{Symbol: req.Symbols[0]}
+		}
+
+		return &endpoint.TickersStreamResponse{Updates: ch}, nil
+	}
+
+	return httptest.NewServer(NewWebsocketHandler(&WebsocketConfig{
+		Session: session,
+		Stream:  stream,
+		ErrorEncoder: func(ctx context.Context, err error, w http.ResponseWriter) {
+			w.WriteHeader(http.StatusUnauthorized)
+		},
+	}))
+}
+
+func dialTestWebsocket(t *testing.T, server *httptest.Server, token string) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?token=" + token
+
+	return websocket.DefaultDialer.Dial(url, nil)
+}
+
+func readTestMessage(t *testing.T, conn *websocket.Conn) WebsocketMessage {
+	var msg WebsocketMessage
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	return msg
+}
+
+func TestWebsocket_Unauthorized(t *testing.T) {
+	server := newTestWebsocketServer(t, nil)
+	defer server.Close()
+
+	_, resp, err := dialTestWebsocket(t, server, "invalid")
+	if !errors.Is(err, websocket.ErrBadHandshake) {
+		t.Errorf("expected error to be %v, got %v", websocket.ErrBadHandshake, err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status to be 401, got %v", resp)
+	}
+}
+
+func TestWebsocket_Subscribe(t *testing.T) {
+	aapl := make(chan types.PriceUpdate, 2)
+	aapl <- types.PriceUpdate{ID: 1, Symbol: "AAPL", Price: decimal.MustParse("100"), Time: time.Now()}
+
+	server := newTestWebsocketServer(t, map[string]chan types.PriceUpdate{
+		"AAPL": aapl,
+	})
+	defer server.Close()
+
+	conn, _, err := dialTestWebsocket(t, server, "test")
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.WriteJSON(WebsocketMessage{Type: WebsocketSubscribe, ID: "1", Symbols: []string{"AAPL"}})
+
+	// first price of a subscription is the snapshot
+	msg := readTestMessage(t, conn)
+	if msg.Type != WebsocketSnapshot || msg.Symbol != "AAPL" || !msg.Price.Equal(decimal.MustParse("100")) {
+		t.Errorf("expected AAPL snapshot at 100, got %+v", msg)
+	}
+
+	aapl <- types.PriceUpdate{ID: 2, Symbol: "AAPL", Price: decimal.MustParse("101"), Time: time.Now()}
+
+	msg = readTestMessage(t, conn)
+	if msg.Type != WebsocketUpdate || !msg.Price.Equal(decimal.MustParse("101")) {
+		t.Errorf("expected AAPL update at 101, got %+v", msg)
+	}
+
+	// unknown symbols are reported with the request id
+	_ = conn.WriteJSON(WebsocketMessage{Type: WebsocketSubscribe, ID: "2", Symbols: []string{"INVALID"}})
+
+	msg = readTestMessage(t, conn)
+	if msg.Type != WebsocketError || msg.ID != "2" || msg.Code != "ticker_not_found" {
+		t.Errorf("expected ticker_not_found error for id 2, got %+v", msg)
+	}
+
+	_ = conn.WriteJSON(WebsocketMessage{Type: "wat", ID: "3"})
+
+	msg = readTestMessage(t, conn)
+	if msg.Type != WebsocketError || msg.ID != "3" || msg.Code != "bad_request" {
+		t.Errorf("expected bad_request error for id 3, got %+v", msg)
+	}
+}
+
+func TestWebsocket_Conflate(t *testing.T) {
+	ctx := context.Background()
+	s := &websocketSession{
+		handler: NewWebsocketHandler(&WebsocketConfig{}).(*websocketHandler),
+		prices:  map[string]WebsocketMessage{},
+		notify:  make(chan struct{}, 1),
+	}
+
+	// a client not reading only gets the latest price of each symbol
+	s.queuePrice(ctx, WebsocketMessage{Type: WebsocketSnapshot, Symbol: "AAPL", Price: newPrice("100")})
+	s.queuePrice(ctx, WebsocketMessage{Type: WebsocketUpdate, Symbol: "MSFT", Price: newPrice("200")})
+	s.queuePrice(ctx, WebsocketMessage{Type: WebsocketUpdate, Symbol: "AAPL", Price: newPrice("101")})
+	s.queuePrice(ctx, WebsocketMessage{Type: WebsocketUpdate, Symbol: "AAPL", Price: newPrice("102")})
+	s.queue(WebsocketMessage{Type: WebsocketError, Code: "bad_request"})
+
+	out := s.drain()
+	if len(out) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(out))
+	}
+
+	if out[0].Type != WebsocketError {
+		t.Errorf("expected control messages first, got %+v", out[0])
+	}
+	if out[1].Symbol != "AAPL" || !out[1].Price.Equal(decimal.MustParse("102")) || out[1].Type != WebsocketSnapshot {
+		t.Errorf("expected AAPL snapshot at 102, got %+v", out[1])
+	}
+	if out[2].Symbol != "MSFT" || !out[2].Price.Equal(decimal.MustParse("200")) {
+		t.Errorf("expected MSFT at 200, got %+v", out[2])
+	}
+
+	if len(s.drain()) != 0 {
+		t.Errorf("expected outbox to be empty after drain")
+	}
+}
+
+func TestWebsocket_Unsubscribe_LatePrice(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &websocketSession{
+		handler: NewWebsocketHandler(&WebsocketConfig{}).(*websocketHandler),
+		subs:    map[string]context.CancelFunc{"AAPL": cancel},
+		prices:  map[string]WebsocketMessage{},
+		notify:  make(chan struct{}, 1),
+	}
+
+	s.queuePrice(ctx, WebsocketMessage{Type: WebsocketSnapshot, Symbol: "AAPL", Price: newPrice("100")})
+	s.unsubscribe(WebsocketMessage{Type: WebsocketUnsubscribe, Symbols: []string{"AAPL"}})
+
+	// read by the pump before the unsubscribe, queued after it
+	s.queuePrice(ctx, WebsocketMessage{Type: WebsocketUpdate, Symbol: "AAPL", Price: newPrice("101")})
+
+	if out := s.drain(); len(out) != 0 {
+		t.Errorf("expected no price after the unsubscribe, got %+v", out)
+	}
+}
+
+func TestWebsocket_Resubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &websocketSession{
+		handler: NewWebsocketHandler(&WebsocketConfig{}).(*websocketHandler),
+		subs:    map[string]context.CancelFunc{"AAPL": cancel},
+		prices:  map[string]WebsocketMessage{},
+		notify:  make(chan struct{}, 1),
+	}
+
+	s.queuePrice(ctx, WebsocketMessage{Type: WebsocketSnapshot, Symbol: "AAPL", Price: newPrice("100")})
+	s.unsubscribe(WebsocketMessage{Type: WebsocketUnsubscribe, Symbols: []string{"AAPL"}})
+
+	// subscribed again before anything was written
+	s.queuePrice(context.Background(), WebsocketMessage{Type: WebsocketSnapshot, Symbol: "AAPL", Price: newPrice("101")})
+
+	out := s.drain()
+	if len(out) != 1 || !out[0].Price.Equal(decimal.MustParse("101")) {
+		t.Errorf("expected a single AAPL snapshot at 101, got %+v", out)
+	}
+}
+
+func TestWebsocket_ReadLimit(t *testing.T) {
+	server := newTestWebsocketServer(t, map[string]chan types.PriceUpdate{})
+	defer server.Close()
+
+	conn, _, err := dialTestWebsocket(t, server, "test")
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "subscribe", "id": "`+strings.Repeat("x", 8192)+`"}`))
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Errorf("expected close 1009, got %v", err)
+	}
+}
+
+func TestWebsocket_MaxPending(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &websocketSession{
+		handler: NewWebsocketHandler(&WebsocketConfig{MaxPending: 2}).(*websocketHandler),
+		cancel:  cancel,
+		prices:  map[string]WebsocketMessage{},
+		notify:  make(chan struct{}, 1),
+	}
+
+	// a client sending junk without reading the errors
+	for i := 0; i < 3; i++ {
+		s.sendError("", &testCodedError{})
+	}
+
+	if ctx.Err() == nil || s.closeCode != websocket.ClosePolicyViolation {
+		t.Errorf("expected the session to close with 1008, got %d", s.closeCode)
+	}
+	if out := s.drain(); len(out) != 2 {
+		t.Errorf("expected 2 pending messages, got %d", len(out))
+	}
+}
+
+func newPrice(s string) *decimal.Decimal {
+	price := decimal.MustParse(s)
+	return &price
+}
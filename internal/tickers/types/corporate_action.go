@@ -0,0 +1,37 @@
+package types
+
+import (
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"time"
+)
+
+type CorporateActionType string
+
+const (
+	CorporateActionSplit    CorporateActionType = "split"
+	CorporateActionDividend CorporateActionType = "dividend"
+)
+
+// CorporateAction changes the raw price from ExDate onwards
+type CorporateAction struct {
+	Symbol string
+	Type   CorporateActionType
+	ExDate time.Time
+
+	// Ratio of a split, new shares for each old share (4 for a 4:1 split)
+	Ratio decimal.Decimal
+	// Amount of a cash dividend per share
+	Amount decimal.Decimal
+}
+
+// Adjustment of historical prices for corporate actions
+type Adjustment string
+
+const (
+	// AdjustmentNone returns raw prices
+	AdjustmentNone Adjustment = "none"
+	// AdjustmentSplit back-adjusts for splits
+	AdjustmentSplit Adjustment = "split"
+	// AdjustmentTotal back-adjusts for splits and reinvested cash dividends
+	AdjustmentTotal Adjustment = "total"
+)
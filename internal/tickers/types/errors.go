@@ -1,9 +1,14 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type ErrTickerNotFound struct {
 	Symbol string
+	// Delisted is set when the symbol existed but no longer trades, zero when it never existed
+	Delisted time.Time
 }
 
 func (e *ErrTickerNotFound) HttpCode() int {
@@ -11,9 +16,47 @@ func (e *ErrTickerNotFound) HttpCode() int {
 }
 
 func (e *ErrTickerNotFound) Code() string {
+	if !e.Delisted.IsZero() {
+		return "ticker_delisted"
+	}
+
 	return "ticker_not_found"
 }
 
 func (e *ErrTickerNotFound) Error() string {
+	if !e.Delisted.IsZero() {
+		return fmt.Sprintf("ticker %s delisted on %s", e.Symbol, e.Delisted.Format("2006-01-02"))
+	}
+
 	return fmt.Sprintf("ticker %s not found", e.Symbol)
 }
+
+type ErrStreamUnavailable struct{}
+
+func (e *ErrStreamUnavailable) HttpCode() int {
+	return 503
+}
+
+func (e *ErrStreamUnavailable) Code() string {
+	return "stream_unavailable"
+}
+
+func (e *ErrStreamUnavailable) Error() string {
+	return "live prices are not available"
+}
+
+type ErrCurrencyNotSupported struct {
+	Currency string
+}
+
+func (e *ErrCurrencyNotSupported) HttpCode() int {
+	return 400
+}
+
+func (e *ErrCurrencyNotSupported) Code() string {
+	return "currency_not_supported"
+}
+
+func (e *ErrCurrencyNotSupported) Error() string {
+	return fmt.Sprintf("currency %s is not supported", e.Currency)
+}
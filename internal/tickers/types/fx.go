@@ -0,0 +1,13 @@
+package types
+
+import (
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"time"
+)
+
+// FXRate is how many units of Currency one US dollar buys on Date
+type FXRate struct {
+	Currency string
+	Date     time.Time
+	Rate     decimal.Decimal
+}
@@ -0,0 +1,46 @@
+package types
+
+import "time"
+
+// Resolution is the length of each bar of a history
+type Resolution string
+
+const (
+	Resolution1m  Resolution = "1m"
+	Resolution5m  Resolution = "5m"
+	Resolution15m Resolution = "15m"
+	Resolution1h  Resolution = "1h"
+	// Resolution1d is a bar per trading day, the default
+	Resolution1d Resolution = "1d"
+)
+
+// Resolutions are all the supported resolutions, finest first
+var Resolutions = []Resolution{Resolution1m, Resolution5m, Resolution15m, Resolution1h, Resolution1d}
+
+// Valid reports whether r is a supported resolution
+func (r Resolution) Valid() bool {
+	return r.Duration() > 0
+}
+
+// Intraday reports whether bars are shorter than a trading day
+func (r Resolution) Intraday() bool {
+	return r.Valid() && r != Resolution1d
+}
+
+// Duration of a bar, a day for Resolution1d although sessions are shorter, 0 when not supported
+func (r Resolution) Duration() time.Duration {
+	switch r {
+	case Resolution1m:
+		return time.Minute
+	case Resolution5m:
+		return time.Minute * 5
+	case Resolution15m:
+		return time.Minute * 15
+	case Resolution1h:
+		return time.Hour
+	case Resolution1d:
+		return time.Hour * 24
+	default:
+		return 0
+	}
+}
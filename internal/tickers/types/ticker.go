@@ -1,38 +1,41 @@
 package types
 
-import "time"
+import (
+	"github.com/falmar/richerage-api/internal/pkg/decimal"
+	"time"
+)
 
 type Ticker struct {
-	Symbol string  `json:"symbol"`
-	Price  float64 `json:"price"`
+	Symbol   string          `json:"symbol"`
+	Price    decimal.Decimal `json:"price"`
+	Currency string          `json:"currency"`
 }
 
+// TickerHistory is a bar of history starting at Date, Price is the close
 type TickerHistory struct {
-	Date  time.Time `json:"date"`
-	Price float64   `json:"price"`
+	Date  time.Time       `json:"date"`
+	Price decimal.Decimal `json:"price"`
+
+	Open   decimal.Decimal `json:"open"`
+	High   decimal.Decimal `json:"high"`
+	Low    decimal.Decimal `json:"low"`
+	Volume int64           `json:"volume"`
+
+	// Symbol the instrument traded under on Date, it changes across renames
+	Symbol   string `json:"symbol"`
+	Currency string `json:"currency"`
+}
+
+// PriceUpdate is a single live price change, ID increases monotonically per publisher
+type PriceUpdate struct {
+	ID     uint64          `json:"-"`
+	Symbol string          `json:"symbol"`
+	Price  decimal.Decimal `json:"price"`
+	Time   time.Time       `json:"time"`
 }
 
-func ValidTickers() []string {
-	return []string{
-		"AAPL",
-		"MSFT",
-		"GOOG",
-		"AMZN",
-		"FB",
-		"TSLA",
-		"NVDA",
-		"JPM",
-		"BABA",
-		"JNJ",
-		"WMT",
-		"PG",
-		"PYPL",
-		"DIS",
-		"ADBE",
-		"PFE",
-		"V",
-		"MA",
-		"CRM",
-		"NFLX",
-	}
+// HistoryChunk is a part of a streamed history sorted newest first, the stream ends after a chunk with Err
+type HistoryChunk struct {
+	History []TickerHistory
+	Err     error
 }
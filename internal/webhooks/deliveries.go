@@ -0,0 +1,46 @@
+package webhooks
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+)
+
+type ListDeliveriesInput struct {
+	Username       string
+	SubscriptionID string
+
+	// Status optionally filters the log, e.g. types.DeliveryDeadLetter
+	Status string
+}
+
+type ListDeliveriesOutput struct {
+	Deliveries []types.Delivery
+}
+
+func (s *service) ListDeliveries(ctx context.Context, in *ListDeliveriesInput) (*ListDeliveriesOutput, error) {
+	_, err := s.getOwnedSubscription(ctx, in.Username, in.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.storage.ListDeliveries(ctx, in.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Status != "" {
+		filtered := deliveries[:0]
+
+		for _, d := range deliveries {
+			if d.Status == in.Status {
+				filtered = append(filtered, d)
+			}
+		}
+
+		deliveries = filtered
+	}
+
+	return &ListDeliveriesOutput{
+		Deliveries: deliveries,
+	}, nil
+}
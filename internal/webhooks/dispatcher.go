@@ -0,0 +1,265 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/pkg/hasher"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Richerage-Event"
+	HeaderDelivery  = "X-Richerage-Delivery"
+	HeaderTimestamp = "X-Richerage-Timestamp"
+	HeaderSignature = "X-Richerage-Signature"
+)
+
+type DispatcherConfig struct {
+	Storage storage.WebhookStorage
+	Client  *http.Client
+	// Logger reports deliveries deferred because the queue is full
+	Logger *zap.Logger
+
+	// MaxAttempts before a delivery is moved to the dead letter status
+	MaxAttempts int
+	// Backoff is the delay after the first failed attempt, doubled on every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	Workers int
+	// QueueSize bounds the deliveries waiting for a worker, Enqueue defers the ones beyond it
+	// by the first backoff
+	QueueSize int
+}
+
+func NewDispatcher(cfg *DispatcherConfig) *Dispatcher {
+	d := &Dispatcher{
+		storage:     cfg.Storage,
+		client:      cfg.Client,
+		logger:      cfg.Logger,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     cfg.Backoff,
+		maxBackoff:  cfg.MaxBackoff,
+		workers:     cfg.Workers,
+	}
+
+	if d.logger == nil {
+		d.logger = zap.NewNop()
+	}
+	if d.client == nil {
+		d.client = &http.Client{Timeout: time.Second * 10}
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = 5
+	}
+	if d.backoff <= 0 {
+		d.backoff = time.Second
+	}
+	if d.maxBackoff <= 0 {
+		d.maxBackoff = time.Minute
+	}
+	if d.workers <= 0 {
+		d.workers = 4
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+
+	d.queue = make(chan string, queueSize)
+	d.wake = make(chan struct{}, 1)
+
+	return d
+}
+
+// Dispatcher delivers queued webhook deliveries in the background,
+// retrying failed attempts with exponential backoff
+type Dispatcher struct {
+	storage storage.WebhookStorage
+	client  *http.Client
+	logger  *zap.Logger
+
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	workers     int
+
+	queue chan string
+
+	// retries wait for their backoff apart from the queue, wake tells the scheduler about a new one
+	mu      sync.Mutex
+	retries retryQueue
+	wake    chan struct{}
+}
+
+// Enqueue hands a delivery to the workers without blocking the caller, when the queue is full
+// the delivery is scheduled like a retry and queued again once the first backoff has passed
+func (d *Dispatcher) Enqueue(deliveryID string) {
+	select {
+	case d.queue <- deliveryID:
+	default:
+		d.logger.Warn("webhook delivery deferred, the queue is full",
+			zap.String("delivery_id", deliveryID),
+			zap.Int("queue_size", cap(d.queue)),
+		)
+
+		d.retryAfter(deliveryID, d.backoff)
+	}
+}
+
+// Run starts the workers and the retry scheduler and blocks until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	done := make(chan struct{})
+
+	go func() {
+		defer func() { done <- struct{}{} }()
+
+		d.schedule(ctx)
+	}()
+
+	for i := 0; i < d.workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-d.queue:
+					// failures are kept in the delivery log
+					_ = d.Deliver(ctx, id)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i <= d.workers; i++ {
+		<-done
+	}
+}
+
+// Deliver makes the next attempt of a delivery, a failed attempt is scheduled again after its
+// backoff until the delivery runs out of attempts, Run queues it back once due
+func (d *Dispatcher) Deliver(ctx context.Context, deliveryID string) error {
+	delivery, err := d.storage.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return err
+	}
+
+	// queued twice, already done with
+	if delivery.Status == types.DeliverySucceeded || delivery.Status == types.DeliveryDeadLetter {
+		return nil
+	}
+
+	sub, err := d.storage.GetSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return err
+	}
+
+	signer := hasher.NewHMACSigner(&hasher.ConfigHMACSigner{
+		Secret: []byte(sub.Secret),
+	})
+
+	attempt := len(delivery.Attempts) + 1
+
+	start := time.Now()
+	statusCode, err := d.send(ctx, signer, sub, delivery, body)
+
+	record := types.DeliveryAttempt{
+		Attempt:    attempt,
+		StatusCode: statusCode,
+		Duration:   time.Since(start),
+		At:         start.UTC(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	delivery.Attempts = append(delivery.Attempts, record)
+	delivery.UpdatedAt = time.Now().UTC()
+
+	switch {
+	case err == nil:
+		delivery.Status = types.DeliverySucceeded
+	case attempt >= d.maxAttempts:
+		delivery.Status = types.DeliveryDeadLetter
+	default:
+		delivery.Status = types.DeliveryRetrying
+	}
+
+	if sErr := d.storage.SaveDelivery(ctx, delivery); sErr != nil {
+		return sErr
+	}
+
+	if delivery.Status == types.DeliveryRetrying {
+		d.retryAfter(delivery.ID, d.backoffFor(attempt))
+	}
+
+	return err
+}
+
+func (d *Dispatcher) send(ctx context.Context, signer hasher.Signer, sub *types.Subscription, delivery *types.Delivery, body []byte) (int, error) {
+	ts := strconv.FormatInt(time.Now().UTC().Unix(), 10)
+
+	// timestamp is part of the signed content so receivers can reject replays
+	sig, err := signer.Sign(ctx, append([]byte(ts+"."), body...))
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event.Type)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, "sha256="+string(sig))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain to allow connection reuse
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, &types.ErrDeliveryFailed{
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) backoffFor(attempt int) time.Duration {
+	delay := d.backoff
+
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+
+		if delay >= d.maxBackoff {
+			return d.maxBackoff
+		}
+	}
+
+	return delay
+}
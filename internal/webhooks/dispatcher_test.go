@@ -0,0 +1,367 @@
+package webhooks
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/hasher"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestDelivery(t *testing.T, st storage.WebhookStorage, url string) *types.Delivery {
+	ctx := context.Background()
+
+	sub := &types.Subscription{
+		ID:        "sub",
+		Username:  "test",
+		URL:       url,
+		Events:    []string{types.EventPing},
+		Secret:    "secret",
+		CreatedAt: time.Now(),
+	}
+	if err := st.CreateSubscription(ctx, sub); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	delivery := &types.Delivery{
+		ID:             "delivery",
+		SubscriptionID: sub.ID,
+		Username:       sub.Username,
+		Event: types.Event{
+			ID:   "event",
+			Type: types.EventPing,
+		},
+		Status:    types.DeliveryPending,
+		CreatedAt: time.Now(),
+	}
+	if err := st.SaveDelivery(ctx, delivery); err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	return delivery
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	ctx := context.Background()
+	signer := hasher.NewHMACSigner(&hasher.ConfigHMACSigner{
+		Secret: []byte("secret"),
+	})
+
+	var received int32
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+
+		body, _ := io.ReadAll(r.Body)
+
+		if r.Header.Get(HeaderEvent) != types.EventPing {
+			t.Errorf("expected event header to be %s, got %s", types.EventPing, r.Header.Get(HeaderEvent))
+		}
+		if r.Header.Get(HeaderDelivery) != "delivery" {
+			t.Errorf("expected delivery header to be delivery, got %s", r.Header.Get(HeaderDelivery))
+		}
+
+		// receivers verify the signature over timestamp + "." + body
+		sig := strings.TrimPrefix(r.Header.Get(HeaderSignature), "sha256=")
+		signed := append([]byte(r.Header.Get(HeaderTimestamp)+"."), body...)
+
+		if err := signer.Verify(r.Context(), signed, []byte(sig)); err != nil {
+			t.Errorf("expected signature to be valid, got %v", err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	st := storage.NewMemoryWebhooks()
+	newTestDelivery(t, st, receiver.URL)
+
+	d := NewDispatcher(&DispatcherConfig{
+		Storage: st,
+	})
+
+	err := d.Deliver(ctx, "delivery")
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	if atomic.LoadInt32(&received) != 1 {
+		t.Errorf("expected 1 request, got %d", received)
+	}
+
+	delivery, err := st.GetDelivery(ctx, "delivery")
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if delivery.Status != types.DeliverySucceeded {
+		t.Errorf("expected status to be %s, got %s", types.DeliverySucceeded, delivery.Status)
+	}
+	if len(delivery.Attempts) != 1 {
+		t.Errorf("expected 1 attempt, got %d", len(delivery.Attempts))
+	} else if delivery.Attempts[0].StatusCode != http.StatusNoContent {
+		t.Errorf("expected attempt status code to be 204, got %d", delivery.Attempts[0].StatusCode)
+	}
+}
+
+// waitDelivery polls the delivery until it has status, failing after a second
+func waitDelivery(t *testing.T, st storage.WebhookStorage, id string, status string) *types.Delivery {
+	for i := 0; i < 100; i++ {
+		delivery, _ := st.GetDelivery(context.Background(), id)
+		if delivery != nil && delivery.Status == status {
+			return delivery
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	t.Fatalf("expected delivery %s to be %s", id, status)
+
+	return nil
+}
+
+func TestDispatcher_Deliver_Retry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received int32
+
+	// fail twice then accept
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&received, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	st := storage.NewMemoryWebhooks()
+	newTestDelivery(t, st, receiver.URL)
+
+	d := NewDispatcher(&DispatcherConfig{
+		Storage:     st,
+		MaxAttempts: 5,
+		Backoff:     time.Millisecond,
+	})
+
+	// a single attempt, the next one is scheduled
+	if err := d.Deliver(ctx, "delivery"); err == nil {
+		t.Errorf("expected error to be set, got nil")
+	}
+	if delivery, _ := st.GetDelivery(ctx, "delivery"); delivery.Status != types.DeliveryRetrying || len(delivery.Attempts) != 1 {
+		t.Errorf("expected 1 attempt and the delivery retrying, got %+v", delivery)
+	}
+
+	go d.Run(ctx)
+
+	delivery := waitDelivery(t, st, "delivery", types.DeliverySucceeded)
+
+	if len(delivery.Attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(delivery.Attempts))
+	}
+
+	if delivery.Attempts[0].StatusCode != http.StatusServiceUnavailable || delivery.Attempts[0].Error == "" {
+		t.Errorf("expected first attempt to be logged as failed, got %+v", delivery.Attempts[0])
+	}
+	if delivery.Attempts[2].StatusCode != http.StatusOK || delivery.Attempts[2].Error != "" {
+		t.Errorf("expected last attempt to be logged as succeeded, got %+v", delivery.Attempts[2])
+	}
+}
+
+func TestDispatcher_Deliver_DeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received int32
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	st := storage.NewMemoryWebhooks()
+	newTestDelivery(t, st, receiver.URL)
+
+	d := NewDispatcher(&DispatcherConfig{
+		Storage:     st,
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	})
+
+	go d.Run(ctx)
+	d.Enqueue("delivery")
+
+	delivery := waitDelivery(t, st, "delivery", types.DeliveryDeadLetter)
+
+	if len(delivery.Attempts) != 3 {
+		t.Errorf("expected 3 attempts, got %d", len(delivery.Attempts))
+	}
+
+	// no more attempts once dead
+	d.Enqueue("delivery")
+	time.Sleep(time.Millisecond * 20)
+
+	if atomic.LoadInt32(&received) != 3 {
+		t.Errorf("expected 3 requests, got %d", received)
+	}
+}
+
+func TestDispatcher_Run_Backoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderDelivery) == "delivery" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	st := storage.NewMemoryWebhooks()
+	newTestDelivery(t, st, receiver.URL)
+	_ = st.SaveDelivery(ctx, &types.Delivery{
+		ID:             "other",
+		SubscriptionID: "sub",
+		Username:       "test",
+		Event:          types.Event{ID: "event", Type: types.EventPing},
+		Status:         types.DeliveryPending,
+	})
+
+	d := NewDispatcher(&DispatcherConfig{
+		Storage:     st,
+		MaxAttempts: 3,
+		Backoff:     time.Hour,
+		Workers:     1,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+
+	d.Enqueue("delivery")
+	waitDelivery(t, st, "delivery", types.DeliveryRetrying)
+
+	// the only worker is not held by the backoff
+	d.Enqueue("other")
+	waitDelivery(t, st, "other", types.DeliverySucceeded)
+
+	// nor is Run once cancelled
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected Run to return once cancelled")
+	}
+
+	delivery, _ := st.GetDelivery(context.Background(), "delivery")
+	if delivery.Status != types.DeliveryRetrying || len(delivery.Attempts) != 1 {
+		t.Errorf("expected 1 attempt and the delivery left retrying, got %+v", delivery)
+	}
+}
+
+func TestDispatcher_Enqueue_Full(t *testing.T) {
+	d := NewDispatcher(&DispatcherConfig{
+		Storage:   storage.NewMemoryWebhooks(),
+		QueueSize: 1,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		// nothing runs the queue, the second one waits for the first backoff
+		d.Enqueue("first")
+		d.Enqueue("second")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected Enqueue not to block on a full queue")
+	}
+
+	if len(d.queue) != 1 || <-d.queue != "first" {
+		t.Errorf("expected only the first delivery queued")
+	}
+
+	if ids := d.dueRetries(time.Now().Add(d.backoff)); len(ids) != 1 || ids[0] != "second" {
+		t.Errorf("expected the second delivery to be scheduled, got %v", ids)
+	}
+}
+
+func TestDispatcher_Enqueue_Full_Delivered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan string, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(HeaderDelivery)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	st := storage.NewMemoryWebhooks()
+	d := NewDispatcher(&DispatcherConfig{
+		Storage:   st,
+		QueueSize: 1,
+		Backoff:   time.Millisecond * 10,
+	})
+
+	first := newTestDelivery(t, st, receiver.URL)
+
+	// enqueued before the workers run, the queue only holds one
+	for _, id := range []string{first.ID, "second", "third"} {
+		delivery := *first
+		delivery.ID = id
+		_ = st.SaveDelivery(ctx, &delivery)
+
+		d.Enqueue(id)
+	}
+
+	go d.Run(ctx)
+
+	seen := map[string]bool{}
+	for len(seen) < 3 {
+		select {
+		case id := <-received:
+			seen[id] = true
+		case <-time.After(time.Second * 5):
+			t.Fatalf("expected every delivery to be sent, got %v", seen)
+		}
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := NewDispatcher(&DispatcherConfig{
+		Backoff:    time.Second,
+		MaxBackoff: time.Second * 10,
+	})
+
+	expected := []time.Duration{
+		time.Second,
+		time.Second * 2,
+		time.Second * 4,
+		time.Second * 8,
+		time.Second * 10,
+		time.Second * 10,
+	}
+
+	for i, e := range expected {
+		if got := d.backoffFor(i + 1); got != e {
+			t.Errorf("expected backoff for attempt %d to be %v, got %v", i+1, e, got)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package endpoint
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/auth"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+)
+
+// userRequest is implemented by every request of this package that acts on behalf of a user
+type userRequest interface {
+	setUsername(username string)
+}
+
+func MakeAuthEndpoint(svc auth.Service, e kitendpoint.Endpoint) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		token, _ := ctx.Value("auth_token").(string)
+
+		// assume that the username is valid and exists in the database
+		out, err := svc.VerifyToken(ctx, &auth.VerifyTokenInput{
+			Token: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if req, ok := request.(userRequest); ok && req != nil {
+			req.setUsername(out.Username)
+		}
+
+		return e(ctx, request)
+	}
+}
@@ -0,0 +1,79 @@
+package endpoint
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/webhooks"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+)
+
+type ListDeliveriesRequest struct {
+	Username string
+
+	SubscriptionID string
+	Status         string
+}
+
+func (r *ListDeliveriesRequest) setUsername(username string) {
+	r.Username = username
+}
+
+type ListDeliveriesResponse struct {
+	Deliveries []types.Delivery
+}
+
+func MakeListDeliveriesEndpoint(svc webhooks.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyListDeliveriesRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := svc.ListDeliveries(ctx, &webhooks.ListDeliveriesInput{
+			Username:       req.Username,
+			SubscriptionID: req.SubscriptionID,
+			Status:         req.Status,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &ListDeliveriesResponse{
+			Deliveries: out.Deliveries,
+		}, nil
+	}
+}
+
+func verifyListDeliveriesRequest(request interface{}) (*ListDeliveriesRequest, error) {
+	req, ok := request.(*ListDeliveriesRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if req.Username == "" {
+		badParams["username"] = "required"
+	}
+	if req.SubscriptionID == "" {
+		badParams["id"] = "required"
+	}
+
+	switch req.Status {
+	case "", types.DeliveryPending, types.DeliveryRetrying, types.DeliverySucceeded, types.DeliveryDeadLetter:
+	default:
+		badParams["status"] = "unknown status " + req.Status
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,234 @@
+package endpoint
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/webhooks"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	"net/url"
+)
+
+type CreateSubscriptionRequest struct {
+	Username string `json:"-"`
+
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+func (r *CreateSubscriptionRequest) setUsername(username string) {
+	r.Username = username
+}
+
+type CreateSubscriptionResponse struct {
+	Subscription types.Subscription
+}
+
+func MakeCreateSubscriptionEndpoint(svc webhooks.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := verifyCreateSubscriptionRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := svc.CreateSubscription(ctx, &webhooks.CreateSubscriptionInput{
+			Username: req.Username,
+			URL:      req.URL,
+			Events:   req.Events,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &CreateSubscriptionResponse{
+			Subscription: out.Subscription,
+		}, nil
+	}
+}
+
+type ListSubscriptionsRequest struct {
+	Username string
+}
+
+func (r *ListSubscriptionsRequest) setUsername(username string) {
+	r.Username = username
+}
+
+type ListSubscriptionsResponse struct {
+	Subscriptions []types.Subscription
+}
+
+func MakeListSubscriptionsEndpoint(svc webhooks.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*ListSubscriptionsRequest)
+		if !ok || req == nil {
+			return nil, &kit.BadRequestError{
+				Message: "invalid request",
+			}
+		}
+
+		if req.Username == "" {
+			return nil, &kit.BadRequestError{
+				Message: "one or more parameters are invalid or missing",
+				Params:  map[string]string{"username": "required"},
+			}
+		}
+
+		out, err := svc.ListSubscriptions(ctx, &webhooks.ListSubscriptionsInput{
+			Username: req.Username,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &ListSubscriptionsResponse{
+			Subscriptions: out.Subscriptions,
+		}, nil
+	}
+}
+
+type DeleteSubscriptionRequest struct {
+	Username string
+	ID       string
+}
+
+func (r *DeleteSubscriptionRequest) setUsername(username string) {
+	r.Username = username
+}
+
+type DeleteSubscriptionResponse struct{}
+
+func MakeDeleteSubscriptionEndpoint(svc webhooks.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*DeleteSubscriptionRequest)
+		if !ok || req == nil {
+			return nil, &kit.BadRequestError{
+				Message: "invalid request",
+			}
+		}
+
+		if err := verifySubscriptionParams(req.Username, req.ID); err != nil {
+			return nil, err
+		}
+
+		_, err := svc.DeleteSubscription(ctx, &webhooks.DeleteSubscriptionInput{
+			Username: req.Username,
+			ID:       req.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &DeleteSubscriptionResponse{}, nil
+	}
+}
+
+type PingRequest struct {
+	Username string
+	ID       string
+}
+
+func (r *PingRequest) setUsername(username string) {
+	r.Username = username
+}
+
+type PingResponse struct {
+	DeliveryID string
+}
+
+func MakePingEndpoint(svc webhooks.Service) kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*PingRequest)
+		if !ok || req == nil {
+			return nil, &kit.BadRequestError{
+				Message: "invalid request",
+			}
+		}
+
+		if err := verifySubscriptionParams(req.Username, req.ID); err != nil {
+			return nil, err
+		}
+
+		out, err := svc.Ping(ctx, &webhooks.PingInput{
+			Username:       req.Username,
+			SubscriptionID: req.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &PingResponse{
+			DeliveryID: out.DeliveryID,
+		}, nil
+	}
+}
+
+func verifyCreateSubscriptionRequest(request interface{}) (*CreateSubscriptionRequest, error) {
+	req, ok := request.(*CreateSubscriptionRequest)
+	if !ok || req == nil {
+		return nil, &kit.BadRequestError{
+			Message: "invalid request",
+		}
+	}
+
+	badParams := map[string]string{}
+
+	if req.Username == "" {
+		badParams["username"] = "required"
+	}
+
+	if req.URL == "" {
+		badParams["url"] = "required"
+	} else if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		badParams["url"] = "must be an absolute http or https url"
+	}
+
+	if len(req.Events) == 0 {
+		badParams["events"] = "required"
+	}
+	for _, e := range req.Events {
+		if !isValidEvent(e) {
+			badParams["events"] = "unknown event " + e
+			break
+		}
+	}
+
+	if len(badParams) > 0 {
+		return nil, &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return req, nil
+}
+
+func verifySubscriptionParams(username string, id string) error {
+	badParams := map[string]string{}
+
+	if username == "" {
+		badParams["username"] = "required"
+	}
+	if id == "" {
+		badParams["id"] = "required"
+	}
+
+	if len(badParams) > 0 {
+		return &kit.BadRequestError{
+			Message: "one or more parameters are invalid or missing",
+			Params:  badParams,
+		}
+	}
+
+	return nil
+}
+
+func isValidEvent(event string) bool {
+	for _, v := range types.ValidEvents() {
+		if v == event {
+			return true
+		}
+	}
+
+	return false
+}
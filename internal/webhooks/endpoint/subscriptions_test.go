@@ -0,0 +1,162 @@
+//go:build test
+
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/auth"
+	authtypes "github.com/falmar/richerage-api/internal/auth/types"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/webhooks"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"testing"
+)
+
+func getDefaultCreateSubscriptionRequest() *CreateSubscriptionRequest {
+	return &CreateSubscriptionRequest{
+		Username: "test",
+		URL:      "https://example.com/hook",
+		Events:   []string{types.EventPortfolioUpdated},
+	}
+}
+
+func TestEndpointCreateSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	svc := webhooks.NewMockService()
+	svc.(*webhooks.MockService).CreateSubscriptionFunc = func(ctx context.Context, in *webhooks.CreateSubscriptionInput) (*webhooks.CreateSubscriptionOutput, error) {
+		if in.Username != "test" {
+			t.Errorf("expected username to be test, got %s", in.Username)
+		}
+
+		return &webhooks.CreateSubscriptionOutput{
+			Subscription: types.Subscription{
+				ID:     "id",
+				URL:    in.URL,
+				Events: in.Events,
+			},
+		}, nil
+	}
+
+	resp, err := MakeCreateSubscriptionEndpoint(svc)(ctx, getDefaultCreateSubscriptionRequest())
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+	}
+
+	if r, ok := resp.(*CreateSubscriptionResponse); !ok || r == nil {
+		t.Errorf("expected response to be of type CreateSubscriptionResponse, got %T", resp)
+	} else if r.Subscription.ID != "id" {
+		t.Errorf("expected subscription id to be id, got %s", r.Subscription.ID)
+	}
+}
+
+func TestEndpointCreateSubscription_VerifyRequest(t *testing.T) {
+	var eBadRequest *kit.BadRequestError
+
+	_, err := verifyCreateSubscriptionRequest(nil)
+	if !errors.As(err, &eBadRequest) {
+		t.Errorf("expected error to be of type BadRequestError, got %T", err)
+	}
+
+	_, err = verifyCreateSubscriptionRequest(getDefaultCreateSubscriptionRequest())
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+	}
+
+	matrix := map[string]func(r *CreateSubscriptionRequest){
+		"username": func(r *CreateSubscriptionRequest) { r.Username = "" },
+		"url":      func(r *CreateSubscriptionRequest) { r.URL = "ftp://example.com" },
+		"events":   func(r *CreateSubscriptionRequest) { r.Events = []string{"unknown"} },
+	}
+
+	for param, mutate := range matrix {
+		req := getDefaultCreateSubscriptionRequest()
+		mutate(req)
+
+		eBadRequest = nil
+		_, err = verifyCreateSubscriptionRequest(req)
+		if !errors.As(err, &eBadRequest) {
+			t.Errorf("expected error to be of type BadRequestError, got %T", err)
+			continue
+		}
+
+		if v, ok := eBadRequest.Params[param]; !ok || v == "" {
+			t.Errorf("expected error param %s to be set, got %v", param, eBadRequest.Params)
+		}
+	}
+}
+
+func TestEndpointListDeliveries_VerifyRequest(t *testing.T) {
+	var eBadRequest *kit.BadRequestError
+
+	_, err := verifyListDeliveriesRequest(&ListDeliveriesRequest{
+		Username:       "test",
+		SubscriptionID: "id",
+		Status:         types.DeliveryDeadLetter,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %T", err)
+	}
+
+	_, err = verifyListDeliveriesRequest(&ListDeliveriesRequest{
+		Username:       "test",
+		SubscriptionID: "id",
+		Status:         "lost",
+	})
+	if !errors.As(err, &eBadRequest) {
+		t.Errorf("expected error to be of type BadRequestError, got %T", err)
+	} else if _, ok := eBadRequest.Params["status"]; !ok {
+		t.Errorf("expected error param status to be set, got %v", eBadRequest.Params)
+	}
+}
+
+func TestEndpointWebhooks_Auth(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "auth_token", "test")
+
+	svc := auth.NewMockService()
+	svc.(*auth.MockService).VerifyTokenFunc = func(ctx context.Context, in *auth.VerifyTokenInput) (*auth.VerifyTokenOutput, error) {
+		if in.Token != "test" {
+			return nil, &authtypes.ErrUnauthorized{}
+		}
+
+		return &auth.VerifyTokenOutput{
+			Username: "john.doe",
+		}, nil
+	}
+
+	// AuthEndpoint should set the username on any request of this package
+	requests := []interface{}{
+		&CreateSubscriptionRequest{},
+		&ListSubscriptionsRequest{},
+		&DeleteSubscriptionRequest{},
+		&PingRequest{},
+		&ListDeliveriesRequest{},
+	}
+
+	for _, req := range requests {
+		endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+			return nil, nil
+		}
+
+		_, err := MakeAuthEndpoint(svc, endpoint)(ctx, req)
+		if err != nil {
+			t.Errorf("expected error to be nil, got %T", err)
+		}
+	}
+
+	if r := requests[0].(*CreateSubscriptionRequest); r.Username != "john.doe" {
+		t.Errorf("expected username to be john.doe, got %s", r.Username)
+	}
+	if r := requests[4].(*ListDeliveriesRequest); r.Username != "john.doe" {
+		t.Errorf("expected username to be john.doe, got %s", r.Username)
+	}
+
+	// AuthEndpoint should return the error raised from auth service
+	_, err := MakeAuthEndpoint(svc, nil)(context.Background(), &PingRequest{})
+
+	var errUnauthorized *authtypes.ErrUnauthorized
+	if !errors.As(err, &errUnauthorized) {
+		t.Errorf("expected error to be of type ErrUnauthorized, got %T", err)
+	}
+}
@@ -0,0 +1,130 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"time"
+)
+
+type PublishInput struct {
+	Username string
+	Type     string
+	Data     interface{}
+}
+
+type PublishOutput struct {
+	DeliveryIDs []string
+}
+
+func (s *service) Publish(ctx context.Context, in *PublishInput) (*PublishOutput, error) {
+	subs, err := s.storage.ListSubscriptions(ctx, in.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := newEvent(in.Type, in.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &PublishOutput{
+		DeliveryIDs: []string{},
+	}
+
+	for _, sub := range subs {
+		if !sub.Wants(in.Type) {
+			continue
+		}
+
+		id, err := s.enqueue(ctx, &sub, event)
+		if err != nil {
+			return nil, err
+		}
+
+		out.DeliveryIDs = append(out.DeliveryIDs, id)
+	}
+
+	return out, nil
+}
+
+type PingInput struct {
+	Username       string
+	SubscriptionID string
+}
+
+type PingOutput struct {
+	DeliveryID string
+}
+
+// Ping sends a ping event to a single subscription regardless of its events,
+// it lets users check their receiver and signature verification
+func (s *service) Ping(ctx context.Context, in *PingInput) (*PingOutput, error) {
+	sub, err := s.getOwnedSubscription(ctx, in.Username, in.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := newEvent(types.EventPing, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.enqueue(ctx, sub, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PingOutput{
+		DeliveryID: id,
+	}, nil
+}
+
+func (s *service) enqueue(ctx context.Context, sub *types.Subscription, event *types.Event) (string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	delivery := &types.Delivery{
+		ID:             id,
+		SubscriptionID: sub.ID,
+		Username:       sub.Username,
+		Event:          *event,
+		Status:         types.DeliveryPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	err = s.storage.SaveDelivery(ctx, delivery)
+	if err != nil {
+		return "", err
+	}
+
+	s.dispatcher.Enqueue(delivery.ID)
+
+	return delivery.ID, nil
+}
+
+func newEvent(eventType string, data interface{}) (*types.Event, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	event := &types.Event{
+		ID:        id,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if data != nil {
+		event.Data, err = json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+type retry struct {
+	deliveryID string
+	at         time.Time
+}
+
+// retryQueue is a min heap of retries by due time
+type retryQueue []retry
+
+func (q retryQueue) Len() int           { return len(q) }
+func (q retryQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+func (q retryQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *retryQueue) Push(x interface{}) {
+	*q = append(*q, x.(retry))
+}
+
+func (q *retryQueue) Pop() interface{} {
+	old := *q
+	v := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return v
+}
+
+// retryAfter schedules the next attempt of a delivery once delay has passed
+func (d *Dispatcher) retryAfter(deliveryID string, delay time.Duration) {
+	d.mu.Lock()
+	heap.Push(&d.retries, retry{deliveryID: deliveryID, at: time.Now().Add(delay)})
+	d.mu.Unlock()
+
+	// the scheduler may be waiting for a later retry
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// nextRetry is the due time of the soonest retry, false when there is none
+func (d *Dispatcher) nextRetry() (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.retries) == 0 {
+		return time.Time{}, false
+	}
+
+	return d.retries[0].at, true
+}
+
+// dueRetries removes the retries due by now
+func (d *Dispatcher) dueRetries(now time.Time) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var ids []string
+	for len(d.retries) > 0 && !d.retries[0].at.After(now) {
+		ids = append(ids, heap.Pop(&d.retries).(retry).deliveryID)
+	}
+
+	return ids
+}
+
+// schedule sends retries back to the workers once due, a single timer waits for the soonest
+// so no worker is held during a backoff, retries still waiting when ctx is cancelled are
+// left as retrying in the delivery log
+func (d *Dispatcher) schedule(ctx context.Context) {
+	for {
+		var timer *time.Timer
+		var due <-chan time.Time
+
+		if at, ok := d.nextRetry(); ok {
+			timer = time.NewTimer(time.Until(at))
+			due = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-d.wake:
+			// a retry was added, it may be due sooner
+		case <-due:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		for _, id := range d.dueRetries(time.Now()) {
+			select {
+			case d.queue <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/falmar/richerage-api/internal/storage"
+)
+
+var ErrInvalidConfig = errors.New("invalid webhooks service config")
+
+var _ Service = (*service)(nil)
+
+type Service interface {
+	CreateSubscription(ctx context.Context, in *CreateSubscriptionInput) (*CreateSubscriptionOutput, error)
+	ListSubscriptions(ctx context.Context, in *ListSubscriptionsInput) (*ListSubscriptionsOutput, error)
+	DeleteSubscription(ctx context.Context, in *DeleteSubscriptionInput) (*DeleteSubscriptionOutput, error)
+
+	ListDeliveries(ctx context.Context, in *ListDeliveriesInput) (*ListDeliveriesOutput, error)
+
+	// Publish fans out an event to every subscription of the user listening for it
+	Publish(ctx context.Context, in *PublishInput) (*PublishOutput, error)
+	Ping(ctx context.Context, in *PingInput) (*PingOutput, error)
+}
+
+type Config struct {
+	Storage    storage.WebhookStorage
+	Dispatcher *Dispatcher
+}
+
+func New(cfg *Config) (Service, error) {
+	if cfg == nil || cfg.Storage == nil || cfg.Dispatcher == nil {
+		return nil, ErrInvalidConfig
+	}
+
+	return &service{
+		storage:    cfg.Storage,
+		dispatcher: cfg.Dispatcher,
+	}, nil
+}
+
+type service struct {
+	storage    storage.WebhookStorage
+	dispatcher *Dispatcher
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,67 @@
+//go:build test
+
+package webhooks
+
+import (
+	"context"
+	"errors"
+)
+
+var _ Service = (*MockService)(nil)
+var ErrMockUncalledFor = errors.New("uncalled for")
+
+func NewMockService() Service {
+	return &MockService{
+		CreateSubscriptionFunc: func(ctx context.Context, in *CreateSubscriptionInput) (*CreateSubscriptionOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		ListSubscriptionsFunc: func(ctx context.Context, in *ListSubscriptionsInput) (*ListSubscriptionsOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		DeleteSubscriptionFunc: func(ctx context.Context, in *DeleteSubscriptionInput) (*DeleteSubscriptionOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		ListDeliveriesFunc: func(ctx context.Context, in *ListDeliveriesInput) (*ListDeliveriesOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		PublishFunc: func(ctx context.Context, in *PublishInput) (*PublishOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+		PingFunc: func(ctx context.Context, in *PingInput) (*PingOutput, error) {
+			return nil, ErrMockUncalledFor
+		},
+	}
+}
+
+type MockService struct {
+	CreateSubscriptionFunc func(ctx context.Context, in *CreateSubscriptionInput) (*CreateSubscriptionOutput, error)
+	ListSubscriptionsFunc  func(ctx context.Context, in *ListSubscriptionsInput) (*ListSubscriptionsOutput, error)
+	DeleteSubscriptionFunc func(ctx context.Context, in *DeleteSubscriptionInput) (*DeleteSubscriptionOutput, error)
+	ListDeliveriesFunc     func(ctx context.Context, in *ListDeliveriesInput) (*ListDeliveriesOutput, error)
+	PublishFunc            func(ctx context.Context, in *PublishInput) (*PublishOutput, error)
+	PingFunc               func(ctx context.Context, in *PingInput) (*PingOutput, error)
+}
+
+func (m *MockService) CreateSubscription(ctx context.Context, in *CreateSubscriptionInput) (*CreateSubscriptionOutput, error) {
+	return m.CreateSubscriptionFunc(ctx, in)
+}
+
+func (m *MockService) ListSubscriptions(ctx context.Context, in *ListSubscriptionsInput) (*ListSubscriptionsOutput, error) {
+	return m.ListSubscriptionsFunc(ctx, in)
+}
+
+func (m *MockService) DeleteSubscription(ctx context.Context, in *DeleteSubscriptionInput) (*DeleteSubscriptionOutput, error) {
+	return m.DeleteSubscriptionFunc(ctx, in)
+}
+
+func (m *MockService) ListDeliveries(ctx context.Context, in *ListDeliveriesInput) (*ListDeliveriesOutput, error) {
+	return m.ListDeliveriesFunc(ctx, in)
+}
+
+func (m *MockService) Publish(ctx context.Context, in *PublishInput) (*PublishOutput, error) {
+	return m.PublishFunc(ctx, in)
+}
+
+func (m *MockService) Ping(ctx context.Context, in *PingInput) (*PingOutput, error) {
+	return m.PingFunc(ctx, in)
+}
@@ -0,0 +1,172 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"github.com/falmar/richerage-api/internal/storage"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhooks_New(t *testing.T) {
+	_, err := New(nil)
+	if err != ErrInvalidConfig {
+		t.Errorf("expected %v, got %v", ErrInvalidConfig, err)
+	}
+
+	_, err = New(&Config{})
+	if err != ErrInvalidConfig {
+		t.Errorf("expected %v, got %v", ErrInvalidConfig, err)
+	}
+
+	st := storage.NewMemoryWebhooks()
+	svc, err := New(&Config{
+		Storage:    st,
+		Dispatcher: NewDispatcher(&DispatcherConfig{Storage: st}),
+	})
+	if svc == nil {
+		t.Error("service is nil")
+	}
+}
+
+func TestWebhooks_Subscriptions(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMemoryWebhooks()
+
+	svc, _ := New(&Config{
+		Storage:    st,
+		Dispatcher: NewDispatcher(&DispatcherConfig{Storage: st}),
+	})
+
+	out, err := svc.CreateSubscription(ctx, &CreateSubscriptionInput{
+		Username: "test",
+		URL:      "http://localhost/hook",
+		Events:   []string{types.EventPortfolioUpdated},
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if out.Subscription.ID == "" || out.Subscription.Secret == "" {
+		t.Errorf("expected id and secret to be set, got %+v", out.Subscription)
+	}
+
+	list, err := svc.ListSubscriptions(ctx, &ListSubscriptionsInput{Username: "test"})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	} else if len(list.Subscriptions) != 1 {
+		t.Fatalf("expected 1 subscription, got %d", len(list.Subscriptions))
+	}
+
+	list, _ = svc.ListSubscriptions(ctx, &ListSubscriptionsInput{Username: "other"})
+	if len(list.Subscriptions) != 0 {
+		t.Errorf("expected 0 subscriptions for other user, got %d", len(list.Subscriptions))
+	}
+
+	// other users cannot touch the subscription
+	var errNotFound *types.ErrSubscriptionNotFound
+
+	_, err = svc.DeleteSubscription(ctx, &DeleteSubscriptionInput{
+		Username: "other",
+		ID:       out.Subscription.ID,
+	})
+	if !errors.As(err, &errNotFound) {
+		t.Errorf("expected error to be %T, got %T", errNotFound, err)
+	}
+
+	_, err = svc.DeleteSubscription(ctx, &DeleteSubscriptionInput{
+		Username: "test",
+		ID:       out.Subscription.ID,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil, got %v", err)
+	}
+
+	list, _ = svc.ListSubscriptions(ctx, &ListSubscriptionsInput{Username: "test"})
+	if len(list.Subscriptions) != 0 {
+		t.Errorf("expected 0 subscriptions after delete, got %d", len(list.Subscriptions))
+	}
+}
+
+func TestWebhooks_Publish(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan string, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(HeaderEvent)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	st := storage.NewMemoryWebhooks()
+	dispatcher := NewDispatcher(&DispatcherConfig{Storage: st})
+	go dispatcher.Run(ctx)
+
+	svc, _ := New(&Config{
+		Storage:    st,
+		Dispatcher: dispatcher,
+	})
+
+	portfolio, _ := svc.CreateSubscription(ctx, &CreateSubscriptionInput{
+		Username: "test",
+		URL:      receiver.URL,
+		Events:   []string{types.EventPortfolioUpdated},
+	})
+	_, _ = svc.CreateSubscription(ctx, &CreateSubscriptionInput{
+		Username: "test",
+		URL:      receiver.URL,
+		Events:   []string{types.EventPing},
+	})
+
+	// only the portfolio subscription wants this event
+	out, err := svc.Publish(ctx, &PublishInput{
+		Username: "test",
+		Type:     types.EventPortfolioUpdated,
+		Data:     map[string]string{"symbol": "AAPL"},
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	} else if len(out.DeliveryIDs) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(out.DeliveryIDs))
+	}
+
+	select {
+	case event := <-received:
+		if event != types.EventPortfolioUpdated {
+			t.Errorf("expected event to be %s, got %s", types.EventPortfolioUpdated, event)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("expected delivery to be received")
+	}
+
+	// wait for the worker to record the outcome
+	var deliveries []types.Delivery
+	for i := 0; i < 100; i++ {
+		log, err := svc.ListDeliveries(ctx, &ListDeliveriesInput{
+			Username:       "test",
+			SubscriptionID: portfolio.Subscription.ID,
+			Status:         types.DeliverySucceeded,
+		})
+		if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+
+		deliveries = log.Deliveries
+		if len(deliveries) > 0 {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if len(deliveries) != 1 {
+		t.Fatalf("expected 1 succeeded delivery, got %d", len(deliveries))
+	}
+	if string(deliveries[0].Event.Data) != `{"symbol":"AAPL"}` {
+		t.Errorf("expected event data to be kept, got %s", deliveries[0].Event.Data)
+	}
+}
@@ -0,0 +1,101 @@
+package webhooks
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"time"
+)
+
+type CreateSubscriptionInput struct {
+	Username string
+	URL      string
+	Events   []string
+}
+
+type CreateSubscriptionOutput struct {
+	Subscription types.Subscription
+}
+
+func (s *service) CreateSubscription(ctx context.Context, in *CreateSubscriptionInput) (*CreateSubscriptionOutput, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := types.Subscription{
+		ID:        id,
+		Username:  in.Username,
+		URL:       in.URL,
+		Events:    in.Events,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err = s.storage.CreateSubscription(ctx, &sub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateSubscriptionOutput{
+		Subscription: sub,
+	}, nil
+}
+
+type ListSubscriptionsInput struct {
+	Username string
+}
+
+type ListSubscriptionsOutput struct {
+	Subscriptions []types.Subscription
+}
+
+func (s *service) ListSubscriptions(ctx context.Context, in *ListSubscriptionsInput) (*ListSubscriptionsOutput, error) {
+	subs, err := s.storage.ListSubscriptions(ctx, in.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListSubscriptionsOutput{
+		Subscriptions: subs,
+	}, nil
+}
+
+type DeleteSubscriptionInput struct {
+	Username string
+	ID       string
+}
+
+type DeleteSubscriptionOutput struct{}
+
+func (s *service) DeleteSubscription(ctx context.Context, in *DeleteSubscriptionInput) (*DeleteSubscriptionOutput, error) {
+	_, err := s.getOwnedSubscription(ctx, in.Username, in.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.storage.DeleteSubscription(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeleteSubscriptionOutput{}, nil
+}
+
+// getOwnedSubscription hides subscriptions of other users behind a not found error
+func (s *service) getOwnedSubscription(ctx context.Context, username string, id string) (*types.Subscription, error) {
+	sub, err := s.storage.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sub.Username != username {
+		return nil, &types.ErrSubscriptionNotFound{ID: id}
+	}
+
+	return sub, nil
+}
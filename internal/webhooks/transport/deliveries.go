@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"context"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/webhooks/endpoint"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"time"
+)
+
+func ListDeliveriesRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	return &endpoint.ListDeliveriesRequest{
+		SubscriptionID: chi.URLParam(r, "id"),
+		Status:         r.URL.Query().Get("status"),
+	}, nil
+}
+
+func ListDeliveriesResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.ListDeliveriesResponse)
+
+	deliveries := make([]interface{}, 0, len(res.Deliveries))
+
+	for _, d := range res.Deliveries {
+		attempts := make([]interface{}, 0, len(d.Attempts))
+
+		for _, a := range d.Attempts {
+			attempt := map[string]interface{}{
+				"attempt":     a.Attempt,
+				"status_code": a.StatusCode,
+				"duration_ms": a.Duration.Milliseconds(),
+				"at":          a.At.Format(time.RFC3339),
+			}
+			if a.Error != "" {
+				attempt["error"] = a.Error
+			}
+
+			attempts = append(attempts, attempt)
+		}
+
+		deliveries = append(deliveries, map[string]interface{}{
+			"id":         d.ID,
+			"event":      d.Event,
+			"status":     d.Status,
+			"attempts":   attempts,
+			"created_at": d.CreatedAt.Format(time.RFC3339),
+			"updated_at": d.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return kit.EncodeResponse(ctx, w, http.StatusOK, deliveries)
+}
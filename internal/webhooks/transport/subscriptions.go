@@ -0,0 +1,87 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/falmar/richerage-api/internal/pkg/kit"
+	"github.com/falmar/richerage-api/internal/webhooks/endpoint"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"net/http"
+	"time"
+)
+
+func CreateSubscriptionRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	req := &endpoint.CreateSubscriptionRequest{}
+
+	// let CreateSubscriptionEndpoint handle the validation of empty body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func CreateSubscriptionResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.CreateSubscriptionResponse)
+
+	// the secret is only disclosed once, on creation
+	sub := formatSubscription(res.Subscription)
+	sub["secret"] = res.Subscription.Secret
+
+	return kit.EncodeResponse(ctx, w, http.StatusCreated, sub)
+}
+
+func ListSubscriptionsRequestDecoder(context.Context, *http.Request) (interface{}, error) {
+	return &endpoint.ListSubscriptionsRequest{}, nil
+}
+
+func ListSubscriptionsResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.ListSubscriptionsResponse)
+
+	subs := make([]interface{}, 0, len(res.Subscriptions))
+
+	for _, sub := range res.Subscriptions {
+		subs = append(subs, formatSubscription(sub))
+	}
+
+	return kit.EncodeResponse(ctx, w, http.StatusOK, subs)
+}
+
+func DeleteSubscriptionRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	return &endpoint.DeleteSubscriptionRequest{
+		ID: chi.URLParam(r, "id"),
+	}, nil
+}
+
+func DeleteSubscriptionResponseEncoder(_ context.Context, w http.ResponseWriter, _ interface{}) error {
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func PingRequestDecoder(_ context.Context, r *http.Request) (interface{}, error) {
+	return &endpoint.PingRequest{
+		ID: chi.URLParam(r, "id"),
+	}, nil
+}
+
+func PingResponseEncoder(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(*endpoint.PingResponse)
+
+	return kit.EncodeResponse(ctx, w, http.StatusAccepted, map[string]interface{}{
+		"delivery_id": res.DeliveryID,
+	})
+}
+
+func formatSubscription(sub types.Subscription) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         sub.ID,
+		"url":        sub.URL,
+		"events":     sub.Events,
+		"created_at": sub.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/falmar/richerage-api/internal/webhooks/endpoint"
+	"github.com/falmar/richerage-api/internal/webhooks/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateSubscription_RequestDecoder(t *testing.T) {
+	body := `{"url": "https://example.com/hook", "events": ["ping"]}`
+	r, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(body))
+
+	out, err := CreateSubscriptionRequestDecoder(context.Background(), r)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	req, ok := out.(*endpoint.CreateSubscriptionRequest)
+	if !ok || req == nil {
+		t.Errorf("expected request to be of type CreateSubscriptionRequest, got %T", out)
+		return
+	}
+
+	if req.URL != "https://example.com/hook" {
+		t.Errorf("expected url to be https://example.com/hook, got %s", req.URL)
+	}
+	if len(req.Events) != 1 || req.Events[0] != "ping" {
+		t.Errorf("expected events to be [ping], got %v", req.Events)
+	}
+}
+
+func TestCreateSubscription_ResponseEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	resp := &endpoint.CreateSubscriptionResponse{
+		Subscription: types.Subscription{
+			ID:        "id",
+			URL:       "https://example.com/hook",
+			Events:    []string{"ping"},
+			Secret:    "secret",
+			CreatedAt: time.Date(2023, 07, 21, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	err := CreateSubscriptionResponseEncoder(context.Background(), w, resp)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status code to be 201, got %d", w.Code)
+	}
+
+	var got map[string]interface{}
+	_ = json.NewDecoder(w.Body).Decode(&got)
+
+	if got["secret"] != "secret" {
+		t.Errorf("expected secret to be disclosed on creation, got %v", got["secret"])
+	}
+	if got["created_at"] != "2023-07-21T00:00:00Z" {
+		t.Errorf("expected created_at to be 2023-07-21T00:00:00Z, got %v", got["created_at"])
+	}
+}
+
+func TestListSubscriptions_ResponseEncoder(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	resp := &endpoint.ListSubscriptionsResponse{
+		Subscriptions: []types.Subscription{
+			{ID: "id", Secret: "secret"},
+		},
+	}
+
+	err := ListSubscriptionsResponseEncoder(context.Background(), w, resp)
+	if err != nil {
+		t.Error("expected error to be nil, got", err)
+	}
+
+	var got []map[string]interface{}
+	_ = json.NewDecoder(w.Body).Decode(&got)
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 subscription, got %d", len(got))
+	}
+	if _, ok := got[0]["secret"]; ok {
+		t.Errorf("expected secret to be hidden when listing")
+	}
+}
@@ -0,0 +1,45 @@
+package types
+
+import "fmt"
+
+type ErrSubscriptionNotFound struct {
+	ID string
+}
+
+func (e *ErrSubscriptionNotFound) HttpCode() int {
+	return 404
+}
+
+func (e *ErrSubscriptionNotFound) Code() string {
+	return "subscription_not_found"
+}
+
+func (e *ErrSubscriptionNotFound) Error() string {
+	return fmt.Sprintf("webhook subscription %s not found", e.ID)
+}
+
+type ErrDeliveryNotFound struct {
+	ID string
+}
+
+func (e *ErrDeliveryNotFound) HttpCode() int {
+	return 404
+}
+
+func (e *ErrDeliveryNotFound) Code() string {
+	return "delivery_not_found"
+}
+
+func (e *ErrDeliveryNotFound) Error() string {
+	return fmt.Sprintf("webhook delivery %s not found", e.ID)
+}
+
+// ErrDeliveryFailed is returned by a single delivery attempt,
+// it is recorded in the delivery log and never surfaced to api clients
+type ErrDeliveryFailed struct {
+	StatusCode int
+}
+
+func (e *ErrDeliveryFailed) Error() string {
+	return fmt.Sprintf("receiver responded with status %d", e.StatusCode)
+}
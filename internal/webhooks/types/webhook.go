@@ -0,0 +1,81 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventPortfolioUpdated = "portfolio.updated"
+	EventPing             = "ping"
+)
+
+const (
+	DeliveryPending    = "pending"
+	DeliveryRetrying   = "retrying"
+	DeliverySucceeded  = "succeeded"
+	DeliveryDeadLetter = "dead_letter"
+)
+
+func ValidEvents() []string {
+	return []string{
+		EventPortfolioUpdated,
+		EventPing,
+	}
+}
+
+type Subscription struct {
+	ID       string
+	Username string
+	URL      string
+	Events   []string
+
+	// Secret is shared with the receiver to verify the payload signature
+	Secret string
+
+	CreatedAt time.Time
+}
+
+func (s *Subscription) Wants(event string) bool {
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// PortfolioUpdated is the data of a portfolio.updated event, the holdings after the change
+type PortfolioUpdated struct {
+	Username string   `json:"username"`
+	Symbols  []string `json:"symbols"`
+}
+
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	Username       string
+	Event          Event
+
+	Status   string
+	Attempts []DeliveryAttempt
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type DeliveryAttempt struct {
+	Attempt    int
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	At         time.Time
+}